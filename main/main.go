@@ -34,11 +34,12 @@ func getProviders() []domprovider.ProviderFactory {
 		&driverprovider.DatastoreProviderFactory{},
 		&driverprovider.AuthProviderFactory{},
 		&driverprovider.ApiProviderFactory{},
-		&driverprovider.DomainEventFactory{},
 		// MetricsProviderFactory is opt-in per service: include it to
 		// enable Prometheus collection and the /metrics scrape endpoint.
 		// The factory itself respects METRICS_ENABLED, so ops can flip
-		// metrics off without rebuilding the binary.
+		// metrics off without rebuilding the binary. Factories that use
+		// metrics declare it, so they run after it wherever it is listed.
 		&driverprovider.MetricsProviderFactory{},
+		&driverprovider.DomainEventFactory{},
 	}
 }
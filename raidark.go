@@ -1,15 +1,19 @@
 package raidark
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	apidomain "github.com/r0x16/Raidark/shared/api/domain"
 	moduleapi "github.com/r0x16/Raidark/shared/api/driver/modules"
 	"github.com/r0x16/Raidark/shared/cmd"
 	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
 	domevents "github.com/r0x16/Raidark/shared/events/domain"
+	domlifecycle "github.com/r0x16/Raidark/shared/lifecycle/domain"
 	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
 	driverprovider "github.com/r0x16/Raidark/shared/providers/driver"
 	svcproviders "github.com/r0x16/Raidark/shared/providers/services"
@@ -23,6 +27,7 @@ type Raidark struct {
 	hub       *domprovider.ProviderHub
 	datastore domdatastore.DatabaseProvider
 	events    domevents.DomainEventsProvider
+	lifecycle domlifecycle.Lifecycle
 }
 
 // New creates a new Raidark instance.
@@ -40,36 +45,57 @@ func New(providers []domprovider.ProviderFactory) *Raidark {
 	if domprovider.Exists[domevents.DomainEventsProvider](raidark.hub) {
 		raidark.events = domprovider.Get[domevents.DomainEventsProvider](raidark.hub)
 	}
+	raidark.lifecycle = domprovider.Get[domlifecycle.Lifecycle](raidark.hub)
 	return raidark
 }
 
 // registerModules registers the modules to the server
 // It adds the root module and the modules to the server.
-// EchoMetricsModule is added unconditionally; it short-circuits internally
-// when no MetricsProvider is registered on the hub, so services that did
-// not opt into metrics get a clean no-op without conditional plumbing here.
+// EchoMetricsModule and EchoEventsModule are added unconditionally; they
+// short-circuit internally when their provider is absent (or, for events,
+// when the active driver needs no tables), so services get a clean no-op
+// without conditional plumbing here.
 func (r *Raidark) registerModules(modules []apidomain.ApiModule) {
 	rootModule := r.RootModule("")
 	r.modules = append(r.modules, &moduleapi.EchoMainModule{EchoModule: rootModule})
 	r.modules = append(r.modules, &moduleapi.EchoMetricsModule{EchoModule: r.RootModule("")})
+	r.modules = append(r.modules, &moduleapi.EchoEventsModule{EchoModule: r.RootModule("")})
 	r.modules = append(r.modules, modules...)
 }
 
 // initializeProviders initializes the providers
 // It adds the base providers and the providers to the hub
 func (r *Raidark) initializeProviders(providers []domprovider.ProviderFactory) *domprovider.ProviderHub {
-	// Add base providers first - they're needed by other providers
+	// Add base providers first - they're needed by other providers. The
+	// lifecycle goes before the rest so they can append their stop hooks.
 	baseProviders := []domprovider.ProviderFactory{
 		&driverprovider.EnvProviderFactory{},
 		&driverprovider.LoggerProviderFactory{},
+		&driverprovider.LifecycleProviderFactory{},
 	}
 	allProviders := append(baseProviders, providers...)
 
 	hubFactory := svcproviders.NewProviderHubFactory()
-	hub := hubFactory.Create(allProviders)
+	hub, err := hubFactory.Create(allProviders)
+	if err != nil {
+		log.Fatalf("Error initializing providers: %v", err)
+	}
 	return hub
 }
 
+// initializeLifecycleHooks appends the hooks of the modules that declare
+// them. They are appended after the providers' hooks, so modules stop before
+// the providers they use.
+func (r *Raidark) initializeLifecycleHooks(modules []apidomain.ApiModule) {
+	for _, module := range modules {
+		if hooked, ok := module.(domlifecycle.HookedModule); ok {
+			for _, hook := range hooked.GetLifecycleHooks() {
+				r.lifecycle.Append(hook)
+			}
+		}
+	}
+}
+
 // initializeEventListeners initializes the event listeners
 // It adds the event listeners to the event provider
 func (r *Raidark) initializeEventListeners(modules []apidomain.ApiModule) {
@@ -82,16 +108,28 @@ func (r *Raidark) initializeEventListeners(modules []apidomain.ApiModule) {
 }
 
 // Run runs the application
-// It registers the modules, initializes the event listeners and executes the command
+// It registers the modules, initializes the event listeners and executes the command.
+// The lifecycle is stopped on the way out, which closes the providers of the
+// commands that do not handle the shutdown themselves.
 func (r *Raidark) Run(modules []apidomain.ApiModule) {
-	if r.datastore != nil {
-		defer r.datastore.Close()
-	}
+	defer r.stop()
 	r.registerModules(modules)
+	r.initializeLifecycleHooks(r.modules)
 	r.initializeEventListeners(r.modules)
 	cmd.Execute(r.hub, r.modules)
 }
 
+// stop runs the lifecycle stop hooks within SHUTDOWN_TIMEOUT_SECONDS
+func (r *Raidark) stop() {
+	env := domprovider.Get[domenv.EnvProvider](r.hub)
+	timeout := time.Duration(env.GetInt("SHUTDOWN_TIMEOUT_SECONDS", 30)) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := r.lifecycle.Stop(ctx); err != nil {
+		log.Printf("Shutdown completed with errors: %v", err)
+	}
+}
+
 // RootModule creates a new EchoModule
 // It is used to create the root module
 func (r *Raidark) RootModule(groupPath string) *moduleapi.EchoModule {
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+
 	domapi "github.com/r0x16/Raidark/shared/api/domain"
 	apiservices "github.com/r0x16/Raidark/shared/api/service"
 	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
@@ -22,13 +24,19 @@ func NewApi(hub *domprovider.ProviderHub, modules []domapi.ApiModule) *Api {
 	return api
 }
 
-func (a *Api) Run() {
+func (a *Api) Run() error {
 
 	a.registerModules(a.ApiProvider, a.Modules)
 
 	service := apiservices.NewApiService(a.ApiProvider, a.LogProvider)
-	service.Run()
+	return service.Run()
+
+}
 
+// Shutdown stops accepting requests and waits for the in-flight ones until
+// ctx expires
+func (a *Api) Shutdown(ctx context.Context) error {
+	return a.ApiProvider.Shutdown(ctx)
 }
 
 func (a *Api) setupProviders() {
@@ -1,11 +1,17 @@
 package domain
 
-import "github.com/r0x16/Raidark/shared/events/domain"
+import (
+	"github.com/r0x16/Raidark/shared/events/domain"
+	"github.com/r0x16/Raidark/shared/migration/domain/schema"
+	"github.com/r0x16/Raidark/shared/migration/domain/seed"
+)
 
 type ApiModule interface {
 	Name() string
 	Setup() error
 	GetModel() []any
 	GetSeedData() []any
+	GetSeedSets() []seed.SeedSet
+	GetMigrations() []schema.Migration
 	GetEventListeners() []domain.EventListener
 }
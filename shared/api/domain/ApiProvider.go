@@ -1,8 +1,13 @@
 package domain
 
+import "context"
+
 type ApiProvider interface {
 	Setup() error
 	Register(module ApiModule)
 	ProvidesModules() []ApiModule
 	Run() error
+	// Shutdown stops accepting connections and waits for the in-flight
+	// requests until ctx expires. Run returns nil once it completes.
+	Shutdown(ctx context.Context) error
 }
@@ -1,6 +1,8 @@
 package driver
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -203,7 +205,16 @@ func (e *EchoApiProvider) Run() error {
 		fmt.Printf("\n%-6s %s", r.Method, r.Path)
 	}
 
-	return e.Server.Start(":" + e.port)
+	err := e.Server.Start(":" + e.port)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown implements domain.ApiProvider.
+func (e *EchoApiProvider) Shutdown(ctx context.Context) error {
+	return e.Server.Shutdown(ctx)
 }
 
 // ProvidesModules implements domain.ApiProvider.
@@ -15,6 +15,8 @@ import (
 	envdomain "github.com/r0x16/Raidark/shared/env/domain"
 	eventdomain "github.com/r0x16/Raidark/shared/events/domain"
 	logdomain "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/migration/domain/schema"
+	"github.com/r0x16/Raidark/shared/migration/domain/seed"
 	providerdomain "github.com/r0x16/Raidark/shared/providers/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -156,4 +158,6 @@ func (m namedAPIModule) Name() string                                 { return m
 func (namedAPIModule) Setup() error                                   { return nil }
 func (namedAPIModule) GetModel() []any                                { return nil }
 func (namedAPIModule) GetSeedData() []any                             { return nil }
+func (namedAPIModule) GetSeedSets() []seed.SeedSet                    { return nil }
+func (namedAPIModule) GetMigrations() []schema.Migration              { return nil }
 func (namedAPIModule) GetEventListeners() []eventdomain.EventListener { return nil }
@@ -20,10 +20,24 @@ func (e *EchoAuthModule) Name() string {
 // Setup implements domain.ApiModule.
 func (e *EchoAuthModule) Setup() error {
 
+	e.Group.GET("/login", e.ActionInjection(controller.LoginAction))
 	e.Group.POST("/exchange", e.ActionInjection(controller.ExchangeAction))
 	e.Group.POST("/refresh", e.ActionInjection(controller.RefreshAction))
 	e.Group.POST("/logout", e.ActionInjection(controller.LogoutAction))
 
+	sessions := e.Group.Group("/sessions", e.RequireAuthentication())
+	sessions.GET("", e.ActionInjection(controller.ListSessionsAction))
+	sessions.DELETE("", e.ActionInjection(controller.RevokeAllSessionsAction))
+	sessions.DELETE("/:id", e.ActionInjection(controller.RevokeSessionAction))
+
+	userSessions := e.Group.Group("/users/:userId/sessions",
+		e.RequireAuthentication(),
+		e.RequirePermission("auth.sessions", "manage"),
+	)
+	userSessions.GET("", e.ActionInjection(controller.AdminListSessionsAction))
+	userSessions.DELETE("", e.ActionInjection(controller.AdminRevokeAllSessionsAction))
+	userSessions.DELETE("/:id", e.ActionInjection(controller.AdminRevokeSessionAction))
+
 	return nil
 }
 
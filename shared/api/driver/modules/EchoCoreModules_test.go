@@ -3,14 +3,30 @@
 package modules_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/labstack/echo/v4"
+	apidomain "github.com/r0x16/Raidark/shared/api/domain"
+	apidriver "github.com/r0x16/Raidark/shared/api/driver"
 	"github.com/r0x16/Raidark/shared/api/driver/modules"
 	authdomain "github.com/r0x16/Raidark/shared/auth/domain"
+	authdriver "github.com/r0x16/Raidark/shared/auth/driver"
+	datastoredomain "github.com/r0x16/Raidark/shared/datastore/domain"
+	datastoredriver "github.com/r0x16/Raidark/shared/datastore/driver"
+	envdriver "github.com/r0x16/Raidark/shared/env/driver"
+	eventsdomain "github.com/r0x16/Raidark/shared/events/domain"
+	eventsdriver "github.com/r0x16/Raidark/shared/events/driver"
+	healthdomain "github.com/r0x16/Raidark/shared/health/domain"
+	logdomain "github.com/r0x16/Raidark/shared/logger/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
 	providerdomain "github.com/r0x16/Raidark/shared/providers/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,20 +45,155 @@ func TestEchoMainModule_RegistersBaseRoutes(t *testing.T) {
 
 	require.Equal(t, http.StatusOK, recorder.Code)
 	assert.Equal(t, "OK", recorder.Body.String())
+	assertRouteRegistered(t, apiProvider.Server, http.MethodGet, "/livez")
+	assertRouteRegistered(t, apiProvider.Server, http.MethodGet, "/readyz")
 }
 
-func TestEchoAuthModule_RegistersAuthEndpoints(t *testing.T) {
+func TestEchoMainModule_ReadyzReportsFailingChecks(t *testing.T) {
+	hub, apiProvider := newMetricsModuleTestHub()
+	providerdomain.Register[dependency](hub, dependency{err: errors.New("connection refused")})
+	module := &modules.EchoMainModule{EchoModule: modules.NewEchoModule("", hub)}
+	require.NoError(t, module.Setup())
+
+	recorder := httptest.NewRecorder()
+	apiProvider.Server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	var report healthdomain.Report
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	assert.Equal(t, healthdomain.StatusDown, report.Status)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "dependency", report.Checks[0].Name)
+	assert.Equal(t, "connection refused", report.Checks[0].Error)
+
+	recorder = httptest.NewRecorder()
+	apiProvider.Server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status":"up"}`, recorder.Body.String())
+}
+
+func TestEchoMainModule_ReadyzPassesWithHealthyChecks(t *testing.T) {
 	hub, apiProvider := newMetricsModuleTestHub()
+	providerdomain.Register[dependency](hub, dependency{})
+	module := &modules.EchoMainModule{EchoModule: modules.NewEchoModule("", hub)}
+	require.NoError(t, module.Setup())
+
+	recorder := httptest.NewRecorder()
+	apiProvider.Server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"status":"up"`)
+}
+
+func TestEchoAuthModule_RegistersAuthEndpoints(t *testing.T) {
+	hub, apiProvider := newAuthModuleTestHub(t)
 	module := &modules.EchoAuthModule{EchoModule: modules.NewEchoModule("/auth", hub)}
 
 	require.NoError(t, module.Setup())
 
+	assertRouteRegistered(t, apiProvider.Server, http.MethodGet, "/auth/login")
 	assertRouteRegistered(t, apiProvider.Server, http.MethodPost, "/auth/exchange")
 	assertRouteRegistered(t, apiProvider.Server, http.MethodPost, "/auth/refresh")
 	assertRouteRegistered(t, apiProvider.Server, http.MethodPost, "/auth/logout")
+	assertRouteRegistered(t, apiProvider.Server, http.MethodGet, "/auth/sessions")
+	assertRouteRegistered(t, apiProvider.Server, http.MethodDelete, "/auth/sessions")
+	assertRouteRegistered(t, apiProvider.Server, http.MethodDelete, "/auth/sessions/:id")
+	assertRouteRegistered(t, apiProvider.Server, http.MethodGet, "/auth/users/:userId/sessions")
+	assertRouteRegistered(t, apiProvider.Server, http.MethodDelete, "/auth/users/:userId/sessions")
+	assertRouteRegistered(t, apiProvider.Server, http.MethodDelete, "/auth/users/:userId/sessions/:id")
 	assert.Len(t, module.GetModel(), 1)
 }
 
+func TestEchoAuthModule_LoginReturnsAuthorizationURL(t *testing.T) {
+	hub, apiProvider := newAuthModuleTestHub(t)
+	module := &modules.EchoAuthModule{EchoModule: modules.NewEchoModule("/auth", hub)}
+	require.NoError(t, module.Setup())
+
+	recorder := httptest.NewRecorder()
+	apiProvider.Server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/auth/login?state=abc", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var body authdomain.LoginResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, "abc", body.State)
+	assert.Contains(t, body.AuthorizationURL, "state=abc")
+
+	recorder = httptest.NewRecorder()
+	apiProvider.Server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Len(t, body.State, 32)
+}
+
+func TestEchoAuthModule_GuardsSessionEndpoints(t *testing.T) {
+	hub, apiProvider := newAuthModuleTestHub(t)
+	module := &modules.EchoAuthModule{EchoModule: modules.NewEchoModule("/auth", hub)}
+	require.NoError(t, module.Setup())
+	auth := providerdomain.Get[authdomain.AuthProvider](hub).(*authdriver.ArrayAuthProvider)
+	userToken, err := auth.MintToken("user1")
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		target string
+		token  string
+		status int
+	}{
+		"own sessions without token":    {target: "/auth/sessions", status: http.StatusBadRequest},
+		"own sessions with bad token":   {target: "/auth/sessions", token: "forged", status: http.StatusUnauthorized},
+		"other user sessions as a user": {target: "/auth/users/admin/sessions", token: userToken.AccessToken, status: http.StatusForbidden},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.token != "" {
+				request.Header.Set(echo.HeaderAuthorization, "Bearer "+tc.token)
+			}
+			recorder := httptest.NewRecorder()
+			apiProvider.Server.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tc.status, recorder.Code)
+		})
+	}
+}
+
+func TestEchoAuthModule_LogsSessionQueriesWithTheRequestTrace(t *testing.T) {
+	hub, apiProvider := newAuthModuleTestHub(t)
+	var queries bytes.Buffer
+	t.Setenv("DB_DATABASE", filepath.Join(t.TempDir(), "sessions.db"))
+	database := datastoredriver.NewGormSqliteDatabaseProvider(envdriver.NewEnvProvider())
+	database.LogProvider = obslog.NewWithWriter(&queries, obslog.FormatJSON, logdomain.Debug)
+	require.NoError(t, database.Connect())
+	t.Cleanup(func() { _ = database.Close() })
+	providerdomain.Register[datastoredomain.DatabaseProvider](hub, database)
+	providerdomain.Register[eventsdomain.DomainEventsProvider](hub, eventsdriver.NewInMemoryDomainEventsProvider(eventsdriver.InMemoryConfig{}, hub))
+	require.NoError(t, apiProvider.Setup())
+	module := &modules.EchoAuthModule{EchoModule: modules.NewEchoModule("/auth", hub)}
+	require.NoError(t, module.Setup())
+	token, err := providerdomain.Get[authdomain.AuthProvider](hub).(*authdriver.ArrayAuthProvider).MintToken("user1")
+	require.NoError(t, err)
+
+	// The sessions table is not migrated, so the query fails and is logged
+	request := httptest.NewRequest(http.MethodGet, "/auth/sessions", nil)
+	request.Header.Set(echo.HeaderAuthorization, "Bearer "+token.AccessToken)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	apiProvider.Server.ServeHTTP(httptest.NewRecorder(), request)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(queries.String())), &line))
+	assert.Equal(t, "Query failed", line["msg"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", line["trace_id"])
+	assert.NotEmpty(t, line["span_id"])
+}
+
+func TestEchoModuleRequireAuthentication_PanicsWithoutAuthProvider(t *testing.T) {
+	hub, _ := newMetricsModuleTestHub()
+	module := modules.NewEchoModule("/auth", hub)
+
+	assert.PanicsWithValue(t, "Auth provider is not set in EchoModule", func() {
+		_ = module.RequireAuthentication()
+	})
+}
+
 func TestEchoApiMainModule_RegistersPingAndAuthenticatedMe(t *testing.T) {
 	hub, apiProvider := newMetricsModuleTestHub()
 	module := &modules.EchoApiMainModule{EchoModule: modules.NewEchoModule("/api", hub)}
@@ -90,14 +241,66 @@ func TestEchoModuleActionInjection_PanicsWhenHubIsMissing(t *testing.T) {
 	})
 }
 
+func TestEchoModuleScopedActionInjection_HandsEachRequestItsOwnScope(t *testing.T) {
+	hub, apiProvider := newMetricsModuleTestHub()
+	providerdomain.RegisterNamed[tenantName](hub, "acme", "Acme Corp")
+	providerdomain.RegisterNamed[tenantName](hub, "globex", "Globex")
+	module := modules.NewEchoModule("", hub)
+
+	selectTenant := func(c echo.Context, scope *providerdomain.ProviderHub) error {
+		name, ok := providerdomain.LookupNamed[tenantName](scope, c.Request().Header.Get("X-Tenant"))
+		if !ok {
+			return echo.NewHTTPError(http.StatusNotFound, "unknown tenant")
+		}
+		providerdomain.Register[tenantName](scope, name)
+		return nil
+	}
+	module.Group.GET("/tenant", module.ScopedActionInjection(func(c echo.Context, scope *providerdomain.ProviderHub) error {
+		assert.True(t, providerdomain.Exists[apidomain.ApiProvider](scope))
+		return c.String(http.StatusOK, string(providerdomain.Get[tenantName](scope)))
+	}, selectTenant))
+
+	for tenant, expected := range map[string]string{"acme": "Acme Corp", "globex": "Globex"} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/tenant", nil)
+		request.Header.Set("X-Tenant", tenant)
+		apiProvider.Server.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, expected, recorder.Body.String())
+	}
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/tenant", nil)
+	request.Header.Set("X-Tenant", "initech")
+	apiProvider.Server.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.False(t, providerdomain.Exists[tenantName](hub))
+}
+
 func TestNewEchoModule_PanicsWhenApiProviderIsMissing(t *testing.T) {
 	hub := &providerdomain.ProviderHub{}
 
-	assert.PanicsWithError(t, "provider *reflect.rtype not found", func() {
+	assert.PanicsWithError(t, "provider domain.ApiProvider not found in hub; registered providers: none", func() {
 		_ = modules.NewEchoModule("", hub)
 	})
 }
 
+// tenantName is the provider the scoped action tests resolve per request
+type tenantName string
+
+// newAuthModuleTestHub adds the array auth provider and its authorizer to the
+// module test hub
+func newAuthModuleTestHub(t *testing.T) (*providerdomain.ProviderHub, *apidriver.EchoApiProvider) {
+	t.Helper()
+	hub, apiProvider := newMetricsModuleTestHub()
+	auth := authdriver.NewArrayAuthProvider()
+	require.NoError(t, auth.Initialize())
+	providerdomain.Register[authdomain.AuthProvider](hub, auth)
+	providerdomain.Register[authdomain.Authorizer](hub, authdriver.NewStaticAuthorizer(authdriver.DefaultArrayGrants()...))
+	return hub, apiProvider
+}
+
 func assertRouteRegistered(t *testing.T, server *echo.Echo, method string, path string) {
 	t.Helper()
 	_ = findRoute(t, server, method, path)
@@ -113,3 +316,15 @@ func findRoute(t *testing.T, server *echo.Echo, method string, path string) *ech
 	t.Fatalf("route %s %s was not registered", method, path)
 	return nil
 }
+
+// dependency contributes a readiness check failing with err
+type dependency struct {
+	err error
+}
+
+func (d dependency) HealthChecks() []healthdomain.Check {
+	return []healthdomain.Check{{
+		Name: "dependency",
+		Run:  func(context.Context) error { return d.err },
+	}}
+}
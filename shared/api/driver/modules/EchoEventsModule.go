@@ -0,0 +1,38 @@
+package modules
+
+import (
+	"github.com/r0x16/Raidark/shared/api/domain"
+	domevents "github.com/r0x16/Raidark/shared/events/domain"
+	modelevents "github.com/r0x16/Raidark/shared/events/domain/model"
+	driverevents "github.com/r0x16/Raidark/shared/events/driver"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// EchoEventsModule contributes the persistence models required by the active
+// DomainEventsProvider to dbmigrate. It mounts no routes.
+//
+// Like EchoMetricsModule it is always registered by raidark.go and decides
+// internally whether it has anything to do: with the in-memory driver it
+// declares no models, with the outbox driver it declares outbox_events.
+type EchoEventsModule struct {
+	*EchoModule
+}
+
+var _ domain.ApiModule = &EchoEventsModule{}
+
+// Name implements domain.ApiModule.
+func (e *EchoEventsModule) Name() string {
+	return "Events"
+}
+
+// GetModel implements domain.ApiModule.
+func (e *EchoEventsModule) GetModel() []any {
+	if !domprovider.Exists[domevents.DomainEventsProvider](e.Hub) {
+		return []any{}
+	}
+	provider := domprovider.Get[domevents.DomainEventsProvider](e.Hub)
+	if _, ok := provider.(*driverevents.OutboxDomainEventsProvider); ok {
+		return []any{&modelevents.OutboxEvent{}}
+	}
+	return []any{}
+}
@@ -2,11 +2,14 @@ package modules
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/r0x16/Raidark/shared/api/domain"
 	"github.com/r0x16/Raidark/shared/api/rest"
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domhealth "github.com/r0x16/Raidark/shared/health/domain"
+	driverhealth "github.com/r0x16/Raidark/shared/health/driver"
 	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
 )
 
@@ -30,6 +33,19 @@ func (e *EchoMainModule) Setup() error {
 		return c.String(http.StatusOK, "OK")
 	})
 
+	// /livez only tells the orchestrator the process is serving requests; it
+	// never consults a dependency, so an outage does not trigger restarts.
+	// /readyz runs the checks contributed by the providers and answers 503
+	// while any of them fails, taking the instance out of rotation.
+	checker := driverhealth.NewHealthChecker(driverhealth.HealthCheckerConfig{
+		Timeout:  time.Duration(env.GetInt("HEALTH_CHECK_TIMEOUT_MS", 2000)) * time.Millisecond,
+		CacheTTL: time.Duration(env.GetInt("HEALTH_CHECK_CACHE_MS", 1000)) * time.Millisecond,
+	}, driverhealth.CollectChecks(e.Hub)...)
+	e.Group.GET("/livez", e.livezAction)
+	e.Group.GET("/readyz", func(c echo.Context) error {
+		return e.readyzAction(c, checker)
+	})
+
 	// /csrf-token is only registered when CSRF_ENABLED=true. When disabled the route simply
 	// does not exist, so callers receive Echo's 404 (route not found) rather than a custom
 	// 404 from the handler. This matches the principle: disabled features leave no surface.
@@ -40,6 +56,22 @@ func (e *EchoMainModule) Setup() error {
 	return nil
 }
 
+// livezAction reports that the process is alive
+func (e *EchoMainModule) livezAction(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"status": domhealth.StatusUp})
+}
+
+// readyzAction returns the breakdown of the readiness checks, with 503 when
+// any of them failed
+func (e *EchoMainModule) readyzAction(c echo.Context, checker domhealth.Checker) error {
+	report := checker.Check(c.Request().Context())
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, report)
+}
+
 // csrfTokenAction returns the CSRF token stored in the Echo context by the CSRF middleware.
 // It is only reachable when CSRF_ENABLED=true (the route is not registered otherwise).
 // A nil token indicates a middleware wiring bug rather than a client error, hence 500.
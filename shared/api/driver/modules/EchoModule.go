@@ -8,6 +8,8 @@ import (
 	domauth "github.com/r0x16/Raidark/shared/auth/domain"
 	domevents "github.com/r0x16/Raidark/shared/events/domain"
 	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/migration/domain/schema"
+	"github.com/r0x16/Raidark/shared/migration/domain/seed"
 	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
 )
 
@@ -21,6 +23,10 @@ type EchoModule struct {
 
 type ActionCallback func(echo.Context, *domprovider.ProviderHub) error
 
+// ScopeInitializer prepares the request scope handed to a scoped action, for
+// example registering the tenant's DatabaseProvider as the default one
+type ScopeInitializer func(echo.Context, *domprovider.ProviderHub) error
+
 func NewEchoModule(groupPath string, hub *domprovider.ProviderHub) *EchoModule {
 	api := domprovider.Get[domapi.ApiProvider](hub)
 	echoServer := api.(*driverapi.EchoApiProvider).Server
@@ -34,27 +40,35 @@ func NewEchoModule(groupPath string, hub *domprovider.ProviderHub) *EchoModule {
 }
 
 func NewAuthenticatedEchoModule(groupPath string, hub *domprovider.ProviderHub) *EchoModule {
+	module := NewEchoModule(groupPath, hub)
+	module.Group.Use(module.RequireAuthentication())
+	return module
+}
 
-	if !domprovider.Exists[domauth.AuthProvider](hub) {
+// RequireAuthentication validates the bearer token of the request with the
+// registered AuthProvider and stores its claims as "user". Use it on single
+// routes or sub-groups of a module that is not authenticated as a whole.
+func (e *EchoModule) RequireAuthentication() echo.MiddlewareFunc {
+	if e.Hub == nil || !domprovider.Exists[domauth.AuthProvider](e.Hub) {
 		panic("Auth provider is not set in EchoModule")
 	}
+	if e.Auth == nil {
+		e.Auth = domprovider.Get[domauth.AuthProvider](e.Hub)
+	}
 
-	module := NewEchoModule(groupPath, hub)
-	module.Auth = domprovider.Get[domauth.AuthProvider](hub)
-	module.Group.Use(middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+	return middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
 		KeyLookup:  "header:" + echo.HeaderAuthorization,
 		AuthScheme: "Bearer",
 		Validator: func(key string, c echo.Context) (bool, error) {
-			token, err := module.Auth.ParseToken(key)
+			token, err := e.Auth.ParseToken(key)
 			if err != nil {
-				module.Log.Error("Error parsing token", map[string]any{"error": err})
+				e.Log.Error("Error parsing token", map[string]any{"error": err})
 				return false, err
 			}
 			c.Set("user", token)
 			return true, nil
 		},
-	}))
-	return module
+	})
 }
 
 func (e *EchoModule) ActionInjection(callback ActionCallback) echo.HandlerFunc {
@@ -67,6 +81,26 @@ func (e *EchoModule) ActionInjection(callback ActionCallback) echo.HandlerFunc {
 	}
 }
 
+// ScopedActionInjection is ActionInjection with a fresh scope of the module
+// hub per request. The initializers run in order before the callback; what
+// they register is only visible to this request and the rest is resolved
+// from the module hub. An initializer error is returned as the request error.
+func (e *EchoModule) ScopedActionInjection(callback ActionCallback, initializers ...ScopeInitializer) echo.HandlerFunc {
+	if e.Hub == nil {
+		panic("Hub is not set in EchoModule")
+	}
+
+	return func(c echo.Context) error {
+		scope := e.Hub.Scope()
+		for _, initialize := range initializers {
+			if err := initialize(c, scope); err != nil {
+				return err
+			}
+		}
+		return callback(c, scope)
+	}
+}
+
 func (e *EchoModule) Setup() error {
 	return nil
 }
@@ -79,6 +113,14 @@ func (e *EchoModule) GetSeedData() []any {
 	return []any{}
 }
 
+func (e *EchoModule) GetSeedSets() []seed.SeedSet {
+	return []seed.SeedSet{}
+}
+
+func (e *EchoModule) GetMigrations() []schema.Migration {
+	return []schema.Migration{}
+}
+
 func (e *EchoModule) GetEventListeners() []domevents.EventListener {
 	return []domevents.EventListener{}
 }
@@ -0,0 +1,89 @@
+package modules
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/r0x16/Raidark/shared/api/rest"
+	domauth "github.com/r0x16/Raidark/shared/auth/domain"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// Policy is a custom authorization rule evaluated against the request and the
+// authenticated claims. Return false to deny with 403, or an error when the
+// decision cannot be made.
+type Policy func(c echo.Context, claims *domauth.Claims) (bool, error)
+
+// RequireRoles allows the request when the authenticated user holds at least
+// one of roles.
+//
+//	m.Group.DELETE("/orders/:id", handler, m.RequireRoles("admin", "support"))
+func (e *EchoModule) RequireRoles(roles ...string) echo.MiddlewareFunc {
+	return e.guard(func(_ echo.Context, claims *domauth.Claims) (bool, error) {
+		return claims.HasAnyRole(roles...), nil
+	})
+}
+
+// RequirePermission allows the request when the registered Authorizer grants
+// action on resource to the authenticated user.
+//
+//	m.Group.GET("/orders", handler, m.RequirePermission("orders", "read"))
+func (e *EchoModule) RequirePermission(resource, action string) echo.MiddlewareFunc {
+	authorizer := e.authorizer()
+	return e.guard(func(c echo.Context, claims *domauth.Claims) (bool, error) {
+		return authorizer.Authorize(c.Request().Context(), claims, resource, action)
+	})
+}
+
+// RequirePolicy allows the request when policy returns true
+func (e *EchoModule) RequirePolicy(policy Policy) echo.MiddlewareFunc {
+	return e.guard(policy)
+}
+
+// guard wraps a policy into a middleware. It must run after the bearer token
+// middleware (RequireAuthentication), which stores the claims as "user".
+// Requests without claims get 401; denials get the standard 403 envelope.
+func (e *EchoModule) guard(policy Policy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := c.Get("user").(*domauth.Claims)
+			if !ok || claims == nil {
+				return rest.RenderError(c, http.StatusUnauthorized, &rest.RESTError{
+					Code:    "auth.unauthenticated",
+					Message: "Authentication is required to access this resource.",
+				})
+			}
+
+			allowed, err := policy(c, claims)
+			if err != nil {
+				e.Log.Error("Error evaluating authorization", map[string]any{
+					"error": err,
+					"user":  claims.Username,
+					"path":  c.Path(),
+				})
+				status, restErr := rest.MapError(err)
+				return rest.RenderError(c, status, restErr)
+			}
+			if !allowed {
+				e.Log.Warning("Authorization denied", map[string]any{
+					"user": claims.Username,
+					"path": c.Path(),
+				})
+				status, restErr := rest.MapError(rest.ErrForbidden)
+				return rest.RenderError(c, status, restErr)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func (e *EchoModule) authorizer() domauth.Authorizer {
+	if e.Hub == nil {
+		panic("Hub is not set in EchoModule")
+	}
+	if !domprovider.Exists[domauth.Authorizer](e.Hub) {
+		panic("Authorizer is not set in EchoModule")
+	}
+	return domprovider.Get[domauth.Authorizer](e.Hub)
+}
@@ -0,0 +1,127 @@
+package modules_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/r0x16/Raidark/shared/api/driver/modules"
+	authdomain "github.com/r0x16/Raidark/shared/auth/domain"
+	authdriver "github.com/r0x16/Raidark/shared/auth/driver"
+	providerdomain "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEchoModuleRequireRoles_AllowsMatchingRoleAndDeniesOthers(t *testing.T) {
+	hub, apiProvider := newMetricsModuleTestHub()
+	module := newGuardedModule(hub, &authdomain.Claims{Username: "bob", Roles: []string{"support"}})
+	module.Group.GET("/support", okHandler, module.RequireRoles("admin", "support"))
+	module.Group.GET("/billing", okHandler, module.RequireRoles("billing"))
+
+	assert.Equal(t, http.StatusOK, serve(apiProvider.Server, "/api/support").Code)
+
+	recorder := serve(apiProvider.Server, "/api/billing")
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, "common.forbidden", errorCode(t, recorder))
+}
+
+func TestEchoModuleRequirePermission_DelegatesToAuthorizer(t *testing.T) {
+	hub, apiProvider := newMetricsModuleTestHub()
+	providerdomain.Register[authdomain.Authorizer](hub, authdriver.NewStaticAuthorizer(
+		authdriver.StaticGrant{Role: "clerk", Resource: "orders", Action: "read"},
+	))
+	module := newGuardedModule(hub, &authdomain.Claims{Username: "carol", Roles: []string{"clerk"}})
+	module.Group.GET("/orders", okHandler, module.RequirePermission("orders", "read"))
+	module.Group.POST("/orders", okHandler, module.RequirePermission("orders", "write"))
+
+	assert.Equal(t, http.StatusOK, serve(apiProvider.Server, "/api/orders").Code)
+
+	recorder := httptest.NewRecorder()
+	apiProvider.Server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/orders", nil))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestEchoModuleRequirePermission_AuthorizerErrorRendersInternalError(t *testing.T) {
+	hub, apiProvider := newMetricsModuleTestHub()
+	providerdomain.Register[authdomain.Authorizer](hub, failingAuthorizer{})
+	module := newGuardedModule(hub, &authdomain.Claims{Username: "carol"})
+	module.Group.GET("/orders", okHandler, module.RequirePermission("orders", "read"))
+
+	recorder := serve(apiProvider.Server, "/api/orders")
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "internal.unexpected", errorCode(t, recorder))
+}
+
+func TestEchoModuleRequirePolicy_UsesRequestAndClaims(t *testing.T) {
+	hub, apiProvider := newMetricsModuleTestHub()
+	module := newGuardedModule(hub, &authdomain.Claims{Username: "dave"})
+	ownProfile := func(c echo.Context, claims *authdomain.Claims) (bool, error) {
+		return c.Param("username") == claims.Username, nil
+	}
+	module.Group.GET("/users/:username", okHandler, module.RequirePolicy(ownProfile))
+
+	assert.Equal(t, http.StatusOK, serve(apiProvider.Server, "/api/users/dave").Code)
+	assert.Equal(t, http.StatusForbidden, serve(apiProvider.Server, "/api/users/erin").Code)
+}
+
+func TestEchoModuleGuards_RejectRequestsWithoutClaims(t *testing.T) {
+	hub, apiProvider := newMetricsModuleTestHub()
+	module := modules.NewEchoModule("/api", hub)
+	module.Group.GET("/admin", okHandler, module.RequireRoles("admin"))
+
+	recorder := serve(apiProvider.Server, "/api/admin")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, "auth.unauthenticated", errorCode(t, recorder))
+}
+
+func TestEchoModuleRequirePermission_PanicsWithoutAuthorizer(t *testing.T) {
+	hub, _ := newMetricsModuleTestHub()
+	module := modules.NewEchoModule("/api", hub)
+
+	assert.PanicsWithValue(t, "Authorizer is not set in EchoModule", func() {
+		_ = module.RequirePermission("orders", "read")
+	})
+}
+
+func newGuardedModule(hub *providerdomain.ProviderHub, claims *authdomain.Claims) *modules.EchoModule {
+	module := modules.NewEchoModule("/api", hub)
+	module.Group.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user", claims)
+			return next(c)
+		}
+	})
+	return module
+}
+
+func okHandler(c echo.Context) error {
+	return c.String(http.StatusOK, "ok")
+}
+
+func serve(server *echo.Echo, target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder
+}
+
+func errorCode(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	return body.Error.Code
+}
+
+type failingAuthorizer struct{}
+
+func (failingAuthorizer) Authorize(context.Context, *authdomain.Claims, string, string) (bool, error) {
+	return false, errors.New("permission backend unavailable")
+}
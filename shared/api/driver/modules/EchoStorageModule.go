@@ -2,16 +2,17 @@ package modules
 
 import (
 	domapi "github.com/r0x16/Raidark/shared/api/domain"
+	domevents "github.com/r0x16/Raidark/shared/events/domain"
 	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
 	domstorage "github.com/r0x16/Raidark/shared/storage/domain"
 	storagedriver "github.com/r0x16/Raidark/shared/storage/driver"
 )
 
-// EchoStorageModule registers the internal signed-URL handler for the filesystem
-// storage driver. It short-circuits silently when no StorageProvider is in the hub
-// (services that don't use storage pay zero overhead) or when the active driver is
-// not a FilesystemStorageProvider (cloud drivers sign externally and don't need the
-// internal handler).
+// EchoStorageModule registers the internal signed-URL, public-file and
+// signed-upload handlers for the filesystem storage driver. It short-circuits silently when no
+// StorageProvider is in the hub (services that don't use storage pay zero
+// overhead) or when the active driver is not a FilesystemStorageProvider (cloud
+// drivers sign externally and don't need the internal handlers).
 type EchoStorageModule struct {
 	*EchoModule
 }
@@ -23,7 +24,9 @@ func (e *EchoStorageModule) Name() string {
 	return "Storage"
 }
 
-// Setup registers GET /_storage/* when the filesystem driver is active.
+// Setup registers GET /_storage/*, GET /_public/* and POST /_storage/uploads
+// when the filesystem driver is active. Uploads publish an ObjectWasUploaded event when
+// a DomainEventsProvider is in the hub.
 func (e *EchoStorageModule) Setup() error {
 	if !domprovider.Exists[domstorage.StorageProvider](e.Hub) {
 		return nil
@@ -35,6 +38,10 @@ func (e *EchoStorageModule) Setup() error {
 		// do not require this internal handler.
 		return nil
 	}
+	events, _ := domprovider.Lookup[domevents.DomainEventsProvider](e.Hub)
+
 	e.Group.GET("/_storage/*", storagedriver.NewSignedUrlHandler(fsProvider))
+	e.Group.GET("/_public/*", storagedriver.NewPublicFileHandler(fsProvider))
+	e.Group.POST("/_storage/uploads", storagedriver.NewSignedUploadHandler(fsProvider, events))
 	return nil
 }
@@ -3,6 +3,7 @@ package rest
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/r0x16/Raidark/shared/ids"
+	"github.com/r0x16/Raidark/shared/observability"
 )
 
 const (
@@ -16,8 +17,9 @@ const (
 // CorrelationID returns an Echo middleware that propagates a request-scoped
 // correlation ID across service boundaries. The middleware reads X-Correlation-ID
 // from the incoming request; if absent or empty, it generates a new UUIDv7.
-// The resolved ID is stored in echo.Context and echoed back in the response header
-// so callers can use it to correlate distributed traces and log entries.
+// The resolved ID is stored in echo.Context and in the request context, and echoed
+// back in the response header so callers can use it to correlate distributed traces
+// and log entries.
 func CorrelationID() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -32,6 +34,7 @@ func CorrelationID() echo.MiddlewareFunc {
 				id = generated
 			}
 			c.Set(correlationIDKey, id)
+			c.SetRequest(c.Request().WithContext(observability.WithCorrelationID(c.Request().Context(), id)))
 			c.Response().Header().Set(correlationIDHeader, id)
 			return next(c)
 		}
@@ -10,12 +10,14 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/r0x16/Raidark/shared/api/rest"
 	"github.com/r0x16/Raidark/shared/ids"
+	"github.com/r0x16/Raidark/shared/observability"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 // TestCorrelationID_headerPresentIsRespected verifies callers can provide their
-// own correlation ID and see the same value in handlers and response headers.
+// own correlation ID and see the same value in handlers, the request context
+// and response headers.
 func TestCorrelationID_headerPresentIsRespected(t *testing.T) {
 	const inputCorrelationID = "client-provided-correlation"
 
@@ -52,6 +54,7 @@ func runCorrelationMiddleware(t *testing.T, headers map[string]string) (*httptes
 	var captured string
 	handler := rest.CorrelationID()(func(c echo.Context) error {
 		captured = rest.GetCorrelationID(c)
+		assert.Equal(t, captured, observability.GetCorrelationID(c.Request().Context()))
 		return c.NoContent(http.StatusNoContent)
 	})
 	require.NoError(t, handler(context))
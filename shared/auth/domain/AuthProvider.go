@@ -1,5 +1,11 @@
 package domain
 
+import "errors"
+
+// ErrUnsupported is wrapped by providers for operations their identity
+// provider does not offer, such as user management without an admin API
+var ErrUnsupported = errors.New("operation not supported by the auth provider")
+
 // AuthProvider defines the interface for authentication providers
 type AuthProvider interface {
 	// Initialize the auth provider with configuration
@@ -0,0 +1,15 @@
+package domain
+
+import "context"
+
+// AuthorizerWildcard matches any resource or action in a grant
+const AuthorizerWildcard = "*"
+
+// Authorizer decides whether an authenticated subject may perform an action
+// on a resource. Implementations must be safe for concurrent use.
+type Authorizer interface {
+	// Authorize reports whether claims grant action on resource. A false
+	// result with a nil error is a regular denial; an error means the
+	// decision could not be made.
+	Authorize(ctx context.Context, claims *Claims, resource, action string) (bool, error)
+}
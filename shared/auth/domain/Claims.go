@@ -15,3 +15,15 @@ type Claims struct {
 	IssuedAt     int64
 	NotBefore    int64
 }
+
+// HasAnyRole reports whether the claims hold at least one of roles
+func (c *Claims) HasAnyRole(roles ...string) bool {
+	for _, held := range c.Roles {
+		for _, role := range roles {
+			if held == role {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+package domain
+
+// LoginResponse represents the response structure for starting a login.
+// The client keeps State and, on the callback, checks that the issuer
+// returned it before posting it to the exchange endpoint with the code.
+type LoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// SessionResponse describes one session of the user. The session cookie
+// value is never exposed; ID is the handle used to revoke it.
+type SessionResponse struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	UserAgent     string    `json:"user_agent"`
+	IPAddress     string    `json:"ip_address"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	RefreshExpiry time.Time `json:"refresh_expiry"`
+	Current       bool      `json:"current"`
+}
+
+// SessionListResponse represents the response structure for session listing
+type SessionListResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+// RevokeSessionsResponse represents the response structure for session revocation
+type RevokeSessionsResponse struct {
+	Message string `json:"message"`
+	Revoked int    `json:"revoked"`
+}
@@ -0,0 +1,37 @@
+package event_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/auth/domain/event"
+	"github.com/r0x16/Raidark/shared/auth/domain/model"
+	"github.com/r0x16/Raidark/shared/events/domain"
+	driverevents "github.com/r0x16/Raidark/shared/events/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionEvents_leaveTheTokensOut(t *testing.T) {
+	session := &model.AuthSession{
+		SessionID:    "session-1",
+		UserID:       "user-42",
+		RefreshToken: "refresh-token-secret",
+		AccessToken:  "access-token-secret",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	registry := driverevents.NewEventRegistry(&event.SessionWasCreated{}, &event.SessionWasDeleted{})
+
+	for _, sessionEvent := range []domain.DomainEvent{
+		&event.SessionWasCreated{Session: session},
+		&event.SessionWasDeleted{Session: session, LogoutAt: time.Now()},
+	} {
+		payload, err := registry.Marshal(context.Background(), sessionEvent)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(payload), "user-42", sessionEvent.Name())
+		assert.NotContains(t, string(payload), "refresh-token-secret", sessionEvent.Name())
+		assert.NotContains(t, string(payload), "access-token-secret", sessionEvent.Name())
+	}
+}
@@ -4,16 +4,21 @@ import (
 	"time"
 
 	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	// Registers the "encrypted" serializer used by the token columns
+	_ "github.com/r0x16/Raidark/shared/datastore/encryption"
 )
 
-// AuthSession represents a user authentication session in the database
+// AuthSession represents a user authentication session in the database.
+// RefreshToken and AccessToken are encrypted at rest when a keyring is
+// configured (see the encryption package), and never serialized to JSON, so
+// the session events do not carry them into the outbox or the brokers.
 type AuthSession struct {
 	domdatastore.BaseModel
 	SessionID     string    `gorm:"uniqueIndex;type:varchar(255);not null" json:"session_id"`
 	UserID        string    `gorm:"type:varchar(255);not null" json:"user_id"`
 	Username      string    `gorm:"type:varchar(255);not null" json:"username"`
-	RefreshToken  string    `gorm:"type:text;not null" json:"refresh_token"`
-	AccessToken   string    `gorm:"type:text;not null" json:"access_token"`
+	RefreshToken  string    `gorm:"type:text;not null;serializer:encrypted" json:"-"`
+	AccessToken   string    `gorm:"type:text;not null;serializer:encrypted" json:"-"`
 	ExpiresAt     time.Time `gorm:"type:timestamp;not null" json:"expires_at"`
 	RefreshExpiry time.Time `gorm:"type:timestamp;not null" json:"refresh_expiry"`
 	UserAgent     string    `gorm:"type:varchar(500)" json:"user_agent"`
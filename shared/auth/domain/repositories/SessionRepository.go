@@ -1,6 +1,10 @@
 package repositories
 
-import "github.com/r0x16/Raidark/shared/auth/domain/model"
+import (
+	"time"
+
+	"github.com/r0x16/Raidark/shared/auth/domain/model"
+)
 
 // SessionRepository defines the interface for session data access operations
 type SessionRepository interface {
@@ -25,6 +29,10 @@ type SessionRepository interface {
 	// Delete all expired sessions
 	DeleteExpiredSessions() error
 
+	// Permanently delete up to limit sessions whose refresh token expired
+	// before the given time, returning how many were deleted
+	DeleteExpiredBatch(before time.Time, limit int) (int, error)
+
 	// Find sessions by user ID
 	FindByUserID(userID string) ([]*model.AuthSession, error)
 
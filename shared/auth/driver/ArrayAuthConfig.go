@@ -0,0 +1,49 @@
+package driver
+
+import (
+	"crypto/rand"
+	"os"
+	"time"
+)
+
+// ArrayAuthConfig holds the configuration of the in-memory auth provider
+type ArrayAuthConfig struct {
+	// FixturePath points to a YAML or JSON file with the users. Empty loads
+	// the built-in test users.
+	FixturePath string
+	// SigningKey signs the dev tokens with HS256. A random key is generated
+	// when empty, so tokens do not survive a restart.
+	SigningKey      []byte
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// NewArrayAuthConfig returns the default configuration: built-in users, a
+// random signing key, one hour access tokens and 30 day refresh tokens
+func NewArrayAuthConfig() *ArrayAuthConfig {
+	return &ArrayAuthConfig{
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: 30 * 24 * time.Hour,
+	}
+}
+
+// NewArrayAuthConfigFromEnv creates a new ArrayAuthConfig from environment variables
+func NewArrayAuthConfigFromEnv() *ArrayAuthConfig {
+	defaults := NewArrayAuthConfig()
+	return &ArrayAuthConfig{
+		FixturePath:     os.Getenv("AUTH_ARRAY_FIXTURE"),
+		SigningKey:      []byte(os.Getenv("AUTH_ARRAY_SIGNING_KEY")),
+		AccessTokenTTL:  getEnvSecondsOrDefault("AUTH_ARRAY_TOKEN_TTL_SECONDS", defaults.AccessTokenTTL),
+		RefreshTokenTTL: getEnvSecondsOrDefault("AUTH_ARRAY_REFRESH_TTL_SECONDS", defaults.RefreshTokenTTL),
+	}
+}
+
+// signingKey returns the configured key or a random one
+func (c *ArrayAuthConfig) signingKey() []byte {
+	if len(c.SigningKey) > 0 {
+		return c.SigningKey
+	}
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+}
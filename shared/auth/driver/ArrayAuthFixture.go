@@ -0,0 +1,106 @@
+package driver
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/r0x16/Raidark/shared/auth/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// ArrayAuthFixture is the file format of the test identities loaded by
+// ArrayAuthProvider. JSON files are accepted too, as JSON is valid YAML.
+//
+//	organization: acme
+//	users:
+//	  - name: carol
+//	    password: secret
+//	    email: carol@acme.test
+//	    roles: [manager]
+type ArrayAuthFixture struct {
+	Organization string             `yaml:"organization"`
+	Users        []ArrayFixtureUser `yaml:"users"`
+}
+
+// ArrayFixtureUser is one identity of an ArrayAuthFixture
+type ArrayFixtureUser struct {
+	Name        string   `yaml:"name"`
+	Password    string   `yaml:"password"`
+	DisplayName string   `yaml:"displayName"`
+	FirstName   string   `yaml:"firstName"`
+	LastName    string   `yaml:"lastName"`
+	Email       string   `yaml:"email"`
+	Type        string   `yaml:"type"`
+	Roles       []string `yaml:"roles"`
+	Admin       bool     `yaml:"admin"`
+	Forbidden   bool     `yaml:"forbidden"`
+}
+
+// LoadArrayAuthFixture reads a fixture file and converts it to users
+func LoadArrayAuthFixture(path string) ([]*domain.User, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth fixture: %w", err)
+	}
+
+	fixture := ArrayAuthFixture{}
+	if err := yaml.Unmarshal(content, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to decode auth fixture %s: %w", path, err)
+	}
+	return fixture.toUsers()
+}
+
+func (f ArrayAuthFixture) toUsers() ([]*domain.User, error) {
+	organization := f.Organization
+	if organization == "" {
+		organization = "test-org"
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	users := make([]*domain.User, 0, len(f.Users))
+	seen := make(map[string]bool, len(f.Users))
+	for _, entry := range f.Users {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("auth fixture user without name")
+		}
+		if seen[entry.Name] {
+			return nil, fmt.Errorf("auth fixture user %q is duplicated", entry.Name)
+		}
+		seen[entry.Name] = true
+
+		userType := entry.Type
+		if userType == "" {
+			userType = "normal-user"
+		}
+		displayName := entry.DisplayName
+		if displayName == "" {
+			displayName = entry.Name
+		}
+
+		roles := make([]*casdoorsdk.Role, 0, len(entry.Roles))
+		for _, role := range entry.Roles {
+			roles = append(roles, &casdoorsdk.Role{Owner: organization, Name: role})
+		}
+
+		users = append(users, &domain.User{User: casdoorsdk.User{
+			Owner:         organization,
+			Name:          entry.Name,
+			Id:            fmt.Sprintf("%s-id", entry.Name),
+			CreatedTime:   now,
+			UpdatedTime:   now,
+			Type:          userType,
+			Password:      entry.Password,
+			DisplayName:   displayName,
+			FirstName:     entry.FirstName,
+			LastName:      entry.LastName,
+			Email:         entry.Email,
+			IsAdmin:       entry.Admin,
+			IsForbidden:   entry.Forbidden,
+			EmailVerified: entry.Email != "",
+			Roles:         roles,
+		}})
+	}
+	return users, nil
+}
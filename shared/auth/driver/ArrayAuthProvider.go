@@ -3,31 +3,81 @@ package driver
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/r0x16/Raidark/shared/auth/domain"
 )
 
-// ArrayAuthProvider implements the AuthProvider interface using an in-memory array
+// Issuer and audience of the dev tokens minted by ArrayAuthProvider
+const (
+	arrayTokenIssuer   = "array-auth-provider"
+	arrayTokenAudience = "raidark-dev"
+)
+
+// ArrayAuthProvider implements the AuthProvider interface using an in-memory array.
+//
+// It mints real HS256 dev tokens for its users and validates them in
+// ParseToken, so expired, forged and unknown-user tokens fail the same way
+// they do with a real identity provider.
 type ArrayAuthProvider struct {
-	users []*domain.User
+	config     *ArrayAuthConfig
+	users      []*domain.User
+	signingKey []byte
+	verifier   *JWTVerifier
+}
+
+// arrayTokenClaims are the claims of the dev tokens. TokenUse tells access
+// and refresh tokens apart.
+type arrayTokenClaims struct {
+	TokenUse string `json:"token_use"`
+	jwt.RegisteredClaims
 }
 
 // Verify interface implementation
 var _ domain.AuthProvider = &ArrayAuthProvider{}
 
-// NewArrayAuthProvider creates a new ArrayAuthProvider instance
+// NewArrayAuthProvider creates a new ArrayAuthProvider instance with the
+// default configuration
 func NewArrayAuthProvider() *ArrayAuthProvider {
+	return NewArrayAuthProviderWithConfig(NewArrayAuthConfig())
+}
+
+// NewArrayAuthProviderWithConfig creates a new ArrayAuthProvider instance
+func NewArrayAuthProviderWithConfig(config *ArrayAuthConfig) *ArrayAuthProvider {
+	signingKey := config.signingKey()
 	return &ArrayAuthProvider{
-		users: make([]*domain.User, 0),
+		config:     config,
+		users:      make([]*domain.User, 0),
+		signingKey: signingKey,
+		verifier: NewJWTVerifier(JWTVerifierConfig{
+			Issuer:     arrayTokenIssuer,
+			Audience:   []string{arrayTokenAudience},
+			Algorithms: []string{jwt.SigningMethodHS256.Alg()},
+		}, nil, signingKey),
 	}
 }
 
-// Initialize the auth provider with configuration
+// NewArrayAuthProviderFromEnv creates a new ArrayAuthProvider from environment variables
+func NewArrayAuthProviderFromEnv() *ArrayAuthProvider {
+	return NewArrayAuthProviderWithConfig(NewArrayAuthConfigFromEnv())
+}
+
+// Initialize loads the users from the configured fixture, or the built-in
+// test users without one
 func (a *ArrayAuthProvider) Initialize() error {
-	// Create some test users
-	a.createTestUsers()
+	if a.config.FixturePath == "" {
+		a.createTestUsers()
+		return nil
+	}
+
+	users, err := LoadArrayAuthFixture(a.config.FixturePath)
+	if err != nil {
+		return err
+	}
+	a.users = users
 	return nil
 }
 
@@ -59,6 +109,7 @@ func (a *ArrayAuthProvider) createTestUsers() {
 				CreatedIp:      "127.0.0.1",
 				LastSigninTime: time.Now().Format(time.RFC3339),
 				LastSigninIp:   "127.0.0.1",
+				Roles:          []*casdoorsdk.Role{{Owner: "test-org", Name: "admin"}},
 			},
 		},
 		{
@@ -86,6 +137,7 @@ func (a *ArrayAuthProvider) createTestUsers() {
 				CreatedIp:      "127.0.0.1",
 				LastSigninTime: time.Now().Format(time.RFC3339),
 				LastSigninIp:   "127.0.0.1",
+				Roles:          []*casdoorsdk.Role{{Owner: "test-org", Name: "user"}},
 			},
 		},
 		{
@@ -113,6 +165,7 @@ func (a *ArrayAuthProvider) createTestUsers() {
 				CreatedIp:      "127.0.0.1",
 				LastSigninTime: time.Now().Format(time.RFC3339),
 				LastSigninIp:   "127.0.0.1",
+				Roles:          []*casdoorsdk.Role{{Owner: "test-org", Name: "user"}},
 			},
 		},
 	}
@@ -126,45 +179,135 @@ func (a *ArrayAuthProvider) GetAuthURL(state string) string {
 	return fmt.Sprintf("http://localhost:8080/mock-auth?state=%s", state)
 }
 
-// GetToken exchanges authorization code for access token
+// GetToken exchanges authorization code for access token. The array
+// provider has no login page: the code carries the credentials as
+// "username:password".
 func (a *ArrayAuthProvider) GetToken(code, state string) (*domain.Token, error) {
-	// For testing purposes, return a mock token
-	return &domain.Token{
-		AccessToken:  "mock-access-token-" + code,
-		TokenType:    "Bearer",
-		RefreshToken: "mock-refresh-token-" + code,
-		Expiry:       time.Now().Add(1 * time.Hour),
-	}, nil
+	username, password, ok := strings.Cut(code, ":")
+	if !ok {
+		return nil, errors.New("authorization code must be username:password")
+	}
+
+	user, err := a.GetUser(username)
+	if err != nil || user.Password != password {
+		return nil, errors.New("invalid credentials")
+	}
+	return a.mint(user)
+}
+
+// MintToken issues a token pair for username without checking its password.
+// Tests use it to authenticate as any fixture user.
+func (a *ArrayAuthProvider) MintToken(username string) (*domain.Token, error) {
+	user, err := a.GetUser(username)
+	if err != nil {
+		return nil, err
+	}
+	return a.mint(user)
 }
 
 // RefreshToken refreshes OAuth token using refresh token
 func (a *ArrayAuthProvider) RefreshToken(refreshToken string) (*domain.Token, error) {
-	// For testing purposes, return a new mock token
+	user, _, err := a.verifyClaims(refreshToken, "refresh")
+	if err != nil {
+		return nil, err
+	}
+	return a.mint(user)
+}
+
+// ParseToken validates a dev access token and returns the claims of its user
+func (a *ArrayAuthProvider) ParseToken(token string) (*domain.Claims, error) {
+	user, claims, err := a.verifyClaims(token, "access")
+	if err != nil {
+		return nil, err
+	}
+
+	domainClaims := &domain.Claims{
+		Username:     user.Name,
+		Name:         user.DisplayName,
+		Email:        user.Email,
+		Organization: user.Owner,
+		Type:         user.Type,
+		Issuer:       claims.Issuer,
+		Subject:      claims.Subject,
+		Audience:     arrayTokenAudience,
+		ExpiresAt:    claims.ExpiresAt.Unix(),
+	}
+	if claims.IssuedAt != nil {
+		domainClaims.IssuedAt = claims.IssuedAt.Unix()
+	}
+	if claims.NotBefore != nil {
+		domainClaims.NotBefore = claims.NotBefore.Unix()
+	}
+	for _, role := range user.Roles {
+		if role != nil && role.Name != "" {
+			domainClaims.Roles = append(domainClaims.Roles, role.Name)
+		}
+	}
+	return domainClaims, nil
+}
+
+// mint signs an access and a refresh token for user
+func (a *ArrayAuthProvider) mint(user *domain.User) (*domain.Token, error) {
+	now := time.Now()
+	expiry := now.Add(a.config.AccessTokenTTL)
+
+	accessToken, err := a.sign(user, "access", now, expiry)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := a.sign(user, "refresh", now, now.Add(a.config.RefreshTokenTTL))
+	if err != nil {
+		return nil, err
+	}
+
 	return &domain.Token{
-		AccessToken:  "mock-refreshed-access-token",
+		AccessToken:  accessToken,
 		TokenType:    "Bearer",
 		RefreshToken: refreshToken,
-		Expiry:       time.Now().Add(1 * time.Hour),
+		Expiry:       expiry,
 	}, nil
 }
 
-// ParseToken parses and validates JWT token
-func (a *ArrayAuthProvider) ParseToken(token string) (*domain.Claims, error) {
-	// For testing purposes, return mock claims for admin user
-	return &domain.Claims{
-		Username:     "admin",
-		Name:         "Administrator",
-		Email:        "admin@test.com",
-		Organization: "test-org",
-		Roles:        []string{"admin"},
-		Type:         "normal-user",
-		Issuer:       "array-auth-provider",
-		Subject:      "admin-id",
-		Audience:     "test-audience",
-		ExpiresAt:    time.Now().Add(1 * time.Hour).Unix(),
-		IssuedAt:     time.Now().Unix(),
-		NotBefore:    time.Now().Unix(),
-	}, nil
+func (a *ArrayAuthProvider) sign(user *domain.User, use string, issuedAt, expiresAt time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, arrayTokenClaims{
+		TokenUse: use,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    arrayTokenIssuer,
+			Subject:   user.Id,
+			Audience:  jwt.ClaimStrings{arrayTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			NotBefore: jwt.NewNumericDate(issuedAt),
+		},
+	})
+
+	signed, err := token.SignedString(a.signingKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign %s token: %w", use, err)
+	}
+	return signed, nil
+}
+
+// verifyClaims validates token and resolves its active user
+func (a *ArrayAuthProvider) verifyClaims(token, use string) (*domain.User, *arrayTokenClaims, error) {
+	claims := &arrayTokenClaims{}
+	if err := a.verifier.Verify(token, claims); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s token: %w", use, err)
+	}
+	if claims.TokenUse != use {
+		return nil, nil, fmt.Errorf("invalid %s token: got a %s token", use, claims.TokenUse)
+	}
+
+	for _, user := range a.users {
+		if user.Id != claims.Subject {
+			continue
+		}
+		if !user.IsActive() {
+			return nil, nil, fmt.Errorf("user %s is disabled", user.Name)
+		}
+		return user, claims, nil
+	}
+	return nil, nil, fmt.Errorf("user %s not found", claims.Subject)
 }
 
 // GetUser gets user information by username
@@ -0,0 +1,111 @@
+package driver_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/r0x16/Raidark/shared/auth/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArrayAuthProvider_tokensResolveToFixtureUsers(t *testing.T) {
+	provider := newFixtureArrayProvider(t, time.Hour)
+
+	token, err := provider.GetToken("carol:carol-secret", "state")
+	require.NoError(t, err)
+
+	claims, err := provider.ParseToken(token.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "carol", claims.Username)
+	assert.Equal(t, "Carol", claims.Name)
+	assert.Equal(t, "acme", claims.Organization)
+	assert.Equal(t, []string{"manager"}, claims.Roles)
+
+	token, err = provider.MintToken("dave")
+	require.NoError(t, err)
+	claims, err = provider.ParseToken(token.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"clerk"}, claims.Roles)
+}
+
+func TestArrayAuthProvider_getTokenChecksCredentials(t *testing.T) {
+	provider := newFixtureArrayProvider(t, time.Hour)
+
+	_, err := provider.GetToken("carol:wrong", "state")
+	assert.ErrorContains(t, err, "invalid credentials")
+	_, err = provider.GetToken("mallory:carol-secret", "state")
+	assert.ErrorContains(t, err, "invalid credentials")
+	_, err = provider.GetToken("carol", "state")
+	assert.ErrorContains(t, err, "username:password")
+}
+
+func TestArrayAuthProvider_parseTokenRejectsInvalidTokens(t *testing.T) {
+	provider := newFixtureArrayProvider(t, time.Hour)
+	token, err := provider.MintToken("carol")
+	require.NoError(t, err)
+
+	_, err = provider.ParseToken(token.RefreshToken)
+	assert.ErrorContains(t, err, "got a refresh token")
+
+	_, err = provider.ParseToken(token.AccessToken + "x")
+	assert.ErrorIs(t, err, driver.ErrTokenSignature)
+
+	other := newFixtureArrayProvider(t, time.Hour)
+	_, err = other.ParseToken(token.AccessToken)
+	assert.ErrorIs(t, err, driver.ErrTokenSignature, "another signing key")
+
+	forbidden, err := provider.MintToken("eve")
+	require.NoError(t, err)
+	_, err = provider.ParseToken(forbidden.AccessToken)
+	assert.ErrorContains(t, err, "disabled")
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "carol-id"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	_, err = provider.ParseToken(unsigned)
+	assert.ErrorIs(t, err, driver.ErrUnsupportedAlgorithm)
+}
+
+func TestArrayAuthProvider_rejectsExpiredTokens(t *testing.T) {
+	provider := newFixtureArrayProvider(t, -time.Minute)
+
+	token, err := provider.MintToken("carol")
+	require.NoError(t, err)
+
+	_, err = provider.ParseToken(token.AccessToken)
+	assert.ErrorIs(t, err, driver.ErrTokenExpired)
+}
+
+func TestArrayAuthProvider_refreshTokenMintsNewPair(t *testing.T) {
+	provider := newFixtureArrayProvider(t, time.Hour)
+	token, err := provider.MintToken("carol")
+	require.NoError(t, err)
+
+	refreshed, err := provider.RefreshToken(token.RefreshToken)
+	require.NoError(t, err)
+	claims, err := provider.ParseToken(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "carol", claims.Username)
+
+	_, err = provider.RefreshToken(token.AccessToken)
+	assert.Error(t, err)
+}
+
+func TestArrayAuthProvider_initializeFailsOnMissingFixture(t *testing.T) {
+	config := driver.NewArrayAuthConfig()
+	config.FixturePath = "testdata/missing.yaml"
+
+	assert.Error(t, driver.NewArrayAuthProviderWithConfig(config).Initialize())
+}
+
+func newFixtureArrayProvider(t *testing.T, ttl time.Duration) *driver.ArrayAuthProvider {
+	t.Helper()
+	config := driver.NewArrayAuthConfig()
+	config.FixturePath = "testdata/array-users.yaml"
+	config.AccessTokenTTL = ttl
+
+	provider := driver.NewArrayAuthProviderWithConfig(config)
+	require.NoError(t, provider.Initialize())
+	return provider
+}
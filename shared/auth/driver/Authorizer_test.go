@@ -0,0 +1,218 @@
+// Package driver_test covers the Authorizer implementations shipped with the
+// auth drivers.
+package driver_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
+	"github.com/r0x16/Raidark/shared/auth/domain"
+	"github.com/r0x16/Raidark/shared/auth/driver"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticAuthorizer_matchesRoleResourceAndAction(t *testing.T) {
+	authorizer := driver.NewStaticAuthorizer(
+		driver.StaticGrant{Role: "clerk", Resource: "orders", Action: "read"},
+		driver.StaticGrant{Role: "manager", Resource: "orders", Action: domain.AuthorizerWildcard},
+	)
+	clerk := &domain.Claims{Username: "carol", Roles: []string{"clerk"}}
+	manager := &domain.Claims{Username: "mia", Roles: []string{"manager"}}
+
+	assertAuthorized(t, authorizer, clerk, "orders", "Read", true)
+	assertAuthorized(t, authorizer, clerk, "orders", "write", false)
+	assertAuthorized(t, authorizer, clerk, "invoices", "read", false)
+	assertAuthorized(t, authorizer, manager, "orders", "delete", true)
+}
+
+func TestStaticAuthorizer_defaultArrayGrantsAllowArrayProviderAdmin(t *testing.T) {
+	provider := driver.NewArrayAuthProvider()
+	require.NoError(t, provider.Initialize())
+	token, err := provider.MintToken("admin")
+	require.NoError(t, err)
+	claims, err := provider.ParseToken(token.AccessToken)
+	require.NoError(t, err)
+
+	assertAuthorized(t, driver.NewStaticAuthorizer(driver.DefaultArrayGrants()...), claims, "orders", "write", true)
+}
+
+func TestLoadStaticGrants_readsTheGrantsFile(t *testing.T) {
+	grants, err := driver.LoadStaticGrants("testdata/grants.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []driver.StaticGrant{
+		{Role: "clerk", Resource: "orders", Action: "read"},
+		{Role: "manager", Resource: "orders", Action: domain.AuthorizerWildcard},
+	}, grants)
+}
+
+func TestLoadStaticGrants_rejectsIncompleteGrants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grants.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("grants:\n  - role: clerk\n    resource: orders\n"), 0o600))
+
+	_, err := driver.LoadStaticGrants(path)
+
+	assert.ErrorContains(t, err, "grant 1 needs a role, a resource and an action")
+}
+
+func TestCasdoorAuthorizer_grantsThroughUsersAndRoles(t *testing.T) {
+	source := &permissionSource{permissions: []*domain.Permission{
+		permission("read-orders", "Allow", []string{"acme/carol"}, nil, []string{"orders"}, []string{"Read"}),
+		permission("manage-orders", "Allow", nil, []string{"acme/manager"}, []string{"orders"}, []string{"Read", "Write"}),
+	}}
+	authorizer := driver.NewCasdoorAuthorizer(source, time.Minute)
+
+	carol := &domain.Claims{Username: "carol", Organization: "acme"}
+	mia := &domain.Claims{Username: "mia", Organization: "acme", Roles: []string{"manager"}}
+
+	assertAuthorized(t, authorizer, carol, "orders", "read", true)
+	assertAuthorized(t, authorizer, carol, "orders", "write", false)
+	assertAuthorized(t, authorizer, mia, "orders", "write", true)
+	assert.Equal(t, 1, source.calls)
+}
+
+func TestCasdoorAuthorizer_denyWinsAndDisabledPermissionsAreIgnored(t *testing.T) {
+	disabled := permission("legacy", "Allow", []string{"acme/carol"}, nil, []string{"invoices"}, []string{"Read"})
+	disabled.IsEnabled = false
+	authorizer := driver.NewCasdoorAuthorizer(&permissionSource{permissions: []*domain.Permission{
+		permission("all", "Allow", []string{"acme/carol"}, nil, []string{"*"}, []string{"Read"}),
+		permission("no-payroll", "Deny", []string{"acme/carol"}, nil, []string{"payroll"}, []string{"Read"}),
+		disabled,
+	}}, time.Minute)
+	carol := &domain.Claims{Username: "carol", Organization: "acme"}
+
+	assertAuthorized(t, authorizer, carol, "orders", "read", true)
+	assertAuthorized(t, authorizer, carol, "payroll", "read", false)
+}
+
+func TestCasdoorAuthorizer_sharesTheRefreshInFlight(t *testing.T) {
+	source := &gatedPermissionSource{release: make(chan struct{}), permissions: []*domain.Permission{
+		permission("read-orders", "Allow", []string{"acme/carol"}, nil, []string{"orders"}, []string{"Read"}),
+	}}
+	authorizer := driver.NewCasdoorAuthorizer(source, 0)
+	carol := &domain.Claims{Username: "carol", Organization: "acme"}
+
+	var wg sync.WaitGroup
+	allowed := make([]bool, 5)
+	for i := range allowed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			allowed[i], _ = authorizer.Authorize(context.Background(), carol, "orders", "read")
+		}()
+	}
+	require.Eventually(t, func() bool { return source.count() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(source.release)
+	wg.Wait()
+
+	assert.Equal(t, 1, source.count())
+	assert.Equal(t, []bool{true, true, true, true, true}, allowed)
+}
+
+func TestCasdoorAuthorizer_keepsLastPermissionsWhenRefreshFails(t *testing.T) {
+	source := &permissionSource{permissions: []*domain.Permission{
+		permission("read-orders", "Allow", []string{"acme/carol"}, nil, []string{"orders"}, []string{"Read"}),
+	}}
+	authorizer := driver.NewCasdoorAuthorizer(source, 0)
+	carol := &domain.Claims{Username: "carol", Organization: "acme"}
+
+	assertAuthorized(t, authorizer, carol, "orders", "read", true)
+
+	source.err = errors.New("casdoor unavailable")
+	assertAuthorized(t, authorizer, carol, "orders", "read", true)
+	assert.Equal(t, 2, source.calls)
+
+	_, err := driver.NewCasdoorAuthorizer(source, 0).Authorize(context.Background(), carol, "orders", "read")
+	assert.ErrorContains(t, err, "casdoor unavailable")
+}
+
+func TestCasdoorAuthorizer_failsClosedOncePermissionsAreTooStale(t *testing.T) {
+	source := &permissionSource{permissions: []*domain.Permission{
+		permission("read-orders", "Allow", []string{"acme/carol"}, nil, []string{"orders"}, []string{"Read"}),
+	}}
+	var logs bytes.Buffer
+	authorizer := driver.NewCasdoorAuthorizer(source, 0).
+		WithMaxStaleness(50 * time.Millisecond).
+		WithLogProvider(obslog.NewWithWriter(&logs, obslog.FormatJSON, domlogger.Debug))
+	carol := &domain.Claims{Username: "carol", Organization: "acme"}
+	assertAuthorized(t, authorizer, carol, "orders", "read", true)
+
+	source.err = errors.New("casdoor unavailable")
+	assertAuthorized(t, authorizer, carol, "orders", "read", true)
+	assert.Contains(t, logs.String(), "casdoor unavailable")
+
+	time.Sleep(60 * time.Millisecond)
+	allowed, err := authorizer.Authorize(context.Background(), carol, "orders", "read")
+	assert.False(t, allowed)
+	assert.ErrorContains(t, err, "casdoor unavailable")
+
+	source.err = nil
+	assertAuthorized(t, authorizer, carol, "orders", "read", true)
+}
+
+func assertAuthorized(t *testing.T, authorizer domain.Authorizer, claims *domain.Claims, resource, action string, expected bool) {
+	t.Helper()
+	allowed, err := authorizer.Authorize(context.Background(), claims, resource, action)
+	require.NoError(t, err)
+	assert.Equal(t, expected, allowed, "%s on %s", action, resource)
+}
+
+func permission(name, effect string, users, roles, resources, actions []string) *domain.Permission {
+	return &domain.Permission{Permission: casdoorsdk.Permission{
+		Owner:     "acme",
+		Name:      name,
+		Users:     users,
+		Roles:     roles,
+		Resources: resources,
+		Actions:   actions,
+		Effect:    effect,
+		IsEnabled: true,
+	}}
+}
+
+// gatedPermissionSource blocks every call until release is closed
+type gatedPermissionSource struct {
+	permissions []*domain.Permission
+	release     chan struct{}
+	mu          sync.Mutex
+	calls       int
+}
+
+func (s *gatedPermissionSource) GetPermissions() ([]*domain.Permission, error) {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+	<-s.release
+	return s.permissions, nil
+}
+
+func (s *gatedPermissionSource) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+type permissionSource struct {
+	permissions []*domain.Permission
+	err         error
+	calls       int
+}
+
+func (s *permissionSource) GetPermissions() ([]*domain.Permission, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.permissions, nil
+}
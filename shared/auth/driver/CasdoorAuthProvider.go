@@ -1,31 +1,47 @@
 package driver
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/casdoor/casdoor-go-sdk/casdoorsdk"
 	"github.com/r0x16/Raidark/shared/auth/domain"
+	domhealth "github.com/r0x16/Raidark/shared/health/domain"
 	"golang.org/x/oauth2"
 )
 
 // CasdoorAuthProvider implements the AuthProvider interface using Casdoor
 type CasdoorAuthProvider struct {
-	config *CasdoorConfig
-	client *casdoorsdk.Client
+	config     *CasdoorConfig
+	httpClient *http.Client
+	client     *casdoorsdk.Client
+	verifier   *JWTVerifier
 }
 
 // Verify interface implementation
 var _ domain.AuthProvider = &CasdoorAuthProvider{}
+var _ domhealth.CheckContributor = &CasdoorAuthProvider{}
 
 // NewCasdoorAuthProvider creates a new CasdoorAuthProvider instance
 func NewCasdoorAuthProvider(config *CasdoorConfig) *CasdoorAuthProvider {
 	return &CasdoorAuthProvider{
-		config: config,
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
+// WithHTTPClient overrides the client used for the health check and the key
+// set downloads
+func (c *CasdoorAuthProvider) WithHTTPClient(client *http.Client) *CasdoorAuthProvider {
+	c.httpClient = client
+	return c
+}
+
 // NewCasdoorAuthProviderFromEnv creates a new CasdoorAuthProvider from environment variables
 func NewCasdoorAuthProviderFromEnv() *CasdoorAuthProvider {
 	config := NewCasdoorConfigFromEnv()
@@ -51,6 +67,24 @@ func (c *CasdoorAuthProvider) Initialize() error {
 		c.config.ApplicationName,
 	)
 
+	certificateKey, err := ParsePublicKeyPEM(c.config.Certificate)
+	if err != nil {
+		return newCasdoorErrorWithCause("failed to parse CASDOOR_CERTIFICATE", err)
+	}
+
+	var jwks *JWKSCache
+	if c.config.JWKSURL != "" {
+		jwks = NewJWKSCache(c.config.JWKSURL, c.config.JWKSCacheTTL).WithHTTPClient(c.httpClient)
+		// Warm the cache; the certificate covers tokens until it succeeds
+		_ = jwks.Refresh(context.Background())
+	}
+
+	c.verifier = NewJWTVerifier(JWTVerifierConfig{
+		Issuer:    c.config.Issuer,
+		Audience:  c.config.Audience,
+		ClockSkew: c.config.ClockSkew,
+	}, jwks, certificateKey)
+
 	return nil
 }
 
@@ -102,16 +136,20 @@ func (c *CasdoorAuthProvider) RefreshToken(refreshToken string) (*domain.Token,
 	return c.convertOAuth2TokenToDomainToken(oauthToken), nil
 }
 
-// ParseToken parses and validates JWT token
+// ParseToken validates a JWT locally against the cached JWKS or the
+// configured certificate, enforcing exp, nbf, iss and aud
 func (c *CasdoorAuthProvider) ParseToken(token string) (*domain.Claims, error) {
-	if c.client == nil {
+	if c.verifier == nil {
 		return nil, newCasdoorError("client not initialized")
 	}
 
-	casdoorClaims, err := c.client.ParseJwtToken(token)
-	if err != nil {
+	casdoorClaims := &casdoorsdk.Claims{}
+	if err := c.verifier.Verify(token, casdoorClaims); err != nil {
 		return nil, newCasdoorErrorWithCause("failed to parse JWT token", err)
 	}
+	if casdoorClaims.IsRefreshToken() {
+		return nil, newCasdoorError("refresh tokens are not accepted as access tokens")
+	}
 
 	return c.convertCasdoorClaimsToDomainClaims(casdoorClaims), nil
 }
@@ -196,18 +234,62 @@ func (c *CasdoorAuthProvider) DeleteUser(user *domain.User) (bool, error) {
 	return success, nil
 }
 
-// HealthCheck verifies if provider is healthy
+// GetPermissions gets the permissions of the configured organization
+func (c *CasdoorAuthProvider) GetPermissions() ([]*domain.Permission, error) {
+	if c.client == nil {
+		return nil, newCasdoorError("client not initialized")
+	}
+
+	casdoorPermissions, err := c.client.GetPermissions()
+	if err != nil {
+		return nil, newCasdoorErrorWithCause("failed to get permissions", err)
+	}
+
+	// Pure composition conversion
+	domainPermissions := make([]*domain.Permission, len(casdoorPermissions))
+	for i, casdoorPermission := range casdoorPermissions {
+		domainPermissions[i] = &domain.Permission{Permission: *casdoorPermission}
+	}
+
+	return domainPermissions, nil
+}
+
+// HealthCheck verifies Casdoor still serves its discovery document. The
+// HTTP client timeout bounds it.
 func (c *CasdoorAuthProvider) HealthCheck() error {
+	return c.checkDiscovery(context.Background())
+}
+
+// HealthChecks reports the Casdoor reachability to the readiness probe. The
+// check is optional: tokens are verified locally, so an unreachable Casdoor
+// only stops logins and refreshes, which restarting pods would not fix.
+func (c *CasdoorAuthProvider) HealthChecks() []domhealth.Check {
+	return []domhealth.Check{{Name: "auth.casdoor", Optional: true, Run: c.checkDiscovery}}
+}
+
+// checkDiscovery downloads the discovery document within ctx. It is a static
+// document, so frequent probes cost Casdoor no database query.
+func (c *CasdoorAuthProvider) checkDiscovery(ctx context.Context) error {
 	if c.client == nil {
 		return newCasdoorError("client not initialized")
 	}
 
-	// Try to get users as a simple health check
-	_, err := c.client.GetUsers()
+	endpoint := strings.TrimRight(c.config.Endpoint, "/") + oidcDiscoveryPath
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return newCasdoorErrorWithCause("health check failed", err)
+	}
+
+	response, err := c.httpClient.Do(request)
 	if err != nil {
 		return newCasdoorErrorWithCause("health check failed", err)
 	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
 
+	if response.StatusCode != http.StatusOK {
+		return newCasdoorError(fmt.Sprintf("health check failed: discovery document returned status %d", response.StatusCode))
+	}
 	return nil
 }
 
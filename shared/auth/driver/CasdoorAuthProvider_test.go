@@ -0,0 +1,130 @@
+package driver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/r0x16/Raidark/shared/auth/driver"
+	"github.com/r0x16/Raidark/shared/internal/testutil/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCasdoorAuthProvider_parseTokenValidatesLocally(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	provider := newCasdoorProvider(t, issuer)
+
+	claims := casdoorClaims(issuer)
+	parsed, err := provider.ParseToken(issuer.Mint(t, claims))
+	require.NoError(t, err)
+
+	assert.Equal(t, "carol", parsed.Username)
+	assert.Equal(t, "Carol", parsed.Name)
+	assert.Equal(t, "acme", parsed.Organization)
+	assert.Equal(t, []string{"manager"}, parsed.Roles)
+	assert.Equal(t, "raidark-client", parsed.Audience)
+	assert.Equal(t, claims["exp"], parsed.ExpiresAt)
+}
+
+func TestCasdoorAuthProvider_parseTokenRejectsInvalidTokens(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	provider := newCasdoorProvider(t, issuer)
+
+	expired := casdoorClaims(issuer)
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err := provider.ParseToken(issuer.Mint(t, expired))
+	assert.ErrorIs(t, err, driver.ErrTokenExpired)
+
+	otherApp := casdoorClaims(issuer)
+	otherApp["aud"] = "other-client"
+	_, err = provider.ParseToken(issuer.Mint(t, otherApp))
+	assert.ErrorIs(t, err, driver.ErrTokenAudience)
+
+	refresh := casdoorClaims(issuer)
+	refresh["TokenType"] = "refresh-token"
+	_, err = provider.ParseToken(issuer.Mint(t, refresh))
+	assert.ErrorContains(t, err, "refresh tokens are not accepted")
+}
+
+func TestCasdoorAuthProvider_parseTokenUsesCertificateWithoutJWKS(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	issuer.FailJWKS(true)
+	provider := newCasdoorProvider(t, issuer)
+
+	unnamed := &oidc.Key{Private: issuer.ActiveKey().Private}
+	_, err := provider.ParseToken(issuer.MintWith(t, unnamed, casdoorClaims(issuer)))
+	assert.NoError(t, err)
+}
+
+func TestCasdoorAuthProvider_healthChecksProbeTheDiscoveryDocument(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+
+	checks := newCasdoorProvider(t, issuer).HealthChecks()
+	require.Len(t, checks, 1)
+	assert.Equal(t, "auth.casdoor", checks[0].Name)
+	assert.True(t, checks[0].Optional)
+	assert.NoError(t, checks[0].Run(context.Background()))
+
+	unreachable := newCasdoorProviderAt(t, issuer, issuer.URL+"/missing").HealthChecks()
+	assert.ErrorContains(t, unreachable[0].Run(context.Background()), "status 404")
+}
+
+func TestCasdoorAuthProvider_healthCheckGivesUpOnAHangingCasdoor(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(hanging.Close)
+	provider := newCasdoorProviderAt(t, issuer, hanging.URL).
+		WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond})
+
+	started := time.Now()
+	err := provider.HealthCheck()
+
+	assert.ErrorContains(t, err, "health check failed")
+	assert.Less(t, time.Since(started), 2*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	provider.WithHTTPClient(&http.Client{})
+	assert.ErrorIs(t, provider.HealthChecks()[0].Run(ctx), context.DeadlineExceeded)
+}
+
+func newCasdoorProvider(t *testing.T, issuer *oidc.Issuer) *driver.CasdoorAuthProvider {
+	t.Helper()
+	return newCasdoorProviderAt(t, issuer, issuer.URL)
+}
+
+func newCasdoorProviderAt(t *testing.T, issuer *oidc.Issuer, endpoint string) *driver.CasdoorAuthProvider {
+	t.Helper()
+	provider := driver.NewCasdoorAuthProvider(&driver.CasdoorConfig{
+		Endpoint:         endpoint,
+		ClientId:         "raidark-client",
+		ClientSecret:     "secret",
+		Certificate:      issuer.CertificatePEM(t),
+		OrganizationName: "acme",
+		ApplicationName:  "raidark",
+		JWKSURL:          issuer.URL + oidc.JWKSPath,
+		JWKSCacheTTL:     time.Hour,
+		Issuer:           issuer.URL,
+		Audience:         []string{"raidark-client"},
+		ClockSkew:        time.Minute,
+	})
+	require.NoError(t, provider.Initialize())
+	return provider
+}
+
+func casdoorClaims(issuer *oidc.Issuer) jwt.MapClaims {
+	claims := issuer.Claims("acme/carol", "raidark-client")
+	claims["owner"] = "acme"
+	claims["name"] = "carol"
+	claims["displayName"] = "Carol"
+	claims["email"] = "carol@acme.test"
+	claims["roles"] = []map[string]any{{"owner": "acme", "name": "manager"}}
+	claims["TokenType"] = "access-token"
+	return claims
+}
@@ -0,0 +1,202 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/auth/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+)
+
+// PermissionSource lists the permissions of the organization.
+// CasdoorAuthProvider implements it.
+type PermissionSource interface {
+	GetPermissions() ([]*domain.Permission, error)
+}
+
+// CasdoorAuthorizer implements the Authorizer interface on top of Casdoor
+// permissions. A permission grants its actions on its resources to the users
+// and roles it lists; a matching permission with the "Deny" effect wins over
+// any allow.
+//
+// Permissions are cached for the configured TTL so a guarded request does not
+// call Casdoor every time. When a refresh fails the last known permissions
+// keep being used until they are older than the max staleness; after that,
+// or without any, Authorize returns the error, so a revoked permission is
+// never honoured for long while Casdoor is down.
+//
+// Casdoor is called outside the lock, and the calls that find the cache
+// expired while a refresh is running share its result.
+type CasdoorAuthorizer struct {
+	source      PermissionSource
+	ttl         time.Duration
+	maxStale    time.Duration
+	log         domlogger.LogProvider
+	now         func() time.Time
+	mu          sync.Mutex
+	permissions []*domain.Permission
+	loadedAt    time.Time
+	refresh     *permissionsRefresh
+}
+
+// permissionsRefresh is a call to the PermissionSource in flight. done is
+// closed once permissions and err are set.
+type permissionsRefresh struct {
+	done        chan struct{}
+	permissions []*domain.Permission
+	err         error
+}
+
+// Verify interface implementation
+var _ domain.Authorizer = &CasdoorAuthorizer{}
+
+// errPermissionsRefreshAborted is returned to the calls waiting on a refresh
+// whose source panicked
+var errPermissionsRefreshAborted = errors.New("casdoor: permissions refresh aborted")
+
+// DefaultPermissionsMaxStaleness is how long CasdoorAuthorizer keeps using
+// permissions it cannot refresh, unless WithMaxStaleness says otherwise
+const DefaultPermissionsMaxStaleness = 5 * time.Minute
+
+// NewCasdoorAuthorizer creates a new CasdoorAuthorizer. A ttl of zero
+// reloads the permissions on every call.
+func NewCasdoorAuthorizer(source PermissionSource, ttl time.Duration) *CasdoorAuthorizer {
+	return &CasdoorAuthorizer{
+		source:   source,
+		ttl:      ttl,
+		maxStale: DefaultPermissionsMaxStaleness,
+		now:      time.Now,
+	}
+}
+
+// WithMaxStaleness overrides how long permissions that fail to refresh keep
+// being used, counted from their last successful load. Zero fails closed on
+// the first failed refresh.
+func (a *CasdoorAuthorizer) WithMaxStaleness(maxStale time.Duration) *CasdoorAuthorizer {
+	a.maxStale = maxStale
+	return a
+}
+
+// WithLogProvider sets the logger that reports failed refreshes
+func (a *CasdoorAuthorizer) WithLogProvider(log domlogger.LogProvider) *CasdoorAuthorizer {
+	a.log = log
+	return a
+}
+
+// Authorize implements domain.Authorizer
+func (a *CasdoorAuthorizer) Authorize(_ context.Context, claims *domain.Claims, resource, action string) (bool, error) {
+	permissions, err := a.load()
+	if err != nil {
+		return false, err
+	}
+
+	allowed := false
+	for _, permission := range permissions {
+		if !permission.IsActive() || !a.appliesTo(permission, claims) {
+			continue
+		}
+		if !matchesAny(permission.Resources, resource) || !matchesAny(permission.Actions, action) {
+			continue
+		}
+		if strings.EqualFold(permission.Effect, "Deny") {
+			return false, nil
+		}
+		allowed = true
+	}
+	return allowed, nil
+}
+
+// load returns the cached permissions, refreshing them once the TTL expires
+func (a *CasdoorAuthorizer) load() ([]*domain.Permission, error) {
+	a.mu.Lock()
+	if a.permissions != nil && a.now().Sub(a.loadedAt) < a.ttl {
+		defer a.mu.Unlock()
+		return a.permissions, nil
+	}
+
+	refresh, started := a.refresh, false
+	if refresh == nil {
+		refresh, started = &permissionsRefresh{done: make(chan struct{})}, true
+		a.refresh = refresh
+	}
+	a.mu.Unlock()
+
+	if started {
+		a.runRefresh(refresh)
+	}
+	<-refresh.done
+	if refresh.err == nil {
+		return refresh.permissions, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	age := a.now().Sub(a.loadedAt)
+	if a.permissions != nil && age < a.maxStale {
+		if started {
+			a.logStaleRefresh(age, refresh.err)
+		}
+		return a.permissions, nil
+	}
+	// The caller logs the error and denies the request
+	return nil, refresh.err
+}
+
+// runRefresh calls Casdoor without holding the lock, then stores the
+// permissions it got and ends the refresh for every waiting call, even when
+// the source panics
+func (a *CasdoorAuthorizer) runRefresh(refresh *permissionsRefresh) {
+	refresh.err = errPermissionsRefreshAborted
+	defer func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if refresh.err == nil {
+			a.permissions = refresh.permissions
+			a.loadedAt = a.now()
+		}
+		a.refresh = nil
+		close(refresh.done)
+	}()
+
+	refresh.permissions, refresh.err = a.source.GetPermissions()
+}
+
+// logStaleRefresh reports a failed refresh answered with cached permissions,
+// which no request error would reveal
+func (a *CasdoorAuthorizer) logStaleRefresh(age time.Duration, err error) {
+	if a.log == nil {
+		return
+	}
+	a.log.Warning("Failed to refresh permissions, using the cached ones", map[string]any{
+		"error":              err.Error(),
+		"cached_age_seconds": int(age.Seconds()),
+		"max_stale_seconds":  int(a.maxStale.Seconds()),
+	})
+}
+
+// appliesTo reports whether the permission lists the subject of claims,
+// directly or through one of its roles. Casdoor references users and roles
+// as "organization/name"; bare names are accepted too.
+func (a *CasdoorAuthorizer) appliesTo(permission *domain.Permission, claims *domain.Claims) bool {
+	if permission.HasUser(claims.Username) || permission.HasUser(claims.Organization+"/"+claims.Username) {
+		return true
+	}
+	for _, role := range claims.Roles {
+		if permission.HasRole(role) || permission.HasRole(claims.Organization+"/"+role) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(granted []string, requested string) bool {
+	for _, value := range granted {
+		if matchesGrant(value, requested) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,11 @@
 package driver
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // CasdoorConfig holds the configuration for Casdoor authentication
 type CasdoorConfig struct {
@@ -11,18 +16,34 @@ type CasdoorConfig struct {
 	OrganizationName string
 	ApplicationName  string
 	RedirectURI      string
+
+	// Token validation. JWKSURL, Issuer and Audience default to the Casdoor
+	// JWKS endpoint, the endpoint itself and the client ID.
+	JWKSURL      string
+	JWKSCacheTTL time.Duration
+	Issuer       string
+	Audience     []string
+	ClockSkew    time.Duration
 }
 
 // NewCasdoorConfigFromEnv creates a new CasdoorConfig from environment variables
 func NewCasdoorConfigFromEnv() *CasdoorConfig {
+	endpoint := strings.TrimRight(getEnvOrDefault("CASDOOR_ENDPOINT", "http://localhost:8000"), "/")
+	clientId := os.Getenv("CASDOOR_CLIENT_ID")
+
 	return &CasdoorConfig{
-		Endpoint:         getEnvOrDefault("CASDOOR_ENDPOINT", "http://localhost:8000"),
-		ClientId:         os.Getenv("CASDOOR_CLIENT_ID"),
+		Endpoint:         endpoint,
+		ClientId:         clientId,
 		ClientSecret:     os.Getenv("CASDOOR_CLIENT_SECRET"),
 		Certificate:      os.Getenv("CASDOOR_CERTIFICATE"),
 		OrganizationName: os.Getenv("CASDOOR_ORGANIZATION"),
 		ApplicationName:  os.Getenv("CASDOOR_APPLICATION"),
 		RedirectURI:      getEnvOrDefault("CASDOOR_REDIRECT_URI", "http://localhost:8080/callback"),
+		JWKSURL:          getEnvOrDefault("CASDOOR_JWKS_URL", endpoint+"/.well-known/jwks"),
+		JWKSCacheTTL:     getEnvSecondsOrDefault("CASDOOR_JWKS_CACHE_TTL_SECONDS", time.Hour),
+		Issuer:           getEnvOrDefault("CASDOOR_JWT_ISSUER", endpoint),
+		Audience:         splitEnvList(getEnvOrDefault("CASDOOR_JWT_AUDIENCE", clientId)),
+		ClockSkew:        getEnvSecondsOrDefault("CASDOOR_JWT_CLOCK_SKEW_SECONDS", DefaultJWTClockSkew),
 	}
 }
 
@@ -56,3 +77,23 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvSecondsOrDefault reads a number of seconds from the environment
+func getEnvSecondsOrDefault(key string, defaultValue time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || seconds < 0 {
+		return defaultValue
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// splitEnvList splits a comma separated value, dropping empty items
+func splitEnvList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,285 @@
+package driver
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultJWKSRefreshInterval is the minimum time between two JWKS downloads
+// triggered by tokens signed with an unknown key
+const DefaultJWKSRefreshInterval = 30 * time.Second
+
+// JWKSCache keeps the signing keys published by an issuer in memory.
+//
+// Keys are looked up by their "kid". A cached key set older than the TTL
+// keeps being served while it is refreshed in the background, so verifying
+// a token never waits on the network once the cache is warm. A token signed
+// with an unknown key triggers a synchronous download to pick up rotated
+// keys, at most once per refresh interval. When a download fails the last
+// known keys are kept.
+type JWKSCache struct {
+	url             string
+	client          *http.Client
+	ttl             time.Duration
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	refreshing  bool
+}
+
+// NewJWKSCache creates a JWKSCache for the JWKS document at url. The cache
+// is empty until the first lookup or Refresh.
+func NewJWKSCache(url string, ttl time.Duration) *JWKSCache {
+	return &JWKSCache{
+		url:             url,
+		client:          &http.Client{Timeout: 5 * time.Second},
+		ttl:             ttl,
+		refreshInterval: DefaultJWKSRefreshInterval,
+		now:             time.Now,
+	}
+}
+
+// WithRefreshInterval overrides the minimum time between downloads
+// triggered by unknown keys
+func (c *JWKSCache) WithRefreshInterval(interval time.Duration) *JWKSCache {
+	c.refreshInterval = interval
+	return c
+}
+
+// WithHTTPClient overrides the client used to download the key set
+func (c *JWKSCache) WithHTTPClient(client *http.Client) *JWKSCache {
+	c.client = client
+	return c
+}
+
+// URL returns the location of the JWKS document
+func (c *JWKSCache) URL() string {
+	return c.url
+}
+
+// Key returns the public key identified by kid. An empty kid matches the
+// only key of a single-key set.
+func (c *JWKSCache) Key(kid string) (crypto.PublicKey, error) {
+	if key, ok := c.cached(kid); ok {
+		return key, nil
+	}
+
+	if !c.claimRefresh() {
+		return nil, fmt.Errorf("%w: kid %q", ErrUnknownSigningKey, kid)
+	}
+	if err := c.fetch(context.Background()); err != nil {
+		return nil, fmt.Errorf("%w: kid %q: %v", ErrUnknownSigningKey, kid, err)
+	}
+
+	if key, ok := c.cached(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: kid %q", ErrUnknownSigningKey, kid)
+}
+
+// Refresh downloads the key set now, regardless of its age
+func (c *JWKSCache) Refresh(ctx context.Context) error {
+	return c.fetch(ctx)
+}
+
+// cached looks kid up in the current key set and schedules a background
+// refresh when the set is older than the TTL
+func (c *JWKSCache) cached(kid string) (crypto.PublicKey, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key, ok := c.keys[kid]
+	if !ok && kid == "" && len(c.keys) == 1 {
+		for _, only := range c.keys {
+			key, ok = only, true
+		}
+	}
+
+	if ok && c.now().Sub(c.fetchedAt) >= c.ttl && !c.refreshing && c.refreshAllowedLocked() {
+		c.refreshing = true
+		c.attemptedAt = c.now()
+		go func() {
+			_ = c.fetch(context.Background())
+			c.mu.Lock()
+			c.refreshing = false
+			c.mu.Unlock()
+		}()
+	}
+	return key, ok
+}
+
+// claimRefresh reserves the next download slot, honouring the refresh interval
+func (c *JWKSCache) claimRefresh() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.refreshAllowedLocked() {
+		return false
+	}
+	c.attemptedAt = c.now()
+	return true
+}
+
+func (c *JWKSCache) refreshAllowedLocked() bool {
+	return c.attemptedAt.IsZero() || c.now().Sub(c.attemptedAt) >= c.refreshInterval
+}
+
+// fetch downloads and parses the key set, replacing the cached one on success
+func (c *JWKSCache) fetch(ctx context.Context) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to download JWKS: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download JWKS: unexpected status %d", response.StatusCode)
+	}
+
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&document); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(document.Keys))
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Skip keys this verifier cannot use instead of dropping the set
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("JWKS at %s holds no usable signing keys", c.url)
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.fetchedAt = c.now()
+	c.mu.Unlock()
+	return nil
+}
+
+// jsonWebKey is the subset of RFC 7517 needed to rebuild public keys
+type jsonWebKey struct {
+	Kty string   `json:"kty"`
+	Kid string   `json:"kid"`
+	Use string   `json:"use"`
+	Alg string   `json:"alg"`
+	N   string   `json:"n"`
+	E   string   `json:"e"`
+	Crv string   `json:"crv"`
+	X   string   `json:"x"`
+	Y   string   `json:"y"`
+	X5c []string `json:"x5c"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		if k.N == "" && len(k.X5c) > 0 {
+			return k.certificateKey()
+		}
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, err := namedCurve(k.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("EC key %q is not on curve %s", k.Kid, k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key %q", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func (k jsonWebKey) certificateKey() (crypto.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(k.X5c[0])
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return certificate.PublicKey, nil
+}
+
+func namedCurve(name string) (elliptic.Curve, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("unsupported EC curve %q", name)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("empty key parameter")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,37 @@
+package driver_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/auth/driver"
+	"github.com/r0x16/Raidark/shared/internal/testutil/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKSCache_refreshesStaleKeysInBackground(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	cache := driver.NewJWKSCache(issuer.URL+oidc.JWKSPath, 0).WithRefreshInterval(0)
+	require.NoError(t, cache.Refresh(context.Background()))
+
+	key, err := cache.Key(issuer.ActiveKey().ID)
+	require.NoError(t, err)
+	assert.Equal(t, &issuer.ActiveKey().Private.PublicKey, key)
+	assert.Eventually(t, func() bool { return issuer.JWKSRequests() == 2 }, time.Second, 10*time.Millisecond)
+}
+
+func TestJWKSCache_keepsLastKeysWhenDownloadFails(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	cache := driver.NewJWKSCache(issuer.URL+oidc.JWKSPath, time.Hour)
+	require.NoError(t, cache.Refresh(context.Background()))
+
+	issuer.FailJWKS(true)
+	assert.Error(t, cache.Refresh(context.Background()))
+
+	_, err := cache.Key(issuer.ActiveKey().ID)
+	assert.NoError(t, err)
+	_, err = cache.Key("")
+	assert.NoError(t, err, "an empty kid matches the only published key")
+}
@@ -0,0 +1,202 @@
+package driver
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// DefaultJWTClockSkew is the tolerance applied to exp, nbf and iat
+const DefaultJWTClockSkew = time.Minute
+
+// Token validation errors. They are wrapped by the providers, use errors.Is
+// to tell them apart.
+var (
+	ErrTokenMalformed       = errors.New("token is malformed")
+	ErrTokenSignature       = errors.New("token signature is invalid")
+	ErrTokenExpired         = errors.New("token is expired")
+	ErrTokenNotYetValid     = errors.New("token is not valid yet")
+	ErrTokenIssuer          = errors.New("token issuer is not accepted")
+	ErrTokenAudience        = errors.New("token audience is not accepted")
+	ErrUnknownSigningKey    = errors.New("no key found to verify the token")
+	ErrUnsupportedAlgorithm = errors.New("token signing algorithm is not accepted")
+)
+
+// defaultJWTAlgorithms are the asymmetric algorithms accepted when the
+// configuration does not restrict them. HMAC has to be enabled explicitly:
+// verifying it with public key material would let anyone forge tokens.
+var defaultJWTAlgorithms = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// JWTVerifierConfig holds the expectations a token has to meet
+type JWTVerifierConfig struct {
+	// Issuer the "iss" claim must equal. Empty skips the check.
+	Issuer string
+	// Audience lists the accepted "aud" values; the token must carry one of
+	// them. Empty skips the check.
+	Audience []string
+	// ClockSkew is the tolerance applied to exp, nbf and iat
+	ClockSkew time.Duration
+	// Algorithms restricts the accepted "alg" values. Empty accepts
+	// defaultJWTAlgorithms.
+	Algorithms []string
+}
+
+// JWTVerifier verifies JWT signatures and registered claims locally.
+//
+// Signing keys come from a JWKSCache, looked up by the token "kid", and from
+// an optional static key such as the certificate configured for Casdoor. A
+// token without a kid, or whose kid the JWKS does not know, is verified with
+// the static key when there is one.
+type JWTVerifier struct {
+	config    JWTVerifierConfig
+	jwks      *JWKSCache
+	staticKey crypto.PublicKey
+	parser    *jwt.Parser
+	now       func() time.Time
+}
+
+// NewJWTVerifier creates a JWTVerifier. jwks and staticKey are both
+// optional, but at least one of them is needed to accept any token.
+func NewJWTVerifier(config JWTVerifierConfig, jwks *JWKSCache, staticKey crypto.PublicKey) *JWTVerifier {
+	algorithms := config.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = defaultJWTAlgorithms
+	}
+
+	return &JWTVerifier{
+		config:    config,
+		jwks:      jwks,
+		staticKey: staticKey,
+		parser:    jwt.NewParser(jwt.WithValidMethods(algorithms), jwt.WithoutClaimsValidation()),
+		now:       time.Now,
+	}
+}
+
+// Verify checks the signature and registered claims of token and decodes
+// its payload into claims
+func (v *JWTVerifier) Verify(token string, claims jwt.Claims) error {
+	if _, err := v.parser.ParseWithClaims(token, claims, v.keyFor); err != nil {
+		return v.translate(err)
+	}
+
+	registered := &jwt.RegisteredClaims{}
+	if _, _, err := v.parser.ParseUnverified(token, registered); err != nil {
+		return fmt.Errorf("%w: %v", ErrTokenMalformed, err)
+	}
+	return v.validate(registered)
+}
+
+// keyFor resolves the verification key of a parsed token
+func (v *JWTVerifier) keyFor(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if v.jwks != nil && (kid != "" || v.staticKey == nil) {
+		key, err := v.jwks.Key(kid)
+		if err == nil {
+			return key, nil
+		}
+		if v.staticKey == nil {
+			return nil, err
+		}
+	}
+
+	if v.staticKey == nil {
+		return nil, fmt.Errorf("%w: kid %q", ErrUnknownSigningKey, kid)
+	}
+	return v.staticKey, nil
+}
+
+// validate enforces exp, nbf, iat, iss and aud with the configured clock skew
+func (v *JWTVerifier) validate(claims *jwt.RegisteredClaims) error {
+	now := v.now()
+	skew := v.config.ClockSkew
+
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("%w: missing exp claim", ErrTokenExpired)
+	}
+	if now.After(claims.ExpiresAt.Add(skew)) {
+		return fmt.Errorf("%w: expired at %s", ErrTokenExpired, claims.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if claims.NotBefore != nil && now.Add(skew).Before(claims.NotBefore.Time) {
+		return fmt.Errorf("%w: valid from %s", ErrTokenNotYetValid, claims.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if claims.IssuedAt != nil && now.Add(skew).Before(claims.IssuedAt.Time) {
+		return fmt.Errorf("%w: issued in the future", ErrTokenNotYetValid)
+	}
+
+	if v.config.Issuer != "" && normalizeIssuer(claims.Issuer) != normalizeIssuer(v.config.Issuer) {
+		return fmt.Errorf("%w: %q", ErrTokenIssuer, claims.Issuer)
+	}
+
+	if len(v.config.Audience) > 0 && !hasAudience(claims.Audience, v.config.Audience) {
+		return fmt.Errorf("%w: %v", ErrTokenAudience, []string(claims.Audience))
+	}
+	return nil
+}
+
+// translate maps the parser errors onto the verifier errors
+func (v *JWTVerifier) translate(err error) error {
+	var validationErr *jwt.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	switch {
+	case validationErr.Inner != nil && errors.Is(validationErr.Inner, ErrUnknownSigningKey):
+		return validationErr.Inner
+	case validationErr.Errors&jwt.ValidationErrorMalformed != 0:
+		return fmt.Errorf("%w: %v", ErrTokenMalformed, err)
+	case strings.Contains(err.Error(), "signing method"):
+		return fmt.Errorf("%w: %v", ErrUnsupportedAlgorithm, err)
+	default:
+		return fmt.Errorf("%w: %v", ErrTokenSignature, err)
+	}
+}
+
+func hasAudience(audience jwt.ClaimStrings, accepted []string) bool {
+	for _, value := range audience {
+		for _, expected := range accepted {
+			if value == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func normalizeIssuer(issuer string) string {
+	return strings.TrimRight(issuer, "/")
+}
+
+// ParsePublicKeyPEM reads a public key from a PEM "PUBLIC KEY" block or from
+// the certificate of a PEM "CERTIFICATE" block
+func ParsePublicKeyPEM(data string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(data)))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return certificate.PublicKey, nil
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+}
@@ -0,0 +1,139 @@
+package driver_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/r0x16/Raidark/shared/auth/driver"
+	"github.com/r0x16/Raidark/shared/internal/testutil/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTVerifier_acceptsTokensSignedWithPublishedKeys(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	verifier := newIssuerVerifier(issuer, nil)
+
+	claims := jwt.MapClaims{}
+	require.NoError(t, verifier.Verify(issuer.Mint(t, issuer.Claims("alice", "raidark")), &claims))
+	assert.Equal(t, "alice", claims["sub"])
+
+	require.NoError(t, verifier.Verify(issuer.Mint(t, issuer.Claims("bob", "raidark")), &jwt.MapClaims{}))
+	assert.Equal(t, 1, issuer.JWKSRequests())
+}
+
+func TestJWTVerifier_picksUpRotatedKeys(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	jwks := driver.NewJWKSCache(issuer.URL+oidc.JWKSPath, time.Hour).WithRefreshInterval(0)
+	verifier := driver.NewJWTVerifier(driver.JWTVerifierConfig{Issuer: issuer.URL}, jwks, nil)
+	require.NoError(t, verifier.Verify(issuer.Mint(t, issuer.Claims("alice", "raidark")), &jwt.MapClaims{}))
+
+	previous := issuer.ActiveKey()
+	issuer.Rotate(t)
+
+	require.NoError(t, verifier.Verify(issuer.Mint(t, issuer.Claims("alice", "raidark")), &jwt.MapClaims{}))
+	require.NoError(t, verifier.Verify(issuer.MintWith(t, previous, issuer.Claims("alice", "raidark")), &jwt.MapClaims{}))
+	assert.Equal(t, 2, issuer.JWKSRequests())
+}
+
+func TestJWTVerifier_rateLimitsDownloadsForUnknownKeys(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	verifier := newIssuerVerifier(issuer, nil)
+	stranger := &oidc.Key{ID: "stranger", Private: issuer.Rotate(t).Private}
+	issuer.Retire()
+
+	for range 3 {
+		err := verifier.Verify(issuer.MintWith(t, stranger, issuer.Claims("mallory", "raidark")), &jwt.MapClaims{})
+		assert.ErrorIs(t, err, driver.ErrUnknownSigningKey)
+	}
+	assert.Equal(t, 1, issuer.JWKSRequests())
+}
+
+func TestJWTVerifier_toleratesClockSkew(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	verifier := newIssuerVerifier(issuer, nil)
+	now := time.Now()
+
+	cases := map[string]struct {
+		exp, nbf time.Time
+		expected error
+	}{
+		"expired within skew":    {exp: now.Add(-30 * time.Second), nbf: now.Add(-time.Hour)},
+		"expired beyond skew":    {exp: now.Add(-2 * time.Minute), nbf: now.Add(-time.Hour), expected: driver.ErrTokenExpired},
+		"not before within":      {exp: now.Add(time.Hour), nbf: now.Add(30 * time.Second)},
+		"not before beyond":      {exp: now.Add(time.Hour), nbf: now.Add(5 * time.Minute), expected: driver.ErrTokenNotYetValid},
+		"missing expiration":     {nbf: now, expected: driver.ErrTokenExpired},
+		"valid for another hour": {exp: now.Add(time.Hour), nbf: now},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			claims := issuer.Claims("alice", "raidark")
+			claims["iat"] = tc.nbf.Unix()
+			claims["nbf"] = tc.nbf.Unix()
+			if tc.exp.IsZero() {
+				delete(claims, "exp")
+			} else {
+				claims["exp"] = tc.exp.Unix()
+			}
+
+			err := verifier.Verify(issuer.Mint(t, claims), &jwt.MapClaims{})
+			if tc.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestJWTVerifier_enforcesIssuerAndAudience(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	verifier := newIssuerVerifier(issuer, nil)
+
+	foreign := issuer.Claims("alice", "raidark")
+	foreign["iss"] = "https://elsewhere.example.com"
+	assert.ErrorIs(t, verifier.Verify(issuer.Mint(t, foreign), &jwt.MapClaims{}), driver.ErrTokenIssuer)
+
+	otherClient := issuer.Claims("alice", "another-app")
+	assert.ErrorIs(t, verifier.Verify(issuer.Mint(t, otherClient), &jwt.MapClaims{}), driver.ErrTokenAudience)
+
+	multiple := issuer.Claims("alice", "")
+	multiple["aud"] = []string{"another-app", "raidark"}
+	assert.NoError(t, verifier.Verify(issuer.Mint(t, multiple), &jwt.MapClaims{}))
+}
+
+func TestJWTVerifier_fallsBackToStaticKey(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	certificateKey, err := driver.ParsePublicKeyPEM(issuer.CertificatePEM(t))
+	require.NoError(t, err)
+	issuer.FailJWKS(true)
+	verifier := newIssuerVerifier(issuer, certificateKey)
+
+	withoutKid := &oidc.Key{Private: issuer.ActiveKey().Private}
+	assert.NoError(t, verifier.Verify(issuer.MintWith(t, withoutKid, issuer.Claims("alice", "raidark")), &jwt.MapClaims{}))
+	assert.NoError(t, verifier.Verify(issuer.Mint(t, issuer.Claims("alice", "raidark")), &jwt.MapClaims{}))
+
+	forged := &oidc.Key{Private: issuer.Rotate(t).Private}
+	assert.ErrorIs(t, verifier.Verify(issuer.MintWith(t, forged, issuer.Claims("alice", "raidark")), &jwt.MapClaims{}), driver.ErrTokenSignature)
+}
+
+func TestJWTVerifier_rejectsSymmetricAlgorithms(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	verifier := newIssuerVerifier(issuer, nil)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, issuer.Claims("alice", "raidark")).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, verifier.Verify(token, &jwt.MapClaims{}), driver.ErrUnsupportedAlgorithm)
+	assert.ErrorIs(t, verifier.Verify("not-a-jwt", &jwt.MapClaims{}), driver.ErrTokenMalformed)
+}
+
+func newIssuerVerifier(issuer *oidc.Issuer, staticKey any) *driver.JWTVerifier {
+	return driver.NewJWTVerifier(driver.JWTVerifierConfig{
+		Issuer:    issuer.URL,
+		Audience:  []string{"raidark"},
+		ClockSkew: time.Minute,
+	}, driver.NewJWKSCache(issuer.URL+oidc.JWKSPath, time.Hour), staticKey)
+}
@@ -0,0 +1,186 @@
+package driver_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/auth/domain"
+	"github.com/r0x16/Raidark/shared/auth/driver"
+	"github.com/r0x16/Raidark/shared/internal/testutil/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCAuthProvider_loginRunsAuthorizationCodeWithPKCE(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	provider := newOIDCProvider(t, issuer, "")
+
+	authURL, err := url.Parse(provider.GetAuthURL("state-1"))
+	require.NoError(t, err)
+	query := authURL.Query()
+	assert.Equal(t, issuer.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, "raidark", query.Get("client_id"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	state := query.Get("state")
+	assert.NotEmpty(t, state)
+	assert.NotContains(t, state, "state-1")
+
+	code := issuer.IssueCode(query.Get("code_challenge"), keycloakClaims(issuer))
+	token, err := provider.GetToken(code, state)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token.RefreshToken)
+
+	claims, err := provider.ParseToken(token.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "carol", claims.Username)
+	assert.Equal(t, "Carol Danvers", claims.Name)
+	assert.Equal(t, "carol@acme.test", claims.Email)
+	assert.Equal(t, []string{"manager", "clerk"}, claims.Roles)
+	assert.Equal(t, "user-42", claims.Subject)
+	assert.Equal(t, issuer.URL, claims.Issuer)
+
+	_, err = provider.GetToken(code, state)
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestOIDCAuthProvider_loginCompletesOnAnotherInstance(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	first := newOIDCProvider(t, issuer, "shared-state-secret")
+	second := newOIDCProvider(t, issuer, "shared-state-secret")
+
+	authURL, err := url.Parse(first.GetAuthURL("state-1"))
+	require.NoError(t, err)
+	code := issuer.IssueCode(authURL.Query().Get("code_challenge"), keycloakClaims(issuer))
+
+	_, err = second.GetToken(code, authURL.Query().Get("state"))
+	assert.NoError(t, err)
+}
+
+func TestOIDCAuthProvider_getTokenRejectsForeignState(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	provider := newOIDCProvider(t, issuer, "")
+	other := newOIDCProvider(t, issuer, "another-state-secret")
+
+	authURL, err := url.Parse(other.GetAuthURL("state-1"))
+	require.NoError(t, err)
+	code := issuer.IssueCode(authURL.Query().Get("code_challenge"), keycloakClaims(issuer))
+
+	_, err = provider.GetToken(code, authURL.Query().Get("state"))
+	assert.ErrorContains(t, err, "unknown or expired login state")
+	_, err = provider.GetToken(code, "state-1")
+	assert.ErrorContains(t, err, "unknown or expired login state")
+}
+
+func TestOIDCAuthProvider_getTokenRejectsExpiredState(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	provider := newOIDCProviderWithConfig(t, issuer, func(config *driver.OIDCConfig) {
+		config.PKCETTL = -time.Minute
+	})
+
+	authURL, err := url.Parse(provider.GetAuthURL("state-1"))
+	require.NoError(t, err)
+	code := issuer.IssueCode(authURL.Query().Get("code_challenge"), keycloakClaims(issuer))
+
+	_, err = provider.GetToken(code, authURL.Query().Get("state"))
+	assert.ErrorContains(t, err, "unknown or expired login state")
+}
+
+func TestOIDCAuthProvider_getTokenFailsWithForeignVerifier(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	provider := newOIDCProvider(t, issuer, "")
+
+	authURL, err := url.Parse(provider.GetAuthURL("state-1"))
+	require.NoError(t, err)
+	code := issuer.IssueCode("challenge-of-another-login", keycloakClaims(issuer))
+
+	_, err = provider.GetToken(code, authURL.Query().Get("state"))
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestOIDCAuthProvider_refreshToken(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	provider := newOIDCProvider(t, issuer, "")
+
+	authURL, err := url.Parse(provider.GetAuthURL("state-1"))
+	require.NoError(t, err)
+	token, err := provider.GetToken(issuer.IssueCode(authURL.Query().Get("code_challenge"), keycloakClaims(issuer)), authURL.Query().Get("state"))
+	require.NoError(t, err)
+
+	refreshed, err := provider.RefreshToken(token.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, token.RefreshToken, refreshed.RefreshToken)
+	assert.True(t, refreshed.Expiry.After(time.Now()))
+
+	_, err = provider.RefreshToken("revoked")
+	assert.Error(t, err)
+}
+
+func TestOIDCAuthProvider_parseTokenEnforcesAudience(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	provider := newOIDCProvider(t, issuer, "")
+
+	_, err := provider.ParseToken(issuer.Mint(t, issuer.Claims("user-42", "another-app")))
+	assert.ErrorIs(t, err, driver.ErrTokenAudience)
+
+	claims, err := provider.ParseToken(issuer.Mint(t, issuer.Claims("user-42", "raidark")))
+	require.NoError(t, err)
+	assert.Equal(t, "user-42", claims.Username, "falls back to the subject")
+}
+
+func TestOIDCAuthProvider_userManagementIsUnsupported(t *testing.T) {
+	provider := newOIDCProvider(t, oidc.NewIssuer(t), "")
+
+	_, err := provider.GetUsers()
+	assert.ErrorIs(t, err, domain.ErrUnsupported)
+	_, err = provider.AddUser(&domain.User{})
+	assert.ErrorIs(t, err, domain.ErrUnsupported)
+	assert.NoError(t, provider.HealthCheck())
+}
+
+func TestOIDCAuthProvider_initializeRejectsIssuerMismatch(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	provider := driver.NewOIDCAuthProvider(&driver.OIDCConfig{
+		IssuerURL: issuer.URL + "/realms/other",
+		ClientId:  "raidark",
+	})
+
+	assert.Error(t, provider.Initialize())
+}
+
+func newOIDCProvider(t *testing.T, issuer *oidc.Issuer, stateSecret string) *driver.OIDCAuthProvider {
+	t.Helper()
+	return newOIDCProviderWithConfig(t, issuer, func(config *driver.OIDCConfig) {
+		config.StateSecret = stateSecret
+	})
+}
+
+func newOIDCProviderWithConfig(t *testing.T, issuer *oidc.Issuer, configure func(*driver.OIDCConfig)) *driver.OIDCAuthProvider {
+	t.Helper()
+	config := &driver.OIDCConfig{
+		IssuerURL:     issuer.URL,
+		ClientId:      "raidark",
+		ClientSecret:  "secret",
+		RedirectURI:   "http://localhost:3000/callback",
+		Scopes:        []string{"openid", "profile"},
+		Audience:      []string{"raidark"},
+		JWKSCacheTTL:  time.Hour,
+		ClockSkew:     time.Minute,
+		UsernameClaim: "preferred_username",
+		RolesClaim:    "realm_access.roles",
+		PKCETTL:       time.Minute,
+	}
+	configure(config)
+	provider := driver.NewOIDCAuthProvider(config)
+	require.NoError(t, provider.Initialize())
+	return provider
+}
+
+func keycloakClaims(issuer *oidc.Issuer) map[string]any {
+	claims := issuer.Claims("user-42", "raidark")
+	claims["preferred_username"] = "carol"
+	claims["name"] = "Carol Danvers"
+	claims["email"] = "carol@acme.test"
+	claims["realm_access"] = map[string]any{"roles": []string{"manager", "clerk"}}
+	return claims
+}
@@ -0,0 +1,65 @@
+package driver
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// OIDCConfig holds the configuration for a generic OpenID Connect issuer
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientId     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string
+
+	// Token validation. Audience defaults to the client ID.
+	Audience     []string
+	JWKSCacheTTL time.Duration
+	ClockSkew    time.Duration
+
+	// Claim mapping. Nested claims use dots, e.g. "realm_access.roles".
+	UsernameClaim     string
+	RolesClaim        string
+	OrganizationClaim string
+
+	// PKCETTL bounds the time between GetAuthURL and GetToken
+	PKCETTL time.Duration
+	// StateSecret encrypts the PKCE verifier into the login state, so any
+	// instance sharing it can complete a login. It defaults to ClientSecret;
+	// without either, a per-process key is used.
+	StateSecret string
+}
+
+// NewOIDCConfigFromEnv creates a new OIDCConfig from environment variables
+func NewOIDCConfigFromEnv() *OIDCConfig {
+	clientId := os.Getenv("OIDC_CLIENT_ID")
+
+	return &OIDCConfig{
+		IssuerURL:         strings.TrimRight(os.Getenv("OIDC_ISSUER_URL"), "/"),
+		ClientId:          clientId,
+		ClientSecret:      os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURI:       getEnvOrDefault("OIDC_REDIRECT_URI", "http://localhost:8080/callback"),
+		Scopes:            splitEnvList(getEnvOrDefault("OIDC_SCOPES", "openid,profile,email,offline_access")),
+		Audience:          splitEnvList(getEnvOrDefault("OIDC_AUDIENCE", clientId)),
+		JWKSCacheTTL:      getEnvSecondsOrDefault("OIDC_JWKS_CACHE_TTL_SECONDS", time.Hour),
+		ClockSkew:         getEnvSecondsOrDefault("OIDC_CLOCK_SKEW_SECONDS", DefaultJWTClockSkew),
+		UsernameClaim:     getEnvOrDefault("OIDC_USERNAME_CLAIM", "preferred_username"),
+		RolesClaim:        getEnvOrDefault("OIDC_ROLES_CLAIM", "roles"),
+		OrganizationClaim: os.Getenv("OIDC_ORGANIZATION_CLAIM"),
+		PKCETTL:           getEnvSecondsOrDefault("OIDC_PKCE_TTL_SECONDS", 10*time.Minute),
+		StateSecret:       os.Getenv("OIDC_STATE_SECRET"),
+	}
+}
+
+// Validate checks if all required configuration fields are present
+func (c *OIDCConfig) Validate() error {
+	if c.IssuerURL == "" {
+		return newOIDCError("OIDC_ISSUER_URL is required")
+	}
+	if c.ClientId == "" {
+		return newOIDCError("OIDC_CLIENT_ID is required")
+	}
+	return nil
+}
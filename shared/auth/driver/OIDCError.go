@@ -0,0 +1,37 @@
+package driver
+
+import "fmt"
+
+// OIDCError represents an error in OpenID Connect operations
+type OIDCError struct {
+	Message string
+	Cause   error
+}
+
+// Error implements the error interface
+func (e *OIDCError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("oidc error: %s, caused by: %v", e.Message, e.Cause)
+	}
+	return fmt.Sprintf("oidc error: %s", e.Message)
+}
+
+// Unwrap implements the errors.Unwrap interface
+func (e *OIDCError) Unwrap() error {
+	return e.Cause
+}
+
+// newOIDCError creates a new OIDCError with a message
+func newOIDCError(message string) *OIDCError {
+	return &OIDCError{
+		Message: message,
+	}
+}
+
+// newOIDCErrorWithCause creates a new OIDCError with a message and cause
+func newOIDCErrorWithCause(message string, cause error) *OIDCError {
+	return &OIDCError{
+		Message: message,
+		Cause:   cause,
+	}
+}
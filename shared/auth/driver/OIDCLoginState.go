@@ -0,0 +1,68 @@
+package driver
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// oidcLoginState is what the state sent to the issuer carries: the caller's
+// own state and the PKCE verifier of the login, until it expires
+type oidcLoginState struct {
+	State     string `json:"s"`
+	Verifier  string `json:"v"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// newOIDCStateCipher derives the AES-256-GCM cipher sealing login states
+// from secret, or from a random key when secret is empty
+func newOIDCStateCipher(secret string) (cipher.AEAD, error) {
+	var key [32]byte
+	if secret != "" {
+		key = sha256.Sum256([]byte("raidark oidc login state\n" + secret))
+	} else if _, err := rand.Read(key[:]); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealLoginState encrypts state so that only holders of the key can read
+// the verifier, and tampering is detected
+func sealLoginState(aead cipher.AEAD, state oidcLoginState) (string, error) {
+	plaintext, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// openLoginState decrypts a sealed state, rejecting forged and expired ones
+func openLoginState(aead cipher.AEAD, sealed string, now time.Time) (oidcLoginState, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(data) < aead.NonceSize() {
+		return oidcLoginState{}, false
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return oidcLoginState{}, false
+	}
+
+	var state oidcLoginState
+	if err := json.Unmarshal(plaintext, &state); err != nil || now.Unix() > state.ExpiresAt {
+		return oidcLoginState{}, false
+	}
+	return state, true
+}
@@ -0,0 +1,122 @@
+package driver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/auth/driver/repositories"
+	"github.com/r0x16/Raidark/shared/auth/service"
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// SessionReaperConfig holds the schedule of the expired session reaper
+type SessionReaperConfig struct {
+	// Interval is the pause between two runs. Zero disables the reaper.
+	Interval time.Duration
+	// BatchSize is the maximum number of sessions deleted per statement
+	BatchSize int
+	// BatchPause is waited between two batches of the same run
+	BatchPause time.Duration
+}
+
+// NewSessionReaperConfigFromEnv reads the reaper settings
+func NewSessionReaperConfigFromEnv(env domenv.EnvProvider) SessionReaperConfig {
+	return SessionReaperConfig{
+		Interval:   time.Duration(env.GetInt("AUTH_SESSION_REAPER_INTERVAL_SECONDS", 3600)) * time.Second,
+		BatchSize:  env.GetInt("AUTH_SESSION_REAPER_BATCH_SIZE", service.DefaultPruneBatchSize),
+		BatchPause: time.Duration(env.GetInt("AUTH_SESSION_REAPER_BATCH_PAUSE_MS", 100)) * time.Millisecond,
+	}
+}
+
+// SessionReaper deletes expired auth sessions on a schedule. Without it the
+// auth_sessions table keeps every session ever created.
+type SessionReaper struct {
+	config    SessionReaperConfig
+	datastore domdatastore.DatabaseProvider
+	metrics   *observability.Metrics
+	log       domlogger.LogProvider
+	wg        sync.WaitGroup
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// NewSessionReaper creates the reaper. The hub must hold a DatabaseProvider;
+// the MetricsProvider is optional.
+func NewSessionReaper(config SessionReaperConfig, hub *domprovider.ProviderHub) *SessionReaper {
+	ctx, cancel := context.WithCancel(context.Background())
+	reaper := &SessionReaper{
+		config:    config,
+		datastore: domprovider.Get[domdatastore.DatabaseProvider](hub),
+		log:       domprovider.Get[domlogger.LogProvider](hub),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	if domprovider.Exists[obsdomain.MetricsProvider](hub) {
+		reaper.metrics = domprovider.Get[obsdomain.MetricsProvider](hub).Metrics()
+	}
+	return reaper
+}
+
+// Start runs the reaper right away and then every Interval until Stop is
+// called. It does nothing when Interval is zero.
+func (r *SessionReaper) Start() {
+	if r.config.Interval <= 0 {
+		r.log.Info("Session reaper disabled", nil)
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+		for {
+			_, _ = r.Run(r.ctx)
+			select {
+			case <-ticker.C:
+			case <-r.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop cancels the running batch and waits for the loop to exit
+func (r *SessionReaper) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// Run deletes the expired sessions once and returns how many were deleted
+func (r *SessionReaper) Run(ctx context.Context) (int, error) {
+	sessionRepo := repositories.NewGormSessionRepository(r.datastore.GetDataStore().Exec.WithContext(ctx))
+	prune := service.NewAuthPruneService(sessionRepo, r.config.BatchSize, r.config.BatchPause, r.metrics)
+
+	deleted, err := prune.PruneExpiredSessions(ctx)
+	if err != nil {
+		r.log.Error("Session reaper failed", map[string]any{
+			"error":   err,
+			"deleted": deleted,
+		})
+		r.recordRun("failure")
+		return deleted, err
+	}
+
+	if deleted > 0 {
+		r.log.Info("Expired sessions pruned", map[string]any{"deleted": deleted})
+	}
+	r.recordRun("success")
+	return deleted, nil
+}
+
+func (r *SessionReaper) recordRun(outcome string) {
+	if r.metrics != nil {
+		r.metrics.RecordSessionPruneRun(outcome)
+	}
+}
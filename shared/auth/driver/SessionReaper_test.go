@@ -0,0 +1,115 @@
+package driver_test
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/r0x16/Raidark/shared/auth/domain/model"
+	"github.com/r0x16/Raidark/shared/auth/driver"
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	driverenv "github.com/r0x16/Raidark/shared/env/driver"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSessionReaper_runDeletesExpiredSessionsAndCountsTheRun(t *testing.T) {
+	hub, db := newReaperHub(t)
+	metrics := observability.NewMetrics()
+	domprovider.Register[obsdomain.MetricsProvider](hub, reaperMetricsProvider{metrics: metrics})
+	seedSessions(t, db, 3, 1)
+
+	reaper := driver.NewSessionReaper(driver.SessionReaperConfig{Interval: time.Hour, BatchSize: 2}, hub)
+	deleted, err := reaper.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, deleted)
+	assert.Equal(t, 3.0, testutil.ToFloat64(metrics.AuthSessionsPrunedTotal))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AuthSessionPruneRunsTotal.WithLabelValues("success")))
+	assert.Equal(t, int64(1), countSessions(t, db))
+}
+
+func TestSessionReaper_startPrunesRightAwayUntilStopped(t *testing.T) {
+	hub, db := newReaperHub(t)
+	seedSessions(t, db, 2, 0)
+
+	reaper := driver.NewSessionReaper(driver.SessionReaperConfig{Interval: time.Hour, BatchSize: 10}, hub)
+	reaper.Start()
+	assert.Eventually(t, func() bool {
+		var count int64
+		return db.Unscoped().Model(&model.AuthSession{}).Count(&count).Error == nil && count == 0
+	}, time.Second, 10*time.Millisecond)
+	reaper.Stop()
+}
+
+func TestSessionReaper_readsItsScheduleFromEnv(t *testing.T) {
+	t.Setenv("AUTH_SESSION_REAPER_INTERVAL_SECONDS", "0")
+	t.Setenv("AUTH_SESSION_REAPER_BATCH_SIZE", "50")
+
+	config := driver.NewSessionReaperConfigFromEnv(driverenv.NewEnvProvider())
+	assert.Zero(t, config.Interval)
+	assert.Equal(t, 50, config.BatchSize)
+	assert.Equal(t, 100*time.Millisecond, config.BatchPause)
+}
+
+func newReaperHub(t *testing.T) (*domprovider.ProviderHub, *gorm.DB) {
+	t.Helper()
+
+	t.Setenv("DATASTORE_TYPE", "sqlite")
+	t.Setenv("DB_DATABASE", filepath.Join(t.TempDir(), "sessions.db"))
+
+	hub := &domprovider.ProviderHub{}
+	env := domprovider.Register[domenv.EnvProvider](hub, driverenv.NewEnvProvider())
+	domprovider.Register[domlogger.LogProvider](hub, obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical))
+
+	database := driverdatastore.NewGormSqliteDatabaseProvider(env)
+	require.NoError(t, database.Connect())
+	t.Cleanup(func() { _ = database.Close() })
+	domprovider.Register[domdatastore.DatabaseProvider](hub, database)
+
+	db := database.GetDataStore().Exec
+	require.NoError(t, db.AutoMigrate(&model.AuthSession{}))
+	return hub, db
+}
+
+func seedSessions(t *testing.T, db *gorm.DB, expired, active int) {
+	t.Helper()
+	for i := 0; i < expired+active; i++ {
+		refreshExpiry := time.Now().Add(time.Hour)
+		if i < expired {
+			refreshExpiry = time.Now().Add(-time.Hour)
+		}
+		require.NoError(t, db.Create(&model.AuthSession{
+			SessionID:     "session-" + string(rune('a'+i)),
+			UserID:        "alice",
+			Username:      "alice",
+			ExpiresAt:     refreshExpiry,
+			RefreshExpiry: refreshExpiry,
+		}).Error)
+	}
+}
+
+func countSessions(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var count int64
+	require.NoError(t, db.Unscoped().Model(&model.AuthSession{}).Count(&count).Error)
+	return count
+}
+
+type reaperMetricsProvider struct {
+	obsdomain.MetricsProvider
+	metrics *observability.Metrics
+}
+
+func (p reaperMetricsProvider) Metrics() *observability.Metrics { return p.metrics }
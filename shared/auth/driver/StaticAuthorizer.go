@@ -0,0 +1,89 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/r0x16/Raidark/shared/auth/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// StaticGrant allows a role to perform an action on a resource. Resource and
+// Action accept domain.AuthorizerWildcard.
+type StaticGrant struct {
+	Role     string `yaml:"role"`
+	Resource string `yaml:"resource"`
+	Action   string `yaml:"action"`
+}
+
+// StaticGrantsFile is the file format of the grants loaded by
+// LoadStaticGrants. JSON files are accepted too, as JSON is valid YAML.
+//
+//	grants:
+//	  - role: clerk
+//	    resource: orders
+//	    action: read
+type StaticGrantsFile struct {
+	Grants []StaticGrant `yaml:"grants"`
+}
+
+// StaticAuthorizer implements the Authorizer interface with an in-memory list
+// of role grants. It pairs with ArrayAuthProvider for local development and
+// tests, and with providers whose grants are set in a grants file.
+type StaticAuthorizer struct {
+	grants []StaticGrant
+}
+
+// Verify interface implementation
+var _ domain.Authorizer = &StaticAuthorizer{}
+
+// NewStaticAuthorizer creates a new StaticAuthorizer with the given grants
+func NewStaticAuthorizer(grants ...StaticGrant) *StaticAuthorizer {
+	return &StaticAuthorizer{grants: grants}
+}
+
+// DefaultArrayGrants returns the policy used with ArrayAuthProvider: the
+// "admin" role, which its tokens carry, may do everything
+func DefaultArrayGrants() []StaticGrant {
+	return []StaticGrant{
+		{Role: "admin", Resource: domain.AuthorizerWildcard, Action: domain.AuthorizerWildcard},
+	}
+}
+
+// LoadStaticGrants reads a grants file. Every grant needs a role, a resource
+// and an action.
+func LoadStaticGrants(path string) ([]StaticGrant, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read grants file: %w", err)
+	}
+
+	file := StaticGrantsFile{}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode grants file %s: %w", path, err)
+	}
+	for i, grant := range file.Grants {
+		if grant.Role == "" || grant.Resource == "" || grant.Action == "" {
+			return nil, fmt.Errorf("grants file %s: grant %d needs a role, a resource and an action", path, i+1)
+		}
+	}
+	return file.Grants, nil
+}
+
+// Authorize implements domain.Authorizer
+func (a *StaticAuthorizer) Authorize(_ context.Context, claims *domain.Claims, resource, action string) (bool, error) {
+	for _, grant := range a.grants {
+		if claims.HasAnyRole(grant.Role) && matchesGrant(grant.Resource, resource) && matchesGrant(grant.Action, action) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchesGrant compares a granted value with a requested one. Actions and
+// resources are case-insensitive, as Casdoor stores actions capitalized.
+func matchesGrant(granted, requested string) bool {
+	return granted == domain.AuthorizerWildcard || strings.EqualFold(granted, requested)
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -103,7 +104,7 @@ func (ec *ExchangeController) Exchange(c echo.Context) error {
 	}
 
 	// Initialize services
-	authService := ec.initializeAuthService(ec.Datastore)
+	authService := ec.initializeAuthService(c.Request().Context(), ec.Datastore)
 	if authService == nil {
 		ec.Log.Error("Failed to initialize authentication service", nil)
 		return rest.RenderError(c, http.StatusInternalServerError, &rest.RESTError{
@@ -191,8 +192,9 @@ func (ec *ExchangeController) extractClientInfo(c echo.Context) (string, string)
 	return userAgent, ipAddress
 }
 
-// initializeAuthService creates and returns an instance of the authentication service
-func (ec *ExchangeController) initializeAuthService(dbProvider domdatastore.DatabaseProvider) *service.AuthExchangeService {
+// initializeAuthService creates and returns an instance of the authentication service,
+// running its queries under ctx
+func (ec *ExchangeController) initializeAuthService(ctx context.Context, dbProvider domdatastore.DatabaseProvider) *service.AuthExchangeService {
 	// Additional safety check - this should not happen if ExchangeAction validation works correctly
 	if ec.Auth == nil {
 		ec.Log.Error("AuthProvider is nil in ExchangeController", nil)
@@ -216,7 +218,7 @@ func (ec *ExchangeController) initializeAuthService(dbProvider domdatastore.Data
 		return nil
 	}
 
-	sessionRepo := repositories.NewGormSessionRepository(dbProvider.GetDataStore().Exec)
+	sessionRepo := repositories.NewGormSessionRepository(dbProvider.GetDataStore().Exec.WithContext(ctx))
 	if sessionRepo == nil {
 		ec.Log.Error("Failed to create session repository", nil)
 		return nil
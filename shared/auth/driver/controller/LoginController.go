@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+	"github.com/r0x16/Raidark/shared/api/rest"
+	"github.com/r0x16/Raidark/shared/auth/domain"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// LoginController starts the OAuth2 authorization code flow
+type LoginController struct {
+	Auth domain.AuthProvider
+}
+
+// LoginAction creates a LoginController instance and delegates to the Login method
+func LoginAction(c echo.Context, hub *domprovider.ProviderHub) error {
+	if !domprovider.Exists[domain.AuthProvider](hub) {
+		return rest.RenderError(c, http.StatusInternalServerError, &rest.RESTError{
+			Code:    "auth.provider_missing",
+			Message: "Authentication provider not configured.",
+		})
+	}
+
+	controller := &LoginController{
+		Auth: domprovider.Get[domain.AuthProvider](hub),
+	}
+	return controller.Login(c)
+}
+
+// Login returns the URL the user has to be sent to, and the state the
+// issuer will send back with the authorization code. The optional state
+// query parameter is carried inside it; a random one is used otherwise.
+func (lc *LoginController) Login(c echo.Context) error {
+	state := c.QueryParam("state")
+	if state == "" {
+		var random [16]byte
+		if _, err := rand.Read(random[:]); err != nil {
+			return rest.RenderError(c, http.StatusInternalServerError, &rest.RESTError{
+				Code:    "auth.login_unavailable",
+				Message: "Failed to start the login.",
+			})
+		}
+		state = hex.EncodeToString(random[:])
+	}
+
+	authURL, err := url.Parse(lc.Auth.GetAuthURL(state))
+	if err != nil || authURL.String() == "" {
+		return rest.RenderError(c, http.StatusServiceUnavailable, &rest.RESTError{
+			Code:    "auth.login_unavailable",
+			Message: "Failed to start the login.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, domain.LoginResponse{
+		AuthorizationURL: authURL.String(),
+		State:            authURL.Query().Get("state"),
+	})
+}
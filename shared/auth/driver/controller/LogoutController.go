@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
@@ -45,7 +46,7 @@ func (lc *LogoutController) Logout(c echo.Context) error {
 	}
 
 	// Initialize auth service
-	authService := lc.initializeAuthService(lc.Datastore)
+	authService := lc.initializeAuthService(c.Request().Context(), lc.Datastore)
 
 	// Invalidate session in database
 	err = lc.invalidateSession(authService, sessionID)
@@ -108,9 +109,10 @@ func (lc *LogoutController) handleNoSession(c echo.Context, err error) error {
 	return c.JSON(http.StatusOK, response)
 }
 
-// initializeAuthService creates and returns an instance of the logout service
-func (lc *LogoutController) initializeAuthService(dbProvider domdatastore.DatabaseProvider) *service.AuthLogoutService {
-	sessionRepo := repositories.NewGormSessionRepository(dbProvider.GetDataStore().Exec)
+// initializeAuthService creates and returns an instance of the logout service,
+// running its queries under ctx
+func (lc *LogoutController) initializeAuthService(ctx context.Context, dbProvider domdatastore.DatabaseProvider) *service.AuthLogoutService {
+	sessionRepo := repositories.NewGormSessionRepository(dbProvider.GetDataStore().Exec.WithContext(ctx))
 	return service.NewAuthLogoutService(sessionRepo, lc.Auth, lc.Events)
 }
 
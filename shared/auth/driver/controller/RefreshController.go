@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"time"
@@ -49,7 +50,7 @@ func (rc *RefreshController) Refresh(c echo.Context) error {
 	userAgent, ipAddress := rc.extractClientInfo(c)
 
 	// Initialize auth service
-	authService := rc.initializeAuthService(rc.Datastore)
+	authService := rc.initializeAuthService(c.Request().Context(), rc.Datastore)
 
 	// Attempt to refresh tokens
 	session, token, err := rc.refreshTokens(authService, sessionID, userAgent, ipAddress)
@@ -96,9 +97,10 @@ func (rc *RefreshController) extractClientInfo(c echo.Context) (string, string)
 	return userAgent, ipAddress
 }
 
-// initializeAuthService creates and returns an instance of the refresh service
-func (rc *RefreshController) initializeAuthService(dbProvider domdatastore.DatabaseProvider) *service.AuthRefreshService {
-	sessionRepo := repositories.NewGormSessionRepository(dbProvider.GetDataStore().Exec)
+// initializeAuthService creates and returns an instance of the refresh service,
+// running its queries under ctx
+func (rc *RefreshController) initializeAuthService(ctx context.Context, dbProvider domdatastore.DatabaseProvider) *service.AuthRefreshService {
+	sessionRepo := repositories.NewGormSessionRepository(dbProvider.GetDataStore().Exec.WithContext(ctx))
 	return service.NewAuthRefreshService(sessionRepo, rc.Auth)
 }
 
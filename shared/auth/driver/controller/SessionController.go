@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/r0x16/Raidark/shared/api/rest"
+	"github.com/r0x16/Raidark/shared/auth/domain"
+	"github.com/r0x16/Raidark/shared/auth/domain/model"
+	"github.com/r0x16/Raidark/shared/auth/driver/repositories"
+	"github.com/r0x16/Raidark/shared/auth/service"
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	domevents "github.com/r0x16/Raidark/shared/events/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// SessionController lists and revokes auth sessions. The self-service
+// actions act on the authenticated user; the admin actions on the user in
+// the :userId path parameter.
+type SessionController struct {
+	Datastore domdatastore.DatabaseProvider
+	Auth      domain.AuthProvider
+	Log       domlogger.LogProvider
+	Events    domevents.DomainEventsProvider
+}
+
+// ListSessionsAction lists the active sessions of the authenticated user
+func ListSessionsAction(c echo.Context, hub *domprovider.ProviderHub) error {
+	return newSessionController(hub).withCaller(c, (*SessionController).List)
+}
+
+// RevokeSessionAction revokes one session of the authenticated user
+func RevokeSessionAction(c echo.Context, hub *domprovider.ProviderHub) error {
+	return newSessionController(hub).withCaller(c, (*SessionController).Revoke)
+}
+
+// RevokeAllSessionsAction revokes every session of the authenticated user
+func RevokeAllSessionsAction(c echo.Context, hub *domprovider.ProviderHub) error {
+	return newSessionController(hub).withCaller(c, (*SessionController).RevokeAll)
+}
+
+// AdminListSessionsAction lists the active sessions of any user
+func AdminListSessionsAction(c echo.Context, hub *domprovider.ProviderHub) error {
+	return newSessionController(hub).List(c, c.Param("userId"))
+}
+
+// AdminRevokeSessionAction revokes one session of any user
+func AdminRevokeSessionAction(c echo.Context, hub *domprovider.ProviderHub) error {
+	return newSessionController(hub).Revoke(c, c.Param("userId"))
+}
+
+// AdminRevokeAllSessionsAction revokes every session of any user
+func AdminRevokeAllSessionsAction(c echo.Context, hub *domprovider.ProviderHub) error {
+	return newSessionController(hub).RevokeAll(c, c.Param("userId"))
+}
+
+// newSessionController builds the controller from the hub. The events
+// provider is optional: without one, revocations publish no event.
+func newSessionController(hub *domprovider.ProviderHub) *SessionController {
+	events, _ := domprovider.Lookup[domevents.DomainEventsProvider](hub)
+	return &SessionController{
+		Datastore: domprovider.Get[domdatastore.DatabaseProvider](hub),
+		Auth:      domprovider.Get[domain.AuthProvider](hub),
+		Log:       domprovider.Get[domlogger.LogProvider](hub),
+		Events:    events,
+	}
+}
+
+// List returns the active sessions of userID
+func (sc *SessionController) List(c echo.Context, userID string) error {
+	sessions, err := sc.initializeSessionService(c).ListActiveSessions(userID)
+	if err != nil {
+		return sc.renderFailure(c, "Failed to list sessions", userID, err)
+	}
+
+	currentSessionID := sc.currentSessionID(c)
+	response := domain.SessionListResponse{Sessions: make([]domain.SessionResponse, 0, len(sessions))}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, sc.buildSessionResponse(session, currentSessionID))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Revoke deletes the session in the :id path parameter when it belongs to userID
+func (sc *SessionController) Revoke(c echo.Context, userID string) error {
+	session, err := sc.initializeSessionService(c).RevokeSession(userID, c.Param("id"))
+	if errors.Is(err, service.ErrSessionNotFound) {
+		status, restErr := rest.MapError(rest.ErrNotFound)
+		return rest.RenderError(c, status, restErr)
+	}
+	if err != nil {
+		return sc.renderFailure(c, "Failed to revoke session", userID, err)
+	}
+
+	if session.SessionID == sc.currentSessionID(c) {
+		sc.clearSessionCookie(c)
+	}
+	sc.logRevocation(c, userID, 1)
+
+	return c.JSON(http.StatusOK, domain.RevokeSessionsResponse{
+		Message: "Session revoked",
+		Revoked: 1,
+	})
+}
+
+// RevokeAll deletes every session of userID ("log out everywhere")
+func (sc *SessionController) RevokeAll(c echo.Context, userID string) error {
+	revoked, err := sc.initializeSessionService(c).RevokeAllSessions(userID)
+	if err != nil {
+		return sc.renderFailure(c, "Failed to revoke sessions", userID, err)
+	}
+
+	if sc.isCaller(c, userID) {
+		sc.clearSessionCookie(c)
+	}
+	sc.logRevocation(c, userID, revoked)
+
+	return c.JSON(http.StatusOK, domain.RevokeSessionsResponse{
+		Message: "Sessions revoked",
+		Revoked: revoked,
+	})
+}
+
+// withCaller runs action for the subject of the authenticated claims
+func (sc *SessionController) withCaller(c echo.Context, action func(*SessionController, echo.Context, string) error) error {
+	claims, ok := c.Get("user").(*domain.Claims)
+	if !ok || claims == nil || claims.Subject == "" {
+		return rest.RenderError(c, http.StatusUnauthorized, &rest.RESTError{
+			Code:    "auth.unauthenticated",
+			Message: "Authentication is required to access this resource.",
+		})
+	}
+	return action(sc, c, claims.Subject)
+}
+
+// initializeSessionService creates and returns an instance of the session
+// service, running its queries under the request context
+func (sc *SessionController) initializeSessionService(c echo.Context) *service.AuthSessionService {
+	sessionRepo := repositories.NewGormSessionRepository(sc.Datastore.GetDataStore().Exec.WithContext(c.Request().Context()))
+	return service.NewAuthSessionService(sessionRepo, sc.Auth, sc.Events, sc.Log)
+}
+
+// buildSessionResponse exposes a session without its cookie value or tokens
+func (sc *SessionController) buildSessionResponse(session *model.AuthSession, currentSessionID string) domain.SessionResponse {
+	return domain.SessionResponse{
+		ID:            string(session.ID),
+		UserID:        session.UserID,
+		UserAgent:     session.UserAgent,
+		IPAddress:     session.IPAddress,
+		CreatedAt:     session.CreatedAt,
+		ExpiresAt:     session.ExpiresAt,
+		RefreshExpiry: session.RefreshExpiry,
+		Current:       currentSessionID != "" && session.SessionID == currentSessionID,
+	}
+}
+
+// currentSessionID returns the session cookie of the request, if any
+func (sc *SessionController) currentSessionID(c echo.Context) string {
+	cookie, err := c.Cookie("app_session")
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func (sc *SessionController) isCaller(c echo.Context, userID string) bool {
+	claims, ok := c.Get("user").(*domain.Claims)
+	return ok && claims != nil && claims.Subject == userID
+}
+
+// clearSessionCookie clears the session cookie from the HTTP response
+func (sc *SessionController) clearSessionCookie(c echo.Context) {
+	c.SetCookie(&http.Cookie{
+		Name:     "app_session",
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		MaxAge:   -1,
+	})
+}
+
+func (sc *SessionController) renderFailure(c echo.Context, message, userID string, err error) error {
+	sc.Log.Error(message, map[string]any{
+		"error":   err.Error(),
+		"user_id": userID,
+	})
+	status, restErr := rest.MapError(err)
+	return rest.RenderError(c, status, restErr)
+}
+
+func (sc *SessionController) logRevocation(c echo.Context, userID string, revoked int) {
+	fields := map[string]any{
+		"user_id": userID,
+		"revoked": revoked,
+		"ip":      c.RealIP(),
+	}
+	if claims, ok := c.Get("user").(*domain.Claims); ok && claims != nil {
+		fields["revoked_by"] = claims.Username
+	}
+	sc.Log.Info("Sessions revoked", fields)
+}
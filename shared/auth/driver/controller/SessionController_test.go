@@ -0,0 +1,69 @@
+package controller_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	domauth "github.com/r0x16/Raidark/shared/auth/domain"
+	"github.com/r0x16/Raidark/shared/auth/domain/model"
+	driverauth "github.com/r0x16/Raidark/shared/auth/driver"
+	"github.com/r0x16/Raidark/shared/auth/driver/controller"
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	driverenv "github.com/r0x16/Raidark/shared/env/driver"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionController_revokesWithoutEventsProvider(t *testing.T) {
+	t.Setenv("DATASTORE_TYPE", "sqlite")
+	t.Setenv("DB_DATABASE", filepath.Join(t.TempDir(), "sessions.db"))
+
+	hub := &domprovider.ProviderHub{}
+	env := domprovider.Register[domenv.EnvProvider](hub, driverenv.NewEnvProvider())
+	domprovider.Register[domlogger.LogProvider](hub, obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical))
+	domprovider.Register[domauth.AuthProvider](hub, driverauth.NewArrayAuthProvider())
+	database := driverdatastore.NewGormSqliteDatabaseProvider(env)
+	require.NoError(t, database.Connect())
+	t.Cleanup(func() { _ = database.Close() })
+	domprovider.Register[domdatastore.DatabaseProvider](hub, database)
+
+	db := database.GetDataStore().Exec
+	require.NoError(t, db.AutoMigrate(&model.AuthSession{}))
+	session := &model.AuthSession{
+		SessionID:     "session-a",
+		UserID:        "alice",
+		Username:      "alice",
+		ExpiresAt:     time.Now().Add(time.Hour),
+		RefreshExpiry: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, db.Create(session).Error)
+
+	c, recorder := newSessionContext("alice", string(session.ID))
+	require.NotPanics(t, func() {
+		require.NoError(t, controller.AdminRevokeSessionAction(c, hub))
+	})
+
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var remaining int64
+	require.NoError(t, db.Model(&model.AuthSession{}).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+}
+
+func newSessionContext(userID, sessionID string) (echo.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(http.MethodDelete, "/", nil)
+	recorder := httptest.NewRecorder()
+	c := echo.New().NewContext(request, recorder)
+	c.SetParamNames("userId", "id")
+	c.SetParamValues(userID, sessionID)
+	return c, recorder
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/r0x16/Raidark/shared/auth/domain/model"
 	"github.com/r0x16/Raidark/shared/auth/domain/repositories"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	"github.com/r0x16/Raidark/shared/datastore/encryption"
 	"gorm.io/gorm"
 )
 
@@ -16,10 +18,12 @@ type GormSessionRepository struct {
 // Verify interface implementation
 var _ repositories.SessionRepository = &GormSessionRepository{}
 
-// NewGormSessionRepository creates a new GORM session repository instance
+// NewGormSessionRepository creates a new GORM session repository instance.
+// Reads are pinned to the primary, so a session revoked or refreshed a moment
+// ago is never read back from a lagging replica.
 func NewGormSessionRepository(db *gorm.DB) *GormSessionRepository {
 	return &GormSessionRepository{
-		db: db,
+		db: driverdatastore.OnPrimary(db),
 	}
 }
 
@@ -67,6 +71,55 @@ func (r *GormSessionRepository) DeleteExpiredSessions() error {
 	return r.db.Where("refresh_expiry < ?", now).Delete(&model.AuthSession{}).Error
 }
 
+// DeleteExpiredBatch implements repositories.SessionRepository. The rows are
+// selected first and deleted by primary key, so each call locks at most
+// limit rows. Soft-deleted sessions are included: revoked sessions are
+// purged once their refresh token expires.
+func (r *GormSessionRepository) DeleteExpiredBatch(before time.Time, limit int) (int, error) {
+	var ids []string
+	err := r.db.Unscoped().Model(&model.AuthSession{}).
+		Where("refresh_expiry < ?", before).
+		Order("refresh_expiry").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	result := r.db.Unscoped().Where("id IN ?", ids).Delete(&model.AuthSession{})
+	return int(result.RowsAffected), result.Error
+}
+
+// ReencryptBatch rewrites up to limit sessions whose tokens are not sealed
+// with the active key of the installed keyring, plaintext ones included, and
+// returns how many were rewritten. Call it until it returns 0 after a key
+// rotation.
+func (r *GormSessionRepository) ReencryptBatch(limit int) (int, error) {
+	keyring := encryption.Current()
+	if keyring == nil {
+		return 0, encryption.ErrNoKeyring
+	}
+
+	current := encryption.Prefix + keyring.ActiveKeyID() + ":%"
+	var sessions []*model.AuthSession
+	err := r.db.Unscoped().
+		Where("(refresh_token <> '' AND refresh_token NOT LIKE ?) OR (access_token <> '' AND access_token NOT LIKE ?)", current, current).
+		Order("id").
+		Limit(limit).
+		Find(&sessions).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for rewritten, session := range sessions {
+		err := r.db.Unscoped().Model(session).Select("RefreshToken", "AccessToken").Updates(session).Error
+		if err != nil {
+			return rewritten, err
+		}
+	}
+	return len(sessions), nil
+}
+
 // FindByUserID implements repositories.SessionRepository
 func (r *GormSessionRepository) FindByUserID(userID string) ([]*model.AuthSession, error) {
 	var sessions []*model.AuthSession
@@ -0,0 +1,123 @@
+package repositories_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/auth/domain/model"
+	"github.com/r0x16/Raidark/shared/auth/driver/repositories"
+	"github.com/r0x16/Raidark/shared/datastore/encryption"
+	"github.com/r0x16/Raidark/shared/internal/testutil/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormSessionRepository_encryptsTokensTransparently(t *testing.T) {
+	useKeyring(t, "k1", "k1")
+	database := db.NewSQLite(t, &model.AuthSession{})
+	repo := repositories.NewGormSessionRepository(database)
+
+	require.NoError(t, repo.Create(newSession("s1", "refresh-1", "access-1")))
+
+	refresh, access := rawTokens(t, database, "s1")
+	assert.True(t, encryption.IsEncrypted(refresh))
+	assert.True(t, encryption.IsEncrypted(access))
+
+	session, err := repo.FindBySessionID("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-1", session.RefreshToken)
+	assert.Equal(t, "access-1", session.AccessToken)
+}
+
+func TestGormSessionRepository_reencryptsOldAndPlaintextRows(t *testing.T) {
+	database := db.NewSQLite(t, &model.AuthSession{})
+	repo := repositories.NewGormSessionRepository(database)
+
+	useKeyring(t, "", "")
+	require.NoError(t, repo.Create(newSession("plain", "refresh-p", "access-p")))
+	useKeyring(t, "k1", "k1")
+	require.NoError(t, repo.Create(newSession("old", "refresh-o", "access-o")))
+	revoked := newSession("revoked", "refresh-r", "")
+	require.NoError(t, repo.Create(revoked))
+	require.NoError(t, repo.Delete(revoked))
+
+	useKeyring(t, "k2", "k1", "k2")
+	total := 0
+	for {
+		rewritten, err := repo.ReencryptBatch(1)
+		require.NoError(t, err)
+		if rewritten == 0 {
+			break
+		}
+		total += rewritten
+	}
+	assert.Equal(t, 3, total)
+
+	useKeyring(t, "k2", "k2")
+	for id, expected := range map[string][2]string{
+		"plain":   {"refresh-p", "access-p"},
+		"old":     {"refresh-o", "access-o"},
+		"revoked": {"refresh-r", ""},
+	} {
+		refresh, access := rawTokens(t, database, id)
+		assert.True(t, encryption.Current().IsCurrent(refresh), id)
+		assert.Equal(t, expected[1] == "", access == "", id)
+
+		var session model.AuthSession
+		require.NoError(t, database.Unscoped().Where("session_id = ?", id).First(&session).Error)
+		assert.Equal(t, expected[0], session.RefreshToken)
+		assert.Equal(t, expected[1], session.AccessToken)
+	}
+}
+
+func TestGormSessionRepository_reencryptRequiresAKeyring(t *testing.T) {
+	useKeyring(t, "", "")
+	repo := repositories.NewGormSessionRepository(db.NewSQLite(t, &model.AuthSession{}))
+
+	_, err := repo.ReencryptBatch(10)
+	assert.ErrorIs(t, err, encryption.ErrNoKeyring)
+}
+
+// useKeyring installs a keyring holding ids, or disables encryption when
+// active is empty
+func useKeyring(t *testing.T, active string, ids ...string) {
+	t.Helper()
+	previous := encryption.Current()
+	t.Cleanup(func() { encryption.Use(previous) })
+
+	if active == "" {
+		encryption.Use(nil)
+		return
+	}
+	keys := map[string][]byte{}
+	for _, id := range ids {
+		keys[id] = bytes.Repeat([]byte(id[1:]), encryption.KeySize)
+	}
+	keyring, err := encryption.NewKeyring(active, keys)
+	require.NoError(t, err)
+	encryption.Use(keyring)
+}
+
+func newSession(sessionID, refreshToken, accessToken string) *model.AuthSession {
+	return &model.AuthSession{
+		SessionID:     sessionID,
+		UserID:        "alice",
+		Username:      "alice",
+		RefreshToken:  refreshToken,
+		AccessToken:   accessToken,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		RefreshExpiry: time.Now().Add(time.Hour),
+	}
+}
+
+func rawTokens(t *testing.T, database *gorm.DB, sessionID string) (string, string) {
+	t.Helper()
+	var row struct {
+		RefreshToken string
+		AccessToken  string
+	}
+	require.NoError(t, database.Raw("SELECT refresh_token, access_token FROM auth_sessions WHERE session_id = ?", sessionID).Scan(&row).Error)
+	return row.RefreshToken, row.AccessToken
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/auth/domain/repositories"
+	"github.com/r0x16/Raidark/shared/observability"
+)
+
+// DefaultPruneBatchSize is the number of sessions deleted per statement
+const DefaultPruneBatchSize = 500
+
+// AuthPruneService deletes the sessions whose refresh token has expired
+type AuthPruneService struct {
+	sessionRepo repositories.SessionRepository
+	batchSize   int
+	batchPause  time.Duration
+	metrics     *observability.Metrics
+	now         func() time.Time
+}
+
+// NewAuthPruneService creates a new prune service. batchSize bounds the rows
+// deleted per statement and batchPause is waited between two batches, so the
+// table is never locked for long. metrics is optional.
+func NewAuthPruneService(
+	sessionRepo repositories.SessionRepository,
+	batchSize int,
+	batchPause time.Duration,
+	metrics *observability.Metrics,
+) *AuthPruneService {
+	if batchSize < 1 {
+		batchSize = DefaultPruneBatchSize
+	}
+	return &AuthPruneService{
+		sessionRepo: sessionRepo,
+		batchSize:   batchSize,
+		batchPause:  batchPause,
+		metrics:     metrics,
+		now:         time.Now,
+	}
+}
+
+// PruneExpiredSessions deletes expired sessions batch by batch until none is
+// left or ctx is cancelled, and returns how many were deleted
+func (s *AuthPruneService) PruneExpiredSessions(ctx context.Context) (int, error) {
+	cutoff := s.now()
+	total := 0
+
+	for {
+		deleted, err := s.sessionRepo.DeleteExpiredBatch(cutoff, s.batchSize)
+		total += deleted
+		if s.metrics != nil && deleted > 0 {
+			s.metrics.RecordSessionsPruned(deleted)
+		}
+		if err != nil {
+			return total, fmt.Errorf("failed to delete expired sessions: %w", err)
+		}
+		if deleted < s.batchSize {
+			return total, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(s.batchPause):
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/r0x16/Raidark/shared/auth/domain/model"
+	"github.com/r0x16/Raidark/shared/auth/driver/repositories"
+	"github.com/r0x16/Raidark/shared/auth/service"
+	"github.com/r0x16/Raidark/shared/internal/testutil/db"
+	"github.com/r0x16/Raidark/shared/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthPruneService_deletesExpiredSessionsInBatches(t *testing.T) {
+	database := db.NewSQLite(t, &model.AuthSession{})
+	repo := repositories.NewGormSessionRepository(database)
+	for _, agent := range []string{"a", "b", "c", "d", "e"} {
+		createSession(t, repo, "alice", agent, -time.Hour)
+	}
+	active := createSession(t, repo, "alice", "active", time.Hour)
+	metrics := observability.NewMetrics()
+
+	deleted, err := service.NewAuthPruneService(repo, 2, 0, metrics).PruneExpiredSessions(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, deleted)
+	assert.Equal(t, 5.0, testutil.ToFloat64(metrics.AuthSessionsPrunedTotal))
+
+	var remaining []*model.AuthSession
+	require.NoError(t, database.Unscoped().Find(&remaining).Error)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, active.SessionID, remaining[0].SessionID)
+}
+
+func TestAuthPruneService_purgesRevokedSessionsOnceExpired(t *testing.T) {
+	database := db.NewSQLite(t, &model.AuthSession{})
+	repo := repositories.NewGormSessionRepository(database)
+	revoked := createSession(t, repo, "alice", "revoked", -time.Hour)
+	require.NoError(t, repo.Delete(revoked))
+
+	deleted, err := service.NewAuthPruneService(repo, 0, 0, nil).PruneExpiredSessions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	var count int64
+	require.NoError(t, database.Unscoped().Model(&model.AuthSession{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestAuthPruneService_stopsBetweenBatchesWhenCancelled(t *testing.T) {
+	repo := repositories.NewGormSessionRepository(db.NewSQLite(t, &model.AuthSession{}))
+	for _, agent := range []string{"a", "b", "c"} {
+		createSession(t, repo, "alice", agent, -time.Hour)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	deleted, err := service.NewAuthPruneService(repo, 1, time.Hour, nil).PruneExpiredSessions(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, deleted)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/auth/domain"
+	"github.com/r0x16/Raidark/shared/auth/domain/event"
+	"github.com/r0x16/Raidark/shared/auth/domain/model"
+	"github.com/r0x16/Raidark/shared/auth/domain/repositories"
+	domevents "github.com/r0x16/Raidark/shared/events/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+)
+
+// ErrSessionNotFound is returned when the session does not exist or does not
+// belong to the given user
+var ErrSessionNotFound = errors.New("session not found")
+
+// AuthSessionService lists and revokes the sessions of a user
+type AuthSessionService struct {
+	*AuthService
+	events domevents.DomainEventsProvider
+	log    domlogger.LogProvider
+	now    func() time.Time
+}
+
+// NewAuthSessionService creates a new session management service
+func NewAuthSessionService(
+	sessionRepo repositories.SessionRepository,
+	authProvider domain.AuthProvider,
+	events domevents.DomainEventsProvider,
+	log domlogger.LogProvider,
+) *AuthSessionService {
+	authService := NewAuthService(sessionRepo, authProvider)
+	return &AuthSessionService{
+		AuthService: authService,
+		events:      events,
+		log:         log,
+		now:         time.Now,
+	}
+}
+
+// ListActiveSessions returns the sessions of userID that can still be refreshed
+func (s *AuthSessionService) ListActiveSessions(userID string) ([]*model.AuthSession, error) {
+	sessions, err := s.GetUserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]*model.AuthSession, 0, len(sessions))
+	for _, session := range sessions {
+		if !session.IsRefreshExpired() {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// RevokeSession deletes the session with the given ID when it belongs to userID
+func (s *AuthSessionService) RevokeSession(userID, id string) (*model.AuthSession, error) {
+	sessions, err := s.GetUserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		if string(session.ID) != id {
+			continue
+		}
+		if err := s.GetSessionRepo().Delete(session); err != nil {
+			return nil, fmt.Errorf("failed to delete session: %w", err)
+		}
+		s.publishDeleted(session)
+		return session, nil
+	}
+	return nil, ErrSessionNotFound
+}
+
+// RevokeAllSessions deletes every session of userID and returns how many
+// were revoked
+func (s *AuthSessionService) RevokeAllSessions(userID string) (int, error) {
+	sessions, err := s.GetUserSessions(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.InvalidateAllUserSessions(userID); err != nil {
+		return 0, err
+	}
+
+	for _, session := range sessions {
+		s.publishDeleted(session)
+	}
+	return len(sessions), nil
+}
+
+// publishDeleted announces a revoked session. The session is already gone,
+// so a failed publish is logged instead of failing the revocation.
+func (s *AuthSessionService) publishDeleted(session *model.AuthSession) {
+	if s.events == nil {
+		return
+	}
+	err := s.events.Publish(&event.SessionWasDeleted{
+		Session:  session,
+		LogoutAt: s.now(),
+	})
+	if err != nil {
+		s.log.Error("Failed to publish session deleted event", map[string]any{
+			"error":   err.Error(),
+			"id":      string(session.ID),
+			"user_id": session.UserID,
+		})
+	}
+}
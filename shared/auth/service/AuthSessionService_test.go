@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/auth/domain/event"
+	"github.com/r0x16/Raidark/shared/auth/domain/model"
+	"github.com/r0x16/Raidark/shared/auth/driver"
+	"github.com/r0x16/Raidark/shared/auth/driver/repositories"
+	"github.com/r0x16/Raidark/shared/auth/service"
+	domevents "github.com/r0x16/Raidark/shared/events/domain"
+	"github.com/r0x16/Raidark/shared/internal/testutil/db"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthSessionService_listsOnlyActiveSessions(t *testing.T) {
+	sessions, repo, _ := newSessionService(t)
+	createSession(t, repo, "alice", "laptop", time.Hour)
+	createSession(t, repo, "alice", "phone", time.Hour)
+	createSession(t, repo, "alice", "stale", -time.Hour)
+	createSession(t, repo, "bob", "desktop", time.Hour)
+
+	active, err := sessions.ListActiveSessions("alice")
+	require.NoError(t, err)
+
+	agents := make([]string, 0, len(active))
+	for _, session := range active {
+		agents = append(agents, session.UserAgent)
+	}
+	assert.ElementsMatch(t, []string{"laptop", "phone"}, agents)
+}
+
+func TestAuthSessionService_revokesOneSessionOfTheUser(t *testing.T) {
+	sessions, repo, events := newSessionService(t)
+	laptop := createSession(t, repo, "alice", "laptop", time.Hour)
+	createSession(t, repo, "alice", "phone", time.Hour)
+	desktop := createSession(t, repo, "bob", "desktop", time.Hour)
+
+	_, err := sessions.RevokeSession("alice", string(desktop.ID))
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+
+	revoked, err := sessions.RevokeSession("alice", string(laptop.ID))
+	require.NoError(t, err)
+	assert.Equal(t, laptop.SessionID, revoked.SessionID)
+
+	remaining, err := sessions.ListActiveSessions("alice")
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "phone", remaining[0].UserAgent)
+
+	require.Len(t, events.published, 1)
+	assert.Equal(t, laptop.SessionID, events.published[0].(*event.SessionWasDeleted).Session.SessionID)
+}
+
+func TestAuthSessionService_revokesAllSessionsOfTheUser(t *testing.T) {
+	sessions, repo, events := newSessionService(t)
+	createSession(t, repo, "alice", "laptop", time.Hour)
+	createSession(t, repo, "alice", "phone", time.Hour)
+	createSession(t, repo, "bob", "desktop", time.Hour)
+
+	revoked, err := sessions.RevokeAllSessions("alice")
+	require.NoError(t, err)
+	assert.Equal(t, 2, revoked)
+	assert.Len(t, events.published, 2)
+
+	remaining, err := sessions.ListActiveSessions("alice")
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+
+	others, err := sessions.ListActiveSessions("bob")
+	require.NoError(t, err)
+	assert.Len(t, others, 1)
+}
+
+func TestAuthSessionService_logsFailedPublishes(t *testing.T) {
+	repo := repositories.NewGormSessionRepository(db.NewSQLite(t, &model.AuthSession{}))
+	var logs bytes.Buffer
+	events := &recordingEvents{err: errors.New("broker unavailable")}
+	sessions := service.NewAuthSessionService(repo, driver.NewArrayAuthProvider(), events,
+		obslog.NewWithWriter(&logs, obslog.FormatJSON, domlogger.Debug))
+	createSession(t, repo, "alice", "laptop", time.Hour)
+
+	revoked, err := sessions.RevokeAllSessions("alice")
+	require.NoError(t, err)
+	assert.Equal(t, 1, revoked)
+	assert.Contains(t, logs.String(), "Failed to publish session deleted event")
+	assert.Contains(t, logs.String(), "broker unavailable")
+	assert.NotContains(t, logs.String(), "alice-laptop", "the cookie value stays out of the logs")
+}
+
+func newSessionService(t *testing.T) (*service.AuthSessionService, *repositories.GormSessionRepository, *recordingEvents) {
+	t.Helper()
+	repo := repositories.NewGormSessionRepository(db.NewSQLite(t, &model.AuthSession{}))
+	events := &recordingEvents{}
+	log := obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical)
+	return service.NewAuthSessionService(repo, driver.NewArrayAuthProvider(), events, log), repo, events
+}
+
+func createSession(t *testing.T, repo *repositories.GormSessionRepository, userID, userAgent string, refreshIn time.Duration) *model.AuthSession {
+	t.Helper()
+	session := &model.AuthSession{
+		SessionID:     userID + "-" + userAgent,
+		UserID:        userID,
+		Username:      userID,
+		RefreshToken:  "refresh",
+		AccessToken:   "access",
+		ExpiresAt:     time.Now().Add(refreshIn),
+		RefreshExpiry: time.Now().Add(refreshIn),
+		UserAgent:     userAgent,
+		IPAddress:     "127.0.0.1",
+	}
+	require.NoError(t, repo.Create(session))
+	return session
+}
+
+// recordingEvents keeps the published events instead of dispatching them,
+// and fails every publish with err when set
+type recordingEvents struct {
+	published []domevents.DomainEvent
+	err       error
+}
+
+func (r *recordingEvents) Collect() {}
+func (r *recordingEvents) Publish(event domevents.DomainEvent) error {
+	r.published = append(r.published, event)
+	return r.err
+}
+func (r *recordingEvents) Subscribe(domevents.EventListener) error    { return nil }
+func (r *recordingEvents) Dispatch(event domevents.DomainEvent) error { return nil }
+func (r *recordingEvents) Close() error                               { return nil }
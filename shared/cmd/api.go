@@ -1,8 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/r0x16/Raidark/shared/api"
 	domapi "github.com/r0x16/Raidark/shared/api/domain"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domlifecycle "github.com/r0x16/Raidark/shared/lifecycle/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
 	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
 	"github.com/spf13/cobra"
 )
@@ -10,17 +19,84 @@ import (
 var apiCmd = &cobra.Command{
 	Use:   "api",
 	Short: "Start the HTTP API server.",
+	Long: "Start the HTTP API server. On SIGINT or SIGTERM the server stops accepting connections, " +
+		"waits API_SHUTDOWN_TIMEOUT_SECONDS for the in-flight requests, then drains the event queue and " +
+		"closes the providers within SHUTDOWN_TIMEOUT_SECONDS.",
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
 
 		hub := ctx.Value(hubKey).(*domprovider.ProviderHub)
 		modules := ctx.Value(modulesKey).([]domapi.ApiModule)
+		lifecycle := domprovider.Get[domlifecycle.Lifecycle](hub)
+
+		if reaper := startSessionReaper(hub, modules); reaper != nil {
+			lifecycle.Append(domlifecycle.Hook{Name: "session reaper", OnStop: stopWith(reaper.Stop)})
+		}
+		if reaper := startProcessedEventReaper(hub, modules); reaper != nil {
+			lifecycle.Append(domlifecycle.Hook{Name: "processed event reaper", OnStop: stopWith(reaper.Stop)})
+		}
 
 		api := api.NewApi(hub, modules)
-		api.Run()
+		serveUntilSignal(ctx, hub, api)
 	},
 }
 
 func init() {
 	RootCmd.AddCommand(apiCmd)
 }
+
+// serveUntilSignal runs the API until it fails or the process receives
+// SIGINT or SIGTERM, then drains the HTTP server and stops the lifecycle
+// hooks, each within its own deadline
+func serveUntilSignal(ctx context.Context, hub *domprovider.ProviderHub, api *api.Api) {
+	env := domprovider.Get[domenv.EnvProvider](hub)
+	log := domprovider.Get[domlogger.LogProvider](hub)
+	lifecycle := domprovider.Get[domlifecycle.Lifecycle](hub)
+
+	signalCtx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	if err := lifecycle.Start(signalCtx); err != nil {
+		log.Critical("Failed to start the application", map[string]any{"error": err})
+		return
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- api.Run()
+	}()
+
+	select {
+	case err := <-served:
+		if err != nil {
+			log.Critical("API server stopped", map[string]any{"error": err})
+		}
+	case <-signalCtx.Done():
+		timeout := time.Duration(env.GetInt("API_SHUTDOWN_TIMEOUT_SECONDS", 15)) * time.Second
+		log.Info("Shutdown signal received, draining HTTP requests", map[string]any{"timeout": timeout})
+
+		drainCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		if err := api.Shutdown(drainCtx); err != nil {
+			log.Error("HTTP requests did not drain in time", map[string]any{"error": err})
+		}
+		cancel()
+		<-served
+	}
+
+	timeout := time.Duration(env.GetInt("SHUTDOWN_TIMEOUT_SECONDS", 30)) * time.Second
+	stopCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := lifecycle.Stop(stopCtx); err != nil {
+		log.Error("Shutdown completed with errors", map[string]any{"error": err})
+		return
+	}
+	log.Info("Shutdown completed", nil)
+}
+
+// stopWith adapts a blocking Stop method to a lifecycle OnStop callback
+func stopWith(stop func()) func(context.Context) error {
+	return func(context.Context) error {
+		stop()
+		return nil
+	}
+}
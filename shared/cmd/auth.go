@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	apidomain "github.com/r0x16/Raidark/shared/api/domain"
+	"github.com/r0x16/Raidark/shared/auth/domain/model"
+	driverauth "github.com/r0x16/Raidark/shared/auth/driver"
+	"github.com/r0x16/Raidark/shared/auth/driver/repositories"
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	"github.com/r0x16/Raidark/shared/datastore/encryption"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneBatchSize  int
+	rotateBatchSize int
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Maintain authentication data.",
+}
+
+var authPruneSessionsCmd = &cobra.Command{
+	Use:   "prune-sessions",
+	Short: "Delete the auth sessions whose refresh token has expired.",
+	Long: "Delete expired auth sessions in batches, as the reaper started by the api command does. " +
+		"Use it from cron when the reaper is disabled with AUTH_SESSION_REAPER_INTERVAL_SECONDS=0.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hub := cmd.Context().Value(hubKey).(*domprovider.ProviderHub)
+		if !domprovider.Exists[domdatastore.DatabaseProvider](hub) {
+			return errors.New("prune-sessions requires a DatabaseProvider")
+		}
+
+		config := driverauth.NewSessionReaperConfigFromEnv(domprovider.Get[domenv.EnvProvider](hub))
+		if cmd.Flags().Changed("batch-size") {
+			config.BatchSize = pruneBatchSize
+		}
+
+		deleted, err := driverauth.NewSessionReaper(config, hub).Run(cmd.Context())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Pruned %d expired sessions\n", deleted)
+		return nil
+	},
+}
+
+var authRotateTokenKeyCmd = &cobra.Command{
+	Use:   "rotate-token-key",
+	Short: "Re-encrypt the stored session tokens with the active encryption key.",
+	Long: "Rewrite every auth session whose tokens are in plaintext or sealed with another key " +
+		"than DATASTORE_ENCRYPTION_ACTIVE_KEY. Keep the previous keys in DATASTORE_ENCRYPTION_KEYS " +
+		"until the command completes.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hub := cmd.Context().Value(hubKey).(*domprovider.ProviderHub)
+		if !domprovider.Exists[domdatastore.DatabaseProvider](hub) {
+			return errors.New("rotate-token-key requires a DatabaseProvider")
+		}
+		if rotateBatchSize < 1 {
+			return fmt.Errorf("invalid batch size %d: must be a positive integer", rotateBatchSize)
+		}
+
+		database := domprovider.Get[domdatastore.DatabaseProvider](hub).GetDataStore().Exec.WithContext(cmd.Context())
+		sessionRepo := repositories.NewGormSessionRepository(database)
+
+		total := 0
+		for {
+			rewritten, err := sessionRepo.ReencryptBatch(rotateBatchSize)
+			total += rewritten
+			if err != nil {
+				return fmt.Errorf("re-encrypted %d sessions before failing: %w", total, err)
+			}
+			if rewritten == 0 {
+				break
+			}
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Re-encrypted %d sessions with key %q\n", total, encryption.Current().ActiveKeyID())
+		return nil
+	},
+}
+
+// startSessionReaper starts the expired session reaper when a module stores
+// auth sessions in the datastore
+func startSessionReaper(hub *domprovider.ProviderHub, modules []apidomain.ApiModule) *driverauth.SessionReaper {
+	if !domprovider.Exists[domdatastore.DatabaseProvider](hub) || !usesAuthSessions(modules) {
+		return nil
+	}
+
+	reaper := driverauth.NewSessionReaper(driverauth.NewSessionReaperConfigFromEnv(domprovider.Get[domenv.EnvProvider](hub)), hub)
+	reaper.Start()
+	return reaper
+}
+
+func usesAuthSessions(modules []apidomain.ApiModule) bool {
+	for _, module := range modules {
+		for _, entity := range module.GetModel() {
+			if _, ok := entity.(*model.AuthSession); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func init() {
+	authPruneSessionsCmd.Flags().IntVar(&pruneBatchSize, "batch-size", 500, "sessions deleted per statement; defaults to AUTH_SESSION_REAPER_BATCH_SIZE")
+	authRotateTokenKeyCmd.Flags().IntVar(&rotateBatchSize, "batch-size", 500, "sessions rewritten per query")
+	authCmd.AddCommand(authPruneSessionsCmd)
+	authCmd.AddCommand(authRotateTokenKeyCmd)
+	RootCmd.AddCommand(authCmd)
+}
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"fmt"
+	"strconv"
+
 	apidomain "github.com/r0x16/Raidark/shared/api/domain"
 	drivermigration "github.com/r0x16/Raidark/shared/migration/driver"
 	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
@@ -10,13 +13,67 @@ import (
 var dbMigrationCmd = &cobra.Command{
 	Use:   "dbmigrate",
 	Short: "Run database schema migrations.",
+	Long:  "Run GORM AutoMigrate on every module model, then apply pending versioned migrations.",
+	Run: func(cmd *cobra.Command, args []string) {
+		newDbmigrate(cmd).Run()
+	},
+}
+
+var dbMigrationUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply pending versioned migrations.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		newDbmigrate(cmd).Up()
+	},
+}
+
+var dbMigrationDownCmd = &cobra.Command{
+	Use:   "down [N]",
+	Short: "Roll back the last N applied migrations (default 1).",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps := 1
+		if len(args) == 1 {
+			parsed, err := strconv.Atoi(args[0])
+			if err != nil || parsed < 1 {
+				return fmt.Errorf("invalid number of steps %q: must be a positive integer", args[0])
+			}
+			steps = parsed
+		}
+		newDbmigrate(cmd).Down(steps)
+		return nil
+	},
+}
+
+var dbMigrationStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show applied, pending and changed migrations.",
+	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		modules := cmd.Context().Value(modulesKey).([]apidomain.ApiModule)
-		hub := cmd.Context().Value(hubKey).(*domprovider.ProviderHub)
-		drivermigration.NewDbmigrate(hub, modules).Run()
+		newDbmigrate(cmd).Status(cmd.OutOrStdout())
 	},
 }
 
+var dbMigrationRedoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Roll back the last applied migration and apply it again.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		newDbmigrate(cmd).Redo()
+	},
+}
+
+func newDbmigrate(cmd *cobra.Command) *drivermigration.Dbmigrate {
+	modules := cmd.Context().Value(modulesKey).([]apidomain.ApiModule)
+	hub := cmd.Context().Value(hubKey).(*domprovider.ProviderHub)
+	return drivermigration.NewDbmigrate(hub, modules)
+}
+
 func init() {
+	dbMigrationCmd.AddCommand(dbMigrationUpCmd)
+	dbMigrationCmd.AddCommand(dbMigrationDownCmd)
+	dbMigrationCmd.AddCommand(dbMigrationStatusCmd)
+	dbMigrationCmd.AddCommand(dbMigrationRedoCmd)
 	RootCmd.AddCommand(dbMigrationCmd)
 }
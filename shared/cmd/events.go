@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	domapi "github.com/r0x16/Raidark/shared/api/domain"
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	"github.com/r0x16/Raidark/shared/events/domain/model"
+	driverevents "github.com/r0x16/Raidark/shared/events/driver"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// startProcessedEventReaper starts the dedup records reaper when a module
+// stores processed events in the datastore
+func startProcessedEventReaper(hub *domprovider.ProviderHub, modules []domapi.ApiModule) *driverevents.ProcessedEventReaper {
+	if !domprovider.Exists[domdatastore.DatabaseProvider](hub) || !usesProcessedEvents(modules) {
+		return nil
+	}
+
+	config := driverevents.NewProcessedEventReaperConfigFromEnv(domprovider.Get[domenv.EnvProvider](hub))
+	reaper := driverevents.NewProcessedEventReaper(config, hub)
+	reaper.Start()
+	return reaper
+}
+
+func usesProcessedEvents(modules []domapi.ApiModule) bool {
+	for _, module := range modules {
+		for _, entity := range module.GetModel() {
+			if _, ok := entity.(*model.ProcessedEvent); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
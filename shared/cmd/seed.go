@@ -1,22 +1,66 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+
 	apidomain "github.com/r0x16/Raidark/shared/api/domain"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	"github.com/r0x16/Raidark/shared/migration/domain/seed"
 	drivermigration "github.com/r0x16/Raidark/shared/migration/driver"
+	"github.com/r0x16/Raidark/shared/migration/driver/seeder"
 	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
 	"github.com/spf13/cobra"
 )
 
+var (
+	seedEnv    string
+	seedOnly   []string
+	seedDryRun bool
+)
+
 var seedCmd = &cobra.Command{
 	Use:   "seed",
 	Short: "Load seed data into the database.",
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: "Upsert the seed sets declared by every module. Sets already loaded with the same " +
+		"content are skipped, so the command can run repeatedly.",
+	RunE: func(cmd *cobra.Command, args []string) error {
 		modules := cmd.Context().Value(modulesKey).([]apidomain.ApiModule)
 		hub := cmd.Context().Value(hubKey).(*domprovider.ProviderHub)
-		drivermigration.NewSeeder(hub, modules).Run()
+
+		environment, err := seedEnvironment(cmd, domprovider.Get[domenv.EnvProvider](hub))
+		if err != nil {
+			return err
+		}
+
+		drivermigration.NewSeeder(hub, modules).Run(seeder.Options{
+			Environment: environment,
+			Only:        seedOnly,
+			DryRun:      seedDryRun,
+		}, cmd.OutOrStdout())
+		return nil
 	},
 }
 
+// seedEnvironment returns the environment given by --env, else by SEED_ENV.
+// There is no default, so production data is never seeded by omission.
+func seedEnvironment(cmd *cobra.Command, env domenv.EnvProvider) (string, error) {
+	environment := seedEnv
+	if !cmd.Flags().Changed("env") {
+		if !env.IsSet("SEED_ENV") {
+			return "", errors.New("seed requires an environment: pass --env or set SEED_ENV")
+		}
+		environment = env.GetString("SEED_ENV", "")
+	}
+	if !seed.IsEnvironment(environment) {
+		return "", fmt.Errorf("invalid environment %q: must be one of %s, %s, %s", environment, seed.EnvDev, seed.EnvTest, seed.EnvProd)
+	}
+	return environment, nil
+}
+
 func init() {
+	seedCmd.Flags().StringVar(&seedEnv, "env", "", "environment whose seed sets are loaded (dev, test, prod); defaults to SEED_ENV")
+	seedCmd.Flags().StringSliceVar(&seedOnly, "only", nil, "comma-separated seed sets to load, as name or module/name")
+	seedCmd.Flags().BoolVar(&seedDryRun, "dry-run", false, "report what would be loaded without writing")
 	dbMigrationCmd.AddCommand(seedCmd)
 }
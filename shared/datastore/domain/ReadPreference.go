@@ -0,0 +1,21 @@
+package domain
+
+import "context"
+
+type primaryReadKey struct{}
+
+// ReadFromPrimary returns a context whose queries skip the read replicas.
+// Read-after-write paths use it to observe their own writes despite the
+// replication lag. Without replicas it has no effect.
+func ReadFromPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadKey{}, true)
+}
+
+// ReadsFromPrimary reports whether ctx was marked with ReadFromPrimary
+func ReadsFromPrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	primary, _ := ctx.Value(primaryReadKey{}).(bool)
+	return primary
+}
@@ -2,6 +2,16 @@ package domain
 
 type Transaction interface {
 	Begin()
-	Commit()
+	// Commit returns the error of a failed commit, whose writes are lost
+	Commit() error
 	Rollback()
 }
+
+// CommitNotifier is implemented by transactions that can run work once they
+// commit. Callers type-assert their Transaction to it.
+type CommitNotifier interface {
+	// AfterCommit runs fn once the transaction commits, or right away when
+	// no transaction is open. fn is discarded if the transaction rolls back
+	// or its commit fails.
+	AfterCommit(fn func())
+}
@@ -0,0 +1,27 @@
+package driver
+
+import (
+	"context"
+	"errors"
+
+	domhealth "github.com/r0x16/Raidark/shared/health/domain"
+	"gorm.io/gorm"
+)
+
+// gormPingCheck returns the readiness check pinging the connection pool
+// behind db
+func gormPingCheck(name string, db *gorm.DB) domhealth.Check {
+	return domhealth.Check{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			if db == nil {
+				return errors.New("database not connected")
+			}
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger sends the GORM logs through the LogProvider, with the trace of
+// the query context. Queries slower than the threshold are logged as
+// warnings and failed queries as errors; with db.Debug() every query is
+// logged at debug level. The SQL is logged with its placeholders, never with
+// the bound values, so secrets and personal data stay out of the logs.
+type GormLogger struct {
+	log           domlogger.LogProvider
+	slowThreshold time.Duration
+	level         gormlogger.LogLevel
+}
+
+var _ gormlogger.Interface = &GormLogger{}
+var _ gorm.ParamsFilter = &GormLogger{}
+
+// NewGormLogger creates the logger. A zero slowThreshold disables the slow
+// query logs.
+func NewGormLogger(log domlogger.LogProvider, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{log: log, slowThreshold: slowThreshold, level: gormlogger.Warn}
+}
+
+// gormConfig returns the GORM configuration of a provider, logging through
+// log when there is one
+func gormConfig(env domenv.EnvProvider, log domlogger.LogProvider) *gorm.Config {
+	config := &gorm.Config{}
+	if log != nil {
+		threshold := time.Duration(env.GetInt("DB_SLOW_QUERY_THRESHOLD_MS", 200)) * time.Millisecond
+		config.Logger = NewGormLogger(log, threshold)
+	}
+	return config
+}
+
+// LogMode implements logger.Interface
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	logger := *l
+	logger.level = level
+	return &logger
+}
+
+// Info implements logger.Interface
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Info {
+		l.log.Info(fmt.Sprintf(msg, args...), traceFields(ctx, map[string]any{}))
+	}
+}
+
+// Warn implements logger.Interface
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Warn {
+		l.log.Warning(fmt.Sprintf(msg, args...), traceFields(ctx, map[string]any{}))
+	}
+}
+
+// Error implements logger.Interface
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Error {
+		l.log.Error(fmt.Sprintf(msg, args...), traceFields(ctx, map[string]any{}))
+	}
+}
+
+// Trace implements logger.Interface. A missing record is not a failure.
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	fields := func() map[string]any {
+		sql, rows := fc()
+		return traceFields(ctx, map[string]any{
+			"sql":         sql,
+			"rows":        rows,
+			"duration_ms": elapsed.Milliseconds(),
+		})
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		data := fields()
+		data["error"] = err.Error()
+		l.log.Error("Query failed", data)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		data := fields()
+		data["threshold_ms"] = l.slowThreshold.Milliseconds()
+		l.log.Warning("Slow query", data)
+	case l.level >= gormlogger.Info:
+		l.log.Debug("Query", fields())
+	}
+}
+
+// ParamsFilter implements gorm.ParamsFilter by dropping the bound values
+func (l *GormLogger) ParamsFilter(_ context.Context, sql string, _ ...any) (string, []any) {
+	return sql, nil
+}
+
+// traceFields adds the trace and span of ctx to data
+func traceFields(ctx context.Context, data map[string]any) map[string]any {
+	if ctx == nil {
+		return data
+	}
+	if traceID := observability.GetTraceID(ctx); traceID != "" {
+		data["trace_id"] = traceID
+	}
+	if spanID := observability.GetSpanID(ctx); spanID != "" {
+		data["span_id"] = spanID
+	}
+	return data
+}
@@ -0,0 +1,95 @@
+package driver_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	driverenv "github.com/r0x16/Raidark/shared/env/driver"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormLogger_logsSlowQueriesWithTheirTrace(t *testing.T) {
+	var out bytes.Buffer
+	logger := driverdatastore.NewGormLogger(obslog.NewWithWriter(&out, obslog.FormatJSON, domlogger.Debug), 100*time.Millisecond)
+	ctx := observability.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	logger.Trace(ctx, time.Now().Add(-time.Second), func() (string, int64) {
+		return "SELECT * FROM notes WHERE id = ?", 1
+	}, nil)
+
+	line := decodeLogLine(t, &out)
+	assert.Equal(t, "Slow query", line["msg"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", line["trace_id"])
+	assert.Equal(t, "SELECT * FROM notes WHERE id = ?", line["sql"])
+	assert.EqualValues(t, 100, line["threshold_ms"])
+}
+
+func TestGormRepository_getExecWithContextLogsTheQueryTrace(t *testing.T) {
+	var out bytes.Buffer
+	t.Setenv("DATASTORE_TYPE", "sqlite")
+	t.Setenv("DB_DATABASE", filepath.Join(t.TempDir(), "primary.db"))
+	hub := &domprovider.ProviderHub{}
+	env := domprovider.Register[domenv.EnvProvider](hub, driverenv.NewEnvProvider())
+	database := driverdatastore.NewGormSqliteDatabaseProvider(env)
+	database.LogProvider = obslog.NewWithWriter(&out, obslog.FormatJSON, domlogger.Debug)
+	require.NoError(t, database.Connect())
+	t.Cleanup(func() { _ = database.Close() })
+	domprovider.Register[domdatastore.DatabaseProvider](hub, database)
+	ctx := observability.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	var read note
+	assert.Error(t, driverdatastore.NewGormRepository(hub).GetExecWithContext(ctx).First(&read).Error)
+
+	line := decodeLogLine(t, &out)
+	assert.Equal(t, "Query failed", line["msg"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", line["trace_id"])
+}
+
+func TestGormLogger_logsFailedQueriesButNotMissingRecords(t *testing.T) {
+	var out bytes.Buffer
+	logger := driverdatastore.NewGormLogger(obslog.NewWithWriter(&out, obslog.FormatJSON, domlogger.Debug), time.Minute)
+	query := func() (string, int64) { return "SELECT * FROM notes", 0 }
+
+	logger.Trace(context.Background(), time.Now(), query, gorm.ErrRecordNotFound)
+	logger.Trace(context.Background(), time.Now(), query, nil)
+	assert.Empty(t, out.String())
+
+	logger.Trace(context.Background(), time.Now(), query, errors.New("no such table: notes"))
+	line := decodeLogLine(t, &out)
+	assert.Equal(t, "Query failed", line["msg"])
+	assert.Equal(t, "no such table: notes", line["error"])
+}
+
+func TestGormLogger_dropsBoundValues(t *testing.T) {
+	logger := driverdatastore.NewGormLogger(obslog.NewWithWriter(&bytes.Buffer{}, obslog.FormatJSON, domlogger.Debug), 0)
+
+	sql, params := logger.ParamsFilter(context.Background(), "SELECT * FROM users WHERE token = ?", "secret")
+
+	assert.Equal(t, "SELECT * FROM users WHERE token = ?", sql)
+	assert.Empty(t, params)
+}
+
+func decodeLogLine(t *testing.T, out *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 1)
+	var line map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	return line
+}
@@ -0,0 +1,84 @@
+package driver
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	"gorm.io/gorm"
+)
+
+// PoolInstrumented is implemented by the database providers that export the
+// statistics of their connection pools
+type PoolInstrumented interface {
+	RegisterPoolMetrics(registerer prometheus.Registerer) error
+}
+
+// poolConfig holds the connection pool limits read from the environment
+type poolConfig struct {
+	maxOpen     int
+	maxIdle     int
+	maxLifetime time.Duration
+	maxIdleTime time.Duration
+}
+
+// readPoolConfig reads the DB_MAX_* and DB_CONN_* variables. Zero disables
+// the corresponding limit, as in database/sql.
+func readPoolConfig(env domenv.EnvProvider) poolConfig {
+	return poolConfig{
+		maxOpen:     env.GetInt("DB_MAX_OPEN_CONNS", 25),
+		maxIdle:     env.GetInt("DB_MAX_IDLE_CONNS", 10),
+		maxLifetime: time.Duration(env.GetInt("DB_CONN_MAX_LIFETIME_SECONDS", 1800)) * time.Second,
+		maxIdleTime: time.Duration(env.GetInt("DB_CONN_MAX_IDLE_TIME_SECONDS", 300)) * time.Second,
+	}
+}
+
+// apply sets the limits on a pool
+func (c poolConfig) apply(pool *sql.DB) {
+	pool.SetMaxOpenConns(c.maxOpen)
+	pool.SetMaxIdleConns(c.maxIdle)
+	pool.SetConnMaxLifetime(c.maxLifetime)
+	pool.SetConnMaxIdleTime(c.maxIdleTime)
+}
+
+// configurePools applies the environment's pool limits to the primary and
+// every replica; each replica gets its own pool of that size
+func configurePools(env domenv.EnvProvider, db *gorm.DB, replicas []gormReplica) error {
+	primary, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	config := readPoolConfig(env)
+	config.apply(primary)
+	for _, replica := range replicas {
+		config.apply(replica.pool)
+	}
+	return nil
+}
+
+// registerPoolCollectors exports the sql.DBStats of every pool as the
+// go_sql_* metrics, labelled db_name="primary" or "replica:<name>"
+func registerPoolCollectors(registerer prometheus.Registerer, db *gorm.DB, replicas []gormReplica) error {
+	if db == nil {
+		return errors.New("database not connected")
+	}
+	primary, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	if err := registerer.Register(collectors.NewDBStatsCollector(primary, "primary")); err != nil {
+		return fmt.Errorf("failed to register the primary pool metrics: %w", err)
+	}
+	for _, replica := range replicas {
+		if err := registerer.Register(collectors.NewDBStatsCollector(replica.pool, "replica:"+replica.name)); err != nil {
+			return fmt.Errorf("failed to register the pool metrics of replica %s: %w", replica.name, err)
+		}
+	}
+	return nil
+}
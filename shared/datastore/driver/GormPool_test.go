@@ -0,0 +1,56 @@
+package driver_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	driverenv "github.com/r0x16/Raidark/shared/env/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormSqliteDatabaseProvider_appliesPoolSettings(t *testing.T) {
+	t.Setenv("DB_DATABASE", filepath.Join(t.TempDir(), "pool.db"))
+	t.Setenv("DB_MAX_OPEN_CONNS", "7")
+
+	database := driverdatastore.NewGormSqliteDatabaseProvider(driverenv.NewEnvProvider())
+	require.NoError(t, database.Connect())
+	t.Cleanup(func() { _ = database.Close() })
+
+	pool, err := database.GetDataStore().Exec.DB()
+	require.NoError(t, err)
+	assert.Equal(t, 7, pool.Stats().MaxOpenConnections)
+}
+
+func TestGormSqliteDatabaseProvider_exportsPoolMetrics(t *testing.T) {
+	dir := t.TempDir()
+	seedNote(t, filepath.Join(dir, "replica.db"), "replica")
+	t.Setenv("DB_DATABASE", filepath.Join(dir, "primary.db"))
+	t.Setenv("DATASTORE_REPLICA_PATHS", filepath.Join(dir, "replica.db"))
+
+	database := driverdatastore.NewGormSqliteDatabaseProvider(driverenv.NewEnvProvider())
+	require.NoError(t, database.Connect())
+	t.Cleanup(func() { _ = database.Close() })
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, database.RegisterPoolMetrics(registry))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	pools := map[string]bool{}
+	for _, family := range families {
+		if family.GetName() != "go_sql_in_use_connections" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "db_name" {
+					pools[label.GetValue()] = true
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]bool{"primary": true, "replica:" + filepath.Join(dir, "replica.db"): true}, pools)
+}
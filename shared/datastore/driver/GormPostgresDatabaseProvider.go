@@ -1,9 +1,15 @@
 package driver
 
 import (
+	"database/sql"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/r0x16/Raidark/shared/datastore/domain"
 	"github.com/r0x16/Raidark/shared/datastore/driver/connection"
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domhealth "github.com/r0x16/Raidark/shared/health/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -11,13 +17,20 @@ import (
 // Represents a postgres database provider connector using gorm
 type GormPostgresDatabaseProvider struct {
 	db          *gorm.DB
+	replicas    []gormReplica
 	envProvider domenv.EnvProvider
 
+	// LogProvider receives the slow and failed queries when set before
+	// Connect; GORM's default logger is used otherwise
+	LogProvider domlogger.LogProvider
+
 	// Deprecated: Use GetTransaction() instead
 	Datastore *domain.DataStore
 }
 
 var _ domain.DatabaseProvider = &GormPostgresDatabaseProvider{}
+var _ domhealth.CheckContributor = &GormPostgresDatabaseProvider{}
+var _ PoolInstrumented = &GormPostgresDatabaseProvider{}
 
 // NewGormPostgresDatabaseProvider creates a new postgres database provider with EnvProvider
 func NewGormPostgresDatabaseProvider(envProvider domenv.EnvProvider) *GormPostgresDatabaseProvider {
@@ -38,12 +51,31 @@ func (g *GormPostgresDatabaseProvider) Connect() error {
 	}
 
 	var err error
-	connection, err := gorm.Open(postgres.Open(dsn.GetDsn()), &gorm.Config{})
+	connection, err := gorm.Open(postgres.Open(dsn.GetDsn()), gormConfig(g.envProvider, g.LogProvider))
 	if err != nil {
 		return err
 	}
 
+	replicas, err := useReplicas(connection, g.envProvider, networkReplicaTargets(g.envProvider, dsn.Port, dsn.Username, dsn.Password, func(host, port, username, password string) string {
+		replica := dsn
+		replica.Host, replica.Port, replica.Username, replica.Password = host, port, username, password
+		return replica.GetDsn()
+	}), replicaDialect{
+		open: postgres.Open,
+		wrap: func(pool *sql.DB) gorm.Dialector { return postgres.New(postgres.Config{Conn: pool}) },
+	})
+	if err == nil {
+		err = configurePools(g.envProvider, connection, replicas)
+	}
+	if err != nil {
+		if sqlDB, dbErr := connection.DB(); dbErr == nil {
+			_ = sqlDB.Close()
+		}
+		return errors.Join(err, closeReplicas(replicas))
+	}
+
 	g.db = connection
+	g.replicas = replicas
 	g.Datastore = domain.NewDataStore(connection)
 	return nil
 }
@@ -56,11 +88,23 @@ func (g *GormPostgresDatabaseProvider) Close() error {
 		if err != nil {
 			return err
 		}
-		return sqlDB.Close()
+		return errors.Join(sqlDB.Close(), closeReplicas(g.replicas))
 	}
 	return nil
 }
 
+// HealthChecks pings the primary and every replica so they are reported by
+// the readiness probe
+func (g *GormPostgresDatabaseProvider) HealthChecks() []domhealth.Check {
+	return append([]domhealth.Check{gormPingCheck("database", g.db)}, replicaChecks(g.replicas)...)
+}
+
+// RegisterPoolMetrics exports the statistics of the primary and replica
+// connection pools
+func (g *GormPostgresDatabaseProvider) RegisterPoolMetrics(registerer prometheus.Registerer) error {
+	return registerPoolCollectors(registerer, g.db, g.replicas)
+}
+
 // Deprecated: Use GetTransaction() instead
 func (g *GormPostgresDatabaseProvider) GetDataStore() *domain.DataStore {
 	return g.Datastore
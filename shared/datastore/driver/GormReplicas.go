@@ -0,0 +1,190 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"github.com/r0x16/Raidark/shared/datastore/domain"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domhealth "github.com/r0x16/Raidark/shared/health/domain"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// gormReplica is a read replica with its own connection pool. down records
+// whether it failed its last health check.
+type gormReplica struct {
+	name string
+	pool *sql.DB
+	down *atomic.Bool
+}
+
+// replicaTarget is a replica to connect to
+type replicaTarget struct {
+	name string
+	dsn  string
+}
+
+// replicaDialect opens a replica from its DSN, then wraps the open pool so
+// dbresolver routes to it instead of opening a second one
+type replicaDialect struct {
+	open func(dsn string) gorm.Dialector
+	wrap func(pool *sql.DB) gorm.Dialector
+}
+
+// networkReplicaTargets reads the comma-separated DATASTORE_REPLICA_HOSTS.
+// An entry is a host or host:port; the replicas share the primary database
+// and credentials unless DATASTORE_REPLICA_USER or DATASTORE_REPLICA_PASSWORD
+// are set.
+func networkReplicaTargets(env domenv.EnvProvider, port, username, password string, dsn func(host, port, username, password string) string) []replicaTarget {
+	username = env.GetString("DATASTORE_REPLICA_USER", username)
+	password = env.GetString("DATASTORE_REPLICA_PASSWORD", password)
+
+	var targets []replicaTarget
+	for _, entry := range env.GetSlice("DATASTORE_REPLICA_HOSTS", nil) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, replicaPort := entry, port
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			host, replicaPort = h, p
+		}
+		targets = append(targets, replicaTarget{
+			name: net.JoinHostPort(host, replicaPort),
+			dsn:  dsn(host, replicaPort, username, password),
+		})
+	}
+	return targets
+}
+
+// fileReplicaTargets reads the comma-separated DATASTORE_REPLICA_PATHS of the
+// sqlite driver, such as the read copies maintained by a replication tool
+func fileReplicaTargets(env domenv.EnvProvider) []replicaTarget {
+	var targets []replicaTarget
+	for _, path := range env.GetSlice("DATASTORE_REPLICA_PATHS", nil) {
+		if path = strings.TrimSpace(path); path != "" {
+			targets = append(targets, replicaTarget{name: path, dsn: path})
+		}
+	}
+	return targets
+}
+
+// useReplicas connects to the replicas and routes the reads of db to them.
+// Writes, transactions and locking reads stay on the primary, as do the
+// queries whose context was marked with domain.ReadFromPrimary and the reads
+// routed to a replica that failed its last health check.
+func useReplicas(db *gorm.DB, env domenv.EnvProvider, targets []replicaTarget, dialect replicaDialect) ([]gormReplica, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	policy, err := replicaPolicy(env.GetString("DATASTORE_REPLICA_POLICY", "random"))
+	if err != nil {
+		return nil, err
+	}
+
+	var replicas []gormReplica
+	var dialectors []gorm.Dialector
+	for _, target := range targets {
+		replica, err := gorm.Open(dialect.open(target.dsn), &gorm.Config{})
+		if err == nil {
+			var pool *sql.DB
+			if pool, err = replica.DB(); err == nil {
+				replicas = append(replicas, gormReplica{name: target.name, pool: pool, down: &atomic.Bool{}})
+				dialectors = append(dialectors, dialect.wrap(pool))
+				continue
+			}
+		}
+		return nil, errors.Join(fmt.Errorf("failed to connect to replica %s: %w", target.name, err), closeReplicas(replicas))
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{Replicas: dialectors, Policy: policy})
+	if err := db.Use(resolver); err != nil {
+		return nil, errors.Join(err, closeReplicas(replicas))
+	}
+	if err := routePrimaryReads(db, replicas); err != nil {
+		return nil, errors.Join(err, closeReplicas(replicas))
+	}
+	return replicas, nil
+}
+
+// replicaPolicy returns the load-balancing policy named by
+// DATASTORE_REPLICA_POLICY
+func replicaPolicy(name string) (dbresolver.Policy, error) {
+	switch name {
+	case "random":
+		return dbresolver.RandomPolicy{}, nil
+	case "round_robin":
+		return dbresolver.RoundRobinPolicy(), nil
+	}
+	return nil, errors.New("invalid replica policy: " + name)
+}
+
+// routePrimaryReads sends the reads whose context was marked with
+// domain.ReadFromPrimary, and those routed to a replica that is down, to the
+// primary. dbresolver runs before every other callback, so the statement is
+// switched back right after it picked a replica and before the query runs.
+func routePrimaryReads(db *gorm.DB, replicas []gormReplica) error {
+	toPrimary := func(tx *gorm.DB) {
+		if domain.ReadsFromPrimary(tx.Statement.Context) || replicaDown(tx.Statement.ConnPool, replicas) {
+			dbresolver.Write.ModifyStatement(tx.Statement)
+		}
+	}
+
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Query().After("gorm:db_resolver").Before("gorm:query").Register("raidark:read_from_primary", toPrimary),
+		callbacks.Row().After("gorm:db_resolver").Before("gorm:row").Register("raidark:read_from_primary", toPrimary),
+		callbacks.Raw().After("gorm:db_resolver").Before("gorm:raw").Register("raidark:read_from_primary", toPrimary),
+	)
+}
+
+// replicaDown reports whether pool is a replica that failed its last health
+// check
+func replicaDown(pool gorm.ConnPool, replicas []gormReplica) bool {
+	if prepared, ok := pool.(*gorm.PreparedStmtDB); ok {
+		pool = prepared.ConnPool
+	}
+	for _, replica := range replicas {
+		if pool == gorm.ConnPool(replica.pool) {
+			return replica.down.Load()
+		}
+	}
+	return false
+}
+
+// replicaChecks pings every replica. The checks are optional: the primary
+// can serve the reads of an unreachable replica, which is taken out of the
+// rotation until it answers a ping again.
+func replicaChecks(replicas []gormReplica) []domhealth.Check {
+	checks := make([]domhealth.Check, 0, len(replicas))
+	for _, replica := range replicas {
+		checks = append(checks, domhealth.Check{
+			Name:     "database.replica." + replica.name,
+			Optional: true,
+			Run: func(ctx context.Context) error {
+				err := replica.pool.PingContext(ctx)
+				replica.down.Store(err != nil)
+				return err
+			},
+		})
+	}
+	return checks
+}
+
+// closeReplicas closes the pools of the replicas
+func closeReplicas(replicas []gormReplica) error {
+	var errs []error
+	for _, replica := range replicas {
+		if err := replica.pool.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close replica %s: %w", replica.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,182 @@
+package driver_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	driverenv "github.com/r0x16/Raidark/shared/env/driver"
+	eventmodel "github.com/r0x16/Raidark/shared/events/domain/model"
+	eventrepositories "github.com/r0x16/Raidark/shared/events/driver/repositories"
+	"github.com/r0x16/Raidark/shared/migration/driver/migrator"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// note is stored with a different origin in the primary and in the replica,
+// so a read tells which one served it
+type note struct {
+	ID     uint
+	Origin string
+}
+
+func TestGormSqliteDatabaseProvider_routesReadsToReplicas(t *testing.T) {
+	repository, database := newReplicatedDatabase(t)
+
+	var read note
+	require.NoError(t, repository.GetExec().First(&read).Error)
+	assert.Equal(t, "replica", read.Origin)
+
+	require.NoError(t, repository.GetExec().Create(&note{Origin: "written"}).Error)
+	var count int64
+	require.NoError(t, repository.GetPrimaryExec().Model(&note{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	checks := database.HealthChecks()
+	require.Len(t, checks, 2)
+	assert.Equal(t, "database", checks[0].Name)
+	assert.False(t, checks[0].Optional)
+	assert.Contains(t, checks[1].Name, "database.replica.")
+	assert.True(t, checks[1].Optional)
+	for _, check := range checks {
+		assert.NoError(t, check.Run(context.Background()), check.Name)
+	}
+}
+
+func TestGormSqliteDatabaseProvider_readsFromPrimaryOnRequest(t *testing.T) {
+	repository, _ := newReplicatedDatabase(t)
+
+	var read note
+	require.NoError(t, repository.GetPrimaryExec().First(&read).Error)
+	assert.Equal(t, "primary", read.Origin)
+
+	read = note{}
+	ctx := domdatastore.ReadFromPrimary(context.Background())
+	require.NoError(t, repository.GetExec().WithContext(ctx).First(&read).Error)
+	assert.Equal(t, "primary", read.Origin)
+
+	read = note{}
+	require.NoError(t, repository.GetExec().Transaction(func(tx *gorm.DB) error {
+		return tx.First(&read).Error
+	}))
+	assert.Equal(t, "primary", read.Origin)
+}
+
+// The library's bookkeeping tables only exist on the primary here, so any
+// read reaching the replica fails
+func TestGormSqliteDatabaseProvider_pinsLibraryRepositoriesToPrimary(t *testing.T) {
+	repository, _ := newReplicatedDatabase(t)
+	exec := repository.GetExec()
+	require.NoError(t, exec.AutoMigrate(&eventmodel.ProcessedEvent{}))
+
+	processed := eventrepositories.NewGormProcessedEventRepository(exec)
+	_, err := processed.MarkProcessed("event-1", "billing", time.Now())
+	require.NoError(t, err)
+	for range 3 {
+		seen, err := processed.IsProcessed("event-1", "billing")
+		require.NoError(t, err)
+		assert.True(t, seen)
+	}
+
+	engine, err := migrator.NewGormMigrator(exec, nil)
+	require.NoError(t, err)
+	_, err = engine.Status()
+	assert.NoError(t, err)
+
+	var read note
+	require.NoError(t, driverdatastore.OnPrimary(exec).First(&read).Error)
+	assert.Equal(t, "primary", read.Origin)
+	require.NoError(t, exec.First(&read).Error)
+	assert.Equal(t, "replica", read.Origin, "the connection itself is left unpinned")
+}
+
+func TestGormSqliteDatabaseProvider_skipsReplicasThatFailTheirCheck(t *testing.T) {
+	dir := t.TempDir()
+	primaryPath := filepath.Join(dir, "primary.db")
+	replicaPath := filepath.Join(dir, "replica.db")
+	seedNote(t, primaryPath, "primary")
+	seedNote(t, replicaPath, "replica")
+
+	// Without idle connections every query reopens the read-only replica,
+	// which fails once its file is gone
+	t.Setenv("DATASTORE_TYPE", "sqlite")
+	t.Setenv("DB_DATABASE", primaryPath)
+	t.Setenv("DATASTORE_REPLICA_PATHS", "file:"+replicaPath+"?mode=ro")
+	t.Setenv("DB_MAX_IDLE_CONNS", "0")
+	hub := &domprovider.ProviderHub{}
+	env := domprovider.Register[domenv.EnvProvider](hub, driverenv.NewEnvProvider())
+	database := driverdatastore.NewGormSqliteDatabaseProvider(env)
+	require.NoError(t, database.Connect())
+	t.Cleanup(func() { _ = database.Close() })
+	domprovider.Register[domdatastore.DatabaseProvider](hub, database)
+	exec := driverdatastore.NewGormRepository(hub).GetExec()
+	replicaCheck := database.HealthChecks()[1]
+
+	require.NoError(t, os.Remove(replicaPath))
+	assert.Error(t, replicaCheck.Run(context.Background()))
+	var read note
+	require.NoError(t, exec.First(&read).Error)
+	assert.Equal(t, "primary", read.Origin)
+
+	seedNote(t, replicaPath, "replica")
+	require.NoError(t, replicaCheck.Run(context.Background()))
+	read = note{}
+	require.NoError(t, exec.First(&read).Error)
+	assert.Equal(t, "replica", read.Origin)
+}
+
+func TestGormSqliteDatabaseProvider_rejectsUnknownReplicaPolicy(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DB_DATABASE", filepath.Join(dir, "primary.db"))
+	t.Setenv("DATASTORE_REPLICA_PATHS", filepath.Join(dir, "replica.db"))
+	t.Setenv("DATASTORE_REPLICA_POLICY", "fastest")
+
+	database := driverdatastore.NewGormSqliteDatabaseProvider(driverenv.NewEnvProvider())
+
+	assert.EqualError(t, database.Connect(), "invalid replica policy: fastest")
+}
+
+// newReplicatedDatabase connects a sqlite primary with one replica, each
+// holding a single note tagged with its origin
+func newReplicatedDatabase(t *testing.T) (*driverdatastore.GormRepository, *driverdatastore.GormSqliteDatabaseProvider) {
+	t.Helper()
+
+	dir := t.TempDir()
+	primaryPath := filepath.Join(dir, "primary.db")
+	replicaPath := filepath.Join(dir, "replica.db")
+	seedNote(t, primaryPath, "primary")
+	seedNote(t, replicaPath, "replica")
+
+	t.Setenv("DATASTORE_TYPE", "sqlite")
+	t.Setenv("DB_DATABASE", primaryPath)
+	t.Setenv("DATASTORE_REPLICA_PATHS", replicaPath)
+
+	hub := &domprovider.ProviderHub{}
+	env := domprovider.Register[domenv.EnvProvider](hub, driverenv.NewEnvProvider())
+	database := driverdatastore.NewGormSqliteDatabaseProvider(env)
+	require.NoError(t, database.Connect())
+	t.Cleanup(func() { _ = database.Close() })
+	domprovider.Register[domdatastore.DatabaseProvider](hub, database)
+
+	return driverdatastore.NewGormRepository(hub), database
+}
+
+func seedNote(t *testing.T, path, origin string) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&note{}))
+	require.NoError(t, db.Create(&note{Origin: origin}).Error)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+}
@@ -1,10 +1,13 @@
 package driver
 
 import (
+	"context"
+
 	"github.com/r0x16/Raidark/shared/datastore/domain"
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
 	domproviders "github.com/r0x16/Raidark/shared/providers/domain"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
 type GormRepository struct {
@@ -35,6 +38,27 @@ func (r *GormRepository) GetExec() *gorm.DB {
 	}
 }
 
+// GetExecWithContext returns the connection running its queries under ctx,
+// so they are cancelled with it and their logs carry its trace
+func (r *GormRepository) GetExecWithContext(ctx context.Context) *gorm.DB {
+	return r.GetExec().WithContext(ctx)
+}
+
+// GetPrimaryExec returns the connection with its reads pinned to the primary,
+// for read-after-write paths that cannot tolerate the replication lag. It is
+// the same as GetExec when no replica is configured.
+func (r *GormRepository) GetPrimaryExec() *gorm.DB {
+	return OnPrimary(r.GetExec())
+}
+
+// OnPrimary returns db with its reads pinned to the primary. Repositories
+// whose reads must see their own writes wrap the connection they receive
+// with it. It changes nothing without replicas or inside a transaction, and
+// the result can be stored and reused like the connection itself.
+func OnPrimary(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write).Session(&gorm.Session{})
+}
+
 func (r *GormRepository) GetTransactionExec(tx domain.Transaction) *gorm.DB {
 	gormtx := tx.(*GormTransaction)
 
@@ -1,9 +1,15 @@
 package driver
 
 import (
+	"database/sql"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/r0x16/Raidark/shared/datastore/domain"
 	"github.com/r0x16/Raidark/shared/datastore/driver/connection"
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domhealth "github.com/r0x16/Raidark/shared/health/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -11,13 +17,20 @@ import (
 // Represents a sqlite database provider connector using gorm
 type GormSqliteDatabaseProvider struct {
 	db          *gorm.DB
+	replicas    []gormReplica
 	envProvider domenv.EnvProvider
 
+	// LogProvider receives the slow and failed queries when set before
+	// Connect; GORM's default logger is used otherwise
+	LogProvider domlogger.LogProvider
+
 	// Deprecated: Use GetTransaction() instead
 	Datastore *domain.DataStore
 }
 
 var _ domain.DatabaseProvider = &GormSqliteDatabaseProvider{}
+var _ domhealth.CheckContributor = &GormSqliteDatabaseProvider{}
+var _ PoolInstrumented = &GormSqliteDatabaseProvider{}
 
 // NewGormSqliteDatabaseProvider creates a new sqlite database provider with EnvProvider
 func NewGormSqliteDatabaseProvider(envProvider domenv.EnvProvider) *GormSqliteDatabaseProvider {
@@ -34,12 +47,27 @@ func (g *GormSqliteDatabaseProvider) Connect() error {
 	}
 
 	var err error
-	connection, err := gorm.Open(sqlite.Open(dsn.GetDsn()), &gorm.Config{})
+	connection, err := gorm.Open(sqlite.Open(dsn.GetDsn()), gormConfig(g.envProvider, g.LogProvider))
 	if err != nil {
 		return err
 	}
 
+	replicas, err := useReplicas(connection, g.envProvider, fileReplicaTargets(g.envProvider), replicaDialect{
+		open: sqlite.Open,
+		wrap: func(pool *sql.DB) gorm.Dialector { return sqlite.New(sqlite.Config{Conn: pool}) },
+	})
+	if err == nil {
+		err = configurePools(g.envProvider, connection, replicas)
+	}
+	if err != nil {
+		if sqlDB, dbErr := connection.DB(); dbErr == nil {
+			_ = sqlDB.Close()
+		}
+		return errors.Join(err, closeReplicas(replicas))
+	}
+
 	g.db = connection
+	g.replicas = replicas
 	g.Datastore = domain.NewDataStore(connection)
 	return nil
 }
@@ -52,11 +80,23 @@ func (g *GormSqliteDatabaseProvider) Close() error {
 		if err != nil {
 			return err
 		}
-		return sqlDB.Close()
+		return errors.Join(sqlDB.Close(), closeReplicas(g.replicas))
 	}
 	return nil
 }
 
+// HealthChecks pings the primary and every replica so they are reported by
+// the readiness probe
+func (g *GormSqliteDatabaseProvider) HealthChecks() []domhealth.Check {
+	return append([]domhealth.Check{gormPingCheck("database", g.db)}, replicaChecks(g.replicas)...)
+}
+
+// RegisterPoolMetrics exports the statistics of the primary and replica
+// connection pools
+func (g *GormSqliteDatabaseProvider) RegisterPoolMetrics(registerer prometheus.Registerer) error {
+	return registerPoolCollectors(registerer, g.db, g.replicas)
+}
+
 // Deprecated: Use GetTransaction() instead
 func (g *GormSqliteDatabaseProvider) GetDataStore() *domain.DataStore {
 	return g.Datastore
@@ -12,9 +12,11 @@ type GormTransaction struct {
 	tx               *gorm.DB
 	IsInTransaction  bool
 	TransactionMutex sync.Mutex
+	afterCommit      []func()
 }
 
 var _ domain.Transaction = &GormTransaction{}
+var _ domain.CommitNotifier = &GormTransaction{}
 
 func NewGormTransaction(db *gorm.DB) *GormTransaction {
 	return &GormTransaction{db: db, IsInTransaction: false}
@@ -35,17 +37,31 @@ func (t *GormTransaction) Begin() {
 	t.IsInTransaction = true
 }
 
-func (t *GormTransaction) Commit() {
+// Commit commits the transaction and then runs the AfterCommit hooks. When
+// the commit fails its writes are gone, so the hooks are dropped and the
+// error is returned.
+func (t *GormTransaction) Commit() error {
 	t.TransactionMutex.Lock()
-	defer t.TransactionMutex.Unlock()
-
 	if !t.IsInTransaction {
-		return
+		t.TransactionMutex.Unlock()
+		return nil
 	}
 
-	t.tx.Commit()
+	err := t.tx.Commit().Error
 	t.tx = nil
 	t.IsInTransaction = false
+	hooks := t.afterCommit
+	t.afterCommit = nil
+	t.TransactionMutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+	// Hooks run unlocked, so they may start a new transaction on t
+	for _, fn := range hooks {
+		fn()
+	}
+	return nil
 }
 
 func (t *GormTransaction) Rollback() {
@@ -59,4 +75,18 @@ func (t *GormTransaction) Rollback() {
 	t.tx.Rollback()
 	t.tx = nil
 	t.IsInTransaction = false
+	t.afterCommit = nil
+}
+
+// AfterCommit queues fn until Commit. Writes made outside a transaction are
+// already committed, so fn runs immediately then.
+func (t *GormTransaction) AfterCommit(fn func()) {
+	t.TransactionMutex.Lock()
+	if t.IsInTransaction {
+		t.afterCommit = append(t.afterCommit, fn)
+		t.TransactionMutex.Unlock()
+		return
+	}
+	t.TransactionMutex.Unlock()
+	fn()
 }
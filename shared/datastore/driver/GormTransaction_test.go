@@ -0,0 +1,49 @@
+package driver_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	driverenv "github.com/r0x16/Raidark/shared/env/driver"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormTransaction_dropsTheHooksOfAFailedCommit(t *testing.T) {
+	t.Setenv("DATASTORE_TYPE", "sqlite")
+	t.Setenv("DB_DATABASE", filepath.Join(t.TempDir(), "tx.db")+"?_foreign_keys=on")
+	hub := &domprovider.ProviderHub{}
+	env := domprovider.Register[domenv.EnvProvider](hub, driverenv.NewEnvProvider())
+	database := driverdatastore.NewGormSqliteDatabaseProvider(env)
+	require.NoError(t, database.Connect())
+	t.Cleanup(func() { _ = database.Close() })
+	domprovider.Register[domdatastore.DatabaseProvider](hub, database)
+	repository := driverdatastore.NewGormRepository(hub)
+	db := repository.GetExec()
+	require.NoError(t, db.Exec("CREATE TABLE parents (id INTEGER PRIMARY KEY)").Error)
+	require.NoError(t, db.Exec("CREATE TABLE children (id INTEGER PRIMARY KEY, parent_id INTEGER "+
+		"REFERENCES parents(id) DEFERRABLE INITIALLY DEFERRED)").Error)
+
+	// The deferred foreign key is only checked, and fails, at commit
+	tx := database.GetTransaction()
+	tx.Begin()
+	committed := false
+	tx.(domdatastore.CommitNotifier).AfterCommit(func() { committed = true })
+	require.NoError(t, repository.GetTransactionExec(tx).Exec("INSERT INTO children (id, parent_id) VALUES (1, 42)").Error)
+
+	assert.ErrorContains(t, tx.Commit(), "FOREIGN KEY constraint failed")
+	assert.False(t, committed)
+	var count int64
+	require.NoError(t, db.Table("children").Count(&count).Error)
+	assert.Zero(t, count)
+
+	tx.Begin()
+	require.NoError(t, repository.GetTransactionExec(tx).Exec("INSERT INTO parents (id) VALUES (42)").Error)
+	tx.(domdatastore.CommitNotifier).AfterCommit(func() { committed = true })
+	require.NoError(t, tx.Commit())
+	assert.True(t, committed)
+}
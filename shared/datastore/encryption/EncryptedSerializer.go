@@ -0,0 +1,84 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+var (
+	mu      sync.RWMutex
+	current *Keyring
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", EncryptedSerializer{})
+}
+
+// Use installs the keyring of the encrypted serializer. With no keyring,
+// values are written in plaintext and encrypted values cannot be read.
+func Use(keyring *Keyring) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = keyring
+}
+
+// Current returns the installed keyring, nil when encryption is disabled
+func Current() *Keyring {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// EncryptedSerializer encrypts string fields with the installed keyring.
+// The column name is authenticated with the value, so a ciphertext cannot be
+// moved to another column. Plaintext values written before encryption was
+// enabled are read as they are.
+type EncryptedSerializer struct{}
+
+// Scan implements schema.SerializerInterface
+func (EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("encrypted field %s: unsupported database value %T", field.Name, dbValue)
+	}
+
+	if IsEncrypted(value) {
+		keyring := Current()
+		if keyring == nil {
+			return fmt.Errorf("encrypted field %s: %w", field.Name, ErrNoKeyring)
+		}
+		plaintext, err := keyring.Decrypt(value, field.DBName)
+		if err != nil {
+			return fmt.Errorf("encrypted field %s: %w", field.Name, err)
+		}
+		value = plaintext
+	}
+
+	field.ReflectValueOf(ctx, dst).SetString(value)
+	return nil
+}
+
+// Value implements schema.SerializerInterface. Empty strings are stored as
+// they are: there is nothing to protect.
+func (EncryptedSerializer) Value(_ context.Context, field *schema.Field, _ reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("encrypted field %s: only string fields are supported", field.Name)
+	}
+
+	keyring := Current()
+	if keyring == nil || value == "" {
+		return value, nil
+	}
+	return keyring.Encrypt(value, field.DBName)
+}
@@ -0,0 +1,69 @@
+package encryption_test
+
+import (
+	"testing"
+
+	"github.com/r0x16/Raidark/shared/datastore/encryption"
+	"github.com/r0x16/Raidark/shared/internal/testutil/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type credential struct {
+	ID     uint   `gorm:"primarykey"`
+	Secret string `gorm:"type:text;not null;serializer:encrypted"`
+}
+
+func TestEncryptedSerializer_storesCiphertextAndReadsPlaintext(t *testing.T) {
+	useKeyring(t, newKeyring(t, "k1", "k1"))
+	database := db.NewSQLite(t, &credential{})
+
+	require.NoError(t, database.Create(&credential{ID: 1, Secret: "s3cr3t"}).Error)
+
+	stored := rawSecret(t, database, 1)
+	assert.True(t, encryption.IsEncrypted(stored))
+	assert.NotContains(t, stored, "s3cr3t")
+
+	var loaded credential
+	require.NoError(t, database.First(&loaded, 1).Error)
+	assert.Equal(t, "s3cr3t", loaded.Secret)
+}
+
+func TestEncryptedSerializer_readsPlaintextWrittenBeforeEncryption(t *testing.T) {
+	useKeyring(t, nil)
+	database := db.NewSQLite(t, &credential{})
+	require.NoError(t, database.Create(&credential{ID: 1, Secret: "legacy"}).Error)
+	assert.Equal(t, "legacy", rawSecret(t, database, 1))
+
+	encryption.Use(newKeyring(t, "k1", "k1"))
+	var loaded credential
+	require.NoError(t, database.First(&loaded, 1).Error)
+	assert.Equal(t, "legacy", loaded.Secret)
+}
+
+func TestEncryptedSerializer_failsWithoutTheKey(t *testing.T) {
+	useKeyring(t, newKeyring(t, "k1", "k1"))
+	database := db.NewSQLite(t, &credential{})
+	require.NoError(t, database.Create(&credential{ID: 1, Secret: "s3cr3t"}).Error)
+
+	encryption.Use(nil)
+	assert.ErrorIs(t, database.First(&credential{}, 1).Error, encryption.ErrNoKeyring)
+
+	encryption.Use(newKeyring(t, "k2", "k2"))
+	assert.ErrorIs(t, database.First(&credential{}, 1).Error, encryption.ErrUnknownKey)
+}
+
+func useKeyring(t *testing.T, keyring *encryption.Keyring) {
+	t.Helper()
+	previous := encryption.Current()
+	encryption.Use(keyring)
+	t.Cleanup(func() { encryption.Use(previous) })
+}
+
+func rawSecret(t *testing.T, database *gorm.DB, id uint) string {
+	t.Helper()
+	var secret string
+	require.NoError(t, database.Raw("SELECT secret FROM credentials WHERE id = ?", id).Scan(&secret).Error)
+	return secret
+}
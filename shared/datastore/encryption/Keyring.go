@@ -0,0 +1,171 @@
+// Package encryption encrypts model fields at rest. Fields tagged with
+// `gorm:"serializer:encrypted"` are sealed with AES-256-GCM by the keyring
+// installed with Use; the key ID travels with every ciphertext so old rows
+// stay readable after a rotation.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Prefix marks an encrypted value: "enc:v1:<key id>:<base64 nonce+ciphertext>"
+const Prefix = "enc:v1:"
+
+// KeySize is the length in bytes of the AES-256 keys
+const KeySize = 32
+
+var (
+	// ErrNoKeyring is returned when an encrypted value is read and no keyring
+	// is installed
+	ErrNoKeyring = errors.New("encryption keyring is not configured")
+	// ErrUnknownKey is returned when a value was encrypted with a key the
+	// keyring does not hold
+	ErrUnknownKey = errors.New("encryption key is not in the keyring")
+	// ErrCiphertext is returned when a value cannot be decrypted
+	ErrCiphertext = errors.New("encrypted value is malformed or was tampered with")
+)
+
+// keyIDPattern keeps key IDs safe to embed in values and LIKE patterns
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// Keyring holds the keys able to decrypt values and the one used to encrypt
+type Keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring. active must be one of keys.
+func NewKeyring(active string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the keyring", active)
+	}
+
+	keyring := &Keyring{active: active, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("invalid key id %q: use letters, digits and dashes", id)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("key %q must be %d bytes, got %d", id, KeySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[id] = aead
+	}
+	return keyring, nil
+}
+
+// ParseKeyring reads a keyring from a comma-separated list of
+// "<key id>:<base64 key>" entries. An empty active selects the first entry.
+// An empty spec returns a nil keyring.
+func ParseKeyring(spec, active string) (*Keyring, error) {
+	keys := map[string][]byte{}
+	for position, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// The entry is not quoted in errors: it may hold the key itself
+		id, encoded, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid key entry #%d: expected <key id>:<base64 key>", position+1)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		if active == "" {
+			active = id
+		}
+		keys[id] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return NewKeyring(active, keys)
+}
+
+// ActiveKeyID returns the ID of the key used to encrypt
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// Encrypt seals plaintext with the active key. aad binds the ciphertext to
+// its context, e.g. the column it is stored in.
+func (k *Keyring) Encrypt(plaintext, aad string) (string, error) {
+	aead := k.keys[k.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), k.additionalData(k.active, aad))
+	return Prefix + k.active + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt with the key it names
+func (k *Keyring) Decrypt(value, aad string) (string, error) {
+	id, payload, ok := split(value)
+	if !ok {
+		return "", ErrCiphertext
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrCiphertext
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, k.additionalData(id, aad))
+	if err != nil {
+		return "", ErrCiphertext
+	}
+	return string(plaintext), nil
+}
+
+// IsCurrent reports whether value is encrypted with the active key
+func (k *Keyring) IsCurrent(value string) bool {
+	id, ok := KeyID(value)
+	return ok && id == k.active
+}
+
+// KeyID returns the ID of the key value was encrypted with
+func KeyID(value string) (string, bool) {
+	id, _, ok := split(value)
+	return id, ok
+}
+
+// IsEncrypted reports whether value carries the encryption prefix
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+func split(value string) (id, payload string, ok bool) {
+	if !IsEncrypted(value) {
+		return "", "", false
+	}
+	return strings.Cut(strings.TrimPrefix(value, Prefix), ":")
+}
+
+// additionalData authenticates the key ID with the caller context, so the
+// prefix cannot be edited to point at another key
+func (k *Keyring) additionalData(id, aad string) []byte {
+	return []byte(id + ":" + aad)
+}
@@ -0,0 +1,91 @@
+package encryption_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/r0x16/Raidark/shared/datastore/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring_roundTripsAndBindsTheContext(t *testing.T) {
+	keyring := newKeyring(t, "k2", "k1", "k2")
+
+	sealed, err := keyring.Encrypt("refresh-secret", "refresh_token")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, encryption.Prefix+"k2:"))
+	assert.NotContains(t, sealed, "refresh-secret")
+	assert.True(t, keyring.IsCurrent(sealed))
+
+	plaintext, err := keyring.Decrypt(sealed, "refresh_token")
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-secret", plaintext)
+
+	_, err = keyring.Decrypt(sealed, "access_token")
+	assert.ErrorIs(t, err, encryption.ErrCiphertext)
+
+	relabelled := strings.Replace(sealed, ":k2:", ":k1:", 1)
+	_, err = keyring.Decrypt(relabelled, "refresh_token")
+	assert.ErrorIs(t, err, encryption.ErrCiphertext)
+}
+
+func TestKeyring_decryptsWithRetiredKeysItStillHolds(t *testing.T) {
+	old := newKeyring(t, "k1", "k1")
+	sealed, err := old.Encrypt("token", "access_token")
+	require.NoError(t, err)
+
+	rotated := newKeyring(t, "k2", "k1", "k2")
+	plaintext, err := rotated.Decrypt(sealed, "access_token")
+	require.NoError(t, err)
+	assert.Equal(t, "token", plaintext)
+	assert.False(t, rotated.IsCurrent(sealed))
+
+	_, err = newKeyring(t, "k2", "k2").Decrypt(sealed, "access_token")
+	assert.ErrorIs(t, err, encryption.ErrUnknownKey)
+}
+
+func TestParseKeyring(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, encryption.KeySize))
+
+	keyring, err := encryption.ParseKeyring("2026-10:"+key+", 2025-01:"+key, "")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10", keyring.ActiveKeyID())
+
+	keyring, err = encryption.ParseKeyring("2026-10:"+key+",2025-01:"+key, "2025-01")
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01", keyring.ActiveKeyID())
+
+	keyring, err = encryption.ParseKeyring("", "")
+	require.NoError(t, err)
+	assert.Nil(t, keyring)
+
+	invalid := map[string]string{
+		"missing id":     key,
+		"short key":      "k1:" + base64.StdEncoding.EncodeToString([]byte("short")),
+		"unsafe id":      "k_1:" + key,
+		"not base64":     "k1:***",
+		"unknown active": "k1:" + key + "|k9",
+	}
+	for name, spec := range invalid {
+		t.Run(name, func(t *testing.T) {
+			spec, active, _ := strings.Cut(spec, "|")
+			_, err := encryption.ParseKeyring(spec, active)
+			require.Error(t, err)
+			assert.NotContains(t, err.Error(), key)
+		})
+	}
+}
+
+func newKeyring(t *testing.T, active string, ids ...string) *encryption.Keyring {
+	t.Helper()
+	keys := map[string][]byte{}
+	for i, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(i + 1)}, encryption.KeySize)
+	}
+	keyring, err := encryption.NewKeyring(active, keys)
+	require.NoError(t, err)
+	return keyring
+}
@@ -0,0 +1,11 @@
+package domain
+
+// AggregateEvent is an optional extension of DomainEvent for events that belong
+// to a single aggregate. Drivers that guarantee ordering (such as the outbox)
+// use the aggregate ID as the ordering key: events of the same aggregate are
+// delivered in the order they were published, while events of different
+// aggregates never block each other.
+type AggregateEvent interface {
+	DomainEvent
+	AggregateID() string
+}
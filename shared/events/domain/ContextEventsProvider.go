@@ -0,0 +1,15 @@
+package domain
+
+import "context"
+
+// ContextEventsProvider is implemented by providers that carry the caller's
+// context along with the event, such as the trace of the request that
+// published it. Brokers use it to propagate W3C trace headers to consumers
+// running in other processes.
+//
+// Callers should type-assert the hub's DomainEventsProvider to this interface
+// and fall back to Publish when the active driver does not support it.
+type ContextEventsProvider interface {
+	DomainEventsProvider
+	PublishWithContext(ctx context.Context, event DomainEvent) error
+}
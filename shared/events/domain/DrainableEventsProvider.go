@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+// DrainableEventsProvider is implemented by providers that hold events in
+// memory. Drain waits until the queued and in-flight events are handled, or
+// ctx expires, so they are not lost when the provider is closed.
+type DrainableEventsProvider interface {
+	DomainEventsProvider
+	Drain(ctx context.Context) error
+}
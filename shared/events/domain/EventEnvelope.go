@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Envelope decoding errors. Use errors.Is to tell them apart.
+var (
+	ErrUnknownEventType        = errors.New("no registered type for event")
+	ErrUnsupportedEventVersion = errors.New("event version is not supported")
+)
+
+// EventEnvelope is the wire format of a domain event. Drivers that move
+// events out of the process, or keep them for later, store the envelope so
+// the event can be rebuilt, replayed and correlated with the request that
+// produced it.
+type EventEnvelope struct {
+	// ID identifies this occurrence of the event, a UUIDv7
+	ID string `json:"id"`
+	// Type is the event name
+	Type string `json:"type"`
+	// Version is the schema version of Payload
+	Version int `json:"version"`
+	// AggregateID is set for events that implement AggregateEvent
+	AggregateID string    `json:"aggregate_id,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	// TraceID, SpanID and CorrelationID come from the publishing context
+	TraceID       string `json:"trace_id,omitempty"`
+	SpanID        string `json:"span_id,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Payload is the JSON form of the concrete event
+	Payload json.RawMessage `json:"payload"`
+}
+
+// VersionedEvent is an optional extension of DomainEvent for events whose
+// payload schema changed over time. Events that do not implement it are at
+// version 1.
+type VersionedEvent interface {
+	DomainEvent
+	EventVersion() int
+}
+
+// Upcaster rewrites the payload of an event from one schema version to the
+// next one, so envelopes stored by older releases still decode into the
+// current type
+type Upcaster func(payload json.RawMessage) (json.RawMessage, error)
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ListenerPolicy bounds how a provider runs an async listener. Zero fields
+// fall back to the provider defaults.
+type ListenerPolicy struct {
+	// Timeout cancels the listener context and gives up on the attempt
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts before the event is dead-lettered
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry; it doubles on every
+	// subsequent failure up to MaxRetryBackoff
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// PolicyEventListener is implemented by listeners that need a policy other
+// than the provider defaults, such as a longer timeout
+type PolicyEventListener interface {
+	EventListener
+	ListenerPolicy() ListenerPolicy
+}
+
+// DeadLetter describes an event a listener failed to handle within its
+// policy
+type DeadLetter struct {
+	Event    DomainEvent
+	EventID  string
+	Listener string
+	Attempts int
+	Err      error
+	FailedAt time.Time
+}
+
+// DeadLetterHandler receives the events listeners gave up on, to store them
+// or raise an alert. It must not block for long.
+type DeadLetterHandler interface {
+	HandleDeadLetter(ctx context.Context, letter DeadLetter)
+}
+
+// DeadLetterFunc adapts a function to DeadLetterHandler
+type DeadLetterFunc func(ctx context.Context, letter DeadLetter)
+
+// HandleDeadLetter implements DeadLetterHandler
+func (f DeadLetterFunc) HandleDeadLetter(ctx context.Context, letter DeadLetter) {
+	f(ctx, letter)
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// TransactionalEventListener is implemented by listeners that can write
+// through a transaction opened by the caller. Wrappers such as the
+// deduplicating listener use it to commit their own bookkeeping together
+// with the listener's writes: if the listener fails, both roll back.
+type TransactionalEventListener interface {
+	EventListener
+	HandleInTransaction(ctx context.Context, tx domdatastore.Transaction, event DomainEvent, hub *domprovider.ProviderHub) error
+}
+
+// NamedEventListener is implemented by listeners that choose the name they
+// are known by to brokers and dedup stores. Drivers fall back to the Go type
+// of the listener otherwise.
+type NamedEventListener interface {
+	EventListener
+	ListenerName() string
+}
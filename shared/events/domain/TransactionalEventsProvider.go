@@ -0,0 +1,15 @@
+package domain
+
+import domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+
+// TransactionalEventsProvider is implemented by providers that can persist an
+// event atomically with the caller's business writes. The event becomes
+// visible to listeners only after tx commits; if tx rolls back, the event is
+// discarded together with the rest of the unit of work.
+//
+// Callers should type-assert the hub's DomainEventsProvider to this interface
+// and fall back to Publish when the active driver is not transactional.
+type TransactionalEventsProvider interface {
+	DomainEventsProvider
+	PublishInTransaction(tx domdatastore.Transaction, event DomainEvent) error
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+)
+
+// Outbox row states. A row starts pending, becomes published once every
+// listener handled it, or failed once it exhausted its delivery attempts.
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusPublished = "published"
+	OutboxStatusFailed    = "failed"
+)
+
+// OutboxEvent represents a serialized domain event waiting to be relayed to
+// its listeners. Rows are written in the same transaction as the business
+// data that produced the event, so an event exists if and only if that
+// transaction committed.
+type OutboxEvent struct {
+	domdatastore.BaseModel
+	AggregateID   string     `gorm:"type:varchar(255);index" json:"aggregate_id"`
+	EventName     string     `gorm:"type:varchar(255);not null" json:"event_name"`
+	Payload       string     `gorm:"type:text;not null" json:"payload"`
+	Status        string     `gorm:"type:varchar(16);not null;index:idx_outbox_events_dispatch,priority:1" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	OccurredAt    time.Time  `gorm:"not null" json:"occurred_at"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_events_dispatch,priority:2" json:"next_attempt_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	PublishedAt   *time.Time `json:"published_at"`
+}
+
+// StoreName returns the datastore name for GORM
+func (OutboxEvent) StoreName() string {
+	return "outbox_events"
+}
+
+// IsPending reports whether the row still has to be delivered
+func (e *OutboxEvent) IsPending() bool {
+	return e.Status == OutboxStatusPending
+}
+
+// OrderingKey returns the key used to serialize delivery. Events without an
+// aggregate are independent from every other event, so their own ID is used.
+func (e *OutboxEvent) OrderingKey() string {
+	if e.AggregateID != "" {
+		return e.AggregateID
+	}
+	return string(e.ID)
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// ProcessedEvent records that a consumer already handled an event. The
+// composite primary key lets the dedup insert fail, or wait, when another
+// delivery of the same event is being handled by the same consumer.
+type ProcessedEvent struct {
+	EventID     string    `gorm:"primaryKey;type:varchar(36)" json:"event_id"`
+	Consumer    string    `gorm:"primaryKey;type:varchar(255)" json:"consumer"`
+	ProcessedAt time.Time `gorm:"not null;index" json:"processed_at"`
+}
+
+// StoreName returns the datastore name for GORM
+func (ProcessedEvent) StoreName() string {
+	return "processed_events"
+}
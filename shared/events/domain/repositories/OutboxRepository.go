@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/r0x16/Raidark/shared/events/domain/model"
+)
+
+// OutboxRepository defines the data access operations used by the outbox
+// provider to store events and by its relay to deliver them
+type OutboxRepository interface {
+	// Create a new outbox record
+	Create(event *model.OutboxEvent) error
+
+	// Find pending events whose next attempt is due, oldest first
+	FindDue(now time.Time, limit int) ([]*model.OutboxEvent, error)
+
+	// Find the ID of the oldest pending event of each given aggregate
+	FindOldestPendingByAggregate(aggregateIDs []string) (map[string]string, error)
+
+	// Claim an event for delivery until the given time. Returns false when
+	// another relay holds the claim or the event is no longer pending.
+	Claim(event *model.OutboxEvent, now, until time.Time) (bool, error)
+
+	// Mark an event as delivered
+	MarkPublished(event *model.OutboxEvent, at time.Time) error
+
+	// Record a failed attempt and schedule the next one
+	MarkRetry(event *model.OutboxEvent, cause error, next time.Time) error
+
+	// Record a failed attempt and stop retrying the event
+	MarkFailed(event *model.OutboxEvent, cause error) error
+
+	// Count events that still have to be delivered
+	CountPending() (int64, error)
+
+	// Count events that are no longer retried
+	CountFailed() (int64, error)
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import "time"
+
+// ProcessedEventRepository defines the data access operations used to
+// deduplicate event deliveries per consumer
+type ProcessedEventRepository interface {
+	// Record that consumer handled eventID. Returns false, without error,
+	// when it was already recorded.
+	MarkProcessed(eventID, consumer string, at time.Time) (bool, error)
+
+	// Report whether consumer already handled eventID
+	IsProcessed(eventID, consumer string) (bool, error)
+
+	// Delete up to limit records processed before the given time and
+	// return how many were deleted
+	DeleteProcessedBefore(before time.Time, limit int) (int, error)
+}
@@ -0,0 +1,160 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	"github.com/r0x16/Raidark/shared/events/domain"
+	"github.com/r0x16/Raidark/shared/events/driver/repositories"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// DeduplicatingEventListener wraps a listener so a redelivered event is only
+// handled once per consumer. Handled events are recorded in the
+// processed_events table under the event ID carried by the context (see
+// observability.WithEventID), so the hub must hold a DatabaseProvider.
+//
+// When the wrapped listener implements TransactionalEventListener, the dedup
+// record is inserted in the transaction handed to HandleInTransaction: the
+// listener's writes and the record commit or roll back together. Other
+// listeners are skipped when the record exists and the record is written
+// after they succeed, which still lets a crash between both steps replay the
+// event.
+//
+// Events without an ID, such as the ones dispatched by the in-memory driver,
+// are handed to the listener as they are.
+type DeduplicatingEventListener struct {
+	listener domain.EventListener
+	consumer string
+	now      func() time.Time
+}
+
+var _ domain.NamedEventListener = &DeduplicatingEventListener{}
+
+// NewDeduplicatingEventListener wraps listener. consumer names the listener
+// in the dedup table and in the brokers; it defaults to the listener name.
+func NewDeduplicatingEventListener(listener domain.EventListener, consumer string) *DeduplicatingEventListener {
+	if consumer == "" {
+		consumer = listenerName(listener)
+	}
+	return &DeduplicatingEventListener{
+		listener: listener,
+		consumer: consumer,
+		now:      time.Now,
+	}
+}
+
+// EventName implements domain.EventListener
+func (l *DeduplicatingEventListener) EventName() string {
+	return l.listener.EventName()
+}
+
+// IsAsync implements domain.EventListener
+func (l *DeduplicatingEventListener) IsAsync() bool {
+	return l.listener.IsAsync()
+}
+
+// ListenerName implements domain.NamedEventListener
+func (l *DeduplicatingEventListener) ListenerName() string {
+	return l.consumer
+}
+
+// Handle implements domain.EventListener
+func (l *DeduplicatingEventListener) Handle(ctx context.Context, event domain.DomainEvent, hub *domprovider.ProviderHub) error {
+	eventID := observability.GetEventID(ctx)
+	if eventID == "" {
+		return l.listener.Handle(ctx, event, hub)
+	}
+
+	if transactional, ok := l.listener.(domain.TransactionalEventListener); ok {
+		return l.handleInTransaction(ctx, transactional, eventID, event, hub)
+	}
+
+	exec := driverdatastore.NewGormRepository(hub).GetExecWithContext(ctx)
+	repo := repositories.NewGormProcessedEventRepository(exec)
+	processed, err := repo.IsProcessed(eventID, l.consumer)
+	if err != nil {
+		return fmt.Errorf("dedup: failed to look up event %s: %w", eventID, err)
+	}
+	if processed {
+		l.logDuplicate(hub, eventID, event)
+		return nil
+	}
+
+	if err := l.listener.Handle(ctx, event, hub); err != nil {
+		return err
+	}
+	if _, err := repo.MarkProcessed(eventID, l.consumer, l.now()); err != nil {
+		return fmt.Errorf("dedup: failed to record event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// handleInTransaction records the event and runs the listener in one
+// transaction. A concurrent delivery of the same event waits on the record
+// insert and finds it once this transaction commits. The transaction runs
+// under ctx, so a cancelled delivery rolls it back, and a panicking listener
+// rolls it back before the panic goes on.
+func (l *DeduplicatingEventListener) handleInTransaction(
+	ctx context.Context,
+	listener domain.TransactionalEventListener,
+	eventID string,
+	event domain.DomainEvent,
+	hub *domprovider.ProviderHub,
+) error {
+	gormRepository := driverdatastore.NewGormRepository(hub)
+	tx := driverdatastore.NewGormTransaction(gormRepository.GetExecWithContext(ctx))
+	tx.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	repo := repositories.NewGormProcessedEventRepository(gormRepository.GetTransactionExec(tx))
+	first, err := repo.MarkProcessed(eventID, l.consumer, l.now())
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("dedup: failed to record event %s: %w", eventID, err)
+	}
+	if !first {
+		tx.Rollback()
+		l.logDuplicate(hub, eventID, event)
+		return nil
+	}
+
+	if err := listener.HandleInTransaction(ctx, tx, event, hub); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("dedup: failed to commit event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+func (l *DeduplicatingEventListener) logDuplicate(hub *domprovider.ProviderHub, eventID string, event domain.DomainEvent) {
+	if !domprovider.Exists[domlogger.LogProvider](hub) {
+		return
+	}
+	domprovider.Get[domlogger.LogProvider](hub).Info("skipping already processed event", map[string]any{
+		"event":    event.Name(),
+		"event_id": eventID,
+		"consumer": l.consumer,
+	})
+}
+
+// listenerName returns the name a listener is known by: the one it chooses
+// through NamedEventListener, or its Go type
+func listenerName(listener domain.EventListener) string {
+	if named, ok := listener.(domain.NamedEventListener); ok {
+		return named.ListenerName()
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", listener), "*")
+}
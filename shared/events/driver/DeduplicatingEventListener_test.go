@@ -0,0 +1,237 @@
+package driver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	driverenv "github.com/r0x16/Raidark/shared/env/driver"
+	"github.com/r0x16/Raidark/shared/events/domain"
+	"github.com/r0x16/Raidark/shared/events/domain/model"
+	driverevents "github.com/r0x16/Raidark/shared/events/driver"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDeduplicatingEventListener_handlesRedeliveriesOnce(t *testing.T) {
+	hub, db := newDedupHub(t)
+	listener := driverevents.NewDeduplicatingEventListener(&ledgerListener{}, "billing")
+	ctx := observability.WithEventID(context.Background(), "event-1")
+
+	for range 3 {
+		require.NoError(t, listener.Handle(ctx, &orderPlaced{OrderID: "order-1"}, hub))
+	}
+
+	assert.Equal(t, int64(1), countRows(t, db, &ledgerEntry{}))
+	assert.Equal(t, int64(1), countRows(t, db, &model.ProcessedEvent{}))
+	assert.Equal(t, "billing", listener.ListenerName())
+}
+
+func TestDeduplicatingEventListener_rollsBackTheRecordWithTheListener(t *testing.T) {
+	hub, db := newDedupHub(t)
+	inner := &ledgerListener{failures: 1}
+	listener := driverevents.NewDeduplicatingEventListener(inner, "billing")
+	ctx := observability.WithEventID(context.Background(), "event-1")
+
+	assert.Error(t, listener.Handle(ctx, &orderPlaced{OrderID: "order-1"}, hub))
+	assert.Equal(t, int64(0), countRows(t, db, &ledgerEntry{}))
+	assert.Equal(t, int64(0), countRows(t, db, &model.ProcessedEvent{}))
+
+	require.NoError(t, listener.Handle(ctx, &orderPlaced{OrderID: "order-1"}, hub))
+	assert.Equal(t, int64(1), countRows(t, db, &ledgerEntry{}))
+	assert.Equal(t, int64(1), countRows(t, db, &model.ProcessedEvent{}))
+}
+
+func TestDeduplicatingEventListener_rollsBackTheRecordWhenTheListenerPanics(t *testing.T) {
+	hub, db := newDedupHub(t)
+	inner := &ledgerListener{panics: true}
+	listener := driverevents.NewDeduplicatingEventListener(inner, "billing")
+	ctx := observability.WithEventID(context.Background(), "event-1")
+
+	assert.PanicsWithValue(t, "ledger corrupted", func() {
+		_ = listener.Handle(ctx, &orderPlaced{OrderID: "order-1"}, hub)
+	})
+	assert.Equal(t, int64(0), countRows(t, db, &ledgerEntry{}))
+	assert.Equal(t, int64(0), countRows(t, db, &model.ProcessedEvent{}))
+
+	inner.panics = false
+	require.NoError(t, listener.Handle(ctx, &orderPlaced{OrderID: "order-1"}, hub))
+	assert.Equal(t, int64(1), countRows(t, db, &ledgerEntry{}))
+}
+
+func TestDeduplicatingEventListener_runsTheTransactionUnderTheHandlerContext(t *testing.T) {
+	hub, db := newDedupHub(t)
+	listener := driverevents.NewDeduplicatingEventListener(&ledgerListener{}, "billing")
+	ctx, cancel := context.WithCancel(observability.WithEventID(context.Background(), "event-1"))
+	cancel()
+
+	assert.ErrorIs(t, listener.Handle(ctx, &orderPlaced{OrderID: "order-1"}, hub), context.Canceled)
+	assert.Equal(t, int64(0), countRows(t, db, &ledgerEntry{}))
+	assert.Equal(t, int64(0), countRows(t, db, &model.ProcessedEvent{}))
+}
+
+func TestDeduplicatingEventListener_returnsCommitFailures(t *testing.T) {
+	hub, db := newDedupHub(t)
+	require.NoError(t, db.Exec("CREATE TABLE orders (id TEXT PRIMARY KEY)").Error)
+	require.NoError(t, db.Exec("CREATE TABLE order_lines (id INTEGER PRIMARY KEY, order_id TEXT "+
+		"REFERENCES orders(id) DEFERRABLE INITIALLY DEFERRED)").Error)
+	listener := driverevents.NewDeduplicatingEventListener(&orphanLineListener{}, "billing")
+	ctx := observability.WithEventID(context.Background(), "event-1")
+
+	// The deferred foreign key only fails at commit
+	err := listener.Handle(ctx, &orderPlaced{OrderID: "order-1"}, hub)
+
+	assert.ErrorContains(t, err, "dedup: failed to commit event event-1")
+	assert.Equal(t, int64(0), countRows(t, db, &model.ProcessedEvent{}))
+}
+
+func TestDeduplicatingEventListener_keepsConsumersApart(t *testing.T) {
+	hub, db := newDedupHub(t)
+	ctx := observability.WithEventID(context.Background(), "event-1")
+
+	require.NoError(t, driverevents.NewDeduplicatingEventListener(&ledgerListener{}, "billing").Handle(ctx, &orderPlaced{OrderID: "order-1"}, hub))
+	require.NoError(t, driverevents.NewDeduplicatingEventListener(&ledgerListener{}, "shipping").Handle(ctx, &orderPlaced{OrderID: "order-1"}, hub))
+
+	assert.Equal(t, int64(2), countRows(t, db, &ledgerEntry{}))
+}
+
+func TestDeduplicatingEventListener_recordsPlainListenersAfterSuccess(t *testing.T) {
+	hub, db := newDedupHub(t)
+	inner := &recordingListener{failures: map[int]int{1: 1}}
+	listener := driverevents.NewDeduplicatingEventListener(inner, "")
+	ctx := observability.WithEventID(context.Background(), "event-1")
+	event := &orderPlaced{OrderID: "order-1", Sequence: 1}
+
+	assert.Error(t, listener.Handle(ctx, event, hub))
+	require.NoError(t, listener.Handle(ctx, event, hub))
+	require.NoError(t, listener.Handle(ctx, event, hub))
+
+	assert.Equal(t, []int{1}, inner.sequences("order-1"))
+	assert.Equal(t, "driver_test.recordingListener", listener.ListenerName())
+	assert.Equal(t, int64(1), countRows(t, db, &model.ProcessedEvent{}))
+}
+
+func TestDeduplicatingEventListener_passesEventsWithoutID(t *testing.T) {
+	hub, db := newDedupHub(t)
+	listener := driverevents.NewDeduplicatingEventListener(&ledgerListener{}, "billing")
+
+	require.NoError(t, listener.Handle(context.Background(), &orderPlaced{OrderID: "order-1"}, hub))
+	require.NoError(t, listener.Handle(context.Background(), &orderPlaced{OrderID: "order-1"}, hub))
+
+	assert.Equal(t, int64(2), countRows(t, db, &ledgerEntry{}))
+	assert.Equal(t, int64(0), countRows(t, db, &model.ProcessedEvent{}))
+}
+
+func TestProcessedEventReaper_deletesRecordsOlderThanTheTTL(t *testing.T) {
+	hub, db := newDedupHub(t)
+	now := time.Now()
+	for i, age := range []time.Duration{48 * time.Hour, 30 * time.Hour, time.Hour} {
+		require.NoError(t, db.Create(&model.ProcessedEvent{
+			EventID:     string(rune('a' + i)),
+			Consumer:    "billing",
+			ProcessedAt: now.Add(-age),
+		}).Error)
+	}
+
+	reaper := driverevents.NewProcessedEventReaper(driverevents.ProcessedEventReaperConfig{TTL: 24 * time.Hour, BatchSize: 1}, hub)
+	deleted, err := reaper.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, deleted)
+	var remaining []model.ProcessedEvent
+	require.NoError(t, db.Find(&remaining).Error)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "c", remaining[0].EventID)
+}
+
+// ledgerEntry is the business row written by ledgerListener
+type ledgerEntry struct {
+	ID      uint `gorm:"primarykey"`
+	OrderID string
+}
+
+// ledgerListener writes a ledger entry through the dedup transaction and
+// fails the first N deliveries after writing, or panics while panics is set,
+// so the rollback is observable
+type ledgerListener struct {
+	domain.AsyncEventListener
+	failures int
+	panics   bool
+}
+
+func (l *ledgerListener) EventName() string { return "orders.placed" }
+
+func (l *ledgerListener) Handle(_ context.Context, event domain.DomainEvent, hub *domprovider.ProviderHub) error {
+	exec := driverdatastore.NewGormRepository(hub).GetExec()
+	return exec.Create(&ledgerEntry{OrderID: event.(*orderPlaced).OrderID}).Error
+}
+
+func (l *ledgerListener) HandleInTransaction(_ context.Context, tx domdatastore.Transaction, event domain.DomainEvent, hub *domprovider.ProviderHub) error {
+	exec := driverdatastore.NewGormRepository(hub).GetTransactionExec(tx)
+	if err := exec.Create(&ledgerEntry{OrderID: event.(*orderPlaced).OrderID}).Error; err != nil {
+		return err
+	}
+	if l.panics {
+		panic("ledger corrupted")
+	}
+	if l.failures > 0 {
+		l.failures--
+		return errors.New("ledger unavailable")
+	}
+	return nil
+}
+
+// orphanLineListener writes an order line whose order does not exist
+type orphanLineListener struct {
+	domain.AsyncEventListener
+}
+
+func (l *orphanLineListener) EventName() string { return "orders.placed" }
+
+func (l *orphanLineListener) Handle(context.Context, domain.DomainEvent, *domprovider.ProviderHub) error {
+	return nil
+}
+
+func (l *orphanLineListener) HandleInTransaction(_ context.Context, tx domdatastore.Transaction, event domain.DomainEvent, hub *domprovider.ProviderHub) error {
+	exec := driverdatastore.NewGormRepository(hub).GetTransactionExec(tx)
+	return exec.Exec("INSERT INTO order_lines (order_id) VALUES (?)", event.(*orderPlaced).OrderID).Error
+}
+
+func newDedupHub(t *testing.T) (*domprovider.ProviderHub, *gorm.DB) {
+	t.Helper()
+
+	t.Setenv("DATASTORE_TYPE", "sqlite")
+	t.Setenv("DB_DATABASE", filepath.Join(t.TempDir(), "dedup.db")+"?_busy_timeout=5000&_foreign_keys=on")
+
+	hub := &domprovider.ProviderHub{}
+	env := domprovider.Register[domenv.EnvProvider](hub, driverenv.NewEnvProvider())
+	domprovider.Register[domlogger.LogProvider](hub, obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical))
+
+	database := driverdatastore.NewGormSqliteDatabaseProvider(env)
+	require.NoError(t, database.Connect())
+	t.Cleanup(func() { _ = database.Close() })
+	domprovider.Register[domdatastore.DatabaseProvider](hub, database)
+
+	db := database.GetDataStore().Exec
+	require.NoError(t, db.AutoMigrate(&model.ProcessedEvent{}, &ledgerEntry{}))
+	return hub, db
+}
+
+func countRows(t *testing.T, db *gorm.DB, entity any) int64 {
+	t.Helper()
+
+	var count int64
+	require.NoError(t, db.Model(entity).Count(&count).Error)
+	return count
+}
@@ -0,0 +1,203 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/events/domain"
+	"github.com/r0x16/Raidark/shared/ids"
+	"github.com/r0x16/Raidark/shared/observability"
+)
+
+// EventRegistry maps event names to their concrete Go types and schema
+// versions. It wraps events into the EventEnvelope wire format and rebuilds
+// them on the receiving side, upcasting payloads written by older versions.
+type EventRegistry struct {
+	mu        sync.RWMutex
+	types     map[string]registeredEvent
+	upcasters map[string]map[int]domain.Upcaster
+}
+
+// registeredEvent is the Go type and current schema version of an event
+type registeredEvent struct {
+	goType  reflect.Type
+	version int
+}
+
+// NewEventRegistry creates an EventRegistry that knows the given prototypes
+func NewEventRegistry(prototypes ...domain.DomainEvent) *EventRegistry {
+	registry := &EventRegistry{
+		types:     make(map[string]registeredEvent),
+		upcasters: make(map[string]map[int]domain.Upcaster),
+	}
+	for _, prototype := range prototypes {
+		registry.Register(prototype)
+	}
+	return registry
+}
+
+// Register records the type and version of prototype under its event name.
+// Registering the same name again replaces the previous type.
+func (r *EventRegistry) Register(prototype domain.DomainEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[prototype.Name()] = registeredEvent{goType: reflect.TypeOf(prototype), version: versionOf(prototype)}
+}
+
+// RegisterUpcaster installs the function that rewrites payloads of eventType
+// from fromVersion to fromVersion+1
+func (r *EventRegistry) RegisterUpcaster(eventType string, fromVersion int, upcaster domain.Upcaster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.upcasters[eventType] == nil {
+		r.upcasters[eventType] = make(map[int]domain.Upcaster)
+	}
+	r.upcasters[eventType][fromVersion] = upcaster
+}
+
+// Wrap builds the envelope of event. The trace and correlation IDs are read
+// from ctx; a zero OccurredAt is replaced by the current time.
+func (r *EventRegistry) Wrap(ctx context.Context, event domain.DomainEvent) (*domain.EventEnvelope, error) {
+	r.remember(event)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize event %q: %w", event.Name(), err)
+	}
+
+	id, err := ids.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := &domain.EventEnvelope{
+		ID:            id,
+		Type:          event.Name(),
+		Version:       versionOf(event),
+		OccurredAt:    event.OccurredAt().UTC(),
+		TraceID:       observability.GetTraceID(ctx),
+		SpanID:        observability.GetSpanID(ctx),
+		CorrelationID: observability.GetCorrelationID(ctx),
+		Payload:       payload,
+	}
+	if envelope.OccurredAt.IsZero() {
+		envelope.OccurredAt = time.Now().UTC()
+	}
+	if aggregate, ok := event.(domain.AggregateEvent); ok {
+		envelope.AggregateID = aggregate.AggregateID()
+	}
+	return envelope, nil
+}
+
+// Unwrap rebuilds the concrete event carried by envelope
+func (r *EventRegistry) Unwrap(envelope *domain.EventEnvelope) (domain.DomainEvent, error) {
+	return r.Decode(envelope.Type, envelope.Version, envelope.Payload)
+}
+
+// Marshal wraps event and serializes the envelope to JSON
+func (r *EventRegistry) Marshal(ctx context.Context, event domain.DomainEvent) ([]byte, error) {
+	envelope, err := r.Wrap(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope)
+}
+
+// Unmarshal parses a JSON envelope and rebuilds the event it carries
+func (r *EventRegistry) Unmarshal(data []byte) (*domain.EventEnvelope, domain.DomainEvent, error) {
+	envelope := &domain.EventEnvelope{}
+	if err := json.Unmarshal(data, envelope); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse event envelope: %w", err)
+	}
+	if envelope.Type == "" {
+		return nil, nil, fmt.Errorf("failed to parse event envelope: missing type")
+	}
+
+	event, err := r.Unwrap(envelope)
+	if err != nil {
+		return envelope, nil, err
+	}
+	return envelope, event, nil
+}
+
+// Decode rebuilds the event called eventType from a payload written at
+// version, running the upcasters up to the registered version
+func (r *EventRegistry) Decode(eventType string, version int, payload json.RawMessage) (domain.DomainEvent, error) {
+	r.mu.RLock()
+	registered, ok := r.types[eventType]
+	upcasters := r.upcasters[eventType]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", domain.ErrUnknownEventType, eventType)
+	}
+
+	if version < 1 {
+		version = 1
+	}
+	if version > registered.version {
+		return nil, fmt.Errorf("%w: %q version %d is newer than %d", domain.ErrUnsupportedEventVersion, eventType, version, registered.version)
+	}
+	for ; version < registered.version; version++ {
+		upcaster, ok := upcasters[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: no upcaster for %q from version %d", domain.ErrUnsupportedEventVersion, eventType, version)
+		}
+		upcasted, err := upcaster(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upcast event %q from version %d: %w", eventType, version, err)
+		}
+		payload = upcasted
+	}
+
+	goType := registered.goType
+	isPointer := goType.Kind() == reflect.Pointer
+	if isPointer {
+		goType = goType.Elem()
+	}
+
+	value := reflect.New(goType)
+	if err := json.Unmarshal(payload, value.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to decode event %q: %w", eventType, err)
+	}
+
+	if isPointer {
+		return value.Interface().(domain.DomainEvent), nil
+	}
+	return value.Elem().Interface().(domain.DomainEvent), nil
+}
+
+// remember registers the concrete type of event the first time it is seen
+func (r *EventRegistry) remember(event domain.DomainEvent) {
+	r.mu.RLock()
+	_, known := r.types[event.Name()]
+	r.mu.RUnlock()
+	if !known {
+		r.Register(event)
+	}
+}
+
+// versionOf returns the schema version of event, 1 unless it says otherwise
+func versionOf(event domain.DomainEvent) int {
+	if versioned, ok := event.(domain.VersionedEvent); ok && versioned.EventVersion() > 0 {
+		return versioned.EventVersion()
+	}
+	return 1
+}
+
+// envelopeContext returns ctx augmented with the event, trace and
+// correlation IDs carried by envelope
+func envelopeContext(ctx context.Context, envelope *domain.EventEnvelope) context.Context {
+	ctx = observability.WithEventID(ctx, envelope.ID)
+	if envelope.TraceID != "" && observability.GetTraceID(ctx) == "" {
+		ctx = observability.WithTraceID(ctx, envelope.TraceID)
+		ctx = observability.WithSpanID(ctx, envelope.SpanID)
+	}
+	if envelope.CorrelationID != "" {
+		ctx = observability.WithCorrelationID(ctx, envelope.CorrelationID)
+	}
+	return ctx
+}
@@ -0,0 +1,95 @@
+package driver_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/events/domain"
+	driverevents "github.com/r0x16/Raidark/shared/events/driver"
+	"github.com/r0x16/Raidark/shared/ids"
+	"github.com/r0x16/Raidark/shared/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventRegistry_roundTripsEventsThroughTheEnvelope(t *testing.T) {
+	registry := driverevents.NewEventRegistry()
+	ctx := observability.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx = observability.WithSpanID(ctx, "00f067aa0ba902b7")
+	ctx = observability.WithCorrelationID(ctx, "request-1")
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	data, err := registry.Marshal(ctx, &orderPlaced{OrderID: "order-1", Sequence: 7, At: at})
+	require.NoError(t, err)
+
+	envelope, event, err := driverevents.NewEventRegistry(&orderPlaced{}).Unmarshal(data)
+	require.NoError(t, err)
+	assert.True(t, ids.IsValidV7(envelope.ID))
+	assert.Equal(t, "orders.placed", envelope.Type)
+	assert.Equal(t, 1, envelope.Version)
+	assert.Equal(t, "order-1", envelope.AggregateID)
+	assert.Equal(t, at, envelope.OccurredAt)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", envelope.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", envelope.SpanID)
+	assert.Equal(t, "request-1", envelope.CorrelationID)
+	assert.Equal(t, &orderPlaced{OrderID: "order-1", Sequence: 7, At: at}, event)
+}
+
+func TestEventRegistry_upcastsOlderVersions(t *testing.T) {
+	registry := driverevents.NewEventRegistry(&customerRenamed{})
+	registry.RegisterUpcaster("customers.renamed", 1, func(payload json.RawMessage) (json.RawMessage, error) {
+		var v1 struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(payload, &v1); err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]string{"full_name": v1.Name})
+	})
+	registry.RegisterUpcaster("customers.renamed", 2, func(payload json.RawMessage) (json.RawMessage, error) {
+		var v2 struct {
+			FullName string `json:"full_name"`
+		}
+		if err := json.Unmarshal(payload, &v2); err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]any{"full_name": v2.FullName, "source": "upcasted"})
+	})
+
+	event, err := registry.Unwrap(&domain.EventEnvelope{Type: "customers.renamed", Version: 1, Payload: json.RawMessage(`{"name":"Ada"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, &customerRenamed{FullName: "Ada", Source: "upcasted"}, event)
+
+	event, err = registry.Unwrap(&domain.EventEnvelope{Type: "customers.renamed", Version: 3, Payload: json.RawMessage(`{"full_name":"Grace","source":"api"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, &customerRenamed{FullName: "Grace", Source: "api"}, event)
+}
+
+func TestEventRegistry_rejectsUndecodableEnvelopes(t *testing.T) {
+	registry := driverevents.NewEventRegistry(&customerRenamed{})
+
+	_, err := registry.Unwrap(&domain.EventEnvelope{Type: "orders.cancelled", Version: 1, Payload: json.RawMessage(`{}`)})
+	assert.ErrorIs(t, err, domain.ErrUnknownEventType)
+
+	_, err = registry.Unwrap(&domain.EventEnvelope{Type: "customers.renamed", Version: 4, Payload: json.RawMessage(`{}`)})
+	assert.ErrorIs(t, err, domain.ErrUnsupportedEventVersion)
+
+	_, err = registry.Unwrap(&domain.EventEnvelope{Type: "customers.renamed", Version: 2, Payload: json.RawMessage(`{}`)})
+	assert.ErrorIs(t, err, domain.ErrUnsupportedEventVersion)
+
+	_, _, err = registry.Unmarshal([]byte(`{"order_id":"order-1"}`))
+	assert.Error(t, err)
+}
+
+// customerRenamed is at version 3: v1 had "name", v2 renamed it "full_name"
+// and v3 added "source"
+type customerRenamed struct {
+	FullName string `json:"full_name"`
+	Source   string `json:"source"`
+}
+
+func (e *customerRenamed) Name() string          { return "customers.renamed" }
+func (e *customerRenamed) OccurredAt() time.Time { return time.Time{} }
+func (e *customerRenamed) EventVersion() int     { return 3 }
@@ -2,42 +2,98 @@ package driver
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/r0x16/Raidark/shared/events/domain"
+	domhealth "github.com/r0x16/Raidark/shared/health/domain"
 	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
 	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
 )
 
+// InMemoryConfig holds the queue and delivery settings of the in-memory
+// provider
+type InMemoryConfig struct {
+	// BufferSize is the capacity of the queue feeding the workers
+	BufferSize int
+	// Workers is the number of goroutines draining the queue
+	Workers int
+	// Policy applies to every async listener; a listener implementing
+	// PolicyEventListener overrides its non-zero fields
+	Policy domain.ListenerPolicy
+	// DeadLetter receives the events a listener failed to handle within its
+	// policy. When nil they are only logged.
+	DeadLetter domain.DeadLetterHandler
+}
+
+// queuedEvent is an event waiting for the workers with the context it was
+// published in
+type queuedEvent struct {
+	ctx   context.Context
+	event domain.DomainEvent
+}
+
+// InMemoryDomainEventsProvider delivers domain events inside the process.
+// Sync listeners run inside Publish; async listeners run in their own
+// goroutine under their ListenerPolicy: each attempt is bounded by a timeout,
+// a panic is turned into an error, failures are retried with exponential
+// backoff and the event is handed to the DeadLetterHandler once the attempts
+// are exhausted. Events do not survive a restart.
 type InMemoryDomainEventsProvider struct {
-	queue           chan domain.DomainEvent
+	queue           chan queuedEvent
 	subscribers     map[string][]domain.EventListener
 	syncSubscribers map[string][]domain.EventListener
 	mu              sync.RWMutex
 	wg              sync.WaitGroup
+	pending         atomic.Int64
 	ctx             context.Context
 	cancel          context.CancelFunc
 	workers         int
+	policy          domain.ListenerPolicy
+	deadLetter      domain.DeadLetterHandler
 	hub             *domprovider.ProviderHub
+	metrics         *observability.Metrics
 	LogProvider     domlogger.LogProvider
 }
 
-var _ domain.DomainEventsProvider = &InMemoryDomainEventsProvider{}
+var _ domain.ContextEventsProvider = &InMemoryDomainEventsProvider{}
+var _ domain.DrainableEventsProvider = &InMemoryDomainEventsProvider{}
+var _ domhealth.CheckContributor = &InMemoryDomainEventsProvider{}
 
-func NewInMemoryDomainEventsProvider(bufferSize int, workers int, hub *domprovider.ProviderHub) *InMemoryDomainEventsProvider {
+// NewInMemoryDomainEventsProvider creates the provider. The MetricsProvider
+// is optional and only feeds the events metrics when present.
+func NewInMemoryDomainEventsProvider(config InMemoryConfig, hub *domprovider.ProviderHub) *InMemoryDomainEventsProvider {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &InMemoryDomainEventsProvider{
-		queue:           make(chan domain.DomainEvent, bufferSize),
+	provider := &InMemoryDomainEventsProvider{
+		queue:           make(chan queuedEvent, config.BufferSize),
 		subscribers:     make(map[string][]domain.EventListener),
 		syncSubscribers: make(map[string][]domain.EventListener),
 		mu:              sync.RWMutex{},
 		wg:              sync.WaitGroup{},
 		ctx:             ctx,
 		cancel:          cancel,
-		workers:         workers,
+		workers:         config.Workers,
+		policy:          config.Policy,
+		deadLetter:      config.DeadLetter,
 		hub:             hub,
 		LogProvider:     domprovider.Get[domlogger.LogProvider](hub),
 	}
+	if domprovider.Exists[obsdomain.MetricsProvider](hub) {
+		provider.metrics = domprovider.Get[obsdomain.MetricsProvider](hub).Metrics()
+	}
+	return provider
+}
+
+// SetDeadLetterHandler replaces the handler that receives the events
+// listeners gave up on. It must be called before events are published.
+func (p *InMemoryDomainEventsProvider) SetDeadLetterHandler(handler domain.DeadLetterHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.deadLetter = handler
 }
 
 func (p *InMemoryDomainEventsProvider) Collect() {
@@ -47,8 +103,9 @@ func (p *InMemoryDomainEventsProvider) Collect() {
 			defer p.wg.Done()
 			for {
 				select {
-				case event := <-p.queue:
-					p.Dispatch(event)
+				case queued := <-p.queue:
+					p.dispatch(queued.ctx, queued.event)
+					p.pending.Add(-1)
 				case <-p.ctx.Done():
 					p.LogProvider.Warning("collect worker stopped", map[string]any{"worker": i})
 					return
@@ -58,27 +115,42 @@ func (p *InMemoryDomainEventsProvider) Collect() {
 	}
 }
 
+// Publish delivers the event without a request context
 func (p *InMemoryDomainEventsProvider) Publish(event domain.DomainEvent) error {
-	p.dispatchSync(event)
+	return p.PublishWithContext(context.Background(), event)
+}
 
+// PublishWithContext delivers the event to the listeners. Sync listeners get
+// ctx as it is; async listeners get its values, such as the trace, but not
+// its cancellation, so they outlive the request that published the event.
+func (p *InMemoryDomainEventsProvider) PublishWithContext(ctx context.Context, event domain.DomainEvent) error {
+	p.dispatchSync(ctx, event)
+
+	queued := queuedEvent{ctx: context.WithoutCancel(ctx), event: event}
+	p.pending.Add(1)
 	select {
-	case p.queue <- event:
+	case p.queue <- queued:
 	default:
-		go func(e domain.DomainEvent) {
-			p.LogProvider.Warning("queue is full waiting for a slot", map[string]any{"event": e})
-			p.queue <- e
-		}(event)
+		go func(q queuedEvent) {
+			p.LogProvider.Warning("queue is full waiting for a slot", map[string]any{"event": q.event})
+			p.queue <- q
+		}(queued)
+	}
+	if p.metrics != nil {
+		p.metrics.RecordEventPublished(event.Name(), "success")
 	}
 	return nil
 }
 
-func (p *InMemoryDomainEventsProvider) dispatchSync(event domain.DomainEvent) {
+// dispatchSync runs the sync listeners inside Publish. A panicking listener
+// is logged like a failing one, so it cannot crash the publisher.
+func (p *InMemoryDomainEventsProvider) dispatchSync(ctx context.Context, event domain.DomainEvent) {
 	p.mu.RLock()
 	handlers, ok := p.syncSubscribers[event.Name()]
 	p.mu.RUnlock()
 	if ok {
 		for _, handler := range handlers {
-			err := handler.Handle(context.Background(), event, p.hub)
+			err := invokeListener(ctx, handler, event, p.hub)
 			if err != nil {
 				p.LogProvider.Error("error dispatching event for handler", map[string]any{
 					"event":   event,
@@ -106,32 +178,221 @@ func (p *InMemoryDomainEventsProvider) Subscribe(handler domain.EventListener) e
 	return nil
 }
 
+// Dispatch delivers the event to every async listener without a request
+// context
 func (p *InMemoryDomainEventsProvider) Dispatch(event domain.DomainEvent) error {
+	p.dispatch(context.Background(), event)
+	return nil
+}
+
+// dispatch starts one delivery per async listener of the event
+func (p *InMemoryDomainEventsProvider) dispatch(ctx context.Context, event domain.DomainEvent) {
 	p.mu.RLock()
-	handlers, ok := p.subscribers[event.Name()]
+	handlers := p.subscribers[event.Name()]
 	p.mu.RUnlock()
-	if !ok {
-		return nil
-	}
 
+	p.pending.Add(int64(len(handlers)))
 	for _, handler := range handlers {
 		go func(h domain.EventListener) {
-			err := h.Handle(context.Background(), event, p.hub)
-			if err != nil {
-				p.LogProvider.Error("error dispatching event for handler", map[string]any{
-					"event":   event,
-					"handler": h,
-					"error":   err,
-				})
-			}
+			defer p.pending.Add(-1)
+			p.deliver(ctx, h, event)
 		}(handler)
 	}
+}
 
-	return nil
+// deliver runs handler under its policy until it succeeds, its attempts are
+// exhausted or the provider is closed
+func (p *InMemoryDomainEventsProvider) deliver(ctx context.Context, handler domain.EventListener, event domain.DomainEvent) {
+	policy := p.policyOf(handler)
+	consumer := listenerName(handler)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && p.metrics != nil {
+			p.metrics.RecordEventRedelivery(event.Name(), consumer)
+		}
+
+		started := time.Now()
+		err = p.attempt(ctx, policy.Timeout, handler, event)
+		if p.metrics != nil {
+			p.metrics.ObserveEventProcessing(event.Name(), consumer, float64(time.Since(started).Milliseconds()))
+		}
+		if err == nil {
+			p.recordConsumed(event.Name(), consumer, "success")
+			return
+		}
+
+		if attempt >= policy.MaxAttempts {
+			p.deadLettered(ctx, consumer, event, attempt, err)
+			return
+		}
+
+		delay := exponentialBackoff(policy.RetryBackoff, policy.MaxRetryBackoff, attempt)
+		p.LogProvider.Warning("event handling failed, retry scheduled", map[string]any{
+			"event":    event.Name(),
+			"consumer": consumer,
+			"attempt":  attempt,
+			"delay":    delay,
+			"error":    err,
+		})
+		p.recordConsumed(event.Name(), consumer, "failure")
+
+		select {
+		case <-time.After(delay):
+		case <-p.ctx.Done():
+			p.LogProvider.Warning("provider closed, event retries abandoned", map[string]any{
+				"event":    event.Name(),
+				"consumer": consumer,
+				"attempt":  attempt,
+			})
+			return
+		}
+	}
 }
 
+// attempt runs handler once. When timeout elapses the listener context is
+// cancelled and the attempt fails without waiting for the listener, so one
+// that ignores its context cannot hold the delivery forever.
+func (p *InMemoryDomainEventsProvider) attempt(ctx context.Context, timeout time.Duration, handler domain.EventListener, event domain.DomainEvent) error {
+	if timeout <= 0 {
+		return invokeListener(ctx, handler, event, p.hub)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- invokeListener(ctx, handler, event, p.hub)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("listener timed out after %s: %w", timeout, ctx.Err())
+	}
+}
+
+// deadLettered hands the event to the DeadLetterHandler, or logs it when
+// there is none
+func (p *InMemoryDomainEventsProvider) deadLettered(ctx context.Context, consumer string, event domain.DomainEvent, attempts int, cause error) {
+	p.LogProvider.Error("event exhausted its attempts, dead-lettered", map[string]any{
+		"event":    event.Name(),
+		"consumer": consumer,
+		"attempts": attempts,
+		"error":    cause,
+	})
+	p.recordConsumed(event.Name(), consumer, "dead_lettered")
+
+	p.mu.RLock()
+	handler := p.deadLetter
+	p.mu.RUnlock()
+	if handler == nil {
+		return
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			p.LogProvider.Error("dead letter handler panicked", map[string]any{
+				"event":    event.Name(),
+				"consumer": consumer,
+				"panic":    recovered,
+			})
+		}
+	}()
+	handler.HandleDeadLetter(ctx, domain.DeadLetter{
+		Event:    event,
+		EventID:  observability.GetEventID(ctx),
+		Listener: consumer,
+		Attempts: attempts,
+		Err:      cause,
+		FailedAt: time.Now(),
+	})
+}
+
+// policyOf merges the listener's own policy over the provider defaults
+func (p *InMemoryDomainEventsProvider) policyOf(handler domain.EventListener) domain.ListenerPolicy {
+	policy := p.policy
+	if custom, ok := handler.(domain.PolicyEventListener); ok {
+		override := custom.ListenerPolicy()
+		if override.Timeout > 0 {
+			policy.Timeout = override.Timeout
+		}
+		if override.MaxAttempts > 0 {
+			policy.MaxAttempts = override.MaxAttempts
+		}
+		if override.RetryBackoff > 0 {
+			policy.RetryBackoff = override.RetryBackoff
+		}
+		if override.MaxRetryBackoff > 0 {
+			policy.MaxRetryBackoff = override.MaxRetryBackoff
+		}
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.MaxRetryBackoff < policy.RetryBackoff {
+		policy.MaxRetryBackoff = policy.RetryBackoff
+	}
+	return policy
+}
+
+func (p *InMemoryDomainEventsProvider) recordConsumed(subject, consumer, outcome string) {
+	if p.metrics != nil {
+		p.metrics.RecordEventConsumed(subject, consumer, outcome)
+	}
+}
+
+// Drain waits until every queued event has been dispatched and every
+// delivery, retries included, has finished. It gives up when ctx expires and
+// reports how many events or deliveries were left.
+func (p *InMemoryDomainEventsProvider) Drain(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		pending := p.pending.Load()
+		if pending == 0 {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("in-memory events: %d events or deliveries still pending: %w", pending, ctx.Err())
+		}
+	}
+}
+
+// HealthChecks reports the queue depth to the readiness probe. A full queue
+// means the workers cannot keep up and every new event parks a goroutine, so
+// the instance should stop taking traffic until they catch up.
+func (p *InMemoryDomainEventsProvider) HealthChecks() []domhealth.Check {
+	return []domhealth.Check{{
+		Name: "events.queue",
+		Run: func(context.Context) error {
+			if depth, capacity := len(p.queue), cap(p.queue); capacity > 0 && depth >= capacity {
+				return fmt.Errorf("event queue is full (%d/%d)", depth, capacity)
+			}
+			return nil
+		},
+	}}
+}
+
+// Close stops the workers. Queued events are dropped and retries are
+// abandoned; call Drain first to let them finish.
 func (p *InMemoryDomainEventsProvider) Close() error {
 	p.cancel()
 	p.wg.Wait()
 	return nil
 }
+
+// invokeListener runs the listener, turning a panic into an error so a broken
+// listener cannot take the process down
+func invokeListener(ctx context.Context, handler domain.EventListener, event domain.DomainEvent, hub *domprovider.ProviderHub) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("listener panicked: %v", recovered)
+		}
+	}()
+	return handler.Handle(ctx, event, hub)
+}
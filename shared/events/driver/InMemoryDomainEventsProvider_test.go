@@ -0,0 +1,220 @@
+package driver_test
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/r0x16/Raidark/shared/events/domain"
+	driverevents "github.com/r0x16/Raidark/shared/events/driver"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryDomainEventsProvider_deliversEventsWithTraceContext(t *testing.T) {
+	metrics := observability.NewMetrics()
+	provider, _ := newInMemoryProvider(t, metrics)
+	listener := &tracingListener{}
+	require.NoError(t, provider.Subscribe(listener))
+
+	ctx, cancel := context.WithCancel(observability.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736"))
+	require.NoError(t, provider.PublishWithContext(ctx, &orderPlaced{OrderID: "order-1", Sequence: 1}))
+	cancel()
+
+	require.Eventually(t, func() bool { return listener.count() == 1 }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", listener.traceID)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.EventsConsumedTotal.WithLabelValues("orders.placed", "driver_test.tracingListener", "success")) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestInMemoryDomainEventsProvider_retriesFailedListeners(t *testing.T) {
+	metrics := observability.NewMetrics()
+	provider, letters := newInMemoryProvider(t, metrics)
+	listener := &recordingListener{failures: map[int]int{1: 2}}
+	require.NoError(t, provider.Subscribe(listener))
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+
+	require.Eventually(t, func() bool { return len(listener.sequences("order-1")) == 1 }, 2*time.Second, 10*time.Millisecond)
+	consumer := "driver_test.recordingListener"
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.EventsConsumedTotal.WithLabelValues("orders.placed", consumer, "failure")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.EventsRedeliveriesTotal.WithLabelValues("orders.placed", consumer)))
+	assert.Empty(t, letters.all())
+}
+
+func TestInMemoryDomainEventsProvider_deadLettersPanickingListeners(t *testing.T) {
+	metrics := observability.NewMetrics()
+	provider, letters := newInMemoryProvider(t, metrics)
+	listener := &misbehavingListener{panics: true}
+	require.NoError(t, provider.Subscribe(listener))
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+
+	require.Eventually(t, func() bool { return len(letters.all()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	letter := letters.all()[0]
+	assert.Equal(t, "orders.placed", letter.Event.Name())
+	assert.Equal(t, "driver_test.misbehavingListener", letter.Listener)
+	assert.Equal(t, 3, letter.Attempts)
+	assert.ErrorContains(t, letter.Err, "listener panicked: boom")
+	assert.Equal(t, 3, listener.count())
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.EventsConsumedTotal.WithLabelValues("orders.placed", letter.Listener, "dead_lettered")))
+}
+
+func TestInMemoryDomainEventsProvider_timesOutSlowListeners(t *testing.T) {
+	provider, letters := newInMemoryProvider(t, nil)
+	listener := &misbehavingListener{
+		blocks: true,
+		policy: domain.ListenerPolicy{Timeout: 20 * time.Millisecond, MaxAttempts: 1},
+	}
+	require.NoError(t, provider.Subscribe(listener))
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+
+	require.Eventually(t, func() bool { return len(letters.all()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	letter := letters.all()[0]
+	assert.Equal(t, 1, letter.Attempts)
+	assert.ErrorIs(t, letter.Err, context.DeadlineExceeded)
+	assert.Equal(t, 1, listener.count())
+}
+
+func TestInMemoryDomainEventsProvider_drainWaitsForRetries(t *testing.T) {
+	provider, _ := newInMemoryProvider(t, nil)
+	listener := &recordingListener{failures: map[int]int{1: 2}}
+	require.NoError(t, provider.Subscribe(listener))
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, provider.Drain(ctx))
+	assert.Equal(t, []int{1}, listener.sequences("order-1"))
+}
+
+func TestInMemoryDomainEventsProvider_drainGivesUpAtTheDeadline(t *testing.T) {
+	provider, _ := newInMemoryProvider(t, nil)
+	listener := &misbehavingListener{blocks: true, policy: domain.ListenerPolicy{Timeout: time.Minute}}
+	require.NoError(t, provider.Subscribe(listener))
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, provider.Drain(ctx), context.DeadlineExceeded)
+}
+
+func TestInMemoryDomainEventsProvider_healthCheckFailsWhenTheQueueIsFull(t *testing.T) {
+	hub := &domprovider.ProviderHub{}
+	domprovider.Register[domlogger.LogProvider](hub, obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical))
+	provider := driverevents.NewInMemoryDomainEventsProvider(driverevents.InMemoryConfig{BufferSize: 1, Workers: 1}, hub)
+	t.Cleanup(func() { _ = provider.Close() })
+
+	checks := provider.HealthChecks()
+	require.Len(t, checks, 1)
+	assert.NoError(t, checks[0].Run(context.Background()))
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+	assert.EqualError(t, checks[0].Run(context.Background()), "event queue is full (1/1)")
+}
+
+func TestInMemoryDomainEventsProvider_isolatesPanickingSyncListeners(t *testing.T) {
+	provider, _ := newInMemoryProvider(t, nil)
+	panicking := &syncListener{panics: true}
+	next := &syncListener{}
+	require.NoError(t, provider.Subscribe(panicking))
+	require.NoError(t, provider.Subscribe(next))
+
+	ctx := observability.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.NotPanics(t, func() {
+		require.NoError(t, provider.PublishWithContext(ctx, &orderPlaced{OrderID: "order-1", Sequence: 1}))
+	})
+	assert.Equal(t, 1, panicking.count())
+	assert.Equal(t, 1, next.count())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", next.traceID)
+}
+
+// misbehavingListener panics or blocks until its context is cancelled, and
+// declares its own policy
+type misbehavingListener struct {
+	domain.AsyncEventListener
+	panics  bool
+	blocks  bool
+	policy  domain.ListenerPolicy
+	mu      sync.Mutex
+	handled int
+}
+
+func (l *misbehavingListener) EventName() string { return "orders.placed" }
+
+func (l *misbehavingListener) ListenerPolicy() domain.ListenerPolicy { return l.policy }
+
+func (l *misbehavingListener) Handle(ctx context.Context, _ domain.DomainEvent, _ *domprovider.ProviderHub) error {
+	l.mu.Lock()
+	l.handled++
+	l.mu.Unlock()
+	if l.panics {
+		panic("boom")
+	}
+	if l.blocks {
+		<-ctx.Done()
+	}
+	return nil
+}
+
+func (l *misbehavingListener) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.handled
+}
+
+// deadLetters collects the dead letters of a provider
+type deadLetters struct {
+	mu      sync.Mutex
+	letters []domain.DeadLetter
+}
+
+func (d *deadLetters) HandleDeadLetter(_ context.Context, letter domain.DeadLetter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.letters = append(d.letters, letter)
+}
+
+func (d *deadLetters) all() []domain.DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]domain.DeadLetter(nil), d.letters...)
+}
+
+func newInMemoryProvider(t *testing.T, metrics *observability.Metrics) (*driverevents.InMemoryDomainEventsProvider, *deadLetters) {
+	t.Helper()
+
+	hub := &domprovider.ProviderHub{}
+	domprovider.Register[domlogger.LogProvider](hub, obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical))
+	if metrics != nil {
+		domprovider.Register[obsdomain.MetricsProvider](hub, staticMetricsProvider{metrics: metrics})
+	}
+
+	letters := &deadLetters{}
+	provider := driverevents.NewInMemoryDomainEventsProvider(driverevents.InMemoryConfig{
+		BufferSize: 10,
+		Workers:    2,
+		Policy: domain.ListenerPolicy{
+			Timeout:         time.Second,
+			MaxAttempts:     3,
+			RetryBackoff:    5 * time.Millisecond,
+			MaxRetryBackoff: 20 * time.Millisecond,
+		},
+		DeadLetter: letters,
+	}, hub)
+	provider.Collect()
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider, letters
+}
@@ -0,0 +1,174 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/r0x16/Raidark/shared/events/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+)
+
+// consumeLocked creates, or resumes, the durable consumer of handler and
+// starts pulling its messages. The caller must hold p.mu.
+func (p *JetStreamDomainEventsProvider) consumeLocked(handler domain.EventListener) error {
+	name := p.ConsumerName(handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.PublishTimeout)
+	defer cancel()
+	consumer, err := p.js.CreateOrUpdateConsumer(ctx, p.config.Stream, jetstream.ConsumerConfig{
+		Durable:       name,
+		FilterSubject: p.subject(handler.EventName()),
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       p.config.AckWait,
+		// The server never gives up on a message: handle dead-letters it
+		// after config.MaxDeliver deliveries, and a message whose dead-letter
+		// copy failed, or whose handler crashed, must still come back.
+		MaxDeliver: -1,
+	})
+	if err != nil {
+		return fmt.Errorf("jetstream: failed to create consumer %s: %w", name, err)
+	}
+
+	consumeContext, err := consumer.Consume(func(msg jetstream.Msg) {
+		p.handle(handler, name, msg)
+	})
+	if err != nil {
+		return fmt.Errorf("jetstream: failed to consume %s: %w", name, err)
+	}
+
+	p.consumers = append(p.consumers, consumeContext)
+	p.durables = append(p.durables, consumer)
+	return nil
+}
+
+// handle runs handler for one message and acks it, schedules a redelivery
+// or dead-letters it once MaxDeliver is reached
+func (p *JetStreamDomainEventsProvider) handle(handler domain.EventListener, consumer string, msg jetstream.Msg) {
+	eventName := msg.Headers().Get(JetStreamEventNameHeader)
+	deliveries := 1
+	if metadata, err := msg.Metadata(); err == nil {
+		deliveries = int(metadata.NumDelivered)
+	}
+	if deliveries > 1 && p.metrics != nil {
+		p.metrics.RecordEventRedelivery(eventName, consumer)
+	}
+
+	started := time.Now()
+	envelope, event, err := p.events.Unmarshal(msg.Data())
+	if err != nil {
+		// A payload that cannot be decoded will not decode on a retry either
+		p.deadLetter(msg, consumer, deliveries, fmt.Errorf("jetstream: %w", err))
+		return
+	}
+
+	ctx := observability.ExtractTrace(context.Background(), msg.Headers())
+	ctx = envelopeContext(ctx, envelope)
+	err = invokeListener(ctx, handler, event, p.hub)
+	if p.metrics != nil {
+		p.metrics.ObserveEventProcessing(eventName, consumer, float64(time.Since(started).Milliseconds()))
+	}
+
+	if err == nil {
+		if ackErr := msg.Ack(); ackErr != nil {
+			p.LogProvider.Error("jetstream failed to ack message", map[string]any{
+				"event":    eventName,
+				"consumer": consumer,
+				"error":    ackErr,
+			})
+		}
+		p.recordConsumed(eventName, consumer, "success")
+		return
+	}
+
+	if deliveries >= p.config.MaxDeliver {
+		p.deadLetter(msg, consumer, deliveries, err)
+		return
+	}
+
+	delay := exponentialBackoff(p.config.RetryBackoff, p.config.MaxRetryBackoff, deliveries)
+	p.LogProvider.Warning("jetstream event handling failed, redelivery scheduled", map[string]any{
+		"event":      eventName,
+		"consumer":   consumer,
+		"deliveries": deliveries,
+		"delay":      delay,
+		"error":      err,
+	})
+	if nakErr := msg.NakWithDelay(delay); nakErr != nil {
+		p.LogProvider.Error("jetstream failed to nak message", map[string]any{
+			"event":    eventName,
+			"consumer": consumer,
+			"error":    nakErr,
+		})
+	}
+	p.recordConsumed(eventName, consumer, "failure")
+}
+
+// deadLetter copies msg to the dead-letter stream and terminates it. When the
+// copy cannot be stored the message is left for a later redelivery instead,
+// so it is never lost.
+func (p *JetStreamDomainEventsProvider) deadLetter(msg jetstream.Msg, consumer string, deliveries int, cause error) {
+	eventName := msg.Headers().Get(JetStreamEventNameHeader)
+
+	letter := nats.NewMsg(p.config.DeadLetterSubjectPrefix + "." + consumer)
+	letter.Data = msg.Data()
+	for key, values := range msg.Headers() {
+		letter.Header[key] = append([]string(nil), values...)
+	}
+	letter.Header.Del(jetstream.MsgIDHeader)
+	letter.Header.Set(JetStreamDeadLetterErrorHeader, cause.Error())
+	letter.Header.Set(JetStreamConsumerHeader, consumer)
+	letter.Header.Set(JetStreamOriginalSubjectHeader, msg.Subject())
+	letter.Header.Set(JetStreamDeliveriesHeader, strconv.Itoa(deliveries))
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.PublishTimeout)
+	defer cancel()
+	id := msg.Headers().Get(jetstream.MsgIDHeader) + ":" + consumer
+	if _, err := p.js.PublishMsg(ctx, letter, jetstream.WithMsgID(id)); err != nil {
+		p.LogProvider.Error("jetstream failed to dead-letter message", map[string]any{
+			"event":    eventName,
+			"consumer": consumer,
+			"error":    err,
+		})
+		_ = msg.NakWithDelay(p.config.MaxRetryBackoff)
+		p.recordConsumed(eventName, consumer, "failure")
+		return
+	}
+
+	p.LogProvider.Error("jetstream event exhausted its deliveries, moved to dead-letter stream", map[string]any{
+		"event":      eventName,
+		"consumer":   consumer,
+		"deliveries": deliveries,
+		"error":      cause,
+	})
+	if err := msg.Term(); err != nil {
+		p.LogProvider.Error("jetstream failed to terminate message", map[string]any{
+			"event":    eventName,
+			"consumer": consumer,
+			"error":    err,
+		})
+	}
+	p.recordConsumed(eventName, consumer, "dead_lettered")
+}
+
+func (p *JetStreamDomainEventsProvider) recordConsumed(subject, consumer, outcome string) {
+	if p.metrics != nil {
+		p.metrics.RecordEventConsumed(subject, consumer, outcome)
+	}
+}
+
+// exponentialBackoff returns base * 2^(attempts-1), capped at max
+func exponentialBackoff(base, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
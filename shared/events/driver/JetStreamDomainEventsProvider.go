@@ -0,0 +1,370 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/r0x16/Raidark/shared/events/domain"
+	domhealth "github.com/r0x16/Raidark/shared/health/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// Headers added to the messages published by the JetStream driver
+const (
+	JetStreamEventNameHeader       = "Raidark-Event-Name"
+	JetStreamDeadLetterErrorHeader = "Raidark-Dead-Letter-Error"
+	JetStreamConsumerHeader        = "Raidark-Consumer"
+	JetStreamOriginalSubjectHeader = "Raidark-Original-Subject"
+	JetStreamDeliveriesHeader      = "Raidark-Deliveries"
+)
+
+// invalidDurableChars matches what NATS does not accept in a consumer name
+var invalidDurableChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// JetStreamConfig holds the connection and delivery settings of the
+// JetStream provider
+type JetStreamConfig struct {
+	// URL is the NATS server URL, or a comma separated list of them
+	URL string
+	// Stream is the JetStream stream that stores the events. It is created,
+	// or updated, on start to capture SubjectPrefix.>
+	Stream string
+	// SubjectPrefix is prepended to the event name to build the subject
+	SubjectPrefix string
+	// ConsumerPrefix namespaces the durable consumer names, so services
+	// sharing a stream keep their own delivery state
+	ConsumerPrefix string
+	// DuplicateWindow is how long the stream remembers message IDs to drop
+	// duplicated publishes
+	DuplicateWindow time.Duration
+	// AckWait is how long the server waits for an ack before redelivering
+	AckWait time.Duration
+	// MaxDeliver is the number of deliveries after which a message is moved
+	// to the dead-letter stream
+	MaxDeliver int
+	// RetryBackoff is the delay before the first redelivery of a failed
+	// message; it doubles on every failure up to MaxRetryBackoff
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// DeadLetterStream stores the messages that exhausted MaxDeliver under
+	// DeadLetterSubjectPrefix.<consumer>
+	DeadLetterStream        string
+	DeadLetterSubjectPrefix string
+	// PublishTimeout bounds the wait for the stream acknowledgement
+	PublishTimeout time.Duration
+	// HealthMaxPending is the number of messages waiting for a consumer, or
+	// for its ack, above which the events.jetstream.backlog check reports it
+	HealthMaxPending int
+}
+
+// JetStreamDomainEventsProvider publishes domain events to a NATS JetStream
+// stream and delivers them to async listeners through durable consumers.
+//
+// Sync listeners keep the in-memory semantics and run inside Publish. Each
+// async listener owns a durable pull consumer filtered on its event subject,
+// so delivery is at-least-once and survives restarts: a failed message is
+// redelivered with exponential backoff until MaxDeliver, then copied to the
+// dead-letter stream and terminated.
+type JetStreamDomainEventsProvider struct {
+	config          JetStreamConfig
+	conn            *nats.Conn
+	js              jetstream.JetStream
+	subscribers     map[string][]domain.EventListener
+	syncSubscribers map[string][]domain.EventListener
+	events          *EventRegistry
+	consumers       []jetstream.ConsumeContext
+	durables        []jetstream.Consumer
+	collecting      bool
+	mu              sync.RWMutex
+	hub             *domprovider.ProviderHub
+	metrics         *observability.Metrics
+	LogProvider     domlogger.LogProvider
+}
+
+var _ domain.ContextEventsProvider = &JetStreamDomainEventsProvider{}
+var _ domhealth.CheckContributor = &JetStreamDomainEventsProvider{}
+
+// NewJetStreamDomainEventsProvider connects to NATS and makes sure the event
+// and dead-letter streams exist. The MetricsProvider is optional and only
+// feeds the events metrics when present.
+func NewJetStreamDomainEventsProvider(config JetStreamConfig, hub *domprovider.ProviderHub) (*JetStreamDomainEventsProvider, error) {
+	conn, err := nats.Connect(config.URL, nats.Name(config.ConsumerPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("jetstream: failed to connect to %s: %w", config.URL, err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("jetstream: %w", err)
+	}
+
+	provider := &JetStreamDomainEventsProvider{
+		config:          config,
+		conn:            conn,
+		js:              js,
+		subscribers:     make(map[string][]domain.EventListener),
+		syncSubscribers: make(map[string][]domain.EventListener),
+		events:          NewEventRegistry(),
+		hub:             hub,
+		LogProvider:     domprovider.Get[domlogger.LogProvider](hub),
+	}
+	if domprovider.Exists[obsdomain.MetricsProvider](hub) {
+		provider.metrics = domprovider.Get[obsdomain.MetricsProvider](hub).Metrics()
+	}
+
+	if err := provider.ensureStreams(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return provider, nil
+}
+
+// RegisterEvent teaches the consumers how to decode events of the
+// prototype's type. Events published by another service never go through
+// this process' Publish, so every event consumed here must be registered.
+func (p *JetStreamDomainEventsProvider) RegisterEvent(prototype domain.DomainEvent) {
+	p.events.Register(prototype)
+}
+
+// Registry returns the registry that wraps and decodes the events, used to
+// register upcasters for older event versions
+func (p *JetStreamDomainEventsProvider) Registry() *EventRegistry {
+	return p.events
+}
+
+// Collect starts a durable consumer for every async listener. Listeners
+// subscribed afterwards start consuming as soon as they subscribe.
+func (p *JetStreamDomainEventsProvider) Collect() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.collecting = true
+	for _, handlers := range p.subscribers {
+		for _, handler := range handlers {
+			if err := p.consumeLocked(handler); err != nil {
+				p.LogProvider.Error("jetstream failed to start consumer", map[string]any{
+					"event":   handler.EventName(),
+					"handler": handler,
+					"error":   err,
+				})
+			}
+		}
+	}
+}
+
+// Publish sends the event without a request context
+func (p *JetStreamDomainEventsProvider) Publish(event domain.DomainEvent) error {
+	return p.PublishWithContext(context.Background(), event)
+}
+
+// PublishWithContext sends the event envelope to the stream. The trace
+// stored in ctx travels in the envelope and the message headers, and the
+// envelope ID is the message ID, so the client retries of a publish are
+// dropped by the stream.
+func (p *JetStreamDomainEventsProvider) PublishWithContext(ctx context.Context, event domain.DomainEvent) error {
+	p.dispatchSync(ctx, event)
+
+	err := p.publish(ctx, event)
+	if p.metrics != nil {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		p.metrics.RecordEventPublished(event.Name(), outcome)
+	}
+	return err
+}
+
+func (p *JetStreamDomainEventsProvider) Subscribe(handler domain.EventListener) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	eventName := handler.EventName()
+	p.LogProvider.Info("subscribing to event", map[string]any{"event": eventName, "handler": handler})
+
+	if !handler.IsAsync() {
+		p.syncSubscribers[eventName] = append(p.syncSubscribers[eventName], handler)
+		return nil
+	}
+
+	p.subscribers[eventName] = append(p.subscribers[eventName], handler)
+	if p.collecting {
+		return p.consumeLocked(handler)
+	}
+	return nil
+}
+
+// Dispatch delivers the event to every local async listener and returns the
+// joined listener errors, panics included. It bypasses the stream.
+func (p *JetStreamDomainEventsProvider) Dispatch(event domain.DomainEvent) error {
+	p.mu.RLock()
+	handlers := p.subscribers[event.Name()]
+	p.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := invokeListener(context.Background(), handler, event, p.hub); err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", handler, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HealthChecks reports the NATS connection to the readiness probe. The
+// client reconnects on its own; the instance is unready in the meantime.
+//
+// The backlog of the consumers is reported by an optional check: the
+// messages wait safely in the stream, and taking the instance out of
+// rotation would not deliver them sooner.
+func (p *JetStreamDomainEventsProvider) HealthChecks() []domhealth.Check {
+	return []domhealth.Check{{
+		Name: "events.jetstream",
+		Run: func(context.Context) error {
+			if status := p.conn.Status(); status != nats.CONNECTED {
+				return fmt.Errorf("jetstream: connection is %s", status)
+			}
+			return nil
+		},
+	}, {
+		Name:     "events.jetstream.backlog",
+		Optional: true,
+		Run:      p.checkBacklog,
+	}}
+}
+
+// checkBacklog fails when a consumer of this process has more messages
+// waiting for delivery or for their ack than HealthMaxPending
+func (p *JetStreamDomainEventsProvider) checkBacklog(ctx context.Context) error {
+	p.mu.RLock()
+	durables := append([]jetstream.Consumer(nil), p.durables...)
+	p.mu.RUnlock()
+
+	for _, consumer := range durables {
+		info, err := consumer.Info(ctx)
+		if err != nil {
+			return fmt.Errorf("jetstream: failed to read consumer %s: %w", consumer.CachedInfo().Name, err)
+		}
+		backlog := info.NumPending + uint64(info.NumAckPending)
+		if backlog > uint64(p.config.HealthMaxPending) {
+			return fmt.Errorf("jetstream consumer %s has %d pending messages (max %d)", info.Name, backlog, p.config.HealthMaxPending)
+		}
+	}
+	return nil
+}
+
+// Close stops the consumers, waits for the messages being handled and closes
+// the connection. Unacked messages are redelivered after AckWait.
+func (p *JetStreamDomainEventsProvider) Close() error {
+	p.mu.Lock()
+	consumers := p.consumers
+	p.consumers = nil
+	p.durables = nil
+	p.collecting = false
+	p.mu.Unlock()
+
+	for _, consumer := range consumers {
+		consumer.Stop()
+	}
+	for _, consumer := range consumers {
+		<-consumer.Closed()
+	}
+
+	p.conn.Close()
+	return nil
+}
+
+// ensureStreams creates or updates the event and dead-letter streams
+func (p *JetStreamDomainEventsProvider) ensureStreams() error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.PublishTimeout)
+	defer cancel()
+
+	_, err := p.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       p.config.Stream,
+		Subjects:   []string{p.config.SubjectPrefix + ".>"},
+		Storage:    jetstream.FileStorage,
+		Duplicates: p.config.DuplicateWindow,
+	})
+	if err != nil {
+		return fmt.Errorf("jetstream: failed to ensure stream %s: %w", p.config.Stream, err)
+	}
+
+	_, err = p.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       p.config.DeadLetterStream,
+		Subjects:   []string{p.config.DeadLetterSubjectPrefix + ".>"},
+		Storage:    jetstream.FileStorage,
+		Duplicates: p.config.DuplicateWindow,
+	})
+	if err != nil {
+		return fmt.Errorf("jetstream: failed to ensure stream %s: %w", p.config.DeadLetterStream, err)
+	}
+	return nil
+}
+
+// publish wraps the event and waits for the stream acknowledgement
+func (p *JetStreamDomainEventsProvider) publish(ctx context.Context, event domain.DomainEvent) error {
+	envelope, err := p.events.Wrap(ctx, event)
+	if err != nil {
+		return fmt.Errorf("jetstream: %w", err)
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("jetstream: failed to serialize event %q: %w", event.Name(), err)
+	}
+
+	msg := nats.NewMsg(p.subject(event.Name()))
+	msg.Data = payload
+	msg.Header.Set(JetStreamEventNameHeader, event.Name())
+	observability.InjectTrace(ctx, msg.Header)
+
+	ctx, cancel := context.WithTimeout(ctx, p.config.PublishTimeout)
+	defer cancel()
+	if _, err := p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(envelope.ID)); err != nil {
+		return fmt.Errorf("jetstream: failed to publish event %q: %w", event.Name(), err)
+	}
+	return nil
+}
+
+// dispatchSync runs the sync listeners in order. A failing or panicking
+// listener is logged and does not stop the others nor the publish.
+func (p *JetStreamDomainEventsProvider) dispatchSync(ctx context.Context, event domain.DomainEvent) {
+	p.mu.RLock()
+	handlers, ok := p.syncSubscribers[event.Name()]
+	p.mu.RUnlock()
+	if ok {
+		for _, handler := range handlers {
+			if err := invokeListener(ctx, handler, event, p.hub); err != nil {
+				p.LogProvider.Error("error dispatching event for handler", map[string]any{
+					"event":   event,
+					"handler": handler,
+					"error":   err,
+				})
+			}
+		}
+	}
+}
+
+// subject returns the subject events called name are published to
+func (p *JetStreamDomainEventsProvider) subject(name string) string {
+	return p.config.SubjectPrefix + "." + name
+}
+
+// ConsumerName returns the durable consumer name used for handler. It is
+// derived from the consumer prefix, the event name and the listener name
+// (its Go type unless it implements NamedEventListener), so it stays stable
+// across restarts and deployments.
+func (p *JetStreamDomainEventsProvider) ConsumerName(handler domain.EventListener) string {
+	name := strings.Join([]string{p.config.ConsumerPrefix, handler.EventName(), listenerName(handler)}, "_")
+	return invalidDurableChars.ReplaceAllString(name, "-")
+}
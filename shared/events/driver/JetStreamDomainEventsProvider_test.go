@@ -0,0 +1,312 @@
+package driver_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/r0x16/Raidark/shared/events/domain"
+	driverevents "github.com/r0x16/Raidark/shared/events/driver"
+	testnats "github.com/r0x16/Raidark/shared/internal/testutil/nats"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJetStreamDomainEventsProvider_deliversEventsWithTraceContext(t *testing.T) {
+	url := testnats.NewJetStreamServer(t)
+	metrics := observability.NewMetrics()
+	provider := newJetStreamProvider(t, url, metrics)
+	listener := &tracingListener{}
+	require.NoError(t, provider.Subscribe(listener))
+	provider.Collect()
+
+	ctx := observability.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx = observability.WithSpanID(ctx, "00f067aa0ba902b7")
+	require.NoError(t, provider.PublishWithContext(ctx, &orderPlaced{OrderID: "order-1", Sequence: 1}))
+
+	require.Eventually(t, func() bool { return listener.count() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", listener.traceID)
+	assert.NotEmpty(t, listener.eventID)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.EventsPublishedTotal.WithLabelValues("orders.placed", "success")))
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.EventsConsumedTotal.WithLabelValues("orders.placed", provider.ConsumerName(listener), "success")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestJetStreamDomainEventsProvider_dropsDuplicatedMessageIDs(t *testing.T) {
+	url := testnats.NewJetStreamServer(t)
+	provider := newJetStreamProvider(t, url, nil)
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+
+	js := newJetStreamClient(t, url)
+	stream, err := js.Stream(context.Background(), "TEST_EVENTS")
+	require.NoError(t, err)
+	stored, err := stream.GetMsg(context.Background(), 1)
+	require.NoError(t, err)
+	var envelope domain.EventEnvelope
+	require.NoError(t, json.Unmarshal(stored.Data, &envelope))
+	id := stored.Header.Get(jetstream.MsgIDHeader)
+	assert.Equal(t, envelope.ID, id)
+	assert.Equal(t, "orders.placed", stored.Header.Get(driverevents.JetStreamEventNameHeader))
+
+	retry := nats.NewMsg(stored.Subject)
+	retry.Data = stored.Data
+	ack, err := js.PublishMsg(context.Background(), retry, jetstream.WithMsgID(id))
+	require.NoError(t, err)
+	assert.True(t, ack.Duplicate)
+
+	info, err := stream.Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), info.State.Msgs)
+}
+
+func TestJetStreamDomainEventsProvider_redeliversFailedMessages(t *testing.T) {
+	url := testnats.NewJetStreamServer(t)
+	metrics := observability.NewMetrics()
+	provider := newJetStreamProvider(t, url, metrics)
+	listener := &recordingListener{failures: map[int]int{1: 1}}
+	require.NoError(t, provider.Subscribe(listener))
+	provider.Collect()
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+
+	require.Eventually(t, func() bool { return len(listener.sequences("order-1")) == 1 }, 5*time.Second, 10*time.Millisecond)
+	consumer := provider.ConsumerName(listener)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.EventsConsumedTotal.WithLabelValues("orders.placed", consumer, "failure")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.EventsRedeliveriesTotal.WithLabelValues("orders.placed", consumer)))
+}
+
+func TestJetStreamDomainEventsProvider_deadLettersAfterMaxDeliver(t *testing.T) {
+	url := testnats.NewJetStreamServer(t)
+	metrics := observability.NewMetrics()
+	provider := newJetStreamProvider(t, url, metrics)
+	listener := &recordingListener{failures: map[int]int{1: 100}}
+	require.NoError(t, provider.Subscribe(listener))
+	provider.Collect()
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+
+	consumer := provider.ConsumerName(listener)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.EventsConsumedTotal.WithLabelValues("orders.placed", consumer, "dead_lettered")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.EventsConsumedTotal.WithLabelValues("orders.placed", consumer, "failure")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.EventsRedeliveriesTotal.WithLabelValues("orders.placed", consumer)))
+	assert.Empty(t, listener.sequences("order-1"))
+
+	stream, err := newJetStreamClient(t, url).Stream(context.Background(), "TEST_EVENTS_DLQ")
+	require.NoError(t, err)
+	letter, err := stream.GetLastMsgForSubject(context.Background(), "test.dlq."+consumer)
+	require.NoError(t, err)
+	assert.Contains(t, letter.Header.Get(driverevents.JetStreamDeadLetterErrorHeader), "listener unavailable")
+	assert.Equal(t, "test.events.orders.placed", letter.Header.Get(driverevents.JetStreamOriginalSubjectHeader))
+	assert.Equal(t, "3", letter.Header.Get(driverevents.JetStreamDeliveriesHeader))
+
+	var envelope domain.EventEnvelope
+	require.NoError(t, json.Unmarshal(letter.Data, &envelope))
+	assert.Equal(t, "orders.placed", envelope.Type)
+	assert.Equal(t, "order-1", envelope.AggregateID)
+	assert.JSONEq(t, `{"order_id":"order-1","sequence":1,"at":"0001-01-01T00:00:00Z"}`, string(envelope.Payload))
+}
+
+func TestJetStreamDomainEventsProvider_keepsMessageWhenDeadLetterFails(t *testing.T) {
+	url := testnats.NewJetStreamServer(t)
+	metrics := observability.NewMetrics()
+	provider := newJetStreamProvider(t, url, metrics)
+	listener := &recordingListener{failures: map[int]int{1: 100}}
+	require.NoError(t, provider.Subscribe(listener))
+	provider.Collect()
+
+	// Without its stream the dead-letter copy cannot be stored
+	js := newJetStreamClient(t, url)
+	require.NoError(t, js.DeleteStream(context.Background(), "TEST_EVENTS_DLQ"))
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+
+	consumer := provider.ConsumerName(listener)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.EventsConsumedTotal.WithLabelValues("orders.placed", consumer, "failure")) >= 4
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Zero(t, testutil.ToFloat64(metrics.EventsConsumedTotal.WithLabelValues("orders.placed", consumer, "dead_lettered")))
+
+	_, err := js.CreateStream(context.Background(), jetstream.StreamConfig{
+		Name:     "TEST_EVENTS_DLQ",
+		Subjects: []string{"test.dlq.>"},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.EventsConsumedTotal.WithLabelValues("orders.placed", consumer, "dead_lettered")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	stream, err := js.Stream(context.Background(), "TEST_EVENTS_DLQ")
+	require.NoError(t, err)
+	letter, err := stream.GetLastMsgForSubject(context.Background(), "test.dlq."+consumer)
+	require.NoError(t, err)
+	assert.Contains(t, letter.Header.Get(driverevents.JetStreamDeadLetterErrorHeader), "listener unavailable")
+}
+
+func TestJetStreamDomainEventsProvider_durableConsumerResumesAfterRestart(t *testing.T) {
+	url := testnats.NewJetStreamServer(t)
+	first := newJetStreamProvider(t, url, nil)
+	listener := &recordingListener{}
+	require.NoError(t, first.Subscribe(listener))
+	first.Collect()
+	require.NoError(t, first.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+	require.Eventually(t, func() bool { return len(listener.sequences("order-1")) == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, first.Close())
+
+	publisher := newJetStreamProvider(t, url, nil)
+	require.NoError(t, publisher.Publish(&orderPlaced{OrderID: "order-1", Sequence: 2}))
+
+	restarted := newJetStreamProvider(t, url, nil)
+	restarted.RegisterEvent(&orderPlaced{})
+	restarted.Collect()
+	require.NoError(t, restarted.Subscribe(listener))
+
+	require.Eventually(t, func() bool { return len(listener.sequences("order-1")) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1, 2}, listener.sequences("order-1"))
+}
+
+func TestJetStreamDomainEventsProvider_namesConsumersAfterTheListener(t *testing.T) {
+	provider := newJetStreamProvider(t, testnats.NewJetStreamServer(t), nil)
+
+	assert.Equal(t, "test_orders-placed_driver_test-recordingListener", provider.ConsumerName(&recordingListener{}))
+	deduplicated := driverevents.NewDeduplicatingEventListener(&recordingListener{}, "billing")
+	assert.Equal(t, "test_orders-placed_billing", provider.ConsumerName(deduplicated))
+}
+
+func TestJetStreamDomainEventsProvider_isolatesPanickingSyncListeners(t *testing.T) {
+	provider := newJetStreamProvider(t, testnats.NewJetStreamServer(t), nil)
+	panicking := &syncListener{panics: true}
+	next := &syncListener{}
+	require.NoError(t, provider.Subscribe(panicking))
+	require.NoError(t, provider.Subscribe(next))
+
+	assert.NotPanics(t, func() {
+		require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+	})
+	assert.Equal(t, 1, panicking.count())
+	assert.Equal(t, 1, next.count())
+}
+
+func TestJetStreamDomainEventsProvider_dispatchReturnsListenerPanics(t *testing.T) {
+	provider := newJetStreamProvider(t, testnats.NewJetStreamServer(t), nil)
+	require.NoError(t, provider.Subscribe(&misbehavingListener{panics: true}))
+
+	var err error
+	assert.NotPanics(t, func() {
+		err = provider.Dispatch(&orderPlaced{OrderID: "order-1", Sequence: 1})
+	})
+	assert.ErrorContains(t, err, "listener panicked: boom")
+}
+
+func TestJetStreamDomainEventsProvider_healthCheckReportsTheBacklog(t *testing.T) {
+	provider := newJetStreamProvider(t, testnats.NewJetStreamServer(t), nil)
+	listener := &gatedListener{release: make(chan struct{})}
+	require.NoError(t, provider.Subscribe(listener))
+	provider.Collect()
+	checks := provider.HealthChecks()
+	require.Len(t, checks, 2)
+	backlog := checks[1]
+	assert.Equal(t, "events.jetstream.backlog", backlog.Name)
+	assert.True(t, backlog.Optional)
+	assert.NoError(t, backlog.Run(context.Background()))
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 2}))
+	require.Eventually(t, func() bool { return backlog.Run(context.Background()) != nil }, 5*time.Second, 10*time.Millisecond)
+	assert.ErrorContains(t, backlog.Run(context.Background()), "pending messages (max 0)")
+
+	close(listener.release)
+	require.Eventually(t, func() bool { return backlog.Run(context.Background()) == nil }, 5*time.Second, 10*time.Millisecond)
+}
+
+// gatedListener holds every delivery until release is closed
+type gatedListener struct {
+	domain.AsyncEventListener
+	release chan struct{}
+}
+
+func (l *gatedListener) EventName() string { return "orders.placed" }
+
+func (l *gatedListener) Handle(context.Context, domain.DomainEvent, *domprovider.ProviderHub) error {
+	<-l.release
+	return nil
+}
+
+// tracingListener records the trace and event IDs carried by the context
+type tracingListener struct {
+	domain.AsyncEventListener
+	mu      sync.Mutex
+	handled int
+	traceID string
+	eventID string
+}
+
+func (l *tracingListener) EventName() string { return "orders.placed" }
+
+func (l *tracingListener) Handle(ctx context.Context, _ domain.DomainEvent, _ *domprovider.ProviderHub) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handled++
+	l.traceID = observability.GetTraceID(ctx)
+	l.eventID = observability.GetEventID(ctx)
+	return nil
+}
+
+func (l *tracingListener) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.handled
+}
+
+func newJetStreamProvider(t *testing.T, url string, metrics *observability.Metrics) *driverevents.JetStreamDomainEventsProvider {
+	t.Helper()
+
+	hub := &domprovider.ProviderHub{}
+	domprovider.Register[domlogger.LogProvider](hub, obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical))
+	if metrics != nil {
+		domprovider.Register[obsdomain.MetricsProvider](hub, staticMetricsProvider{metrics: metrics})
+	}
+
+	provider, err := driverevents.NewJetStreamDomainEventsProvider(driverevents.JetStreamConfig{
+		URL:                     url,
+		Stream:                  "TEST_EVENTS",
+		SubjectPrefix:           "test.events",
+		ConsumerPrefix:          "test",
+		DuplicateWindow:         time.Minute,
+		AckWait:                 time.Second,
+		MaxDeliver:              3,
+		RetryBackoff:            10 * time.Millisecond,
+		MaxRetryBackoff:         50 * time.Millisecond,
+		DeadLetterStream:        "TEST_EVENTS_DLQ",
+		DeadLetterSubjectPrefix: "test.dlq",
+		PublishTimeout:          5 * time.Second,
+	}, hub)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+func newJetStreamClient(t *testing.T, url string) jetstream.JetStream {
+	t.Helper()
+
+	conn, err := nats.Connect(url)
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+	return js
+}
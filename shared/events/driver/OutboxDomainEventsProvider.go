@@ -0,0 +1,307 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	"github.com/r0x16/Raidark/shared/events/domain"
+	"github.com/r0x16/Raidark/shared/events/domain/model"
+	"github.com/r0x16/Raidark/shared/events/driver/repositories"
+	domhealth "github.com/r0x16/Raidark/shared/health/domain"
+	"github.com/r0x16/Raidark/shared/ids"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"gorm.io/gorm"
+)
+
+// OutboxConfig holds the relay tuning knobs of the outbox provider
+type OutboxConfig struct {
+	// PollInterval is the pause between two relay iterations
+	PollInterval time.Duration
+	// BatchSize is the maximum number of rows read per iteration
+	BatchSize int
+	// MaxAttempts is the number of failed deliveries after which a row is
+	// marked as failed and no longer retried
+	MaxAttempts int
+	// Partitions is the number of concurrent delivery workers. Rows are
+	// assigned to a worker by their ordering key, so one aggregate is always
+	// handled by the same worker
+	Partitions int
+	// RetryBackoff is the delay before the first retry; it doubles on every
+	// subsequent failure up to MaxRetryBackoff
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// ClaimTimeout is how long a relay owns a row while delivering it. A relay
+	// that dies mid-delivery releases its rows once the claim expires
+	ClaimTimeout time.Duration
+	// HealthMaxPending and HealthMaxFailed are the row counts above which the
+	// events.outbox health check reports the backlog
+	HealthMaxPending int
+	HealthMaxFailed  int
+}
+
+// OutboxDomainEventsProvider stores events in the outbox_events table and
+// relays them to asynchronous listeners from a background loop.
+//
+// Sync listeners keep the in-memory semantics and run inside Publish. Async
+// listeners only see an event once the row that carries it is committed,
+// which makes delivery at-least-once: a row is retried until every listener
+// returns nil or MaxAttempts is reached.
+type OutboxDomainEventsProvider struct {
+	config          OutboxConfig
+	repository      *driverdatastore.GormRepository
+	subscribers     map[string][]domain.EventListener
+	syncSubscribers map[string][]domain.EventListener
+	events          *EventRegistry
+	mu              sync.RWMutex
+	wg              sync.WaitGroup
+	ctx             context.Context
+	cancel          context.CancelFunc
+	hub             *domprovider.ProviderHub
+	metrics         *observability.Metrics
+	LogProvider     domlogger.LogProvider
+}
+
+var _ domain.TransactionalEventsProvider = &OutboxDomainEventsProvider{}
+var _ domain.ContextEventsProvider = &OutboxDomainEventsProvider{}
+var _ domhealth.CheckContributor = &OutboxDomainEventsProvider{}
+
+// NewOutboxDomainEventsProvider creates the provider. The hub must already
+// hold a Gorm DatabaseProvider; the MetricsProvider is optional and only
+// feeds the outbox pending gauge when present.
+func NewOutboxDomainEventsProvider(config OutboxConfig, hub *domprovider.ProviderHub) *OutboxDomainEventsProvider {
+	ctx, cancel := context.WithCancel(context.Background())
+	provider := &OutboxDomainEventsProvider{
+		config:          config,
+		repository:      driverdatastore.NewGormRepository(hub),
+		subscribers:     make(map[string][]domain.EventListener),
+		syncSubscribers: make(map[string][]domain.EventListener),
+		events:          NewEventRegistry(),
+		ctx:             ctx,
+		cancel:          cancel,
+		hub:             hub,
+		LogProvider:     domprovider.Get[domlogger.LogProvider](hub),
+	}
+	if domprovider.Exists[obsdomain.MetricsProvider](hub) {
+		provider.metrics = domprovider.Get[obsdomain.MetricsProvider](hub).Metrics()
+	}
+	return provider
+}
+
+// RegisterEvent teaches the relay how to decode events of the prototype's
+// type. Publish registers types automatically, so this is only needed for
+// events that may still be pending from a previous run of the process.
+func (p *OutboxDomainEventsProvider) RegisterEvent(prototype domain.DomainEvent) {
+	p.events.Register(prototype)
+}
+
+// Registry returns the registry that wraps and decodes the stored events,
+// used to register upcasters for older event versions
+func (p *OutboxDomainEventsProvider) Registry() *EventRegistry {
+	return p.events
+}
+
+// Collect starts the relay loop
+func (p *OutboxDomainEventsProvider) Collect() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			p.relay()
+			select {
+			case <-ticker.C:
+			case <-p.ctx.Done():
+				p.LogProvider.Warning("outbox relay stopped", nil)
+				return
+			}
+		}
+	}()
+}
+
+// Publish stores the event in its own transaction
+func (p *OutboxDomainEventsProvider) Publish(event domain.DomainEvent) error {
+	return p.PublishWithContext(context.Background(), event)
+}
+
+// PublishWithContext stores the event in its own transaction, keeping the
+// trace and correlation IDs of ctx in its envelope
+func (p *OutboxDomainEventsProvider) PublishWithContext(ctx context.Context, event domain.DomainEvent) error {
+	p.dispatchSync(ctx, event)
+	return p.store(ctx, p.repository.GetExecWithContext(ctx), event)
+}
+
+// PublishInTransaction stores the event in the caller's transaction, so the
+// event is relayed only if tx commits. Sync listeners run once tx commits and
+// never see an event whose transaction rolled back, which requires tx to be a
+// CommitNotifier when the event has sync listeners.
+func (p *OutboxDomainEventsProvider) PublishInTransaction(tx domdatastore.Transaction, event domain.DomainEvent) error {
+	notifier, ok := tx.(domdatastore.CommitNotifier)
+	if !ok && p.hasSyncListeners(event) {
+		return fmt.Errorf("outbox: sync listeners of %q need a transaction that runs commit hooks", event.Name())
+	}
+	if err := p.store(context.Background(), p.repository.GetTransactionExec(tx), event); err != nil {
+		return err
+	}
+	if ok {
+		notifier.AfterCommit(func() { p.dispatchSync(context.Background(), event) })
+	}
+	return nil
+}
+
+func (p *OutboxDomainEventsProvider) Subscribe(handler domain.EventListener) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	eventName := handler.EventName()
+	p.LogProvider.Info("subscribing to event", map[string]any{"event": eventName, "handler": handler})
+
+	if handler.IsAsync() {
+		p.subscribers[eventName] = append(p.subscribers[eventName], handler)
+		return nil
+	}
+
+	p.syncSubscribers[eventName] = append(p.syncSubscribers[eventName], handler)
+	return nil
+}
+
+// Dispatch delivers the event to every async listener and returns the joined
+// listener errors. The relay uses it for each outbox row; calling it directly
+// bypasses the outbox.
+func (p *OutboxDomainEventsProvider) Dispatch(event domain.DomainEvent) error {
+	return p.dispatch(context.Background(), event)
+}
+
+// HealthChecks reports the outbox backlog to the readiness probe. The check
+// is optional: the rows wait safely in the database, and taking the instance
+// out of rotation would not deliver them sooner.
+func (p *OutboxDomainEventsProvider) HealthChecks() []domhealth.Check {
+	return []domhealth.Check{{
+		Name:     "events.outbox",
+		Optional: true,
+		Run: func(ctx context.Context) error {
+			repo := repositories.NewGormOutboxRepository(p.repository.GetExecWithContext(ctx))
+			pending, err := repo.CountPending()
+			if err != nil {
+				return fmt.Errorf("outbox: failed to count pending events: %w", err)
+			}
+			if pending > int64(p.config.HealthMaxPending) {
+				return fmt.Errorf("outbox has %d pending events (max %d)", pending, p.config.HealthMaxPending)
+			}
+			failed, err := repo.CountFailed()
+			if err != nil {
+				return fmt.Errorf("outbox: failed to count failed events: %w", err)
+			}
+			if failed > int64(p.config.HealthMaxFailed) {
+				return fmt.Errorf("outbox has %d failed events (max %d)", failed, p.config.HealthMaxFailed)
+			}
+			return nil
+		},
+	}}
+}
+
+// Close stops the relay and waits for the in-flight iteration to finish
+func (p *OutboxDomainEventsProvider) Close() error {
+	p.cancel()
+	p.wg.Wait()
+	return nil
+}
+
+func (p *OutboxDomainEventsProvider) dispatch(ctx context.Context, event domain.DomainEvent) error {
+	p.mu.RLock()
+	handlers := p.subscribers[event.Name()]
+	p.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := invokeListener(ctx, handler, event, p.hub); err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", handler, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *OutboxDomainEventsProvider) hasSyncListeners(event domain.DomainEvent) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.syncSubscribers[event.Name()]) > 0
+}
+
+func (p *OutboxDomainEventsProvider) dispatchSync(ctx context.Context, event domain.DomainEvent) {
+	p.mu.RLock()
+	handlers, ok := p.syncSubscribers[event.Name()]
+	p.mu.RUnlock()
+	if ok {
+		for _, handler := range handlers {
+			err := invokeListener(ctx, handler, event, p.hub)
+			if err != nil {
+				p.LogProvider.Error("error dispatching event for handler", map[string]any{
+					"event":   event,
+					"handler": handler,
+					"error":   err,
+				})
+			}
+		}
+	}
+}
+
+// store wraps the event in its envelope and inserts it as a pending outbox
+// row using db, which may be bound to a transaction. The row shares the ID of
+// the envelope.
+func (p *OutboxDomainEventsProvider) store(ctx context.Context, db *gorm.DB, event domain.DomainEvent) error {
+	envelope, err := p.events.Wrap(ctx, event)
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("outbox: failed to serialize event %q: %w", event.Name(), err)
+	}
+
+	row := &model.OutboxEvent{
+		AggregateID:   envelope.AggregateID,
+		EventName:     envelope.Type,
+		Payload:       string(payload),
+		Status:        model.OutboxStatusPending,
+		OccurredAt:    envelope.OccurredAt,
+		NextAttemptAt: time.Now(),
+	}
+	row.ID = ids.UUIDv7(envelope.ID)
+
+	if err := repositories.NewGormOutboxRepository(db).Create(row); err != nil {
+		return fmt.Errorf("outbox: failed to store event %q: %w", event.Name(), err)
+	}
+	return nil
+}
+
+// decode rebuilds the envelope and the concrete event stored in row. Rows
+// written before the envelope existed hold the bare event payload; they are
+// read as version 1 of the event.
+func (p *OutboxDomainEventsProvider) decode(row *model.OutboxEvent) (*domain.EventEnvelope, domain.DomainEvent, error) {
+	envelope := &domain.EventEnvelope{}
+	if err := json.Unmarshal([]byte(row.Payload), envelope); err != nil || envelope.Type == "" || len(envelope.Payload) == 0 {
+		envelope = &domain.EventEnvelope{
+			ID:          string(row.ID),
+			Type:        row.EventName,
+			Version:     1,
+			AggregateID: row.AggregateID,
+			OccurredAt:  row.OccurredAt,
+			Payload:     json.RawMessage(row.Payload),
+		}
+	}
+
+	event, err := p.events.Unwrap(envelope)
+	if err != nil {
+		return nil, nil, fmt.Errorf("outbox: %w", err)
+	}
+	return envelope, event, nil
+}
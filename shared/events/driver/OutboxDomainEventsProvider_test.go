@@ -0,0 +1,326 @@
+// Package driver_test verifies the outbox events driver end to end against a
+// file-backed SQLite database: atomic enqueue, relay retries and per-aggregate
+// ordering.
+package driver_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	driverenv "github.com/r0x16/Raidark/shared/env/driver"
+	"github.com/r0x16/Raidark/shared/events/domain"
+	"github.com/r0x16/Raidark/shared/events/domain/model"
+	driverevents "github.com/r0x16/Raidark/shared/events/driver"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/observability"
+	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestOutboxDomainEventsProvider_rolledBackTransactionLeavesNoEvent(t *testing.T) {
+	provider, db, database := newOutboxProvider(t, nil)
+
+	tx := database.GetTransaction()
+	tx.Begin()
+	require.NoError(t, provider.PublishInTransaction(tx, &orderPlaced{OrderID: "order-1"}))
+	tx.Rollback()
+
+	assert.Equal(t, int64(0), countOutboxRows(t, db, ""))
+
+	tx = database.GetTransaction()
+	tx.Begin()
+	require.NoError(t, provider.PublishInTransaction(tx, &orderPlaced{OrderID: "order-1"}))
+	tx.Commit()
+
+	assert.Equal(t, int64(1), countOutboxRows(t, db, model.OutboxStatusPending))
+}
+
+func TestOutboxDomainEventsProvider_runsSyncListenersAfterCommit(t *testing.T) {
+	provider, _, database := newOutboxProvider(t, nil)
+	listener := &syncListener{}
+	require.NoError(t, provider.Subscribe(listener))
+
+	tx := database.GetTransaction()
+	tx.Begin()
+	require.NoError(t, provider.PublishInTransaction(tx, &orderPlaced{OrderID: "order-1"}))
+	assert.Zero(t, listener.count())
+	tx.Rollback()
+	assert.Zero(t, listener.count())
+
+	tx = database.GetTransaction()
+	tx.Begin()
+	require.NoError(t, provider.PublishInTransaction(tx, &orderPlaced{OrderID: "order-2"}))
+	assert.Zero(t, listener.count())
+	tx.Commit()
+	assert.Equal(t, 1, listener.count())
+}
+
+func TestOutboxDomainEventsProvider_isolatesPanickingListeners(t *testing.T) {
+	provider, db, _ := newOutboxProvider(t, nil)
+	listener := &syncListener{panics: true}
+	require.NoError(t, provider.Subscribe(listener))
+	require.NoError(t, provider.Subscribe(&misbehavingListener{panics: true}))
+	provider.Collect()
+
+	ctx := observability.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, provider.PublishWithContext(ctx, &orderPlaced{OrderID: "order-1", Sequence: 1}))
+	assert.Equal(t, 1, listener.count())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", listener.traceID)
+
+	// The relay survives the panics: the row exhausts its attempts
+	require.Eventually(t, func() bool {
+		return countOutboxRows(t, db, model.OutboxStatusFailed) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var row model.OutboxEvent
+	require.NoError(t, db.First(&row).Error)
+	assert.Contains(t, row.LastError, "listener panicked: boom")
+}
+
+func TestOutboxDomainEventsProvider_relaysCommittedEventsToAsyncListeners(t *testing.T) {
+	metrics := observability.NewMetrics()
+	provider, db, _ := newOutboxProvider(t, metrics)
+	listener := &recordingListener{}
+	require.NoError(t, provider.Subscribe(listener))
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+	provider.Collect()
+
+	require.Eventually(t, func() bool {
+		return countOutboxRows(t, db, model.OutboxStatusPublished) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1}, listener.sequences("order-1"))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.OutboxPending))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.EventsPublishedTotal.WithLabelValues("orders.placed", "success")))
+}
+
+func TestOutboxDomainEventsProvider_retriesThenFailsAfterMaxAttempts(t *testing.T) {
+	provider, db, _ := newOutboxProvider(t, nil)
+	listener := &recordingListener{failures: map[int]int{1: 100}}
+	require.NoError(t, provider.Subscribe(listener))
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+	provider.Collect()
+
+	require.Eventually(t, func() bool {
+		return countOutboxRows(t, db, model.OutboxStatusFailed) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var row model.OutboxEvent
+	require.NoError(t, db.First(&row).Error)
+	assert.Equal(t, 3, row.Attempts)
+	assert.Contains(t, row.LastError, "listener unavailable")
+	assert.Nil(t, row.PublishedAt)
+}
+
+func TestOutboxDomainEventsProvider_healthCheckReportsTheBacklog(t *testing.T) {
+	provider, db, _ := newOutboxProvider(t, nil)
+	checks := provider.HealthChecks()
+	require.Len(t, checks, 1)
+	assert.Equal(t, "events.outbox", checks[0].Name)
+	assert.True(t, checks[0].Optional)
+	assert.NoError(t, checks[0].Run(context.Background()))
+
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: 1}))
+	assert.EqualError(t, checks[0].Run(context.Background()), "outbox has 1 pending events (max 0)")
+
+	require.NoError(t, db.Model(&model.OutboxEvent{}).Where("1 = 1").Update("status", model.OutboxStatusFailed).Error)
+	assert.EqualError(t, checks[0].Run(context.Background()), "outbox has 1 failed events (max 0)")
+}
+
+func TestOutboxDomainEventsProvider_preservesOrderPerAggregate(t *testing.T) {
+	provider, db, _ := newOutboxProvider(t, nil)
+	listener := &recordingListener{failures: map[int]int{1: 1}}
+	require.NoError(t, provider.Subscribe(listener))
+
+	for sequence := 1; sequence <= 3; sequence++ {
+		require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-1", Sequence: sequence}))
+	}
+	require.NoError(t, provider.Publish(&orderPlaced{OrderID: "order-2", Sequence: 1}))
+	provider.Collect()
+
+	require.Eventually(t, func() bool {
+		return countOutboxRows(t, db, model.OutboxStatusPublished) == 4
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1, 2, 3}, listener.sequences("order-1"))
+	assert.Equal(t, []int{1}, listener.sequences("order-2"))
+}
+
+func TestOutboxDomainEventsProvider_storesEventEnvelopes(t *testing.T) {
+	provider, db, _ := newOutboxProvider(t, nil)
+
+	ctx := observability.WithCorrelationID(context.Background(), "request-1")
+	require.NoError(t, provider.PublishWithContext(ctx, &orderPlaced{OrderID: "order-1", Sequence: 1}))
+
+	var row model.OutboxEvent
+	require.NoError(t, db.First(&row).Error)
+	var envelope domain.EventEnvelope
+	require.NoError(t, json.Unmarshal([]byte(row.Payload), &envelope))
+	assert.Equal(t, string(row.ID), envelope.ID)
+	assert.Equal(t, "orders.placed", envelope.Type)
+	assert.Equal(t, "order-1", envelope.AggregateID)
+	assert.Equal(t, "request-1", envelope.CorrelationID)
+	assert.JSONEq(t, `{"order_id":"order-1","sequence":1,"at":"0001-01-01T00:00:00Z"}`, string(envelope.Payload))
+}
+
+func TestOutboxDomainEventsProvider_relaysRowsWrittenBeforeTheEnvelope(t *testing.T) {
+	provider, db, _ := newOutboxProvider(t, nil)
+	provider.RegisterEvent(&orderPlaced{})
+	listener := &recordingListener{}
+	require.NoError(t, provider.Subscribe(listener))
+
+	require.NoError(t, db.Create(&model.OutboxEvent{
+		AggregateID:   "order-1",
+		EventName:     "orders.placed",
+		Payload:       `{"order_id":"order-1","sequence":1}`,
+		Status:        model.OutboxStatusPending,
+		OccurredAt:    time.Now(),
+		NextAttemptAt: time.Now(),
+	}).Error)
+	provider.Collect()
+
+	require.Eventually(t, func() bool {
+		return countOutboxRows(t, db, model.OutboxStatusPublished) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1}, listener.sequences("order-1"))
+}
+
+type orderPlaced struct {
+	OrderID  string    `json:"order_id"`
+	Sequence int       `json:"sequence"`
+	At       time.Time `json:"at"`
+}
+
+func (e *orderPlaced) Name() string          { return "orders.placed" }
+func (e *orderPlaced) OccurredAt() time.Time { return e.At }
+func (e *orderPlaced) AggregateID() string   { return e.OrderID }
+
+// recordingListener records successful deliveries per aggregate and fails the
+// first N deliveries of the sequences listed in failures.
+type recordingListener struct {
+	domain.AsyncEventListener
+	mu        sync.Mutex
+	failures  map[int]int
+	delivered map[string][]int
+}
+
+func (l *recordingListener) EventName() string { return "orders.placed" }
+
+func (l *recordingListener) Handle(_ context.Context, event domain.DomainEvent, _ *domprovider.ProviderHub) error {
+	placed := event.(*orderPlaced)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.failures[placed.Sequence] > 0 && placed.OrderID == "order-1" {
+		l.failures[placed.Sequence]--
+		return errors.New("listener unavailable")
+	}
+	if l.delivered == nil {
+		l.delivered = map[string][]int{}
+	}
+	l.delivered[placed.OrderID] = append(l.delivered[placed.OrderID], placed.Sequence)
+	return nil
+}
+
+func (l *recordingListener) sequences(orderID string) []int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]int(nil), l.delivered[orderID]...)
+}
+
+// syncListener counts the events it handles inside Publish, keeps the trace
+// ID of the last one and panics when asked to
+type syncListener struct {
+	domain.SyncEventListener
+	panics  bool
+	mu      sync.Mutex
+	handled int
+	traceID string
+}
+
+func (l *syncListener) EventName() string { return "orders.placed" }
+
+func (l *syncListener) Handle(ctx context.Context, _ domain.DomainEvent, _ *domprovider.ProviderHub) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handled++
+	l.traceID = observability.GetTraceID(ctx)
+	if l.panics {
+		panic("boom")
+	}
+	return nil
+}
+
+func (l *syncListener) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.handled
+}
+
+func newOutboxProvider(t *testing.T, metrics *observability.Metrics) (*driverevents.OutboxDomainEventsProvider, *gorm.DB, domdatastore.DatabaseProvider) {
+	t.Helper()
+
+	t.Setenv("DATASTORE_TYPE", "sqlite")
+	t.Setenv("DB_DATABASE", filepath.Join(t.TempDir(), "outbox.db")+"?_busy_timeout=5000")
+
+	hub := &domprovider.ProviderHub{}
+	env := domprovider.Register[domenv.EnvProvider](hub, driverenv.NewEnvProvider())
+	domprovider.Register[domlogger.LogProvider](hub, obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical))
+	if metrics != nil {
+		domprovider.Register[obsdomain.MetricsProvider](hub, staticMetricsProvider{metrics: metrics})
+	}
+
+	database := driverdatastore.NewGormSqliteDatabaseProvider(env)
+	require.NoError(t, database.Connect())
+	t.Cleanup(func() { _ = database.Close() })
+	domprovider.Register[domdatastore.DatabaseProvider](hub, database)
+
+	db := database.GetDataStore().Exec
+	require.NoError(t, db.AutoMigrate(&model.OutboxEvent{}))
+
+	provider := driverevents.NewOutboxDomainEventsProvider(driverevents.OutboxConfig{
+		PollInterval:    10 * time.Millisecond,
+		BatchSize:       10,
+		MaxAttempts:     3,
+		Partitions:      4,
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: 5 * time.Millisecond,
+		ClaimTimeout:    time.Second,
+	}, hub)
+	t.Cleanup(func() { _ = provider.Close() })
+
+	return provider, db, database
+}
+
+func countOutboxRows(t *testing.T, db *gorm.DB, status string) int64 {
+	t.Helper()
+
+	query := db.Model(&model.OutboxEvent{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var count int64
+	require.NoError(t, query.Count(&count).Error)
+	return count
+}
+
+type staticMetricsProvider struct {
+	obsdomain.MetricsProvider
+	metrics *observability.Metrics
+}
+
+func (p staticMetricsProvider) Metrics() *observability.Metrics { return p.metrics }
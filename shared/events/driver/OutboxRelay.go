@@ -0,0 +1,208 @@
+package driver
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/events/domain/model"
+	"github.com/r0x16/Raidark/shared/events/domain/repositories"
+	driverrepositories "github.com/r0x16/Raidark/shared/events/driver/repositories"
+)
+
+// relay runs one iteration of the outbox loop: it reads the due rows, groups
+// them by ordering key and delivers each group sequentially on the worker
+// that owns the key. The iteration returns once every group is handled, so
+// no row is in flight between two iterations.
+func (p *OutboxDomainEventsProvider) relay() {
+	repo := driverrepositories.NewGormOutboxRepository(p.repository.GetExec())
+
+	rows, err := repo.FindDue(time.Now(), p.config.BatchSize)
+	if err != nil {
+		p.LogProvider.Error("outbox relay failed to read pending events", map[string]any{"error": err})
+		return
+	}
+
+	groups, err := p.deliverableGroups(repo, rows)
+	if err != nil {
+		p.LogProvider.Error("outbox relay failed to resolve aggregate order", map[string]any{"error": err})
+		return
+	}
+
+	partitions := make([][][]*model.OutboxEvent, p.partitionCount())
+	for _, group := range groups {
+		index := p.partitionOf(group[0].OrderingKey())
+		partitions[index] = append(partitions[index], group)
+	}
+
+	var wg sync.WaitGroup
+	for _, partition := range partitions {
+		if len(partition) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(partition [][]*model.OutboxEvent) {
+			defer wg.Done()
+			for _, group := range partition {
+				p.deliverGroup(repo, group)
+			}
+		}(partition)
+	}
+	wg.Wait()
+
+	p.reportPending(repo)
+}
+
+// deliverableGroups splits rows into per-key groups that keep the read order.
+// A group is only deliverable when its first row is the oldest pending row of
+// its aggregate; otherwise an older event of that aggregate is still waiting
+// for a retry and the newer ones must not overtake it.
+func (p *OutboxDomainEventsProvider) deliverableGroups(repo repositories.OutboxRepository, rows []*model.OutboxEvent) ([][]*model.OutboxEvent, error) {
+	keys := []string{}
+	byKey := map[string][]*model.OutboxEvent{}
+	aggregateIDs := []string{}
+	for _, row := range rows {
+		key := row.OrderingKey()
+		if _, seen := byKey[key]; !seen {
+			keys = append(keys, key)
+			if row.AggregateID != "" {
+				aggregateIDs = append(aggregateIDs, row.AggregateID)
+			}
+		}
+		byKey[key] = append(byKey[key], row)
+	}
+
+	oldest, err := repo.FindOldestPendingByAggregate(aggregateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([][]*model.OutboxEvent, 0, len(keys))
+	for _, key := range keys {
+		group := byKey[key]
+		head := group[0]
+		if head.AggregateID != "" && oldest[head.AggregateID] != string(head.ID) {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// deliverGroup delivers the rows of one ordering key in order and stops at the
+// first row that cannot be delivered, leaving the rest for a later iteration
+func (p *OutboxDomainEventsProvider) deliverGroup(repo repositories.OutboxRepository, group []*model.OutboxEvent) {
+	for _, row := range group {
+		now := time.Now()
+		claimed, err := repo.Claim(row, now, now.Add(p.config.ClaimTimeout))
+		if err != nil {
+			p.LogProvider.Error("outbox relay failed to claim event", map[string]any{
+				"event_id": row.ID,
+				"error":    err,
+			})
+			return
+		}
+		if !claimed {
+			return
+		}
+
+		if err := p.deliver(row); err != nil {
+			p.handleDeliveryFailure(repo, row, err)
+			return
+		}
+
+		if err := repo.MarkPublished(row, time.Now()); err != nil {
+			p.LogProvider.Error("outbox relay failed to mark event as published", map[string]any{
+				"event_id": row.ID,
+				"error":    err,
+			})
+			return
+		}
+	}
+}
+
+// deliver decodes the row and hands the event to every async listener
+func (p *OutboxDomainEventsProvider) deliver(row *model.OutboxEvent) error {
+	envelope, event, err := p.decode(row)
+	if err != nil {
+		return err
+	}
+
+	ctx := envelopeContext(context.Background(), envelope)
+	err = p.dispatch(ctx, event)
+	if p.metrics != nil {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		p.metrics.RecordEventPublished(row.EventName, outcome)
+	}
+	return err
+}
+
+// handleDeliveryFailure schedules the next attempt with exponential backoff,
+// or gives up on the row once it reaches MaxAttempts
+func (p *OutboxDomainEventsProvider) handleDeliveryFailure(repo repositories.OutboxRepository, row *model.OutboxEvent, cause error) {
+	if row.Attempts+1 >= p.config.MaxAttempts {
+		p.LogProvider.Error("outbox event exhausted its delivery attempts", map[string]any{
+			"event_id": row.ID,
+			"event":    row.EventName,
+			"attempts": row.Attempts + 1,
+			"error":    cause,
+		})
+		if err := repo.MarkFailed(row, cause); err != nil {
+			p.LogProvider.Error("outbox relay failed to mark event as failed", map[string]any{
+				"event_id": row.ID,
+				"error":    err,
+			})
+		}
+		return
+	}
+
+	next := time.Now().Add(p.backoff(row.Attempts + 1))
+	p.LogProvider.Warning("outbox event delivery failed, retry scheduled", map[string]any{
+		"event_id":        row.ID,
+		"event":           row.EventName,
+		"attempts":        row.Attempts + 1,
+		"next_attempt_at": next,
+		"error":           cause,
+	})
+	if err := repo.MarkRetry(row, cause, next); err != nil {
+		p.LogProvider.Error("outbox relay failed to schedule retry", map[string]any{
+			"event_id": row.ID,
+			"error":    err,
+		})
+	}
+}
+
+// backoff returns RetryBackoff * 2^(attempts-1), capped at MaxRetryBackoff
+func (p *OutboxDomainEventsProvider) backoff(attempts int) time.Duration {
+	return exponentialBackoff(p.config.RetryBackoff, p.config.MaxRetryBackoff, attempts)
+}
+
+// reportPending feeds the outbox depth gauge when metrics are enabled
+func (p *OutboxDomainEventsProvider) reportPending(repo repositories.OutboxRepository) {
+	if p.metrics == nil {
+		return
+	}
+	pending, err := repo.CountPending()
+	if err != nil {
+		p.LogProvider.Error("outbox relay failed to count pending events", map[string]any{"error": err})
+		return
+	}
+	p.metrics.SetOutboxPending(float64(pending))
+}
+
+func (p *OutboxDomainEventsProvider) partitionCount() int {
+	if p.config.Partitions < 1 {
+		return 1
+	}
+	return p.config.Partitions
+}
+
+func (p *OutboxDomainEventsProvider) partitionOf(key string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return int(hash.Sum32() % uint32(p.partitionCount()))
+}
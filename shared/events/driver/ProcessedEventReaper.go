@@ -0,0 +1,131 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	"github.com/r0x16/Raidark/shared/events/driver/repositories"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// ProcessedEventReaperConfig holds the retention and schedule of the
+// processed events reaper
+type ProcessedEventReaperConfig struct {
+	// TTL is how long a dedup record is kept. It must exceed the longest
+	// time an event can still be redelivered.
+	TTL time.Duration
+	// Interval is the pause between two runs. Zero disables the reaper.
+	Interval time.Duration
+	// BatchSize is the maximum number of records deleted per statement
+	BatchSize int
+	// BatchPause is waited between two batches of the same run
+	BatchPause time.Duration
+}
+
+// NewProcessedEventReaperConfigFromEnv reads the reaper settings
+func NewProcessedEventReaperConfigFromEnv(env domenv.EnvProvider) ProcessedEventReaperConfig {
+	return ProcessedEventReaperConfig{
+		TTL:        time.Duration(env.GetInt("EVENTS_DEDUP_TTL_HOURS", 168)) * time.Hour,
+		Interval:   time.Duration(env.GetInt("EVENTS_DEDUP_REAPER_INTERVAL_SECONDS", 3600)) * time.Second,
+		BatchSize:  env.GetInt("EVENTS_DEDUP_REAPER_BATCH_SIZE", 500),
+		BatchPause: time.Duration(env.GetInt("EVENTS_DEDUP_REAPER_BATCH_PAUSE_MS", 100)) * time.Millisecond,
+	}
+}
+
+// ProcessedEventReaper deletes the dedup records older than the TTL on a
+// schedule. Without it processed_events keeps a row per handled event.
+type ProcessedEventReaper struct {
+	config    ProcessedEventReaperConfig
+	datastore domdatastore.DatabaseProvider
+	log       domlogger.LogProvider
+	now       func() time.Time
+	wg        sync.WaitGroup
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// NewProcessedEventReaper creates the reaper. The hub must hold a
+// DatabaseProvider.
+func NewProcessedEventReaper(config ProcessedEventReaperConfig, hub *domprovider.ProviderHub) *ProcessedEventReaper {
+	if config.BatchSize < 1 {
+		config.BatchSize = 500
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ProcessedEventReaper{
+		config:    config,
+		datastore: domprovider.Get[domdatastore.DatabaseProvider](hub),
+		log:       domprovider.Get[domlogger.LogProvider](hub),
+		now:       time.Now,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start runs the reaper right away and then every Interval until Stop is
+// called. It does nothing when Interval is zero.
+func (r *ProcessedEventReaper) Start() {
+	if r.config.Interval <= 0 {
+		r.log.Info("Processed events reaper disabled", nil)
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+		for {
+			_, _ = r.Run(r.ctx)
+			select {
+			case <-ticker.C:
+			case <-r.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop cancels the running batch and waits for the loop to exit
+func (r *ProcessedEventReaper) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// Run deletes the expired records once and returns how many were deleted
+func (r *ProcessedEventReaper) Run(ctx context.Context) (int, error) {
+	repo := repositories.NewGormProcessedEventRepository(r.datastore.GetDataStore().Exec.WithContext(ctx))
+	cutoff := r.now().Add(-r.config.TTL)
+	total := 0
+
+	for {
+		deleted, err := repo.DeleteProcessedBefore(cutoff, r.config.BatchSize)
+		total += deleted
+		if err != nil {
+			err = fmt.Errorf("failed to delete processed events: %w", err)
+			r.log.Error("Processed events reaper failed", map[string]any{
+				"error":   err,
+				"deleted": total,
+			})
+			return total, err
+		}
+		if deleted < r.config.BatchSize {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(r.config.BatchPause):
+		}
+	}
+
+	if total > 0 {
+		r.log.Info("Processed events pruned", map[string]any{"deleted": total})
+	}
+	return total, nil
+}
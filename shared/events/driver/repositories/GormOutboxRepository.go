@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"time"
+
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	"github.com/r0x16/Raidark/shared/events/domain/model"
+	"github.com/r0x16/Raidark/shared/events/domain/repositories"
+	"gorm.io/gorm"
+)
+
+// GormOutboxRepository implements OutboxRepository using GORM
+type GormOutboxRepository struct {
+	db *gorm.DB
+}
+
+// Verify interface implementation
+var _ repositories.OutboxRepository = &GormOutboxRepository{}
+
+// NewGormOutboxRepository creates a new GORM outbox repository instance.
+// Pass the transaction-bound *gorm.DB to make writes part of that transaction.
+// Reads are pinned to the primary, where the relay claims its rows.
+func NewGormOutboxRepository(db *gorm.DB) *GormOutboxRepository {
+	return &GormOutboxRepository{
+		db: driverdatastore.OnPrimary(db),
+	}
+}
+
+// Create implements repositories.OutboxRepository
+func (r *GormOutboxRepository) Create(event *model.OutboxEvent) error {
+	return r.db.Create(event).Error
+}
+
+// FindDue implements repositories.OutboxRepository
+// UUIDv7 primary keys sort chronologically, so ordering by id preserves the
+// publication order without relying on timestamp precision.
+func (r *GormOutboxRepository) FindDue(now time.Time, limit int) ([]*model.OutboxEvent, error) {
+	var events []*model.OutboxEvent
+	err := r.db.
+		Where("status = ? AND next_attempt_at <= ?", model.OutboxStatusPending, now).
+		Where("locked_until IS NULL OR locked_until < ?", now).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// FindOldestPendingByAggregate implements repositories.OutboxRepository
+func (r *GormOutboxRepository) FindOldestPendingByAggregate(aggregateIDs []string) (map[string]string, error) {
+	oldest := make(map[string]string, len(aggregateIDs))
+	if len(aggregateIDs) == 0 {
+		return oldest, nil
+	}
+
+	var rows []struct {
+		AggregateID string
+		ID          string
+	}
+	err := r.db.Model(&model.OutboxEvent{}).
+		Select("aggregate_id, MIN(id) AS id").
+		Where("status = ? AND aggregate_id IN ?", model.OutboxStatusPending, aggregateIDs).
+		Group("aggregate_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		oldest[row.AggregateID] = row.ID
+	}
+	return oldest, nil
+}
+
+// Claim implements repositories.OutboxRepository
+func (r *GormOutboxRepository) Claim(event *model.OutboxEvent, now, until time.Time) (bool, error) {
+	result := r.db.Model(&model.OutboxEvent{}).
+		Where("id = ? AND status = ?", event.ID, model.OutboxStatusPending).
+		Where("locked_until IS NULL OR locked_until < ?", now).
+		Update("locked_until", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		event.LockedUntil = &until
+		return true, nil
+	}
+	return false, nil
+}
+
+// MarkPublished implements repositories.OutboxRepository
+func (r *GormOutboxRepository) MarkPublished(event *model.OutboxEvent, at time.Time) error {
+	event.Status = model.OutboxStatusPublished
+	event.PublishedAt = &at
+	event.LockedUntil = nil
+	event.LastError = ""
+	return r.db.Model(event).Select("status", "published_at", "locked_until", "last_error").Updates(event).Error
+}
+
+// MarkRetry implements repositories.OutboxRepository
+func (r *GormOutboxRepository) MarkRetry(event *model.OutboxEvent, cause error, next time.Time) error {
+	event.Attempts++
+	event.LastError = cause.Error()
+	event.NextAttemptAt = next
+	event.LockedUntil = nil
+	return r.db.Model(event).Select("attempts", "last_error", "next_attempt_at", "locked_until").Updates(event).Error
+}
+
+// MarkFailed implements repositories.OutboxRepository
+func (r *GormOutboxRepository) MarkFailed(event *model.OutboxEvent, cause error) error {
+	event.Attempts++
+	event.Status = model.OutboxStatusFailed
+	event.LastError = cause.Error()
+	event.LockedUntil = nil
+	return r.db.Model(event).Select("attempts", "status", "last_error", "locked_until").Updates(event).Error
+}
+
+// CountPending implements repositories.OutboxRepository
+func (r *GormOutboxRepository) CountPending() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.OutboxEvent{}).Where("status = ?", model.OutboxStatusPending).Count(&count).Error
+	return count, err
+}
+
+// CountFailed implements repositories.OutboxRepository
+func (r *GormOutboxRepository) CountFailed() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.OutboxEvent{}).Where("status = ?", model.OutboxStatusFailed).Count(&count).Error
+	return count, err
+}
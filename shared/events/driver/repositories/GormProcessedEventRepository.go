@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"time"
+
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	"github.com/r0x16/Raidark/shared/events/domain/model"
+	"github.com/r0x16/Raidark/shared/events/domain/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormProcessedEventRepository implements ProcessedEventRepository using GORM
+type GormProcessedEventRepository struct {
+	db *gorm.DB
+}
+
+// Verify interface implementation
+var _ repositories.ProcessedEventRepository = &GormProcessedEventRepository{}
+
+// NewGormProcessedEventRepository creates a new GORM processed event
+// repository instance. Pass the transaction-bound *gorm.DB to make the
+// dedup record part of that transaction. Reads are pinned to the primary, so
+// an event just marked processed is never seen as new on a lagging replica.
+func NewGormProcessedEventRepository(db *gorm.DB) *GormProcessedEventRepository {
+	return &GormProcessedEventRepository{
+		db: driverdatastore.OnPrimary(db),
+	}
+}
+
+// MarkProcessed implements repositories.ProcessedEventRepository. A
+// concurrent insert of the same key blocks until the other transaction ends
+// and then inserts nothing.
+func (r *GormProcessedEventRepository) MarkProcessed(eventID, consumer string, at time.Time) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.ProcessedEvent{
+		EventID:     eventID,
+		Consumer:    consumer,
+		ProcessedAt: at,
+	})
+	return result.RowsAffected == 1, result.Error
+}
+
+// IsProcessed implements repositories.ProcessedEventRepository
+func (r *GormProcessedEventRepository) IsProcessed(eventID, consumer string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.ProcessedEvent{}).
+		Where("event_id = ? AND consumer = ?", eventID, consumer).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteProcessedBefore implements repositories.ProcessedEventRepository.
+// The keys are selected first so each call locks at most limit rows.
+func (r *GormProcessedEventRepository) DeleteProcessedBefore(before time.Time, limit int) (int, error) {
+	var rows []*model.ProcessedEvent
+	err := r.db.Select("event_id", "consumer").
+		Where("processed_at < ?", before).
+		Order("processed_at").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+
+	keys := make([][]any, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, []any{row.EventID, row.Consumer})
+	}
+	result := r.db.Where("(event_id, consumer) IN ?", keys).Delete(&model.ProcessedEvent{})
+	return int(result.RowsAffected), result.Error
+}
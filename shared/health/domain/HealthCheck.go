@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Check is a named probe of a dependency the application needs to serve
+// traffic, such as a database ping or an issuer request
+type Check struct {
+	// Name identifies the check in the readiness report, such as "database"
+	Name string
+	// Timeout overrides the default per-check timeout when positive
+	Timeout time.Duration
+	// Optional checks are reported but never mark the application unready,
+	// for dependencies it can serve traffic without
+	Optional bool
+	Run      func(ctx context.Context) error
+}
+
+// CheckContributor is implemented by providers that contribute readiness
+// checks. A provider that only implements HealthCheckedProvider contributes a
+// single check named after its hub key.
+type CheckContributor interface {
+	HealthChecks() []Check
+}
+
+// Checker runs the readiness checks and reports their outcome
+type Checker interface {
+	Check(ctx context.Context) Report
+}
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// CheckResult is the outcome of a single check
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Optional   bool   `json:"optional,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report is the outcome of every check. Its status is down when any check
+// that is not optional failed.
+type Report struct {
+	Status    string        `json:"status"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// Healthy reports whether every check that is not optional passed
+func (r Report) Healthy() bool {
+	return r.Status == StatusUp
+}
@@ -0,0 +1,147 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/health/domain"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// HealthCheckerConfig tunes how the checks run
+type HealthCheckerConfig struct {
+	// Timeout bounds each check that does not declare its own
+	Timeout time.Duration
+	// CacheTTL is how long a report is served before the checks run again.
+	// Zero runs the checks on every call.
+	CacheTTL time.Duration
+}
+
+// HealthChecker runs the checks concurrently, each under its own timeout,
+// and caches the report briefly so frequent probes do not hammer the
+// dependencies. Concurrent callers share a single run.
+type HealthChecker struct {
+	checks []domain.Check
+	config HealthCheckerConfig
+	mu     sync.Mutex
+	report *domain.Report
+	now    func() time.Time
+}
+
+var _ domain.Checker = &HealthChecker{}
+
+// NewHealthChecker creates a checker over the given checks
+func NewHealthChecker(config HealthCheckerConfig, checks ...domain.Check) *HealthChecker {
+	return &HealthChecker{
+		checks: checks,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// CollectChecks gathers the checks of every provider in the hub. Providers
+// implementing CheckContributor contribute their own checks; those that only
+// implement HealthCheckedProvider contribute one check named after their hub
+// key. A provider registered under several keys is only collected once.
+func CollectChecks(hub *domprovider.ProviderHub) []domain.Check {
+	var checks []domain.Check
+	domprovider.Each(hub, func(t reflect.Type, provider any) {
+		switch p := provider.(type) {
+		case domain.CheckContributor:
+			checks = append(checks, p.HealthChecks()...)
+		case domprovider.HealthCheckedProvider:
+			checks = append(checks, domain.Check{Name: domprovider.TypeName(t), Run: p.HealthCheck})
+		}
+	})
+	return checks
+}
+
+// Check returns the cached report while it is fresh, and runs every check
+// otherwise
+func (h *HealthChecker) Check(ctx context.Context) domain.Report {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.report != nil && h.now().Sub(h.report.CheckedAt) < h.config.CacheTTL {
+		return *h.report
+	}
+
+	// The report is shared with later callers, so a caller giving up early
+	// must not fail the checks for everyone else
+	report := h.run(context.WithoutCancel(ctx))
+	h.report = &report
+	return report
+}
+
+// run executes the checks concurrently and sorts the results by name
+func (h *HealthChecker) run(ctx context.Context) domain.Report {
+	results := make([]domain.CheckResult, len(h.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.runCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := domain.Report{Status: domain.StatusUp, CheckedAt: h.now(), Checks: results}
+	for _, result := range results {
+		if result.Status != domain.StatusUp && !result.Optional {
+			report.Status = domain.StatusDown
+		}
+	}
+	return report
+}
+
+// runCheck runs a single check under its timeout. A check that ignores its
+// context is abandoned when the timeout expires.
+func (h *HealthChecker) runCheck(ctx context.Context, check domain.Check) domain.CheckResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = h.config.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("check panicked: %v", recovered)
+			}
+		}()
+		done <- check.Run(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s: %w", timeout, ctx.Err())
+	}
+
+	result := domain.CheckResult{
+		Name:       check.Name,
+		Status:     domain.StatusUp,
+		Optional:   check.Optional,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = domain.StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+package driver_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/r0x16/Raidark/shared/health/domain"
+	"github.com/r0x16/Raidark/shared/health/driver"
+	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthChecker_runsChecksConcurrently(t *testing.T) {
+	slow := func(context.Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}
+	checker := driver.NewHealthChecker(driver.HealthCheckerConfig{Timeout: time.Second},
+		domain.Check{Name: "b", Run: slow},
+		domain.Check{Name: "a", Run: slow},
+	)
+
+	started := time.Now()
+	report := checker.Check(context.Background())
+
+	assert.Less(t, time.Since(started), 190*time.Millisecond)
+	assert.True(t, report.Healthy())
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "a", report.Checks[0].Name)
+	assert.Equal(t, "b", report.Checks[1].Name)
+}
+
+func TestHealthChecker_failsChecksPastTheirTimeout(t *testing.T) {
+	blocked := make(chan struct{})
+	defer close(blocked)
+	checker := driver.NewHealthChecker(driver.HealthCheckerConfig{Timeout: time.Second},
+		domain.Check{Name: "ignores context", Timeout: 20 * time.Millisecond, Run: func(context.Context) error {
+			<-blocked
+			return nil
+		}},
+		domain.Check{Name: "healthy", Run: func(context.Context) error { return nil }},
+	)
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, domain.StatusDown, report.Status)
+	assert.Equal(t, domain.StatusDown, report.Checks[1].Status)
+	assert.Contains(t, report.Checks[1].Error, "check timed out after 20ms")
+	assert.Equal(t, domain.StatusUp, report.Checks[0].Status)
+}
+
+func TestHealthChecker_optionalChecksDoNotFailTheReport(t *testing.T) {
+	checker := driver.NewHealthChecker(driver.HealthCheckerConfig{},
+		domain.Check{Name: "replica", Optional: true, Run: func(context.Context) error { return errors.New("unreachable") }},
+		domain.Check{Name: "primary", Run: func(context.Context) error { return nil }},
+	)
+
+	report := checker.Check(context.Background())
+
+	assert.True(t, report.Healthy())
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, domain.CheckResult{Name: "replica", Status: domain.StatusDown, Optional: true, Error: "unreachable"},
+		domain.CheckResult{Name: report.Checks[1].Name, Status: report.Checks[1].Status, Optional: report.Checks[1].Optional, Error: report.Checks[1].Error})
+}
+
+func TestHealthChecker_reportsPanickingChecks(t *testing.T) {
+	checker := driver.NewHealthChecker(driver.HealthCheckerConfig{},
+		domain.Check{Name: "panics", Run: func(context.Context) error { panic("boom") }},
+	)
+
+	report := checker.Check(context.Background())
+
+	assert.False(t, report.Healthy())
+	assert.Equal(t, "check panicked: boom", report.Checks[0].Error)
+}
+
+func TestHealthChecker_cachesTheReport(t *testing.T) {
+	var runs atomic.Int32
+	check := domain.Check{Name: "counted", Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}}
+
+	cached := driver.NewHealthChecker(driver.HealthCheckerConfig{CacheTTL: time.Minute}, check)
+	cached.Check(context.Background())
+	cached.Check(context.Background())
+	assert.Equal(t, int32(1), runs.Load())
+
+	uncached := driver.NewHealthChecker(driver.HealthCheckerConfig{}, check)
+	uncached.Check(context.Background())
+	uncached.Check(context.Background())
+	assert.Equal(t, int32(3), runs.Load())
+}
+
+func TestHealthChecker_ignoresCancelledCallers(t *testing.T) {
+	checker := driver.NewHealthChecker(driver.HealthCheckerConfig{Timeout: time.Second},
+		domain.Check{Name: "context", Run: func(ctx context.Context) error { return ctx.Err() }},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.True(t, checker.Check(ctx).Healthy())
+}
+
+func TestCollectChecks_gathersContributorsAndHealthCheckedProviders(t *testing.T) {
+	hub := &domprovider.ProviderHub{}
+	shared := &contributor{}
+	domprovider.Register[*contributor](hub, shared)
+	domprovider.Register[domain.CheckContributor](hub, shared)
+	domprovider.Register[pinger](hub, pinger{err: errors.New("unreachable")})
+	domprovider.Register[string](hub, "not a provider with checks")
+
+	checks := driver.CollectChecks(hub)
+
+	require.Len(t, checks, 2)
+	assert.Equal(t, "contributor", checks[0].Name)
+	assert.Equal(t, "driver_test.pinger", checks[1].Name)
+	assert.EqualError(t, checks[1].Run(context.Background()), "unreachable")
+}
+
+// contributor contributes a single passing check
+type contributor struct{}
+
+func (*contributor) HealthChecks() []domain.Check {
+	return []domain.Check{{Name: "contributor", Run: func(context.Context) error { return nil }}}
+}
+
+// pinger only implements HealthCheckedProvider
+type pinger struct {
+	err error
+}
+
+func (p pinger) HealthCheck(context.Context) error { return p.err }
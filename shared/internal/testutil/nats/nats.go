@@ -0,0 +1,41 @@
+// Package nats contiene un servidor NATS embebido con JetStream para tests,
+// sin depender de servicios externos.
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// NewJetStreamServer levanta un servidor NATS en un puerto libre con
+// JetStream guardando en un directorio temporal, registra su apagado en el
+// cleanup del test y devuelve la URL de cliente.
+func NewJetStreamServer(t testing.TB) string {
+	t.Helper()
+
+	natsServer, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("create nats server: %v", err)
+	}
+
+	go natsServer.Start()
+	if !natsServer.ReadyForConnections(10 * time.Second) {
+		natsServer.Shutdown()
+		t.Fatalf("nats server not ready for connections")
+	}
+	t.Cleanup(func() {
+		natsServer.Shutdown()
+		natsServer.WaitForShutdown()
+	})
+
+	return natsServer.ClientURL()
+}
@@ -0,0 +1,34 @@
+// Package nats valida el servidor embebido con smoke tests mínimos.
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewJetStreamServer_smoke verifica que el servidor acepta conexiones y
+// que JetStream guarda y confirma mensajes publicados.
+func TestNewJetStreamServer_smoke(t *testing.T) {
+	conn, err := nats.Connect(NewJetStreamServer(t))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: "SMOKE", Subjects: []string{"smoke.>"}})
+	require.NoError(t, err)
+
+	ack, err := js.Publish(ctx, "smoke.hello", []byte("raidark"))
+	require.NoError(t, err)
+	assert.Equal(t, "SMOKE", ack.Stream)
+	assert.Equal(t, uint64(1), ack.Sequence)
+}
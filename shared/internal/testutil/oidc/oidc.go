@@ -0,0 +1,285 @@
+// Package oidc contiene un emisor OIDC local para tests: publica discovery,
+// JWKS y un token endpoint con PKCE sobre httptest y firma tokens con claves
+// RSA rotables.
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// JWKSPath es la ruta donde el emisor publica sus claves, la misma que usa
+// Casdoor.
+const JWKSPath = "/.well-known/jwks"
+
+// DiscoveryPath es la ruta del documento de discovery OIDC.
+const DiscoveryPath = "/.well-known/openid-configuration"
+
+// TokenPath es la ruta del token endpoint.
+const TokenPath = "/token"
+
+// Key es una clave de firma publicada por el emisor.
+type Key struct {
+	ID      string
+	Private *rsa.PrivateKey
+}
+
+// Issuer es un emisor OIDC en memoria respaldado por httptest.
+type Issuer struct {
+	Server *httptest.Server
+	URL    string
+
+	mu            sync.Mutex
+	keys          []*Key
+	jwksRequests  int
+	failJWKS      bool
+	codes         map[string]grant
+	refreshTokens map[string]jwt.MapClaims
+}
+
+// grant es un código de autorización pendiente de canje.
+type grant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+// NewIssuer levanta el emisor con una clave activa y registra el cierre del
+// servidor en el cleanup del test.
+func NewIssuer(t testing.TB) *Issuer {
+	t.Helper()
+
+	issuer := &Issuer{
+		codes:         make(map[string]grant),
+		refreshTokens: make(map[string]jwt.MapClaims),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(JWKSPath, issuer.serveJWKS)
+	mux.HandleFunc(DiscoveryPath, issuer.serveDiscovery)
+	mux.HandleFunc(TokenPath, issuer.serveToken)
+
+	issuer.Server = httptest.NewServer(mux)
+	issuer.URL = issuer.Server.URL
+	t.Cleanup(issuer.Server.Close)
+
+	issuer.Rotate(t)
+	return issuer
+}
+
+// Rotate genera una nueva clave activa. Las anteriores siguen publicadas
+// hasta llamar a Retire.
+func (i *Issuer) Rotate(t testing.TB) *Key {
+	t.Helper()
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	key := &Key{ID: fmt.Sprintf("key-%d", len(i.keys)+1), Private: private}
+	i.keys = append([]*Key{key}, i.keys...)
+	return key
+}
+
+// Retire deja de publicar todas las claves salvo la activa.
+func (i *Issuer) Retire() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.keys = i.keys[:1]
+}
+
+// ActiveKey devuelve la clave con la que Mint firma.
+func (i *Issuer) ActiveKey() *Key {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.keys[0]
+}
+
+// FailJWKS hace que el endpoint JWKS responda 503 mientras fail sea true.
+func (i *Issuer) FailJWKS(fail bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.failJWKS = fail
+}
+
+// JWKSRequests cuenta las descargas del JWKS recibidas.
+func (i *Issuer) JWKSRequests() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.jwksRequests
+}
+
+// Claims devuelve claims válidos por una hora emitidos por este emisor para
+// audience, listos para ajustar en el test.
+func (i *Issuer) Claims(subject, audience string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss": i.URL,
+		"sub": subject,
+		"aud": audience,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+// Mint firma claims con RS256 usando la clave activa.
+func (i *Issuer) Mint(t testing.TB, claims jwt.Claims) string {
+	t.Helper()
+	return i.MintWith(t, i.ActiveKey(), claims)
+}
+
+// MintWith firma claims con RS256 usando key, que no necesita estar
+// publicada.
+func (i *Issuer) MintWith(t testing.TB, key *Key, claims jwt.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	signed, err := token.SignedString(key.Private)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+// IssueCode registra un código de autorización ligado al code_challenge S256
+// de la petición de login. Al canjearlo, el token endpoint entrega un access
+// token con claims y un refresh token reutilizable.
+func (i *Issuer) IssueCode(challenge string, claims jwt.MapClaims) string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	code := fmt.Sprintf("code-%d", len(i.codes)+1)
+	i.codes[code] = grant{challenge: challenge, claims: claims}
+	return code
+}
+
+// CertificatePEM devuelve un certificado autofirmado PEM de la clave activa,
+// con el mismo formato que el certificado que entrega Casdoor.
+func (i *Issuer) CertificatePEM(t testing.TB) string {
+	t.Helper()
+
+	key := i.ActiveKey()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: key.ID},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.Private.PublicKey, key.Private)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func (i *Issuer) serveJWKS(w http.ResponseWriter, _ *http.Request) {
+	i.mu.Lock()
+	i.jwksRequests++
+	fail := i.failJWKS
+	keys := make([]map[string]string, 0, len(i.keys))
+	for _, key := range i.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": key.ID,
+			"n":   base64.RawURLEncoding.EncodeToString(key.Private.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.Private.E)).Bytes()),
+		})
+	}
+	i.mu.Unlock()
+
+	if fail {
+		http.Error(w, "jwks unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, map[string]any{"keys": keys})
+}
+
+func (i *Issuer) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	var claims jwt.MapClaims
+	i.mu.Lock()
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		pending, ok := i.codes[r.PostForm.Get("code")]
+		delete(i.codes, r.PostForm.Get("code"))
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if ok && base64.RawURLEncoding.EncodeToString(sum[:]) == pending.challenge {
+			claims = pending.claims
+		}
+	case "refresh_token":
+		claims = i.refreshTokens[r.PostForm.Get("refresh_token")]
+	}
+	refreshToken := fmt.Sprintf("refresh-%d", len(i.refreshTokens)+1)
+	if claims != nil {
+		i.refreshTokens[refreshToken] = claims
+	}
+	key := i.keys[0]
+	i.mu.Unlock()
+
+	if claims == nil {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = key.ID
+	signed, err := token.SignedString(key.Private)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"access_token":  signed,
+		"id_token":      signed,
+		"token_type":    "Bearer",
+		"refresh_token": refreshToken,
+		"expires_in":    3600,
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+func (i *Issuer) serveDiscovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]any{
+		"issuer":                                i.URL,
+		"authorization_endpoint":                i.URL + "/authorize",
+		"token_endpoint":                        i.URL + TokenPath,
+		"userinfo_endpoint":                     i.URL + "/userinfo",
+		"jwks_uri":                              i.URL + JWKSPath,
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,34 @@
+// Package oidc valida el emisor local con smoke tests mínimos.
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIssuer_smoke verifica que los tokens firmados validen con la clave
+// activa y que discovery apunte al JWKS publicado.
+func TestIssuer_smoke(t *testing.T) {
+	issuer := NewIssuer(t)
+	token := issuer.Mint(t, issuer.Claims("alice", "raidark"))
+
+	parsed, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) {
+		return &issuer.ActiveKey().Private.PublicKey, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, issuer.ActiveKey().ID, parsed.Header["kid"])
+
+	response, err := http.Get(issuer.URL + DiscoveryPath)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	var discovery map[string]any
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&discovery))
+	assert.Equal(t, issuer.URL, discovery["issuer"])
+	assert.Equal(t, issuer.URL+JWKSPath, discovery["jwks_uri"])
+}
@@ -0,0 +1,493 @@
+// Package s3 contiene un servidor S3 en memoria para tests: atiende sobre
+// httptest el subconjunto de la API que usa el driver s3 de storage (objetos,
+// copias, ACLs, listados, multipart y buckets) sin validar firmas.
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Object es un objeto almacenado por el servidor.
+type Object struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+	ACL         string
+	Metadata    map[string]string
+	ModifiedAt  time.Time
+}
+
+// Server es un servidor S3 en memoria respaldado por httptest.
+type Server struct {
+	Server *httptest.Server
+	// Endpoint es el host:puerto del servidor, sin esquema, como lo espera
+	// minio-go.
+	Endpoint string
+
+	mu         sync.Mutex
+	buckets    map[string]map[string]*Object
+	uploads    map[string]*upload
+	nextUpload int
+	completed  int
+}
+
+// upload es una subida multipart en curso.
+type upload struct {
+	bucket string
+	key    string
+	object Object
+	parts  map[int][]byte
+}
+
+// NewServer levanta el servidor con los buckets indicados y registra su
+// cierre en el cleanup del test.
+func NewServer(t testing.TB, buckets ...string) *Server {
+	t.Helper()
+
+	server := &Server{
+		buckets: make(map[string]map[string]*Object),
+		uploads: make(map[string]*upload),
+	}
+	for _, bucket := range buckets {
+		server.buckets[bucket] = make(map[string]*Object)
+	}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serve))
+	server.Endpoint = strings.TrimPrefix(server.Server.URL, "http://")
+	t.Cleanup(server.Server.Close)
+	return server
+}
+
+// Object devuelve una copia del objeto almacenado bajo bucket/key.
+func (s *Server) Object(bucket, key string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, ok := s.buckets[bucket][key]
+	if !ok {
+		return Object{}, false
+	}
+	return *object, true
+}
+
+// Keys devuelve las claves almacenadas en el bucket, ordenadas.
+func (s *Server) Keys(bucket string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.buckets[bucket]))
+	for key := range s.buckets[bucket] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CompletedMultipartUploads cuenta las subidas multipart completadas.
+func (s *Server) CompletedMultipartUploads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.completed
+}
+
+// serve enruta la petición según el estilo de ruta /bucket/key.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	objects, ok := s.buckets[bucket]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case key == "" && r.Method == http.MethodGet && query.Get("list-type") == "2":
+		s.listObjects(w, objects, query)
+	case key == "":
+		writeError(w, r, http.StatusNotImplemented, "NotImplemented")
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.initiateUpload(w, r, bucket, key)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		s.uploadPart(w, r, query.Get("uploadId"), query.Get("partNumber"))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		s.completeUpload(w, r, objects, query.Get("uploadId"))
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		s.abortUpload(w, query.Get("uploadId"))
+	case r.Method == http.MethodGet && query.Has("acl"):
+		s.getACL(w, r, objects, key)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		s.copyObject(w, r, objects, key)
+	case r.Method == http.MethodPut:
+		s.putObject(w, r, objects, key)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		s.getObject(w, r, objects, key)
+	case r.Method == http.MethodDelete:
+		s.mu.Lock()
+		delete(objects, key)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, r, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, objects map[string]*Object, key string) {
+	data, err := readBody(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+
+	object := newObject(r)
+	object.Data = data
+	object.ETag = md5Hex(data)
+
+	s.mu.Lock()
+	objects[key] = &object
+	s.mu.Unlock()
+
+	w.Header().Set("ETag", `"`+object.ETag+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, objects map[string]*Object, key string) {
+	s.mu.Lock()
+	object, ok := objects[key]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", object.ContentType)
+	header.Set("Content-Length", strconv.Itoa(len(object.Data)))
+	header.Set("ETag", `"`+object.ETag+`"`)
+	header.Set("Last-Modified", object.ModifiedAt.UTC().Format(http.TimeFormat))
+	for name, value := range object.Metadata {
+		header.Set("X-Amz-Meta-"+name, value)
+	}
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(object.Data)
+	}
+}
+
+// copyObject copia el objeto indicado en X-Amz-Copy-Source. Como en S3, los
+// metadatos se conservan salvo con la directiva REPLACE y la ACL siempre sale
+// de la petición.
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, objects map[string]*Object, key string) {
+	source, err := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "InvalidArgument")
+		return
+	}
+	bucket, sourceKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	original, ok := s.buckets[bucket][sourceKey]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+
+	object := newObject(r)
+	if r.Header.Get("X-Amz-Metadata-Directive") != "REPLACE" {
+		object.ContentType = original.ContentType
+		object.Metadata = original.Metadata
+	}
+	object.Data = original.Data
+	object.ETag = original.ETag
+	objects[key] = &object
+
+	writeXML(w, struct {
+		XMLName      xml.Name `xml:"CopyObjectResult"`
+		ETag         string
+		LastModified string
+	}{ETag: `"` + object.ETag + `"`, LastModified: object.ModifiedAt.UTC().Format(time.RFC3339)})
+}
+
+// getACL devuelve la política del objeto con las concesiones que S3 asocia a
+// las ACL predefinidas private y public-read.
+func (s *Server) getACL(w http.ResponseWriter, r *http.Request, objects map[string]*Object, key string) {
+	s.mu.Lock()
+	object, ok := objects[key]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+
+	type grantee struct {
+		ID  string `xml:"ID,omitempty"`
+		URI string `xml:"URI,omitempty"`
+	}
+	type grant struct {
+		Grantee    grantee
+		Permission string
+	}
+	grants := []grant{{Grantee: grantee{ID: "owner"}, Permission: "FULL_CONTROL"}}
+	if object.ACL == "public-read" {
+		grants = append(grants, grant{Grantee: grantee{URI: "http://acs.amazonaws.com/groups/global/AllUsers"}, Permission: "READ"})
+	}
+
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"AccessControlPolicy"`
+		Owner   struct {
+			ID string
+		}
+		Grants []grant `xml:"AccessControlList>Grant"`
+	}{Owner: struct{ ID string }{ID: "owner"}, Grants: grants})
+}
+
+// listObjects implementa ListObjectsV2 sin delimitador: claves en orden con
+// prefix, start-after, continuation-token y max-keys.
+func (s *Server) listObjects(w http.ResponseWriter, objects map[string]*Object, query url.Values) {
+	prefix := query.Get("prefix")
+	after := max(query.Get("start-after"), query.Get("continuation-token"))
+	maxKeys, err := strconv.Atoi(query.Get("max-keys"))
+	if err != nil || maxKeys <= 0 {
+		maxKeys = 1000
+	}
+
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+		StorageClass string
+	}
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Prefix                string
+		KeyCount              int
+		MaxKeys               int
+		IsTruncated           bool
+		NextContinuationToken string `xml:",omitempty"`
+		Contents              []content
+	}{Prefix: prefix, MaxKeys: maxKeys}
+
+	s.mu.Lock()
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		if strings.HasPrefix(key, prefix) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > maxKeys {
+		keys = keys[:maxKeys]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[maxKeys-1]
+	}
+	for _, key := range keys {
+		object := objects[key]
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			LastModified: object.ModifiedAt.UTC().Format(time.RFC3339),
+			ETag:         `"` + object.ETag + `"`,
+			Size:         len(object.Data),
+			StorageClass: "STANDARD",
+		})
+	}
+	s.mu.Unlock()
+
+	result.KeyCount = len(result.Contents)
+	writeXML(w, result)
+}
+
+func (s *Server) initiateUpload(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	s.mu.Lock()
+	s.nextUpload++
+	id := strconv.Itoa(s.nextUpload)
+	s.uploads[id] = &upload{bucket: bucket, key: key, object: newObject(r), parts: make(map[int][]byte)}
+	s.mu.Unlock()
+
+	writeXML(w, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Bucket   string
+		Key      string
+		UploadID string `xml:"UploadId"`
+	}{Bucket: bucket, Key: key, UploadID: id})
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, id, number string) {
+	part, err := strconv.Atoi(number)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "InvalidArgument")
+		return
+	}
+	data, err := readBody(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+
+	s.mu.Lock()
+	pending, ok := s.uploads[id]
+	if ok {
+		pending.parts[part] = data
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+
+	w.Header().Set("ETag", `"`+md5Hex(data)+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) completeUpload(w http.ResponseWriter, r *http.Request, objects map[string]*Object, id string) {
+	var request struct {
+		Parts []struct {
+			PartNumber int
+		} `xml:"Part"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, r, http.StatusBadRequest, "MalformedXML")
+		return
+	}
+
+	s.mu.Lock()
+	pending, ok := s.uploads[id]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, r, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+
+	// El ETag multipart es el MD5 de los MD5 de las partes seguido de su
+	// número, como en S3.
+	var data bytes.Buffer
+	digests := md5.New()
+	for _, part := range request.Parts {
+		chunk := pending.parts[part.PartNumber]
+		data.Write(chunk)
+		sum := md5.Sum(chunk)
+		digests.Write(sum[:])
+	}
+	object := pending.object
+	object.Data = data.Bytes()
+	object.ETag = fmt.Sprintf("%s-%d", hex.EncodeToString(digests.Sum(nil)), len(request.Parts))
+	objects[pending.key] = &object
+	delete(s.uploads, id)
+	s.completed++
+	s.mu.Unlock()
+
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Bucket  string
+		Key     string
+		ETag    string
+	}{Bucket: pending.bucket, Key: pending.key, ETag: `"` + object.ETag + `"`})
+}
+
+func (s *Server) abortUpload(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	delete(s.uploads, id)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newObject toma el tipo de contenido, la ACL y los metadatos de la
+// petición.
+func newObject(r *http.Request) Object {
+	object := Object{
+		ContentType: r.Header.Get("Content-Type"),
+		ACL:         r.Header.Get("X-Amz-Acl"),
+		Metadata:    make(map[string]string),
+		ModifiedAt:  time.Now(),
+	}
+	if object.ContentType == "" {
+		object.ContentType = "binary/octet-stream"
+	}
+	for name, values := range r.Header {
+		if meta, ok := strings.CutPrefix(name, "X-Amz-Meta-"); ok {
+			object.Metadata[strings.ToLower(meta)] = values[0]
+		}
+	}
+	return object
+}
+
+// readBody lee el cuerpo de la petición, decodificando el formato
+// aws-chunked de las subidas con firma en streaming o checksums al final.
+func readBody(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, err
+		}
+		if _, err := reader.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func writeXML(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(body)
+}
+
+// writeError responde con el documento de error de S3; las respuestas a HEAD
+// no llevan cuerpo.
+func writeError(w http.ResponseWriter, r *http.Request, status int, code string) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName  xml.Name `xml:"Error"`
+		Code     string
+		Message  string
+		Resource string
+	}{Code: code, Message: code, Resource: r.URL.Path})
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
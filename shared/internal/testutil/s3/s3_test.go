@@ -0,0 +1,67 @@
+// Package s3 valida el servidor en memoria con smoke tests mínimos.
+package s3
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewServer_smoke verifica que el servidor guarda, sirve y borra objetos
+// y que responde 404 a buckets desconocidos.
+func TestNewServer_smoke(t *testing.T) {
+	server := NewServer(t, "media")
+	url := server.Server.URL + "/media/docs/hello.txt"
+
+	request, err := http.NewRequest(http.MethodPut, url, strings.NewReader("raidark"))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "text/plain")
+	request.Header.Set("X-Amz-Acl", "public-read")
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	object, ok := server.Object("media", "docs/hello.txt")
+	require.True(t, ok)
+	assert.Equal(t, "public-read", object.ACL)
+	assert.Equal(t, `"`+object.ETag+`"`, response.Header.Get("ETag"))
+
+	response, err = http.Get(url)
+	require.NoError(t, err)
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "raidark", string(body))
+	assert.Equal(t, "text/plain", response.Header.Get("Content-Type"))
+
+	request, err = http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	response, err = http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	assert.Empty(t, server.Keys("media"))
+
+	response, err = http.Get(server.Server.URL + "/missing/key")
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+// TestReadBody_decodesAwsChunked verifica la decodificación del cuerpo
+// aws-chunked, incluidas las firmas por chunk y los checksums al final.
+func TestReadBody_decodesAwsChunked(t *testing.T) {
+	body := "3;chunk-signature=abc\r\nrai\r\n4;chunk-signature=def\r\ndark\r\n0;chunk-signature=ghi\r\nx-amz-checksum-crc32c:AAAA\r\n\r\n"
+	request, err := http.NewRequest(http.MethodPut, "/media/key", strings.NewReader(body))
+	require.NoError(t, err)
+	request.Header.Set("X-Amz-Content-Sha256", "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER")
+
+	data, err := readBody(request)
+	require.NoError(t, err)
+	assert.Equal(t, "raidark", string(data))
+}
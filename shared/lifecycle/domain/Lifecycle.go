@@ -0,0 +1,28 @@
+package domain
+
+import "context"
+
+// Hook is a pair of callbacks run when the application starts serving and
+// when it shuts down. Either callback may be nil.
+type Hook struct {
+	// Name identifies the hook in the logs
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Lifecycle runs the hooks appended by providers and modules. Start runs the
+// OnStart callbacks in the order the hooks were appended; Stop runs the
+// OnStop callbacks in reverse order, so a component stops before the ones it
+// was built on.
+type Lifecycle interface {
+	Append(hook Hook)
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// HookedModule is implemented by modules that own resources to start with
+// the application or release when it shuts down
+type HookedModule interface {
+	GetLifecycleHooks() []Hook
+}
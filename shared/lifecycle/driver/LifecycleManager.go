@@ -0,0 +1,96 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/r0x16/Raidark/shared/lifecycle/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+)
+
+// LifecycleManager runs the start and stop hooks of the application. Stop
+// only runs the hooks once, so it can be called both by the command that
+// handles the shutdown signal and by a deferred call on the way out.
+type LifecycleManager struct {
+	mu      sync.Mutex
+	hooks   []domain.Hook
+	stopped bool
+	log     domlogger.LogProvider
+}
+
+var _ domain.Lifecycle = &LifecycleManager{}
+
+func NewLifecycleManager(log domlogger.LogProvider) *LifecycleManager {
+	return &LifecycleManager{log: log}
+}
+
+// Append implements domain.Lifecycle
+func (m *LifecycleManager) Append(hook domain.Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// Start runs the OnStart callbacks in order. When one fails the whole
+// lifecycle is stopped, releasing what the providers already hold, and the
+// error is returned.
+func (m *LifecycleManager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := append([]domain.Hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	for _, hook := range hooks {
+		if hook.OnStart == nil {
+			continue
+		}
+		if err := hook.OnStart(ctx); err != nil {
+			err = fmt.Errorf("lifecycle: %s failed to start: %w", hook.Name, err)
+			if stopErr := m.Stop(ctx); stopErr != nil {
+				err = errors.Join(err, stopErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop runs the OnStop callbacks in reverse order. Every hook runs even when
+// a previous one fails or ctx expires, so resources are released as far as
+// possible; the failures are returned joined.
+func (m *LifecycleManager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stopped = true
+	hooks := append([]domain.Hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+		m.log.Info("Stopping", map[string]any{"hook": hook.Name})
+		if err := m.stopHook(ctx, hook); err != nil {
+			m.log.Error("Failed to stop", map[string]any{"hook": hook.Name, "error": err})
+			errs = append(errs, fmt.Errorf("lifecycle: %s failed to stop: %w", hook.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopHook runs one OnStop callback, turning a panic into an error so the
+// remaining hooks still run
+func (m *LifecycleManager) stopHook(ctx context.Context, hook domain.Hook) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panicked: %v", recovered)
+		}
+	}()
+	return hook.OnStop(ctx)
+}
@@ -0,0 +1,78 @@
+package driver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/r0x16/Raidark/shared/lifecycle/domain"
+	driverlifecycle "github.com/r0x16/Raidark/shared/lifecycle/driver"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycleManager_startsInOrderAndStopsInReverse(t *testing.T) {
+	manager := newLifecycleManager()
+	var calls []string
+	for _, name := range []string{"datastore", "events", "module"} {
+		manager.Append(domain.Hook{
+			Name:    name,
+			OnStart: func(context.Context) error { calls = append(calls, "start "+name); return nil },
+			OnStop:  func(context.Context) error { calls = append(calls, "stop "+name); return nil },
+		})
+	}
+
+	require.NoError(t, manager.Start(context.Background()))
+	require.NoError(t, manager.Stop(context.Background()))
+	require.NoError(t, manager.Stop(context.Background()))
+
+	assert.Equal(t, []string{
+		"start datastore", "start events", "start module",
+		"stop module", "stop events", "stop datastore",
+	}, calls)
+}
+
+func TestLifecycleManager_stopRunsEveryHookAndJoinsFailures(t *testing.T) {
+	manager := newLifecycleManager()
+	var stopped []string
+	manager.Append(domain.Hook{Name: "datastore", OnStop: func(context.Context) error {
+		stopped = append(stopped, "datastore")
+		return nil
+	}})
+	manager.Append(domain.Hook{Name: "events", OnStop: func(context.Context) error {
+		return errors.New("queue not drained")
+	}})
+	manager.Append(domain.Hook{Name: "module", OnStop: func(context.Context) error {
+		panic("boom")
+	}})
+
+	err := manager.Stop(context.Background())
+
+	assert.ErrorContains(t, err, "events failed to stop: queue not drained")
+	assert.ErrorContains(t, err, "module failed to stop: panicked: boom")
+	assert.Equal(t, []string{"datastore"}, stopped)
+}
+
+func TestLifecycleManager_stopsWhenAHookFailsToStart(t *testing.T) {
+	manager := newLifecycleManager()
+	stopped := false
+	manager.Append(domain.Hook{Name: "datastore", OnStop: func(context.Context) error {
+		stopped = true
+		return nil
+	}})
+	manager.Append(domain.Hook{Name: "broker", OnStart: func(context.Context) error {
+		return errors.New("unreachable")
+	}})
+
+	err := manager.Start(context.Background())
+
+	assert.ErrorContains(t, err, "broker failed to start: unreachable")
+	assert.True(t, stopped)
+}
+
+func newLifecycleManager() *driverlifecycle.LifecycleManager {
+	return driverlifecycle.NewLifecycleManager(obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical))
+}
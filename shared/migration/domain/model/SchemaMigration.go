@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// SchemaMigration records a versioned migration that has been applied
+type SchemaMigration struct {
+	Version   string    `gorm:"primaryKey;type:varchar(255)" json:"version"`
+	Module    string    `gorm:"type:varchar(255);not null" json:"module"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Checksum  string    `gorm:"type:varchar(64);not null" json:"checksum"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
+
+// StoreName returns the datastore name for GORM
+func (SchemaMigration) StoreName() string {
+	return "schema_migrations"
+}
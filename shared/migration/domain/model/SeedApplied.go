@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// SeedApplied records the last load of a seed set
+type SeedApplied struct {
+	Module      string    `gorm:"primaryKey;type:varchar(255)" json:"module"`
+	Name        string    `gorm:"primaryKey;type:varchar(255)" json:"name"`
+	Checksum    string    `gorm:"type:varchar(64);not null" json:"checksum"`
+	Environment string    `gorm:"type:varchar(64);not null" json:"environment"`
+	Rows        int       `gorm:"not null" json:"rows"`
+	AppliedAt   time.Time `gorm:"not null" json:"applied_at"`
+}
+
+// TableName pins the ledger table name; GORM would otherwise pluralize it
+func (SeedApplied) TableName() string {
+	return "seeds_applied"
+}
+
+// StoreName returns the datastore name for GORM
+func (SeedApplied) StoreName() string {
+	return "seeds_applied"
+}
@@ -0,0 +1,70 @@
+// Package schema defines versioned schema migrations contributed by modules.
+// Migrations complement GORM's AutoMigrate for everything it cannot express:
+// indexes, data backfills, column renames and reversible changes.
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrIrreversible is returned when rolling back a migration without a down step
+var ErrIrreversible = errors.New("schema: migration has no down step")
+
+// Migration is a single versioned schema change.
+//
+// Versions are compared lexicographically across every module, so use a
+// fixed-width, sortable format such as a UTC timestamp ("20260401120000").
+// A migration is written either as SQL (UpSQL/DownSQL) or as Go functions
+// (Up/Down); when both are set the Go functions win.
+type Migration struct {
+	Version string
+	Name    string
+
+	UpSQL   string
+	DownSQL string
+
+	Up   func(tx *gorm.DB) error
+	Down func(tx *gorm.DB) error
+}
+
+// Checksum identifies the content of the migration. For SQL migrations it is
+// the SHA-256 of the up and down statements, so editing an applied file is
+// detected. Go migrations cannot be hashed by content; their checksum only
+// covers the version and name.
+func (m Migration) Checksum() string {
+	hash := sha256.New()
+	if m.Up != nil {
+		hash.Write([]byte("go\n" + m.Version + "\n" + m.Name))
+	} else {
+		hash.Write([]byte("sql\n" + m.UpSQL + "\n-- down --\n" + m.DownSQL))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// IsReversible reports whether the migration can be rolled back
+func (m Migration) IsReversible() bool {
+	return m.Down != nil || m.DownSQL != ""
+}
+
+// ApplyUp runs the up step on tx
+func (m Migration) ApplyUp(tx *gorm.DB) error {
+	if m.Up != nil {
+		return m.Up(tx)
+	}
+	return tx.Exec(m.UpSQL).Error
+}
+
+// ApplyDown runs the down step on tx
+func (m Migration) ApplyDown(tx *gorm.DB) error {
+	if m.Down != nil {
+		return m.Down(tx)
+	}
+	if m.DownSQL == "" {
+		return ErrIrreversible
+	}
+	return tx.Exec(m.DownSQL).Error
+}
@@ -0,0 +1,108 @@
+package schema
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// FromFS loads SQL migrations from dir inside fsys, typically an embed.FS.
+//
+// Files must be named {version}_{name}.up.sql and, optionally,
+// {version}_{name}.down.sql, where version is numeric. Other files are
+// ignored. The returned slice is sorted by version.
+//
+//	//go:embed migrations/*.sql
+//	var migrationFiles embed.FS
+//
+//	func (m *OrdersModule) GetMigrations() []schema.Migration {
+//		return schema.MustFromFS(migrationFiles, "migrations")
+//	}
+func FromFS(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("schema: read migrations dir %q: %w", dir, err)
+	}
+
+	byVersion := map[string]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		fileName := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		stem := strings.TrimSuffix(fileName, "."+direction+".sql")
+		version, name, ok := strings.Cut(stem, "_")
+		if !ok || !isVersion(version) || name == "" {
+			return nil, fmt.Errorf("schema: migration file %q does not match {version}_{name}.%s.sql", fileName, direction)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("schema: read migration file %q: %w", fileName, err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("schema: migration version %s has conflicting names %q and %q", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.UpSQL = string(content)
+		} else {
+			migration.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpSQL == "" {
+			return nil, fmt.Errorf("schema: migration %s_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// isVersion reports whether version is a non-empty run of digits
+func isVersion(version string) bool {
+	if version == "" {
+		return false
+	}
+	for _, r := range version {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// MustFromFS is like FromFS but panics on error. Embedded migrations are part
+// of the binary, so a malformed file is a build defect rather than a runtime
+// condition.
+func MustFromFS(fsys fs.FS, dir string) []Migration {
+	migrations, err := FromFS(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return migrations
+}
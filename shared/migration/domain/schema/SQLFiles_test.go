@@ -0,0 +1,51 @@
+package schema_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/r0x16/Raidark/shared/migration/domain/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromFS_pairsUpAndDownFilesSortedByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/20260201000000_add_index.up.sql":       {Data: []byte("CREATE INDEX idx ON orders (id)")},
+		"migrations/20260101000000_create_orders.up.sql":   {Data: []byte("CREATE TABLE orders (id TEXT)")},
+		"migrations/20260101000000_create_orders.down.sql": {Data: []byte("DROP TABLE orders")},
+		"migrations/README.md":                             {Data: []byte("ignored")},
+	}
+
+	migrations, err := schema.FromFS(fsys, "migrations")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	assert.Equal(t, "20260101000000", migrations[0].Version)
+	assert.Equal(t, "create_orders", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE orders", migrations[0].DownSQL)
+	assert.True(t, migrations[0].IsReversible())
+	assert.Equal(t, "add_index", migrations[1].Name)
+	assert.False(t, migrations[1].IsReversible())
+}
+
+func TestFromFS_rejectsMalformedFiles(t *testing.T) {
+	_, err := schema.FromFS(fstest.MapFS{
+		"migrations/create_orders.up.sql": {Data: []byte("CREATE TABLE orders (id TEXT)")},
+	}, "migrations")
+	assert.ErrorContains(t, err, "does not match")
+
+	_, err = schema.FromFS(fstest.MapFS{
+		"migrations/20260101000000_create_orders.down.sql": {Data: []byte("DROP TABLE orders")},
+	}, "migrations")
+	assert.ErrorContains(t, err, "has no up file")
+}
+
+func TestMigration_checksumTracksSQLContent(t *testing.T) {
+	original := schema.Migration{Version: "1", Name: "a", UpSQL: "CREATE TABLE a (id TEXT)"}
+	edited := original
+	edited.UpSQL = "CREATE TABLE a (id INTEGER)"
+
+	assert.NotEqual(t, original.Checksum(), edited.Checksum())
+	assert.Equal(t, original.Checksum(), original.Checksum())
+}
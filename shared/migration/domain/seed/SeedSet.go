@@ -0,0 +1,55 @@
+package seed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Environments a seed set can be tagged with
+const (
+	EnvDev  = "dev"
+	EnvTest = "test"
+	EnvProd = "prod"
+)
+
+// SeedSet is a named group of rows a module loads into the database.
+//
+// Rows are upserted: a row that already exists, as identified by the
+// conflict columns, is updated instead of inserted again, so a set can run
+// any number of times. By default the conflict columns are the first unique
+// field or unique index of the model, falling back to its primary key.
+type SeedSet struct {
+	// Name identifies the set inside its module and in the seeds_applied ledger
+	Name string
+	// Environments restricts the set to the listed environments. An empty list
+	// loads the set in every environment, which suits reference data.
+	Environments []string
+	// Data holds the rows: pointers to models or slices of models
+	Data []any
+	// ConflictColumns overrides the columns that identify an existing row
+	ConflictColumns []string
+}
+
+// IsEnvironment reports whether env is one of EnvDev, EnvTest and EnvProd
+func IsEnvironment(env string) bool {
+	return slices.Contains([]string{EnvDev, EnvTest, EnvProd}, env)
+}
+
+// AppliesTo reports whether the set is loaded in env
+func (s SeedSet) AppliesTo(env string) bool {
+	return len(s.Environments) == 0 || slices.Contains(s.Environments, env)
+}
+
+// Checksum identifies the content of the set. The ledger stores it, so a set
+// is loaded again only when its data changes.
+func (s SeedSet) Checksum() (string, error) {
+	payload, err := json.Marshal(s.Data)
+	if err != nil {
+		return "", fmt.Errorf("seed: hash set %q: %w", s.Name, err)
+	}
+	hash := sha256.Sum256(payload)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -1,9 +1,14 @@
 package controller
 
 import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
 	apidomain "github.com/r0x16/Raidark/shared/api/domain"
 	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
 	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/migration/driver/migrator"
 )
 
 type DbMigrationController struct {
@@ -12,6 +17,8 @@ type DbMigrationController struct {
 	Modules          []apidomain.ApiModule
 }
 
+// MigrateAction runs AutoMigrate on the module models and then applies the
+// pending versioned migrations
 func (d *DbMigrationController) MigrateAction() error {
 	modelsError := d.migrateModels()
 	if modelsError != nil {
@@ -20,9 +27,108 @@ func (d *DbMigrationController) MigrateAction() error {
 	}
 	d.LogProvider.Info("Models migrated successfully", map[string]any{"models": d.Modules})
 
+	return d.UpAction()
+}
+
+// UpAction applies the pending versioned migrations
+func (d *DbMigrationController) UpAction() error {
+	engine, err := d.migrator()
+	if err != nil {
+		return err
+	}
+
+	applied, err := engine.Up()
+	for _, migration := range applied {
+		d.LogProvider.Info("Migration applied", d.migrationFields(migration))
+	}
+	if err != nil {
+		d.LogProvider.Error("Error applying migrations", map[string]any{"error": err})
+		return err
+	}
+	d.LogProvider.Info("Migrations up to date", map[string]any{"applied": len(applied)})
+	return nil
+}
+
+// DownAction rolls back the last steps applied migrations
+func (d *DbMigrationController) DownAction(steps int) error {
+	engine, err := d.migrator()
+	if err != nil {
+		return err
+	}
+
+	rolledBack, err := engine.Down(steps)
+	for _, migration := range rolledBack {
+		d.LogProvider.Info("Migration rolled back", d.migrationFields(migration))
+	}
+	if err != nil {
+		d.LogProvider.Error("Error rolling back migrations", map[string]any{"error": err})
+		return err
+	}
+	return nil
+}
+
+// RedoAction rolls back the last applied migration and applies it again
+func (d *DbMigrationController) RedoAction() error {
+	engine, err := d.migrator()
+	if err != nil {
+		return err
+	}
+
+	migration, err := engine.Redo()
+	if err != nil {
+		d.LogProvider.Error("Error redoing migration", map[string]any{"error": err})
+		return err
+	}
+	if migration == nil {
+		d.LogProvider.Info("No applied migration to redo", nil)
+		return nil
+	}
+	d.LogProvider.Info("Migration redone", d.migrationFields(*migration))
 	return nil
 }
 
+// StatusAction writes one line per migration to out
+func (d *DbMigrationController) StatusAction(out io.Writer) error {
+	engine, err := d.migrator()
+	if err != nil {
+		return err
+	}
+
+	statuses, err := engine.Status()
+	if err != nil {
+		d.LogProvider.Error("Error reading migration status", map[string]any{"error": err})
+		return err
+	}
+
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tMODULE\tSTATE\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", status.Version, status.Name, status.Module, status.State, appliedAt)
+	}
+	return writer.Flush()
+}
+
+func (d *DbMigrationController) migrator() (*migrator.GormMigrator, error) {
+	engine, err := migrator.NewGormMigrator(d.DatabaseProvider.GetDataStore().Exec, d.Modules)
+	if err != nil {
+		d.LogProvider.Error("Error loading migrations", map[string]any{"error": err})
+		return nil, err
+	}
+	return engine, nil
+}
+
+func (d *DbMigrationController) migrationFields(migration migrator.ModuleMigration) map[string]any {
+	return map[string]any{
+		"version": migration.Version,
+		"name":    migration.Name,
+		"module":  migration.Module,
+	}
+}
+
 func (d *DbMigrationController) migrateModels() error {
 	models := d.extractModels(d.Modules)
 	d.LogProvider.Info("Migrating models", map[string]any{"models": models})
@@ -1,9 +1,14 @@
 package controller
 
 import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
 	apidomain "github.com/r0x16/Raidark/shared/api/domain"
 	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
 	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/migration/driver/seeder"
 )
 
 type SeederController struct {
@@ -12,62 +17,42 @@ type SeederController struct {
 	Modules          []apidomain.ApiModule
 }
 
-func (c *SeederController) SeedAction() error {
-	seedDataError := c.seedData()
+// SeedAction loads the seed sets selected by options and writes one line per
+// set to out
+func (c *SeederController) SeedAction(options seeder.Options, out io.Writer) error {
+	results, seedDataError := c.seedData(options)
 	if seedDataError != nil {
 		c.LogProvider.Error("Error seeding data", map[string]any{"error": seedDataError})
 		return seedDataError
 	}
-	c.LogProvider.Info("Data seeded successfully", map[string]any{"modules": c.Modules})
-
-	return nil
-}
 
-func (c *SeederController) seedData() error {
-	db := c.DatabaseProvider.GetDataStore().Exec
-	tx := db.Begin()
-
-	// Extract seed data from all modules
-	seedDataSets := c.extractSeedData(c.Modules)
-	c.LogProvider.Info("Seeding data", map[string]any{"dataSets": len(seedDataSets)})
-
-	// Insert each seed data set
-	for _, seedDataSet := range seedDataSets {
-		if err := tx.Create(seedDataSet).Error; err != nil {
-			tx.Rollback()
-			return err
-		}
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "MODULE\tSET\tROWS\tACTION")
+	for _, result := range results {
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%s\n", result.Module, result.Name, result.Rows, result.Action)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
 	}
 
-	tx.Commit()
+	if options.DryRun {
+		c.LogProvider.Info("Seed dry run finished, nothing was written", map[string]any{"environment": options.Environment, "sets": len(results)})
+		return nil
+	}
+	c.LogProvider.Info("Data seeded successfully", map[string]any{"environment": options.Environment, "sets": len(results)})
 	return nil
 }
 
-func (c *SeederController) extractSeedData(modules []apidomain.ApiModule) []any {
-	seedData := []any{}
-	for _, module := range modules {
-		seedData = append(seedData, module.GetSeedData()...)
+func (c *SeederController) seedData(options seeder.Options) ([]seeder.SeedResult, error) {
+	engine, err := seeder.NewGormSeeder(c.DatabaseProvider.GetDataStore().Exec, c.Modules)
+	if err != nil {
+		return nil, err
 	}
-	return seedData
-}
-
-// Example of seeding
-/* func (c *SeederController) seedTimeUnit(tx *gorm.DB) error {
-	timeUnits := seed.TimeUnitList
-	numericTimeUnits := seed.TimeUnitNumericList
-
-	repo := repository.NewGormTimeUnitRepository(tx)
-
-	for key, value := range timeUnits {
-		err := repo.Store(&model.TimeUnit{
-			Code:        key,
-			NumericCode: numericTimeUnits[key],
-			Name:        value,
-		})
 
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-} */
+	c.LogProvider.Info("Seeding data", map[string]any{
+		"environment": options.Environment,
+		"only":        options.Only,
+		"dry_run":     options.DryRun,
+	})
+	return engine.Run(options)
+}
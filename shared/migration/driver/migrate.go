@@ -1,6 +1,7 @@
 package dbmigrate
 
 import (
+	"io"
 	"os"
 
 	apidomain "github.com/r0x16/Raidark/shared/api/domain"
@@ -24,19 +25,43 @@ func NewDbmigrate(hub *domprovider.ProviderHub, modules []apidomain.ApiModule) *
 	}
 }
 
+// Run migrates the module models and applies pending versioned migrations
 func (d *Dbmigrate) Run() {
+	d.exitOnError(d.controller().MigrateAction())
+}
+
+// Up applies pending versioned migrations only
+func (d *Dbmigrate) Up() {
+	d.exitOnError(d.controller().UpAction())
+}
 
-	dbmigrator := &controller.DbMigrationController{
+// Down rolls back the last steps applied migrations
+func (d *Dbmigrate) Down(steps int) {
+	d.exitOnError(d.controller().DownAction(steps))
+}
+
+// Redo rolls back the last applied migration and applies it again
+func (d *Dbmigrate) Redo() {
+	d.exitOnError(d.controller().RedoAction())
+}
+
+// Status prints the state of every migration to out
+func (d *Dbmigrate) Status(out io.Writer) {
+	d.exitOnError(d.controller().StatusAction(out))
+}
+
+func (d *Dbmigrate) controller() *controller.DbMigrationController {
+	return &controller.DbMigrationController{
 		LogProvider:      d.logProvider,
 		DatabaseProvider: d.databaseProvider,
 		Modules:          d.modules,
 	}
-	err := dbmigrator.MigrateAction()
+}
 
+func (d *Dbmigrate) exitOnError(err error) {
 	if err != nil {
 		// TODO: Catch processing error, and handle it appropriately, such as logging the error or retrying the operation.
 		d.logProvider.Critical("Error processing db migration", map[string]any{"error": err})
 		os.Exit(1)
 	}
-
 }
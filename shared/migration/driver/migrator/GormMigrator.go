@@ -0,0 +1,284 @@
+// Package migrator applies the versioned migrations declared by modules and
+// tracks them in the schema_migrations table.
+package migrator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apidomain "github.com/r0x16/Raidark/shared/api/domain"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	"github.com/r0x16/Raidark/shared/migration/domain/model"
+	"github.com/r0x16/Raidark/shared/migration/domain/schema"
+	"gorm.io/gorm"
+)
+
+// Migration states reported by Status
+const (
+	StateApplied = "applied"
+	StatePending = "pending"
+	// StateChanged marks an applied migration whose checksum no longer
+	// matches the code. Up and Down refuse to run while one exists.
+	StateChanged = "changed"
+	// StateMissing marks an applied migration no registered module declares
+	StateMissing = "missing"
+)
+
+// ErrChecksumMismatch is returned when an applied migration was edited
+var ErrChecksumMismatch = errors.New("migrator: applied migration checksum mismatch")
+
+// ModuleMigration is a migration together with the module that declared it
+type ModuleMigration struct {
+	Module string
+	schema.Migration
+}
+
+// MigrationStatus describes one migration as seen by Status
+type MigrationStatus struct {
+	Version   string
+	Name      string
+	Module    string
+	State     string
+	AppliedAt *time.Time
+}
+
+// GormMigrator runs module migrations against a GORM connection. Every
+// migration runs in its own transaction together with its bookkeeping row,
+// so a failing migration is never recorded as applied. Note that MySQL
+// commits DDL implicitly, which limits that guarantee to data changes.
+type GormMigrator struct {
+	db         *gorm.DB
+	migrations []ModuleMigration
+}
+
+// NewGormMigrator collects the migrations of every module and sorts them by
+// version. Two migrations sharing a version are rejected. Reads of db are
+// pinned to the primary, so a replica lagging behind never hides an applied
+// migration.
+func NewGormMigrator(db *gorm.DB, modules []apidomain.ApiModule) (*GormMigrator, error) {
+	migrations := []ModuleMigration{}
+	owners := map[string]string{}
+	for _, module := range modules {
+		for _, migration := range module.GetMigrations() {
+			if migration.Version == "" {
+				return nil, fmt.Errorf("migrator: module %s declares a migration without version", module.Name())
+			}
+			if owner, ok := owners[migration.Version]; ok {
+				return nil, fmt.Errorf("migrator: version %s is declared by both %s and %s", migration.Version, owner, module.Name())
+			}
+			owners[migration.Version] = module.Name()
+			migrations = append(migrations, ModuleMigration{Module: module.Name(), Migration: migration})
+		}
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return &GormMigrator{db: driverdatastore.OnPrimary(db), migrations: migrations}, nil
+}
+
+// Up applies every pending migration in version order and returns the ones
+// it applied
+func (m *GormMigrator) Up() ([]ModuleMigration, error) {
+	applied, err := m.verifiedApplied()
+	if err != nil {
+		return nil, err
+	}
+
+	done := []ModuleMigration{}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := m.applyUp(migration); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first, and
+// returns the ones it rolled back
+func (m *GormMigrator) Down(steps int) ([]ModuleMigration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("migrator: down steps must be at least 1, got %d", steps)
+	}
+
+	applied, err := m.verifiedApplied()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	if steps > len(versions) {
+		steps = len(versions)
+	}
+
+	done := []ModuleMigration{}
+	for _, version := range versions[:steps] {
+		migration, ok := m.find(version)
+		if !ok {
+			return done, fmt.Errorf("migrator: applied migration %s is not declared by any module", version)
+		}
+		if !migration.IsReversible() {
+			return done, fmt.Errorf("migrator: migration %s_%s: %w", migration.Version, migration.Name, schema.ErrIrreversible)
+		}
+		if err := m.applyDown(migration); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Redo rolls back the last applied migration and applies it again
+func (m *GormMigrator) Redo() (*ModuleMigration, error) {
+	rolledBack, err := m.Down(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(rolledBack) == 0 {
+		return nil, nil
+	}
+
+	migration := rolledBack[0]
+	if err := m.applyUp(migration); err != nil {
+		return nil, err
+	}
+	return &migration, nil
+}
+
+// Status lists every known migration, declared or applied, in version order
+func (m *GormMigrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []MigrationStatus{}
+	for _, migration := range m.migrations {
+		status := MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Module:  migration.Module,
+			State:   StatePending,
+		}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.State = StateApplied
+			if record.Checksum != migration.Checksum() {
+				status.State = StateChanged
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	for version, record := range applied {
+		if _, ok := m.find(version); ok {
+			continue
+		}
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   record.Version,
+			Name:      record.Name,
+			Module:    record.Module,
+			State:     StateMissing,
+			AppliedAt: &appliedAt,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// applied loads the bookkeeping rows, creating the table on first use
+func (m *GormMigrator) applied() (map[string]model.SchemaMigration, error) {
+	if err := m.db.AutoMigrate(&model.SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("migrator: create schema_migrations: %w", err)
+	}
+
+	var records []model.SchemaMigration
+	if err := m.db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("migrator: read schema_migrations: %w", err)
+	}
+
+	applied := make(map[string]model.SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// verifiedApplied is applied plus the checksum guard: it fails when any
+// applied migration was edited after it ran
+func (m *GormMigrator) verifiedApplied() (map[string]model.SchemaMigration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+		if ok && record.Checksum != migration.Checksum() {
+			changed = append(changed, migration.Version+"_"+migration.Name)
+		}
+	}
+	if len(changed) > 0 {
+		return nil, fmt.Errorf("%w: %s (add a new migration instead of editing an applied one)", ErrChecksumMismatch, strings.Join(changed, ", "))
+	}
+	return applied, nil
+}
+
+func (m *GormMigrator) applyUp(migration ModuleMigration) error {
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := migration.ApplyUp(tx); err != nil {
+			return err
+		}
+		return tx.Create(&model.SchemaMigration{
+			Version:   migration.Version,
+			Module:    migration.Module,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum(),
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migrator: apply %s_%s (%s): %w", migration.Version, migration.Name, migration.Module, err)
+	}
+	return nil
+}
+
+func (m *GormMigrator) applyDown(migration ModuleMigration) error {
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := migration.ApplyDown(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&model.SchemaMigration{Version: migration.Version}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migrator: roll back %s_%s (%s): %w", migration.Version, migration.Name, migration.Module, err)
+	}
+	return nil
+}
+
+func (m *GormMigrator) find(version string) (ModuleMigration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return ModuleMigration{}, false
+}
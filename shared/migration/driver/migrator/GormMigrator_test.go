@@ -0,0 +1,210 @@
+// Package migrator_test covers the versioned migration engine against SQLite:
+// ordering across modules, the checksum guard, atomic failures and rollback.
+package migrator_test
+
+import (
+	"errors"
+	"testing"
+
+	apidomain "github.com/r0x16/Raidark/shared/api/domain"
+	eventdomain "github.com/r0x16/Raidark/shared/events/domain"
+	testdb "github.com/r0x16/Raidark/shared/internal/testutil/db"
+	"github.com/r0x16/Raidark/shared/migration/domain/model"
+	"github.com/r0x16/Raidark/shared/migration/domain/schema"
+	"github.com/r0x16/Raidark/shared/migration/domain/seed"
+	"github.com/r0x16/Raidark/shared/migration/driver/migrator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormMigrator_appliesPendingMigrationsOnceInVersionOrder(t *testing.T) {
+	db := testdb.NewSQLite(t)
+	modules := []apidomain.ApiModule{
+		migrationModule{name: "orders", migrations: []schema.Migration{ordersTable()}},
+		migrationModule{name: "catalog", migrations: []schema.Migration{productsTable()}},
+	}
+
+	engine, err := migrator.NewGormMigrator(db, modules)
+	require.NoError(t, err)
+
+	applied, err := engine.Up()
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+	assert.Equal(t, "catalog", applied[0].Module)
+	assert.Equal(t, "orders", applied[1].Module)
+	assert.True(t, db.Migrator().HasTable("orders"))
+
+	applied, err = engine.Up()
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	var records []model.SchemaMigration
+	require.NoError(t, db.Order("version").Find(&records).Error)
+	require.Len(t, records, 2)
+	assert.Equal(t, "20260101000000", records[0].Version)
+	assert.Equal(t, productsTable().Checksum(), records[0].Checksum)
+}
+
+func TestGormMigrator_rejectsEditedAppliedMigration(t *testing.T) {
+	db := testdb.NewSQLite(t)
+	engine, err := migrator.NewGormMigrator(db, []apidomain.ApiModule{
+		migrationModule{name: "orders", migrations: []schema.Migration{ordersTable()}},
+	})
+	require.NoError(t, err)
+	_, err = engine.Up()
+	require.NoError(t, err)
+
+	edited := ordersTable()
+	edited.UpSQL = "CREATE TABLE orders (id TEXT PRIMARY KEY, total INTEGER)"
+	engine, err = migrator.NewGormMigrator(db, []apidomain.ApiModule{
+		migrationModule{name: "orders", migrations: []schema.Migration{edited}},
+	})
+	require.NoError(t, err)
+
+	_, err = engine.Up()
+	assert.ErrorIs(t, err, migrator.ErrChecksumMismatch)
+	_, err = engine.Down(1)
+	assert.ErrorIs(t, err, migrator.ErrChecksumMismatch)
+
+	statuses, err := engine.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, migrator.StateChanged, statuses[0].State)
+}
+
+func TestGormMigrator_failedMigrationIsNotRecorded(t *testing.T) {
+	db := testdb.NewSQLite(t)
+	broken := schema.Migration{
+		Version: "20260301000000",
+		Name:    "backfill",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("CREATE TABLE audit (id TEXT PRIMARY KEY)").Error; err != nil {
+				return err
+			}
+			return errors.New("backfill failed")
+		},
+	}
+	engine, err := migrator.NewGormMigrator(db, []apidomain.ApiModule{
+		migrationModule{name: "orders", migrations: []schema.Migration{ordersTable(), broken}},
+	})
+	require.NoError(t, err)
+
+	applied, err := engine.Up()
+	require.ErrorContains(t, err, "backfill failed")
+	require.Len(t, applied, 1)
+	assert.False(t, db.Migrator().HasTable("audit"))
+
+	statuses, err := engine.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, migrator.StateApplied, statuses[0].State)
+	assert.Equal(t, migrator.StatePending, statuses[1].State)
+	assert.Nil(t, statuses[1].AppliedAt)
+}
+
+func TestGormMigrator_downAndRedo(t *testing.T) {
+	db := testdb.NewSQLite(t)
+	engine, err := migrator.NewGormMigrator(db, []apidomain.ApiModule{
+		migrationModule{name: "catalog", migrations: []schema.Migration{productsTable()}},
+		migrationModule{name: "orders", migrations: []schema.Migration{ordersTable()}},
+	})
+	require.NoError(t, err)
+	_, err = engine.Up()
+	require.NoError(t, err)
+
+	redone, err := engine.Redo()
+	require.NoError(t, err)
+	require.NotNil(t, redone)
+	assert.Equal(t, "20260201000000", redone.Version)
+	assert.True(t, db.Migrator().HasTable("orders"))
+
+	rolledBack, err := engine.Down(5)
+	require.NoError(t, err)
+	require.Len(t, rolledBack, 2)
+	assert.Equal(t, "20260201000000", rolledBack[0].Version)
+	assert.Equal(t, "20260101000000", rolledBack[1].Version)
+	assert.False(t, db.Migrator().HasTable("orders"))
+	assert.False(t, db.Migrator().HasTable("products"))
+
+	var count int64
+	require.NoError(t, db.Model(&model.SchemaMigration{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestGormMigrator_downRefusesIrreversibleMigration(t *testing.T) {
+	db := testdb.NewSQLite(t)
+	oneWay := productsTable()
+	oneWay.DownSQL = ""
+	engine, err := migrator.NewGormMigrator(db, []apidomain.ApiModule{
+		migrationModule{name: "catalog", migrations: []schema.Migration{oneWay}},
+	})
+	require.NoError(t, err)
+	_, err = engine.Up()
+	require.NoError(t, err)
+
+	_, err = engine.Down(1)
+	assert.ErrorIs(t, err, schema.ErrIrreversible)
+	assert.True(t, db.Migrator().HasTable("products"))
+}
+
+func TestGormMigrator_reportsMissingMigrations(t *testing.T) {
+	db := testdb.NewSQLite(t)
+	engine, err := migrator.NewGormMigrator(db, []apidomain.ApiModule{
+		migrationModule{name: "catalog", migrations: []schema.Migration{productsTable()}},
+	})
+	require.NoError(t, err)
+	_, err = engine.Up()
+	require.NoError(t, err)
+
+	engine, err = migrator.NewGormMigrator(db, []apidomain.ApiModule{
+		migrationModule{name: "orders", migrations: []schema.Migration{ordersTable()}},
+	})
+	require.NoError(t, err)
+
+	statuses, err := engine.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, migrator.StateMissing, statuses[0].State)
+	assert.Equal(t, "catalog", statuses[0].Module)
+	assert.Equal(t, migrator.StatePending, statuses[1].State)
+}
+
+func TestNewGormMigrator_rejectsDuplicateVersions(t *testing.T) {
+	_, err := migrator.NewGormMigrator(testdb.NewSQLite(t), []apidomain.ApiModule{
+		migrationModule{name: "catalog", migrations: []schema.Migration{productsTable()}},
+		migrationModule{name: "orders", migrations: []schema.Migration{productsTable()}},
+	})
+	assert.ErrorContains(t, err, "declared by both catalog and orders")
+}
+
+func productsTable() schema.Migration {
+	return schema.Migration{
+		Version: "20260101000000",
+		Name:    "create_products",
+		UpSQL:   "CREATE TABLE products (id TEXT PRIMARY KEY, name TEXT NOT NULL)",
+		DownSQL: "DROP TABLE products",
+	}
+}
+
+func ordersTable() schema.Migration {
+	return schema.Migration{
+		Version: "20260201000000",
+		Name:    "create_orders",
+		UpSQL:   "CREATE TABLE orders (id TEXT PRIMARY KEY)",
+		DownSQL: "DROP TABLE orders",
+	}
+}
+
+type migrationModule struct {
+	name       string
+	migrations []schema.Migration
+}
+
+func (m migrationModule) Name() string                                 { return m.name }
+func (migrationModule) Setup() error                                   { return nil }
+func (migrationModule) GetModel() []any                                { return nil }
+func (migrationModule) GetSeedData() []any                             { return nil }
+func (migrationModule) GetSeedSets() []seed.SeedSet                    { return nil }
+func (m migrationModule) GetMigrations() []schema.Migration            { return m.migrations }
+func (migrationModule) GetEventListeners() []eventdomain.EventListener { return nil }
@@ -1,12 +1,14 @@
 package dbmigrate
 
 import (
+	"io"
 	"os"
 
 	apidomain "github.com/r0x16/Raidark/shared/api/domain"
 	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
 	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
 	"github.com/r0x16/Raidark/shared/migration/driver/controller"
+	"github.com/r0x16/Raidark/shared/migration/driver/seeder"
 	domprovider "github.com/r0x16/Raidark/shared/providers/domain"
 )
 
@@ -24,13 +26,14 @@ func NewSeeder(hub *domprovider.ProviderHub, modules []apidomain.ApiModule) *See
 	}
 }
 
-func (d *Seeder) Run() {
+// Run loads the seed sets selected by options and prints a summary to out
+func (d *Seeder) Run(options seeder.Options, out io.Writer) {
 	seedController := &controller.SeederController{
 		LogProvider:      d.logProvider,
 		DatabaseProvider: d.databaseProvider,
 		Modules:          d.modules,
 	}
-	err := seedController.SeedAction()
+	err := seedController.SeedAction(options, out)
 
 	if err != nil {
 		// TODO: Catch processing error, and handle it appropriately, such as logging the error or retrying the operation.
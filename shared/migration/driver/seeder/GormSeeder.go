@@ -0,0 +1,286 @@
+// Package seeder loads the seed sets declared by modules with upsert
+// semantics and tracks them in the seeds_applied ledger.
+package seeder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	apidomain "github.com/r0x16/Raidark/shared/api/domain"
+	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	"github.com/r0x16/Raidark/shared/migration/domain/model"
+	"github.com/r0x16/Raidark/shared/migration/domain/seed"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LegacySetName is the name given to the rows a module still returns from
+// GetSeedData instead of GetSeedSets
+const LegacySetName = "default"
+
+// Actions reported for each selected seed set
+const (
+	ActionApplied     = "applied"
+	ActionUnchanged   = "unchanged"
+	ActionWouldApply  = "would apply"
+	ActionWouldUpdate = "would update"
+)
+
+// ModuleSeedSet is a seed set together with the module that declared it
+type ModuleSeedSet struct {
+	Module string
+	seed.SeedSet
+	// checksum is taken when the set is collected, before loading it fills
+	// generated fields such as IDs into the rows
+	checksum string
+}
+
+// Key is the "module/name" form accepted by Options.Only
+func (s ModuleSeedSet) Key() string {
+	return s.Module + "/" + s.Name
+}
+
+// Options selects what a run loads
+type Options struct {
+	// Environment filters out sets tagged for other environments
+	Environment string
+	// Only restricts the run to the listed sets, given as "name" or
+	// "module/name". Empty means every set of the environment.
+	Only []string
+	// DryRun reports what would be loaded without writing anything
+	DryRun bool
+}
+
+// SeedResult describes what a run did with one seed set
+type SeedResult struct {
+	Module string
+	Name   string
+	Action string
+	Rows   int
+}
+
+// GormSeeder loads module seed sets through GORM. Each set is upserted in its
+// own transaction together with its ledger row. A set whose checksum matches
+// the ledger is skipped, so running the seeder twice is a no-op.
+type GormSeeder struct {
+	db   *gorm.DB
+	sets []ModuleSeedSet
+}
+
+// NewGormSeeder collects the seed sets of every module in registration order.
+// Rows returned by GetSeedData become a set named LegacySetName that loads in
+// every environment. Reads of db are pinned to the primary, so the ledger is
+// never read from a lagging replica.
+func NewGormSeeder(db *gorm.DB, modules []apidomain.ApiModule) (*GormSeeder, error) {
+	sets := []ModuleSeedSet{}
+	seen := map[string]bool{}
+	for _, module := range modules {
+		moduleSets := module.GetSeedSets()
+		if legacy := module.GetSeedData(); len(legacy) > 0 {
+			moduleSets = append(moduleSets, seed.SeedSet{Name: LegacySetName, Data: legacy})
+		}
+
+		for _, set := range moduleSets {
+			if set.Name == "" {
+				return nil, fmt.Errorf("seeder: module %s declares a seed set without name", module.Name())
+			}
+			checksum, err := set.Checksum()
+			if err != nil {
+				return nil, err
+			}
+			moduleSet := ModuleSeedSet{Module: module.Name(), SeedSet: set, checksum: checksum}
+			if seen[moduleSet.Key()] {
+				return nil, fmt.Errorf("seeder: seed set %s is declared twice", moduleSet.Key())
+			}
+			seen[moduleSet.Key()] = true
+			sets = append(sets, moduleSet)
+		}
+	}
+	return &GormSeeder{db: driverdatastore.OnPrimary(db), sets: sets}, nil
+}
+
+// Run loads the sets selected by options and reports one result per set
+func (s *GormSeeder) Run(options Options) ([]SeedResult, error) {
+	selected, err := s.selectSets(options)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger, err := s.ledger(options.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []SeedResult{}
+	for _, set := range selected {
+		result := SeedResult{Module: set.Module, Name: set.Name, Rows: countRows(set.Data)}
+		record, known := ledger[set.Key()]
+		switch {
+		case known && record.Checksum == set.checksum:
+			result.Action = ActionUnchanged
+		case options.DryRun && known:
+			result.Action = ActionWouldUpdate
+		case options.DryRun:
+			result.Action = ActionWouldApply
+		default:
+			if err := s.apply(set, options.Environment, result.Rows); err != nil {
+				return results, err
+			}
+			result.Action = ActionApplied
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// selectSets applies the environment and --only filters. Naming a set in
+// Only that no module declares is an error, so a typo never silently seeds
+// nothing.
+func (s *GormSeeder) selectSets(options Options) ([]ModuleSeedSet, error) {
+	if !seed.IsEnvironment(options.Environment) {
+		return nil, fmt.Errorf("seeder: unknown environment %q, expected %s, %s or %s", options.Environment, seed.EnvDev, seed.EnvTest, seed.EnvProd)
+	}
+
+	only := map[string]bool{}
+	for _, name := range options.Only {
+		if name = strings.TrimSpace(name); name != "" {
+			only[name] = false
+		}
+	}
+
+	selected := []ModuleSeedSet{}
+	for _, set := range s.sets {
+		if len(only) > 0 {
+			_, byKey := only[set.Key()]
+			_, byName := only[set.Name]
+			if !byKey && !byName {
+				continue
+			}
+			if byKey {
+				only[set.Key()] = true
+			}
+			if byName {
+				only[set.Name] = true
+			}
+		}
+		if set.AppliesTo(options.Environment) {
+			selected = append(selected, set)
+		}
+	}
+
+	for name, matched := range only {
+		if !matched {
+			return nil, fmt.Errorf("seeder: unknown seed set %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// ledger loads the seeds_applied rows by key. Outside dry runs it creates the
+// table on first use; a dry run never writes, so a missing table just means
+// nothing was seeded yet.
+func (s *GormSeeder) ledger(dryRun bool) (map[string]model.SeedApplied, error) {
+	if dryRun {
+		if !s.db.Migrator().HasTable(&model.SeedApplied{}) {
+			return map[string]model.SeedApplied{}, nil
+		}
+	} else if err := s.db.AutoMigrate(&model.SeedApplied{}); err != nil {
+		return nil, fmt.Errorf("seeder: create seeds_applied: %w", err)
+	}
+
+	var records []model.SeedApplied
+	if err := s.db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("seeder: read seeds_applied: %w", err)
+	}
+
+	ledger := make(map[string]model.SeedApplied, len(records))
+	for _, record := range records {
+		ledger[record.Module+"/"+record.Name] = record
+	}
+	return ledger, nil
+}
+
+func (s *GormSeeder) apply(set ModuleSeedSet, environment string, rows int) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, data := range set.Data {
+			columns, err := conflictColumns(tx, data, set.ConflictColumns)
+			if err != nil {
+				return err
+			}
+			err = tx.Clauses(clause.OnConflict{Columns: columns, UpdateAll: true}).Create(data).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Save(&model.SeedApplied{
+			Module:      set.Module,
+			Name:        set.Name,
+			Checksum:    set.checksum,
+			Environment: environment,
+			Rows:        rows,
+			AppliedAt:   time.Now().UTC(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("seeder: load %s: %w", set.Key(), err)
+	}
+	return nil
+}
+
+// conflictColumns returns the columns that identify an existing row of
+// data's model: the explicit override, else the first unique field, else the
+// first unique index, else the primary key
+func conflictColumns(db *gorm.DB, data any, override []string) ([]clause.Column, error) {
+	if len(override) > 0 {
+		return toColumns(override), nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(data); err != nil {
+		return nil, fmt.Errorf("parse seed model %T: %w", data, err)
+	}
+
+	for _, field := range stmt.Schema.Fields {
+		if field.Unique && field.DBName != "" {
+			return toColumns([]string{field.DBName}), nil
+		}
+	}
+	for _, index := range stmt.Schema.ParseIndexes() {
+		if index.Class != "UNIQUE" {
+			continue
+		}
+		names := make([]string, 0, len(index.Fields))
+		for _, option := range index.Fields {
+			names = append(names, option.DBName)
+		}
+		return toColumns(names), nil
+	}
+	if len(stmt.Schema.PrimaryFieldDBNames) > 0 {
+		return toColumns(stmt.Schema.PrimaryFieldDBNames), nil
+	}
+	return nil, fmt.Errorf("seed model %T has no unique or primary key columns", data)
+}
+
+func toColumns(names []string) []clause.Column {
+	columns := make([]clause.Column, 0, len(names))
+	for _, name := range names {
+		columns = append(columns, clause.Column{Name: name})
+	}
+	return columns
+}
+
+// countRows counts the rows of data, expanding slices
+func countRows(data []any) int {
+	rows := 0
+	for _, item := range data {
+		value := reflect.Indirect(reflect.ValueOf(item))
+		if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+			rows += value.Len()
+		} else {
+			rows++
+		}
+	}
+	return rows
+}
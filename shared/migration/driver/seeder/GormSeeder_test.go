@@ -0,0 +1,157 @@
+// Package seeder_test covers idempotent seeding against SQLite: upserts,
+// environment filtering, the ledger and dry runs.
+package seeder_test
+
+import (
+	"testing"
+
+	apidomain "github.com/r0x16/Raidark/shared/api/domain"
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
+	eventdomain "github.com/r0x16/Raidark/shared/events/domain"
+	testdb "github.com/r0x16/Raidark/shared/internal/testutil/db"
+	"github.com/r0x16/Raidark/shared/migration/domain/model"
+	"github.com/r0x16/Raidark/shared/migration/domain/schema"
+	"github.com/r0x16/Raidark/shared/migration/domain/seed"
+	"github.com/r0x16/Raidark/shared/migration/driver/seeder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGormSeeder_runningTwiceDoesNotDuplicateRows(t *testing.T) {
+	db := testdb.NewSQLite(t, &country{})
+	engine := newSeeder(t, db, seedModule{name: "geo", sets: []seed.SeedSet{countries("Chile")}})
+
+	results, err := engine.Run(seeder.Options{Environment: seed.EnvProd})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, seeder.ActionApplied, results[0].Action)
+	assert.Equal(t, 2, results[0].Rows)
+
+	results, err = engine.Run(seeder.Options{Environment: seed.EnvProd})
+	require.NoError(t, err)
+	assert.Equal(t, seeder.ActionUnchanged, results[0].Action)
+	assert.Equal(t, int64(2), countCountries(t, db))
+}
+
+func TestGormSeeder_changedSetUpdatesExistingRows(t *testing.T) {
+	db := testdb.NewSQLite(t, &country{})
+	_, err := newSeeder(t, db, seedModule{name: "geo", sets: []seed.SeedSet{countries("Chile")}}).
+		Run(seeder.Options{Environment: seed.EnvDev})
+	require.NoError(t, err)
+
+	results, err := newSeeder(t, db, seedModule{name: "geo", sets: []seed.SeedSet{countries("República de Chile")}}).
+		Run(seeder.Options{Environment: seed.EnvDev})
+	require.NoError(t, err)
+	assert.Equal(t, seeder.ActionApplied, results[0].Action)
+
+	var chile country
+	require.NoError(t, db.Where("code = ?", "CL").First(&chile).Error)
+	assert.Equal(t, "República de Chile", chile.Name)
+	assert.Equal(t, int64(2), countCountries(t, db))
+
+	var record model.SeedApplied
+	require.NoError(t, db.Where("module = ? AND name = ?", "geo", "countries").First(&record).Error)
+	assert.Equal(t, seed.EnvDev, record.Environment)
+	assert.Equal(t, 2, record.Rows)
+}
+
+func TestGormSeeder_filtersByEnvironmentAndOnly(t *testing.T) {
+	db := testdb.NewSQLite(t, &country{})
+	demo := seed.SeedSet{
+		Name:         "demo",
+		Environments: []string{seed.EnvDev, seed.EnvTest},
+		Data:         []any{&country{Code: "ZZ", Name: "Demoland"}},
+	}
+	engine := newSeeder(t, db, seedModule{name: "geo", sets: []seed.SeedSet{countries("Chile"), demo}})
+
+	results, err := engine.Run(seeder.Options{Environment: seed.EnvProd})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "countries", results[0].Name)
+
+	results, err = engine.Run(seeder.Options{Environment: seed.EnvTest, Only: []string{"geo/demo"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "demo", results[0].Name)
+	assert.Equal(t, int64(3), countCountries(t, db))
+
+	_, err = engine.Run(seeder.Options{Environment: seed.EnvTest, Only: []string{"contries"}})
+	assert.ErrorContains(t, err, `unknown seed set "contries"`)
+
+	for _, env := range []string{"", "production", "Prod"} {
+		_, err = engine.Run(seeder.Options{Environment: env})
+		assert.ErrorContains(t, err, "unknown environment")
+	}
+	assert.Equal(t, int64(3), countCountries(t, db))
+}
+
+func TestGormSeeder_dryRunWritesNothing(t *testing.T) {
+	db := testdb.NewSQLite(t, &country{})
+	engine := newSeeder(t, db, seedModule{name: "geo", sets: []seed.SeedSet{countries("Chile")}})
+
+	results, err := engine.Run(seeder.Options{Environment: seed.EnvDev, DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, seeder.ActionWouldApply, results[0].Action)
+	assert.Zero(t, countCountries(t, db))
+	assert.False(t, db.Migrator().HasTable(&model.SeedApplied{}))
+}
+
+func TestGormSeeder_wrapsLegacySeedData(t *testing.T) {
+	db := testdb.NewSQLite(t, &country{})
+	engine := newSeeder(t, db, seedModule{name: "geo", legacy: []any{&country{Code: "AR", Name: "Argentina"}}})
+
+	results, err := engine.Run(seeder.Options{Environment: seed.EnvDev})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, seeder.LegacySetName, results[0].Name)
+
+	_, err = engine.Run(seeder.Options{Environment: seed.EnvDev, Only: []string{seeder.LegacySetName}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), countCountries(t, db))
+}
+
+type country struct {
+	domdatastore.BaseModel
+	Code string `gorm:"type:varchar(2);uniqueIndex"`
+	Name string
+}
+
+func countries(chileName string) seed.SeedSet {
+	return seed.SeedSet{
+		Name: "countries",
+		Data: []any{&[]country{
+			{Code: "CL", Name: chileName},
+			{Code: "PE", Name: "Perú"},
+		}},
+	}
+}
+
+func newSeeder(t *testing.T, db *gorm.DB, modules ...apidomain.ApiModule) *seeder.GormSeeder {
+	t.Helper()
+	engine, err := seeder.NewGormSeeder(db, modules)
+	require.NoError(t, err)
+	return engine
+}
+
+func countCountries(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var count int64
+	require.NoError(t, db.Model(&country{}).Count(&count).Error)
+	return count
+}
+
+type seedModule struct {
+	name   string
+	sets   []seed.SeedSet
+	legacy []any
+}
+
+func (m seedModule) Name() string                                 { return m.name }
+func (seedModule) Setup() error                                   { return nil }
+func (seedModule) GetModel() []any                                { return nil }
+func (m seedModule) GetSeedData() []any                           { return m.legacy }
+func (m seedModule) GetSeedSets() []seed.SeedSet                  { return m.sets }
+func (seedModule) GetMigrations() []schema.Migration              { return nil }
+func (seedModule) GetEventListeners() []eventdomain.EventListener { return nil }
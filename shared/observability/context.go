@@ -37,12 +37,13 @@ const (
 // Distinct unexported types prevent accidental collisions with other packages
 // that store values in the same context.Context.
 type (
-	traceIDCtxKey    struct{}
-	spanIDCtxKey     struct{}
-	traceFlagsCtxKey struct{}
-	traceStateCtxKey struct{}
-	serviceCtxKey    struct{}
-	eventIDCtxKey    struct{}
+	traceIDCtxKey       struct{}
+	spanIDCtxKey        struct{}
+	traceFlagsCtxKey    struct{}
+	traceStateCtxKey    struct{}
+	serviceCtxKey       struct{}
+	eventIDCtxKey       struct{}
+	correlationIDCtxKey struct{}
 )
 
 // defaultServiceName is the fallback service name returned by GetServiceName
@@ -156,3 +157,19 @@ func GetEventID(ctx context.Context) string {
 	v, _ := ctx.Value(eventIDCtxKey{}).(string)
 	return v
 }
+
+// WithCorrelationID returns ctx augmented with the request correlation_id, so
+// code that only sees the Go context (event publishers, background work) can
+// carry it forward.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDCtxKey{}, correlationID)
+}
+
+// GetCorrelationID returns the correlation_id stored in ctx, or "" if unset.
+func GetCorrelationID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	v, _ := ctx.Value(correlationIDCtxKey{}).(string)
+	return v
+}
@@ -63,6 +63,15 @@ type Metrics struct {
 	// has fallen behind and is the most direct signal for outbox-related
 	// alerting.
 	OutboxPending prometheus.Gauge
+
+	// AuthSessionsPrunedTotal counts the expired auth sessions deleted by
+	// the session reaper and the prune-sessions command.
+	AuthSessionsPrunedTotal prometheus.Counter
+
+	// AuthSessionPruneRunsTotal counts reaper runs with an "outcome" label
+	// (success, failure). A run that keeps failing lets auth_sessions grow
+	// without bound, so alert on the failure rate.
+	AuthSessionPruneRunsTotal *prometheus.CounterVec
 }
 
 // NewMetrics constructs a Metrics bundle with all collectors registered on a
@@ -131,6 +140,21 @@ func NewMetrics() *Metrics {
 				Help: "Current depth of the transactional outbox awaiting publication.",
 			},
 		),
+
+		AuthSessionsPrunedTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "auth_sessions_pruned_total",
+				Help: "Total number of expired auth sessions deleted by the session reaper.",
+			},
+		),
+
+		AuthSessionPruneRunsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "auth_session_prune_runs_total",
+				Help: "Total number of session reaper runs, labelled by outcome.",
+			},
+			[]string{"outcome"},
+		),
 	}
 
 	// MustRegister panics on collision; collisions can only happen here if
@@ -144,6 +168,8 @@ func NewMetrics() *Metrics {
 		m.EventsRedeliveriesTotal,
 		m.EventProcessingDurationMs,
 		m.OutboxPending,
+		m.AuthSessionsPrunedTotal,
+		m.AuthSessionPruneRunsTotal,
 	)
 
 	return m
@@ -181,3 +207,14 @@ func (m *Metrics) ObserveEventProcessing(subject, consumer string, durationMs fl
 func (m *Metrics) SetOutboxPending(pending float64) {
 	m.OutboxPending.Set(pending)
 }
+
+// RecordSessionsPruned adds the sessions deleted by one reaper batch to the
+// pruned counter.
+func (m *Metrics) RecordSessionsPruned(count int) {
+	m.AuthSessionsPrunedTotal.Add(float64(count))
+}
+
+// RecordSessionPruneRun counts a finished reaper run with its outcome.
+func (m *Metrics) RecordSessionPruneRun(outcome string) {
+	m.AuthSessionPruneRunsTotal.WithLabelValues(outcome).Inc()
+}
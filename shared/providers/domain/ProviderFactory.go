@@ -1,6 +1,57 @@
 package domain
 
+import (
+	"context"
+	"reflect"
+)
+
 type ProviderFactory interface {
 	Init(*ProviderHub)
 	Register(*ProviderHub) error
 }
+
+// DependentProviderFactory is implemented by factories that declare the
+// provider types they register and the ones they read from the hub, so the
+// hub factory can run them in dependency order. A factory that does not
+// implement it runs after every factory listed before it.
+type DependentProviderFactory interface {
+	ProviderFactory
+	Provides() []reflect.Type
+	DependsOn() []Dependency
+}
+
+// Dependency is a provider type a factory reads from the hub. A required
+// dependency must be provided by another factory; an optional one is only
+// waited for when some factory provides it.
+type Dependency struct {
+	Type     reflect.Type
+	Optional bool
+}
+
+// Requires declares a required dependency on the provider type T
+func Requires[T any]() Dependency {
+	return Dependency{Type: TypeOf[T]()}
+}
+
+// Uses declares an optional dependency on the provider type T
+func Uses[T any]() Dependency {
+	return Dependency{Type: TypeOf[T](), Optional: true}
+}
+
+// StartableProvider is implemented by providers that start work once the
+// application starts serving, such as background consumers
+type StartableProvider interface {
+	Start(ctx context.Context) error
+}
+
+// StoppableProvider is implemented by providers that release resources on
+// shutdown. Providers are stopped in the reverse order of their factories.
+type StoppableProvider interface {
+	Stop(ctx context.Context) error
+}
+
+// HealthCheckedProvider is implemented by providers that can tell whether
+// their backing service is usable
+type HealthCheckedProvider interface {
+	HealthCheck(ctx context.Context) error
+}
@@ -1,15 +1,42 @@
 package domain
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 )
 
 // ProviderHub is a dependency injection container that stores and retrieves
 // providers of different types using reflection and generics.
-// It maintains a map of types to their corresponding provider instances.
+// It maintains a map of types to their corresponding provider instances and
+// is safe to use from several goroutines.
+//
+// Besides the default instance of a type, a hub holds named instances of it,
+// such as a "replica" DatabaseProvider next to the primary one. A scope
+// created with Scope holds its own registrations and falls back to the hub
+// it was created from for everything else.
 type ProviderHub struct {
-	providers map[reflect.Type]any
+	mu        sync.RWMutex
+	parent    *ProviderHub
+	providers map[providerKey]any
+	order     []reflect.Type
+}
+
+// providerKey identifies a registration; the default instance of a type has
+// an empty name
+type providerKey struct {
+	t    reflect.Type
+	name string
+}
+
+// Scope returns a child hub, for example for one request. Registrations made
+// on the scope are only visible through it and shadow the parent's ones;
+// everything else is looked up in the parent.
+func (h *ProviderHub) Scope() *ProviderHub {
+	return &ProviderHub{parent: h}
 }
 
 // Register stores a provider instance in the hub using its type as the key.
@@ -23,11 +50,22 @@ type ProviderHub struct {
 // Returns:
 //   - The same provider instance for method chaining
 func Register[T any](hub *ProviderHub, provider T) T {
+	return RegisterNamed(hub, "", provider)
+}
+
+// RegisterNamed stores a named instance of T, next to its default instance
+// and the other names. An empty name registers the default instance.
+func RegisterNamed[T any](hub *ProviderHub, name string, provider T) T {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
 	if hub.providers == nil {
-		hub.providers = make(map[reflect.Type]any)
+		hub.providers = make(map[providerKey]any)
 	}
-	t := reflect.TypeOf((*T)(nil))
-	hub.providers[t] = provider
+	key := providerKey{t: TypeOf[T](), name: name}
+	if _, ok := hub.providers[key]; !ok && name == "" {
+		hub.order = append(hub.order, key.t)
+	}
+	hub.providers[key] = provider
 	return provider
 }
 
@@ -42,14 +80,75 @@ func Register[T any](hub *ProviderHub, provider T) T {
 //   - The provider instance of the requested type
 //
 // Panics:
-//   - If the provider type is not found in the hub
+//   - If the provider type is not found in the hub, listing the registered
+//     types
 func Get[T any](hub *ProviderHub) T {
-	t := reflect.TypeOf((*T)(nil))
-	provider, ok := hub.providers[t]
+	provider, ok := Lookup[T](hub)
+	if !ok {
+		registered := "none"
+		if names := hub.registeredNames(); len(names) > 0 {
+			registered = strings.Join(names, ", ")
+		}
+		panic(fmt.Errorf("provider %s not found in hub; registered providers: %s", TypeName(TypeOf[T]()), registered))
+	}
+	return provider
+}
+
+// Lookup retrieves a provider instance from the hub by its type and reports
+// whether it was registered, for callers that can work without it.
+func Lookup[T any](hub *ProviderHub) (T, bool) {
+	return LookupNamed[T](hub, "")
+}
+
+// GetNamed retrieves the instance of T registered under name. It panics,
+// listing the registered names of T, when there is none.
+func GetNamed[T any](hub *ProviderHub, name string) T {
+	provider, ok := LookupNamed[T](hub, name)
 	if !ok {
-		panic(fmt.Errorf("provider %T not found", t))
+		registered := "none"
+		if names := Names[T](hub); len(names) > 0 {
+			registered = strings.Join(names, ", ")
+		}
+		panic(fmt.Errorf("provider %s named %q not found in hub; registered names: %s", TypeName(TypeOf[T]()), name, registered))
+	}
+	return provider
+}
+
+// LookupNamed retrieves the instance of T registered under name and reports
+// whether there is one
+func LookupNamed[T any](hub *ProviderHub, name string) (T, bool) {
+	provider, ok := hub.lookup(providerKey{t: TypeOf[T](), name: name})
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return provider.(T), true
+}
+
+// ExistsNamed checks if an instance of T is registered under name
+func ExistsNamed[T any](hub *ProviderHub, name string) bool {
+	_, ok := hub.lookup(providerKey{t: TypeOf[T](), name: name})
+	return ok
+}
+
+// Names returns the sorted names T is registered under, in the hub and its
+// parents, without the default instance
+func Names[T any](hub *ProviderHub) []string {
+	t := TypeOf[T]()
+	seen := map[string]bool{}
+	var names []string
+	for current := hub; current != nil; current = current.parent {
+		current.mu.RLock()
+		for key := range current.providers {
+			if key.t == t && key.name != "" && !seen[key.name] {
+				seen[key.name] = true
+				names = append(names, key.name)
+			}
+		}
+		current.mu.RUnlock()
 	}
-	return provider.(T)
+	sort.Strings(names)
+	return names
 }
 
 // Exists checks if a provider of a given type exists in the hub.
@@ -61,7 +160,126 @@ func Get[T any](hub *ProviderHub) T {
 // Returns:
 //   - True if the provider exists, false otherwise
 func Exists[T any](hub *ProviderHub) bool {
-	t := reflect.TypeOf((*T)(nil))
-	_, ok := hub.providers[t]
+	return ExistsType(hub, TypeOf[T]())
+}
+
+// ExistsType is Exists for a type key obtained with TypeOf
+func ExistsType(hub *ProviderHub, t reflect.Type) bool {
+	_, ok := hub.lookup(providerKey{t: t})
 	return ok
 }
+
+// GetType retrieves the provider registered under a type key obtained with
+// TypeOf, or nil when there is none
+func GetType(hub *ProviderHub, t reflect.Type) any {
+	provider, _ := hub.lookup(providerKey{t: t})
+	return provider
+}
+
+// RegisteredTypes returns the types with a default instance registered in
+// the hub itself, not its parents, in the order they were first registered
+func RegisteredTypes(hub *ProviderHub) []reflect.Type {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	return append([]reflect.Type(nil), hub.order...)
+}
+
+// Each calls visit for every default instance registered in the hub itself,
+// in registration order, with the first type it was registered under. A
+// provider registered under several types is visited once.
+func Each(hub *ProviderHub, visit func(t reflect.Type, provider any)) {
+	hub.mu.RLock()
+	types := append([]reflect.Type(nil), hub.order...)
+	providers := make([]any, len(types))
+	for i, t := range types {
+		providers[i] = hub.providers[providerKey{t: t}]
+	}
+	hub.mu.RUnlock()
+
+	var visited []any
+	for i, provider := range providers {
+		if ContainsInstance(visited, provider) {
+			continue
+		}
+		visited = append(visited, provider)
+		visit(types[i], provider)
+	}
+}
+
+// HealthCheck runs the health check of every registered provider that
+// implements HealthCheckedProvider and returns the failures by provider type.
+// A provider registered under several types is checked once.
+func HealthCheck(ctx context.Context, hub *ProviderHub) map[string]error {
+	failures := map[string]error{}
+	Each(hub, func(t reflect.Type, provider any) {
+		checker, ok := provider.(HealthCheckedProvider)
+		if !ok {
+			return
+		}
+		if err := checker.HealthCheck(ctx); err != nil {
+			failures[TypeName(t)] = err
+		}
+	})
+	return failures
+}
+
+// TypeOf returns the key T is registered under in the hub
+func TypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil))
+}
+
+// TypeName returns the readable name of a hub key, such as
+// "domain.DatabaseProvider"
+func TypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem().String()
+	}
+	return t.String()
+}
+
+// lookup finds key in the hub, then in its parents
+func (h *ProviderHub) lookup(key providerKey) (any, bool) {
+	for current := h; current != nil; current = current.parent {
+		current.mu.RLock()
+		provider, ok := current.providers[key]
+		current.mu.RUnlock()
+		if ok {
+			return provider, true
+		}
+	}
+	return nil, false
+}
+
+// registeredNames returns the sorted names of the types with a default
+// instance in the hub or its parents
+func (h *ProviderHub) registeredNames() []string {
+	seen := map[reflect.Type]bool{}
+	var names []string
+	for current := h; current != nil; current = current.parent {
+		current.mu.RLock()
+		for _, t := range current.order {
+			if !seen[t] {
+				seen[t] = true
+				names = append(names, TypeName(t))
+			}
+		}
+		current.mu.RUnlock()
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ContainsInstance reports whether instance is already in instances, only
+// comparing the values whose type supports it. A nil instance matches a nil
+// entry.
+func ContainsInstance(instances []any, instance any) bool {
+	if t := reflect.TypeOf(instance); t != nil && !t.Comparable() {
+		return false
+	}
+	for _, seen := range instances {
+		if reflect.TypeOf(seen) == reflect.TypeOf(instance) && seen == instance {
+			return true
+		}
+	}
+	return false
+}
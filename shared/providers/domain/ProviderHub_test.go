@@ -0,0 +1,87 @@
+package domain_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/r0x16/Raidark/shared/providers/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+type clock interface{ Now() string }
+type cache interface{ Get(key string) string }
+type queue interface{ Push(item string) }
+
+type fixedClock struct{}
+
+func (fixedClock) Now() string { return "noon" }
+
+func TestProviderHub_reportsMissingProvidersAndIsSafeForConcurrentUse(t *testing.T) {
+	hub := &domain.ProviderHub{}
+	domain.Register[clock](hub, fixedClock{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			domain.Register[queue](hub, nil)
+		}()
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "noon", domain.Get[clock](hub).Now())
+		}()
+	}
+	wg.Wait()
+
+	assert.PanicsWithError(t, "provider domain_test.cache not found in hub; registered providers: domain_test.clock, domain_test.queue", func() {
+		domain.Get[cache](hub)
+	})
+}
+
+func TestProviderHub_keepsNamedInstancesNextToTheDefault(t *testing.T) {
+	hub := &domain.ProviderHub{}
+	domain.Register[clock](hub, fixedClock{})
+	domain.RegisterNamed[clock](hub, "utc", utcClock{})
+	domain.RegisterNamed[clock](hub, "local", fixedClock{})
+
+	assert.Equal(t, "noon", domain.Get[clock](hub).Now())
+	assert.Equal(t, "12:00Z", domain.GetNamed[clock](hub, "utc").Now())
+	assert.True(t, domain.ExistsNamed[clock](hub, "local"))
+	assert.False(t, domain.ExistsNamed[clock](hub, "mars"))
+	assert.Equal(t, []string{"local", "utc"}, domain.Names[clock](hub))
+	assert.PanicsWithError(t, `provider domain_test.clock named "mars" not found in hub; registered names: local, utc`, func() {
+		domain.GetNamed[clock](hub, "mars")
+	})
+}
+
+func TestProviderHub_scopesFallBackToTheirParent(t *testing.T) {
+	hub := &domain.ProviderHub{}
+	domain.Register[clock](hub, fixedClock{})
+	domain.RegisterNamed[clock](hub, "utc", utcClock{})
+
+	scope := hub.Scope()
+	domain.Register[clock](scope, utcClock{})
+	domain.Register[queue](scope, nil)
+
+	assert.Equal(t, "12:00Z", domain.Get[clock](scope).Now())
+	assert.Equal(t, "12:00Z", domain.GetNamed[clock](scope, "utc").Now())
+	assert.True(t, domain.Exists[queue](scope))
+	assert.Equal(t, "noon", domain.Get[clock](hub).Now())
+	assert.False(t, domain.Exists[queue](hub))
+}
+
+type utcClock struct{}
+
+func (utcClock) Now() string { return "12:00Z" }
+
+func TestContainsInstance_comparesOnlyComparableValues(t *testing.T) {
+	counter := &struct{ n int }{}
+	instances := []any{counter, map[string]int{}, nil}
+
+	assert.True(t, domain.ContainsInstance(instances, counter))
+	assert.False(t, domain.ContainsInstance(instances, &struct{ n int }{}))
+	assert.False(t, domain.ContainsInstance(instances, map[string]int{}))
+	assert.True(t, domain.ContainsInstance(instances, nil))
+	assert.False(t, domain.ContainsInstance(instances[:1], nil))
+}
@@ -1,12 +1,18 @@
 package driver
 
 import (
+	"reflect"
+
 	domapi "github.com/r0x16/Raidark/shared/api/domain"
 	driverapi "github.com/r0x16/Raidark/shared/api/driver"
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
 	"github.com/r0x16/Raidark/shared/providers/domain"
 )
 
+var _ domain.DependentProviderFactory = &ApiProviderFactory{}
+
 type ApiProviderFactory struct {
 	env domenv.EnvProvider
 }
@@ -15,6 +21,20 @@ func (f *ApiProviderFactory) Init(hub *domain.ProviderHub) {
 	f.env = domain.Get[domenv.EnvProvider](hub)
 }
 
+// Provides implements domain.DependentProviderFactory
+func (f *ApiProviderFactory) Provides() []reflect.Type {
+	return []reflect.Type{domain.TypeOf[domapi.ApiProvider]()}
+}
+
+// DependsOn implements domain.DependentProviderFactory
+func (f *ApiProviderFactory) DependsOn() []domain.Dependency {
+	return []domain.Dependency{
+		domain.Requires[domenv.EnvProvider](),
+		domain.Requires[domlogger.LogProvider](),
+		domain.Uses[obsdomain.MetricsProvider](),
+	}
+}
+
 func (f *ApiProviderFactory) Register(hub *domain.ProviderHub) error {
 	provider := f.getProvider(hub)
 	err := provider.Setup()
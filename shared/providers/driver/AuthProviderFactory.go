@@ -2,6 +2,8 @@ package driver
 
 import (
 	"fmt"
+	"reflect"
+	"time"
 
 	domauth "github.com/r0x16/Raidark/shared/auth/domain"
 	driverauth "github.com/r0x16/Raidark/shared/auth/driver"
@@ -10,6 +12,8 @@ import (
 	"github.com/r0x16/Raidark/shared/providers/domain"
 )
 
+var _ domain.DependentProviderFactory = &AuthProviderFactory{}
+
 type AuthProviderFactory struct {
 	env domenv.EnvProvider
 	log domlogger.LogProvider
@@ -20,6 +24,19 @@ func (f *AuthProviderFactory) Init(hub *domain.ProviderHub) {
 	f.log = domain.Get[domlogger.LogProvider](hub)
 }
 
+// Provides implements domain.DependentProviderFactory
+func (f *AuthProviderFactory) Provides() []reflect.Type {
+	return []reflect.Type{domain.TypeOf[domauth.AuthProvider](), domain.TypeOf[domauth.Authorizer]()}
+}
+
+// DependsOn implements domain.DependentProviderFactory
+func (f *AuthProviderFactory) DependsOn() []domain.Dependency {
+	return []domain.Dependency{
+		domain.Requires[domenv.EnvProvider](),
+		domain.Requires[domlogger.LogProvider](),
+	}
+}
+
 func (f *AuthProviderFactory) Register(hub *domain.ProviderHub) error {
 	f.log.Info("Attempting to register AuthProvider", nil)
 
@@ -50,6 +67,16 @@ func (f *AuthProviderFactory) Register(hub *domain.ProviderHub) error {
 	domain.Register(hub, provider)
 
 	f.log.Info("AuthProvider successfully registered in hub", nil)
+
+	authorizer, err := f.getAuthorizer(authType, provider)
+	if err != nil {
+		f.log.Error("Failed to create Authorizer", map[string]any{
+			"error": err.Error(),
+		})
+		return fmt.Errorf("failed to create Authorizer: %w", err)
+	}
+	domain.Register(hub, authorizer)
+	f.log.Info("Authorizer successfully registered in hub", nil)
 	return nil
 }
 
@@ -57,9 +84,39 @@ func (f *AuthProviderFactory) getProvider(authType string) (domauth.AuthProvider
 	switch authType {
 	case "casdoor":
 		return driverauth.NewCasdoorAuthProviderFromEnv(), nil
+	case "oidc":
+		return driverauth.NewOIDCAuthProviderFromEnv(), nil
 	case "array":
-		return driverauth.NewArrayAuthProvider(), nil
+		return driverauth.NewArrayAuthProviderFromEnv(), nil
 	default:
 		return nil, fmt.Errorf("unsupported auth provider type: %s", authType)
 	}
 }
+
+// getAuthorizer pairs the provider with its Authorizer: Casdoor permissions for
+// casdoor, the grants of AUTHZ_GRANTS_FILE when set, the admin-only dev policy
+// for array. Any other provider denies every guarded request.
+func (f *AuthProviderFactory) getAuthorizer(authType string, provider domauth.AuthProvider) (domauth.Authorizer, error) {
+	if casdoor, ok := provider.(*driverauth.CasdoorAuthProvider); ok {
+		ttl := time.Duration(f.env.GetInt("AUTHZ_CACHE_TTL_SECONDS", 60)) * time.Second
+		maxStale := time.Duration(f.env.GetInt("AUTHZ_MAX_STALENESS_SECONDS", int(driverauth.DefaultPermissionsMaxStaleness.Seconds()))) * time.Second
+		return driverauth.NewCasdoorAuthorizer(casdoor, ttl).WithMaxStaleness(maxStale).WithLogProvider(f.log), nil
+	}
+
+	if path := f.env.GetString("AUTHZ_GRANTS_FILE", ""); path != "" {
+		grants, err := driverauth.LoadStaticGrants(path)
+		if err != nil {
+			return nil, fmt.Errorf("AUTHZ_GRANTS_FILE: %w", err)
+		}
+		return driverauth.NewStaticAuthorizer(grants...), nil
+	}
+
+	if authType == "array" {
+		return driverauth.NewStaticAuthorizer(driverauth.DefaultArrayGrants()...), nil
+	}
+
+	f.log.Warning("No AUTHZ_GRANTS_FILE set, guarded requests will be denied", map[string]any{
+		"type": authType,
+	})
+	return driverauth.NewStaticAuthorizer(), nil
+}
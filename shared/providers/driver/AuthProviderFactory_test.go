@@ -0,0 +1,87 @@
+package driver_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	domauth "github.com/r0x16/Raidark/shared/auth/domain"
+	envdomain "github.com/r0x16/Raidark/shared/env/domain"
+	"github.com/r0x16/Raidark/shared/internal/testutil/oidc"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	providerdomain "github.com/r0x16/Raidark/shared/providers/domain"
+	providerdriver "github.com/r0x16/Raidark/shared/providers/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthProviderFactory_arrayGetsTheDevPolicy(t *testing.T) {
+	authorizer := registerAuth(t, map[string]string{"AUTH_PROVIDER_TYPE": "array"})
+
+	assertAllowed(t, authorizer, "admin", "orders", "delete", true)
+}
+
+func TestAuthProviderFactory_oidcDeniesWithoutGrantsFile(t *testing.T) {
+	useOIDCIssuer(t)
+	authorizer := registerAuth(t, map[string]string{"AUTH_PROVIDER_TYPE": "oidc"})
+
+	assertAllowed(t, authorizer, "admin", "orders", "read", false)
+}
+
+func TestAuthProviderFactory_oidcUsesTheGrantsFile(t *testing.T) {
+	useOIDCIssuer(t)
+	authorizer := registerAuth(t, map[string]string{
+		"AUTH_PROVIDER_TYPE": "oidc",
+		"AUTHZ_GRANTS_FILE":  "testdata/grants.yaml",
+	})
+
+	assertAllowed(t, authorizer, "clerk", "orders", "read", true)
+	assertAllowed(t, authorizer, "clerk", "orders", "write", false)
+	assertAllowed(t, authorizer, "admin", "orders", "read", false)
+}
+
+func TestAuthProviderFactory_rejectsMissingGrantsFile(t *testing.T) {
+	hub := newAuthHub(map[string]string{
+		"AUTH_PROVIDER_TYPE": "array",
+		"AUTHZ_GRANTS_FILE":  "testdata/missing.yaml",
+	})
+	factory := &providerdriver.AuthProviderFactory{}
+	factory.Init(hub)
+
+	err := factory.Register(hub)
+
+	assert.ErrorContains(t, err, "AUTHZ_GRANTS_FILE")
+	assert.False(t, providerdomain.Exists[domauth.Authorizer](hub))
+}
+
+func registerAuth(t *testing.T, strings map[string]string) domauth.Authorizer {
+	t.Helper()
+	hub := newAuthHub(strings)
+	factory := &providerdriver.AuthProviderFactory{}
+	factory.Init(hub)
+	require.NoError(t, factory.Register(hub))
+	return providerdomain.Get[domauth.Authorizer](hub)
+}
+
+func newAuthHub(strings map[string]string) *providerdomain.ProviderHub {
+	hub := &providerdomain.ProviderHub{}
+	providerdomain.Register[envdomain.EnvProvider](hub, mapEnvProvider{strings: strings})
+	providerdomain.Register[domlogger.LogProvider](hub, obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical))
+	return hub
+}
+
+// useOIDCIssuer points the OIDC provider, which reads its own env, to a local issuer
+func useOIDCIssuer(t *testing.T) {
+	issuer := oidc.NewIssuer(t)
+	t.Setenv("OIDC_ISSUER_URL", issuer.URL)
+	t.Setenv("OIDC_CLIENT_ID", "raidark")
+}
+
+func assertAllowed(t *testing.T, authorizer domauth.Authorizer, role, resource, action string, want bool) {
+	t.Helper()
+	claims := &domauth.Claims{Username: "carol", Roles: []string{role}}
+	allowed, err := authorizer.Authorize(context.Background(), claims, resource, action)
+	require.NoError(t, err)
+	assert.Equal(t, want, allowed, "%s %s %s", role, resource, action)
+}
@@ -1,20 +1,48 @@
 package driver
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"reflect"
 
 	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
 	driverdatastore "github.com/r0x16/Raidark/shared/datastore/driver"
+	"github.com/r0x16/Raidark/shared/datastore/encryption"
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domlifecycle "github.com/r0x16/Raidark/shared/lifecycle/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
 	"github.com/r0x16/Raidark/shared/providers/domain"
 )
 
+var _ domain.DependentProviderFactory = &DatastoreProviderFactory{}
+
 type DatastoreProviderFactory struct {
-	env domenv.EnvProvider
+	env     domenv.EnvProvider
+	log     domlogger.LogProvider
+	metrics obsdomain.MetricsProvider
 }
 
 func (f *DatastoreProviderFactory) Init(hub *domain.ProviderHub) {
 	f.env = domain.Get[domenv.EnvProvider](hub)
+	f.log = domain.Get[domlogger.LogProvider](hub)
+	f.metrics, _ = domain.Lookup[obsdomain.MetricsProvider](hub)
+}
+
+// Provides implements domain.DependentProviderFactory
+func (f *DatastoreProviderFactory) Provides() []reflect.Type {
+	return []reflect.Type{domain.TypeOf[domdatastore.DatabaseProvider]()}
+}
+
+// DependsOn implements domain.DependentProviderFactory
+func (f *DatastoreProviderFactory) DependsOn() []domain.Dependency {
+	return []domain.Dependency{
+		domain.Requires[domenv.EnvProvider](),
+		domain.Requires[domlogger.LogProvider](),
+		domain.Uses[domlifecycle.Lifecycle](),
+		domain.Uses[obsdomain.MetricsProvider](),
+	}
 }
 
 /*
@@ -27,8 +55,15 @@ func (f *DatastoreProviderFactory) Init(hub *domain.ProviderHub) {
 
 * Supported execution engines: (default: gorm)
 * - Gorm
+
+* Reads are routed to the replicas listed in DATASTORE_REPLICA_HOSTS
+* (or DATASTORE_REPLICA_PATHS for sqlite) when set
  */
 func (f *DatastoreProviderFactory) Register(hub *domain.ProviderHub) error {
+	if err := f.configureEncryption(); err != nil {
+		return err
+	}
+
 	dbtype := f.env.GetString("DATASTORE_TYPE", "sqlite")
 	provider, err := f.getProvider(dbtype)
 
@@ -36,11 +71,54 @@ func (f *DatastoreProviderFactory) Register(hub *domain.ProviderHub) error {
 		return err
 	}
 
+	if err := f.registerPoolMetrics(provider); err != nil {
+		_ = provider.Close()
+		return err
+	}
+
 	domain.Register(hub, provider)
+	appendHook(hub, domlifecycle.Hook{
+		Name: "datastore",
+		OnStop: func(context.Context) error {
+			return provider.Close()
+		},
+	})
 
 	return nil
 }
 
+/*
+*
+
+	Install the keyring of the encrypted field serializer. Without
+	DATASTORE_ENCRYPTION_KEYS, encrypted fields are stored in plaintext.
+*/
+func (f *DatastoreProviderFactory) configureEncryption() error {
+	keyring, err := encryption.ParseKeyring(
+		f.env.GetString("DATASTORE_ENCRYPTION_KEYS", ""),
+		f.env.GetString("DATASTORE_ENCRYPTION_ACTIVE_KEY", ""),
+	)
+	if err != nil {
+		return fmt.Errorf("invalid DATASTORE_ENCRYPTION_KEYS: %w", err)
+	}
+	encryption.Use(keyring)
+	return nil
+}
+
+/*
+*
+
+	Export the connection pool statistics when the MetricsProvider is
+	registered
+*/
+func (f *DatastoreProviderFactory) registerPoolMetrics(provider domdatastore.DatabaseProvider) error {
+	instrumented, ok := provider.(driverdatastore.PoolInstrumented)
+	if f.metrics == nil || !ok {
+		return nil
+	}
+	return instrumented.RegisterPoolMetrics(f.metrics.Metrics().Registry)
+}
+
 /*
 *
 
@@ -65,6 +143,7 @@ func (f *DatastoreProviderFactory) getProvider(dbtype string) (domdatastore.Data
 */
 func (f *DatastoreProviderFactory) providesPostgres() (domdatastore.DatabaseProvider, error) {
 	connection := driverdatastore.NewGormPostgresDatabaseProvider(f.env)
+	connection.LogProvider = f.log
 	err := connection.Connect()
 
 	if err != nil {
@@ -81,6 +160,7 @@ func (f *DatastoreProviderFactory) providesPostgres() (domdatastore.DatabaseProv
 */
 func (f *DatastoreProviderFactory) providesMysql() (domdatastore.DatabaseProvider, error) {
 	connection := driverdatastore.NewGormMysqlDatabaseProvider(f.env)
+	connection.LogProvider = f.log
 	err := connection.Connect()
 
 	if err != nil {
@@ -97,6 +177,7 @@ func (f *DatastoreProviderFactory) providesMysql() (domdatastore.DatabaseProvide
 */
 func (f *DatastoreProviderFactory) providesSqlite() (domdatastore.DatabaseProvider, error) {
 	connection := driverdatastore.NewGormSqliteDatabaseProvider(f.env)
+	connection.LogProvider = f.log
 	err := connection.Connect()
 
 	if err != nil {
@@ -1,12 +1,19 @@
 package driver
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"time"
 
+	domdatastore "github.com/r0x16/Raidark/shared/datastore/domain"
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
 	domevents "github.com/r0x16/Raidark/shared/events/domain"
 	driverevents "github.com/r0x16/Raidark/shared/events/driver"
+	domlifecycle "github.com/r0x16/Raidark/shared/lifecycle/domain"
 	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
 	"github.com/r0x16/Raidark/shared/providers/domain"
 )
 
@@ -15,13 +22,29 @@ type DomainEventFactory struct {
 	logProvider domlogger.LogProvider
 }
 
-var _ domain.ProviderFactory = &DomainEventFactory{}
+var _ domain.DependentProviderFactory = &DomainEventFactory{}
 
 func (f *DomainEventFactory) Init(hub *domain.ProviderHub) {
 	f.envProvider = domain.Get[domenv.EnvProvider](hub)
 	f.logProvider = domain.Get[domlogger.LogProvider](hub)
 }
 
+// Provides implements domain.DependentProviderFactory
+func (f *DomainEventFactory) Provides() []reflect.Type {
+	return []reflect.Type{domain.TypeOf[domevents.DomainEventsProvider]()}
+}
+
+// DependsOn implements domain.DependentProviderFactory
+func (f *DomainEventFactory) DependsOn() []domain.Dependency {
+	return []domain.Dependency{
+		domain.Requires[domenv.EnvProvider](),
+		domain.Requires[domlogger.LogProvider](),
+		domain.Uses[obsdomain.MetricsProvider](),
+		domain.Uses[domdatastore.DatabaseProvider](),
+		domain.Uses[domlifecycle.Lifecycle](),
+	}
+}
+
 func (f *DomainEventFactory) Register(hub *domain.ProviderHub) error {
 	domainEventProviderType := f.envProvider.GetString("DOMAIN_EVENT_PROVIDER_TYPE", "in-memory")
 	provider, err := f.getProvider(domainEventProviderType, hub)
@@ -30,16 +53,45 @@ func (f *DomainEventFactory) Register(hub *domain.ProviderHub) error {
 	}
 	domain.Register(hub, provider)
 	provider.Collect()
+	appendHook(hub, domlifecycle.Hook{
+		Name: "domain events",
+		OnStop: func(ctx context.Context) error {
+			return closeEvents(ctx, provider)
+		},
+	})
 	return nil
 }
 
+// closeEvents lets the in-memory events finish within ctx and closes the
+// provider, even when the drain runs out of time
+func closeEvents(ctx context.Context, provider domevents.DomainEventsProvider) error {
+	var drainErr error
+	if drainable, ok := provider.(domevents.DrainableEventsProvider); ok {
+		drainErr = drainable.Drain(ctx)
+	}
+	return errors.Join(drainErr, provider.Close())
+}
+
 func (f *DomainEventFactory) getProvider(providerType string, hub *domain.ProviderHub) (domevents.DomainEventsProvider, error) {
 	switch providerType {
 	case "in-memory":
-		bufferSize := f.envProvider.GetInt("DOMAIN_EVENT_BUFFER_SIZE", 100)
-		workers := f.envProvider.GetInt("DOMAIN_EVENT_WORKERS", 8)
-		provider := driverevents.NewInMemoryDomainEventsProvider(bufferSize, workers, hub)
+		provider := driverevents.NewInMemoryDomainEventsProvider(f.inMemoryConfig(), hub)
 		return provider, nil
+	case "outbox":
+		config, err := f.outboxConfig()
+		if err != nil {
+			return nil, err
+		}
+		if !domain.Exists[domdatastore.DatabaseProvider](hub) {
+			return nil, errors.New("outbox domain event provider requires a DatabaseProvider registered before DomainEventFactory")
+		}
+		return driverevents.NewOutboxDomainEventsProvider(config, hub), nil
+	case "nats-jetstream":
+		config, err := f.jetStreamConfig()
+		if err != nil {
+			return nil, err
+		}
+		return driverevents.NewJetStreamDomainEventsProvider(config, hub)
 	}
 
 	f.logProvider.Error("invalid domain event provider type", map[string]any{
@@ -47,3 +99,104 @@ func (f *DomainEventFactory) getProvider(providerType string, hub *domain.Provid
 	})
 	return nil, errors.New("invalid domain event provider type: " + providerType)
 }
+
+// inMemoryConfig reads the queue and listener policy settings of the
+// in-memory provider
+func (f *DomainEventFactory) inMemoryConfig() driverevents.InMemoryConfig {
+	return driverevents.InMemoryConfig{
+		BufferSize: f.envProvider.GetInt("DOMAIN_EVENT_BUFFER_SIZE", 100),
+		Workers:    f.envProvider.GetInt("DOMAIN_EVENT_WORKERS", 8),
+		Policy: domevents.ListenerPolicy{
+			Timeout:         time.Duration(f.envProvider.GetInt("DOMAIN_EVENT_HANDLER_TIMEOUT_MS", 30000)) * time.Millisecond,
+			MaxAttempts:     f.envProvider.GetInt("DOMAIN_EVENT_MAX_ATTEMPTS", 3),
+			RetryBackoff:    time.Duration(f.envProvider.GetInt("DOMAIN_EVENT_RETRY_BACKOFF_MS", 200)) * time.Millisecond,
+			MaxRetryBackoff: time.Duration(f.envProvider.GetInt("DOMAIN_EVENT_RETRY_BACKOFF_MAX_MS", 5000)) * time.Millisecond,
+		},
+	}
+}
+
+// outboxConfig reads the relay settings of the outbox provider and rejects
+// the values the relay cannot run with
+func (f *DomainEventFactory) outboxConfig() (driverevents.OutboxConfig, error) {
+	config := driverevents.OutboxConfig{
+		PollInterval:    time.Duration(f.envProvider.GetInt("OUTBOX_POLL_INTERVAL_MS", 500)) * time.Millisecond,
+		BatchSize:       f.envProvider.GetInt("OUTBOX_BATCH_SIZE", 100),
+		MaxAttempts:     f.envProvider.GetInt("OUTBOX_MAX_ATTEMPTS", 20),
+		Partitions:      f.envProvider.GetInt("OUTBOX_AGGREGATE_PARTITIONS", 8),
+		RetryBackoff:    time.Duration(f.envProvider.GetInt("OUTBOX_RETRY_BACKOFF_MS", 1000)) * time.Millisecond,
+		MaxRetryBackoff: time.Duration(f.envProvider.GetInt("OUTBOX_RETRY_BACKOFF_MAX_MS", 60000)) * time.Millisecond,
+		ClaimTimeout:    time.Duration(f.envProvider.GetInt("OUTBOX_CLAIM_TIMEOUT_MS", 30000)) * time.Millisecond,
+
+		HealthMaxPending: f.envProvider.GetInt("OUTBOX_HEALTH_MAX_PENDING", 10000),
+		HealthMaxFailed:  f.envProvider.GetInt("OUTBOX_HEALTH_MAX_FAILED", 0),
+	}
+
+	positive := []struct {
+		name  string
+		value int64
+	}{
+		{"OUTBOX_POLL_INTERVAL_MS", int64(config.PollInterval)},
+		{"OUTBOX_BATCH_SIZE", int64(config.BatchSize)},
+		{"OUTBOX_MAX_ATTEMPTS", int64(config.MaxAttempts)},
+		{"OUTBOX_AGGREGATE_PARTITIONS", int64(config.Partitions)},
+		{"OUTBOX_RETRY_BACKOFF_MS", int64(config.RetryBackoff)},
+		{"OUTBOX_CLAIM_TIMEOUT_MS", int64(config.ClaimTimeout)},
+	}
+	for _, setting := range positive {
+		if setting.value <= 0 {
+			return driverevents.OutboxConfig{}, fmt.Errorf("%s must be positive", setting.name)
+		}
+	}
+	if config.MaxRetryBackoff < config.RetryBackoff {
+		return driverevents.OutboxConfig{}, errors.New("OUTBOX_RETRY_BACKOFF_MAX_MS must not be lower than OUTBOX_RETRY_BACKOFF_MS")
+	}
+	if config.HealthMaxPending < 0 {
+		return driverevents.OutboxConfig{}, errors.New("OUTBOX_HEALTH_MAX_PENDING must not be negative")
+	}
+	if config.HealthMaxFailed < 0 {
+		return driverevents.OutboxConfig{}, errors.New("OUTBOX_HEALTH_MAX_FAILED must not be negative")
+	}
+	return config, nil
+}
+
+// jetStreamConfig reads the connection and delivery settings of the
+// JetStream provider and rejects the values the consumers cannot run with
+func (f *DomainEventFactory) jetStreamConfig() (driverevents.JetStreamConfig, error) {
+	config := driverevents.JetStreamConfig{
+		URL:                     f.envProvider.GetString("NATS_URL", "nats://127.0.0.1:4222"),
+		Stream:                  f.envProvider.GetString("NATS_JETSTREAM_STREAM", "RAIDARK_EVENTS"),
+		SubjectPrefix:           f.envProvider.GetString("NATS_JETSTREAM_SUBJECT_PREFIX", "raidark.events"),
+		ConsumerPrefix:          f.envProvider.GetString("NATS_JETSTREAM_CONSUMER_PREFIX", "raidark"),
+		DuplicateWindow:         time.Duration(f.envProvider.GetInt("NATS_JETSTREAM_DEDUP_WINDOW_SECONDS", 120)) * time.Second,
+		AckWait:                 time.Duration(f.envProvider.GetInt("NATS_JETSTREAM_ACK_WAIT_MS", 30000)) * time.Millisecond,
+		MaxDeliver:              f.envProvider.GetInt("NATS_JETSTREAM_MAX_DELIVER", 10),
+		RetryBackoff:            time.Duration(f.envProvider.GetInt("NATS_JETSTREAM_BACKOFF_MS", 1000)) * time.Millisecond,
+		MaxRetryBackoff:         time.Duration(f.envProvider.GetInt("NATS_JETSTREAM_BACKOFF_MAX_MS", 60000)) * time.Millisecond,
+		DeadLetterStream:        f.envProvider.GetString("NATS_JETSTREAM_DLQ_STREAM", "RAIDARK_EVENTS_DLQ"),
+		DeadLetterSubjectPrefix: f.envProvider.GetString("NATS_JETSTREAM_DLQ_SUBJECT_PREFIX", "raidark.dlq"),
+		PublishTimeout:          time.Duration(f.envProvider.GetInt("NATS_JETSTREAM_PUBLISH_TIMEOUT_MS", 5000)) * time.Millisecond,
+		HealthMaxPending:        f.envProvider.GetInt("NATS_JETSTREAM_HEALTH_MAX_PENDING", 10000),
+	}
+
+	positive := []struct {
+		name  string
+		value int64
+	}{
+		{"NATS_JETSTREAM_ACK_WAIT_MS", int64(config.AckWait)},
+		{"NATS_JETSTREAM_MAX_DELIVER", int64(config.MaxDeliver)},
+		{"NATS_JETSTREAM_BACKOFF_MS", int64(config.RetryBackoff)},
+		{"NATS_JETSTREAM_PUBLISH_TIMEOUT_MS", int64(config.PublishTimeout)},
+	}
+	for _, setting := range positive {
+		if setting.value <= 0 {
+			return driverevents.JetStreamConfig{}, fmt.Errorf("%s must be positive", setting.name)
+		}
+	}
+	if config.MaxRetryBackoff < config.RetryBackoff {
+		return driverevents.JetStreamConfig{}, errors.New("NATS_JETSTREAM_BACKOFF_MAX_MS must not be lower than NATS_JETSTREAM_BACKOFF_MS")
+	}
+	if config.HealthMaxPending < 0 {
+		return driverevents.JetStreamConfig{}, errors.New("NATS_JETSTREAM_HEALTH_MAX_PENDING must not be negative")
+	}
+	return config, nil
+}
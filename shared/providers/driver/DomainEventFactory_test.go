@@ -0,0 +1,72 @@
+package driver_test
+
+import (
+	"io"
+	"testing"
+
+	envdomain "github.com/r0x16/Raidark/shared/env/domain"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	providerdomain "github.com/r0x16/Raidark/shared/providers/domain"
+	providerdriver "github.com/r0x16/Raidark/shared/providers/driver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainEventFactory_RejectsInvalidJetStreamSettings(t *testing.T) {
+	tests := map[string]struct {
+		ints map[string]int
+		want string
+	}{
+		"ack-wait":        {ints: map[string]int{"NATS_JETSTREAM_ACK_WAIT_MS": 0}, want: "NATS_JETSTREAM_ACK_WAIT_MS must be positive"},
+		"max-deliver":     {ints: map[string]int{"NATS_JETSTREAM_MAX_DELIVER": 0}, want: "NATS_JETSTREAM_MAX_DELIVER must be positive"},
+		"backoff":         {ints: map[string]int{"NATS_JETSTREAM_BACKOFF_MS": 0}, want: "NATS_JETSTREAM_BACKOFF_MS must be positive"},
+		"publish-timeout": {ints: map[string]int{"NATS_JETSTREAM_PUBLISH_TIMEOUT_MS": -1}, want: "NATS_JETSTREAM_PUBLISH_TIMEOUT_MS must be positive"},
+		"backoff-ceiling": {ints: map[string]int{"NATS_JETSTREAM_BACKOFF_MAX_MS": 10}, want: "NATS_JETSTREAM_BACKOFF_MAX_MS must not be lower than NATS_JETSTREAM_BACKOFF_MS"},
+		"health-pending":  {ints: map[string]int{"NATS_JETSTREAM_HEALTH_MAX_PENDING": -1}, want: "NATS_JETSTREAM_HEALTH_MAX_PENDING must not be negative"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			hub := &providerdomain.ProviderHub{}
+			providerdomain.Register[envdomain.EnvProvider](hub, mapEnvProvider{
+				strings: map[string]string{"DOMAIN_EVENT_PROVIDER_TYPE": "nats-jetstream"},
+				ints:    tt.ints,
+			})
+			providerdomain.Register[domlogger.LogProvider](hub, obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical))
+			factory := &providerdriver.DomainEventFactory{}
+			factory.Init(hub)
+
+			assert.EqualError(t, factory.Register(hub), tt.want)
+		})
+	}
+}
+
+func TestDomainEventFactory_RejectsInvalidOutboxSettings(t *testing.T) {
+	tests := map[string]struct {
+		ints map[string]int
+		want string
+	}{
+		"poll-interval":   {ints: map[string]int{"OUTBOX_POLL_INTERVAL_MS": 0}, want: "OUTBOX_POLL_INTERVAL_MS must be positive"},
+		"batch-size":      {ints: map[string]int{"OUTBOX_BATCH_SIZE": -1}, want: "OUTBOX_BATCH_SIZE must be positive"},
+		"partitions":      {ints: map[string]int{"OUTBOX_AGGREGATE_PARTITIONS": 0}, want: "OUTBOX_AGGREGATE_PARTITIONS must be positive"},
+		"backoff":         {ints: map[string]int{"OUTBOX_RETRY_BACKOFF_MS": 0}, want: "OUTBOX_RETRY_BACKOFF_MS must be positive"},
+		"backoff-ceiling": {ints: map[string]int{"OUTBOX_RETRY_BACKOFF_MAX_MS": 10}, want: "OUTBOX_RETRY_BACKOFF_MAX_MS must not be lower than OUTBOX_RETRY_BACKOFF_MS"},
+		"health-pending":  {ints: map[string]int{"OUTBOX_HEALTH_MAX_PENDING": -1}, want: "OUTBOX_HEALTH_MAX_PENDING must not be negative"},
+		"health-failed":   {ints: map[string]int{"OUTBOX_HEALTH_MAX_FAILED": -1}, want: "OUTBOX_HEALTH_MAX_FAILED must not be negative"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			hub := &providerdomain.ProviderHub{}
+			providerdomain.Register[envdomain.EnvProvider](hub, mapEnvProvider{
+				strings: map[string]string{"DOMAIN_EVENT_PROVIDER_TYPE": "outbox"},
+				ints:    tt.ints,
+			})
+			providerdomain.Register[domlogger.LogProvider](hub, obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical))
+			factory := &providerdriver.DomainEventFactory{}
+			factory.Init(hub)
+
+			assert.EqualError(t, factory.Register(hub), tt.want)
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package driver
 
 import (
+	"reflect"
+
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
 	driverenv "github.com/r0x16/Raidark/shared/env/driver"
 	"github.com/r0x16/Raidark/shared/providers/domain"
 )
 
+var _ domain.DependentProviderFactory = &EnvProviderFactory{}
+
 type EnvProviderFactory struct {
 }
 
@@ -13,6 +17,16 @@ func (f *EnvProviderFactory) Init(hub *domain.ProviderHub) {
 	// EnvProvider doesn't depend on other providers - it's a base dependency
 }
 
+// Provides implements domain.DependentProviderFactory
+func (f *EnvProviderFactory) Provides() []reflect.Type {
+	return []reflect.Type{domain.TypeOf[domenv.EnvProvider]()}
+}
+
+// DependsOn implements domain.DependentProviderFactory
+func (f *EnvProviderFactory) DependsOn() []domain.Dependency {
+	return nil
+}
+
 func (f *EnvProviderFactory) Register(hub *domain.ProviderHub) error {
 	domain.Register(hub, f.getProvider())
 	return nil
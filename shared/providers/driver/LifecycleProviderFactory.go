@@ -0,0 +1,46 @@
+package driver
+
+import (
+	"reflect"
+
+	domlifecycle "github.com/r0x16/Raidark/shared/lifecycle/domain"
+	driverlifecycle "github.com/r0x16/Raidark/shared/lifecycle/driver"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	"github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// LifecycleProviderFactory registers the lifecycle that collects the start
+// and stop hooks of the providers registered after it
+var _ domain.DependentProviderFactory = &LifecycleProviderFactory{}
+
+type LifecycleProviderFactory struct {
+	log domlogger.LogProvider
+}
+
+func (f *LifecycleProviderFactory) Init(hub *domain.ProviderHub) {
+	f.log = domain.Get[domlogger.LogProvider](hub)
+}
+
+// Provides implements domain.DependentProviderFactory
+func (f *LifecycleProviderFactory) Provides() []reflect.Type {
+	return []reflect.Type{domain.TypeOf[domlifecycle.Lifecycle]()}
+}
+
+// DependsOn implements domain.DependentProviderFactory
+func (f *LifecycleProviderFactory) DependsOn() []domain.Dependency {
+	return []domain.Dependency{
+		domain.Requires[domlogger.LogProvider](),
+	}
+}
+
+func (f *LifecycleProviderFactory) Register(hub *domain.ProviderHub) error {
+	domain.Register[domlifecycle.Lifecycle](hub, driverlifecycle.NewLifecycleManager(f.log))
+	return nil
+}
+
+// appendHook adds hook to the hub's lifecycle, when there is one
+func appendHook(hub *domain.ProviderHub, hook domlifecycle.Hook) {
+	if domain.Exists[domlifecycle.Lifecycle](hub) {
+		domain.Get[domlifecycle.Lifecycle](hub).Append(hook)
+	}
+}
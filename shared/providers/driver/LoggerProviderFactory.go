@@ -2,6 +2,7 @@ package driver
 
 import (
 	"errors"
+	"reflect"
 
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
 	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
@@ -10,6 +11,8 @@ import (
 	"github.com/r0x16/Raidark/shared/providers/domain"
 )
 
+var _ domain.DependentProviderFactory = &LoggerProviderFactory{}
+
 type LoggerProviderFactory struct {
 	env domenv.EnvProvider
 }
@@ -18,6 +21,18 @@ func (f *LoggerProviderFactory) Init(hub *domain.ProviderHub) {
 	f.env = domain.Get[domenv.EnvProvider](hub)
 }
 
+// Provides implements domain.DependentProviderFactory
+func (f *LoggerProviderFactory) Provides() []reflect.Type {
+	return []reflect.Type{domain.TypeOf[domlogger.LogProvider]()}
+}
+
+// DependsOn implements domain.DependentProviderFactory
+func (f *LoggerProviderFactory) DependsOn() []domain.Dependency {
+	return []domain.Dependency{
+		domain.Requires[domenv.EnvProvider](),
+	}
+}
+
 func (f *LoggerProviderFactory) Register(hub *domain.ProviderHub) error {
 	loggerType := f.env.GetString("LOGGER_TYPE", "observability")
 	provider, err := f.getProvider(loggerType)
@@ -1,6 +1,8 @@
 package driver
 
 import (
+	"reflect"
+
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
 	"github.com/r0x16/Raidark/shared/observability"
 	obsdomain "github.com/r0x16/Raidark/shared/observability/domain"
@@ -18,6 +20,8 @@ import (
 // without rebuilding the binary. Consumers (EchoApiProvider, the
 // EchoMetricsModule) probe the hub with domprovider.Exists before using
 // the provider, so the absence is harmless.
+var _ domain.DependentProviderFactory = &MetricsProviderFactory{}
+
 type MetricsProviderFactory struct {
 	env domenv.EnvProvider
 }
@@ -29,6 +33,18 @@ func (f *MetricsProviderFactory) Init(hub *domain.ProviderHub) {
 	f.env = domain.Get[domenv.EnvProvider](hub)
 }
 
+// Provides implements domain.DependentProviderFactory
+func (f *MetricsProviderFactory) Provides() []reflect.Type {
+	return []reflect.Type{domain.TypeOf[obsdomain.MetricsProvider]()}
+}
+
+// DependsOn implements domain.DependentProviderFactory
+func (f *MetricsProviderFactory) DependsOn() []domain.Dependency {
+	return []domain.Dependency{
+		domain.Requires[domenv.EnvProvider](),
+	}
+}
+
 // Register builds the provider and stores it on the hub when metrics are
 // enabled. SERVICE_NAME is registered globally regardless of metrics state
 // because it is also consumed by the observability logger to stamp the
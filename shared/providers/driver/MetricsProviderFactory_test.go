@@ -52,6 +52,7 @@ func TestMetricsProviderFactory_SkipsProviderWhenMetricsDisabled(t *testing.T) {
 type mapEnvProvider struct {
 	strings map[string]string
 	bools   map[string]bool
+	ints    map[string]int
 }
 
 func (m mapEnvProvider) GetString(key, defaultValue string) string {
@@ -68,7 +69,12 @@ func (m mapEnvProvider) GetBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
-func (m mapEnvProvider) GetInt(_ string, defaultValue int) int { return defaultValue }
+func (m mapEnvProvider) GetInt(key string, defaultValue int) int {
+	if value, ok := m.ints[key]; ok {
+		return value
+	}
+	return defaultValue
+}
 func (m mapEnvProvider) GetFloat(_ string, defaultValue float64) float64 {
 	return defaultValue
 }
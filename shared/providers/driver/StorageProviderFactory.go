@@ -2,6 +2,7 @@ package driver
 
 import (
 	"fmt"
+	"reflect"
 
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
 	"github.com/r0x16/Raidark/shared/providers/domain"
@@ -10,7 +11,10 @@ import (
 )
 
 // StorageProviderFactory registers a StorageProvider in the provider hub.
-// The concrete driver is selected by STORAGE_DRIVER (default: "filesystem").
+// The concrete driver is selected by STORAGE_DRIVER: "filesystem" (default)
+// or "s3".
+var _ domain.DependentProviderFactory = &StorageProviderFactory{}
+
 type StorageProviderFactory struct {
 	env domenv.EnvProvider
 }
@@ -20,6 +24,18 @@ func (f *StorageProviderFactory) Init(hub *domain.ProviderHub) {
 	f.env = domain.Get[domenv.EnvProvider](hub)
 }
 
+// Provides implements domain.DependentProviderFactory
+func (f *StorageProviderFactory) Provides() []reflect.Type {
+	return []reflect.Type{domain.TypeOf[domstorage.StorageProvider]()}
+}
+
+// DependsOn implements domain.DependentProviderFactory
+func (f *StorageProviderFactory) DependsOn() []domain.Dependency {
+	return []domain.Dependency{
+		domain.Requires[domenv.EnvProvider](),
+	}
+}
+
 // Register implements domain.ProviderFactory.
 // It registers the StorageProvider interface type so that consumers can retrieve
 // the provider without knowing the concrete driver. EchoStorageModule type-asserts
@@ -34,6 +50,13 @@ func (f *StorageProviderFactory) Register(hub *domain.ProviderHub) error {
 		}
 		domain.Register[domstorage.StorageProvider](hub, p)
 		return nil
+	case "s3":
+		p, err := storagedriver.NewS3StorageProvider(f.env)
+		if err != nil {
+			return fmt.Errorf("storage: failed to initialize s3 driver: %w", err)
+		}
+		domain.Register[domstorage.StorageProvider](hub, p)
+		return nil
 	default:
 		return fmt.Errorf("storage: unsupported driver %q", driverName)
 	}
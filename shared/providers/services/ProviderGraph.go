@@ -0,0 +1,168 @@
+package providers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/r0x16/Raidark/shared/providers/domain"
+)
+
+// DependencyError reports every problem found in the factory declarations,
+// so a misconfigured application can be fixed in one pass
+type DependencyError struct {
+	Problems []string
+}
+
+func (e *DependencyError) Error() string {
+	return "provider dependencies cannot be resolved:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// factoryNode is a factory with its declarations and its position in the
+// list it was given in
+type factoryNode struct {
+	index     int
+	factory   domain.ProviderFactory
+	provides  []reflect.Type
+	dependsOn []domain.Dependency
+	declared  bool
+}
+
+func (n *factoryNode) name() string {
+	return factoryName(n.factory)
+}
+
+func factoryName(factory domain.ProviderFactory) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", factory), "*")
+}
+
+// sortFactories orders the factories so each one runs after the factories
+// providing its dependencies. Among independent factories the given order is
+// kept, and a factory that declares nothing runs after every factory listed
+// before it.
+func sortFactories(factories []domain.ProviderFactory) ([]*factoryNode, error) {
+	nodes := make([]*factoryNode, len(factories))
+	for i, factory := range factories {
+		nodes[i] = &factoryNode{index: i, factory: factory}
+		if dependent, ok := factory.(domain.DependentProviderFactory); ok {
+			nodes[i].declared = true
+			nodes[i].provides = dependent.Provides()
+			nodes[i].dependsOn = dependent.DependsOn()
+		}
+	}
+
+	var problems []string
+	providedBy := map[reflect.Type]*factoryNode{}
+	for _, node := range nodes {
+		for _, t := range node.provides {
+			if other, ok := providedBy[t]; ok {
+				problems = append(problems, fmt.Sprintf("%s is provided by both %s and %s", domain.TypeName(t), other.name(), node.name()))
+				continue
+			}
+			providedBy[t] = node
+		}
+	}
+
+	// edges[i] holds the nodes that must run after node i
+	edges := make([][]int, len(nodes))
+	inDegree := make([]int, len(nodes))
+	addEdge := func(from, to int) {
+		for _, existing := range edges[from] {
+			if existing == to {
+				return
+			}
+		}
+		edges[from] = append(edges[from], to)
+		inDegree[to]++
+	}
+	for _, node := range nodes {
+		if !node.declared {
+			for before := 0; before < node.index; before++ {
+				addEdge(before, node.index)
+			}
+			continue
+		}
+		for _, dependency := range node.dependsOn {
+			provider, ok := providedBy[dependency.Type]
+			if !ok {
+				if !dependency.Optional {
+					problems = append(problems, fmt.Sprintf("%s requires %s, which no factory provides", node.name(), domain.TypeName(dependency.Type)))
+				}
+				continue
+			}
+			if provider != node {
+				addEdge(provider.index, node.index)
+			}
+		}
+	}
+
+	sorted := make([]*factoryNode, 0, len(nodes))
+	done := make([]bool, len(nodes))
+	for len(sorted) < len(nodes) {
+		next := -1
+		for i := range nodes {
+			if !done[i] && inDegree[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			problems = append(problems, "dependency cycle: "+describeCycle(nodes, edges, done))
+			break
+		}
+		done[next] = true
+		sorted = append(sorted, nodes[next])
+		for _, to := range edges[next] {
+			inDegree[to]--
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &DependencyError{Problems: problems}
+	}
+	return sorted, nil
+}
+
+// describeCycle walks from an unsorted factory to a factory it depends on
+// until one repeats, and returns the loop as "A -> B -> A", read as "A
+// depends on B, which depends on A"
+func describeCycle(nodes []*factoryNode, edges [][]int, done []bool) string {
+	start := 0
+	for start < len(nodes) && done[start] {
+		start++
+	}
+
+	position := map[int]int{}
+	var path []int
+	for current := start; ; {
+		if at, seen := position[current]; seen {
+			names := make([]string, 0, len(path)-at+1)
+			for _, i := range path[at:] {
+				names = append(names, nodes[i].name())
+			}
+			names = append(names, nodes[current].name())
+			return strings.Join(names, " -> ")
+		}
+		position[current] = len(path)
+		path = append(path, current)
+		// Every unsorted node depends on another unsorted node
+		for from := range edges {
+			if done[from] {
+				continue
+			}
+			if containsIndex(edges[from], current) {
+				current = from
+				break
+			}
+		}
+	}
+}
+
+func containsIndex(indexes []int, index int) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,13 @@
 package providers
 
-import "github.com/r0x16/Raidark/shared/providers/domain"
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	domlifecycle "github.com/r0x16/Raidark/shared/lifecycle/domain"
+	"github.com/r0x16/Raidark/shared/providers/domain"
+)
 
 type ProviderHubFactory struct {
 	hub *domain.ProviderHub
@@ -12,10 +19,77 @@ func NewProviderHubFactory() *ProviderHubFactory {
 	}
 }
 
-func (f *ProviderHubFactory) Create(providers []domain.ProviderFactory) *domain.ProviderHub {
-	for _, provider := range providers {
-		provider.Init(f.hub)
-		provider.Register(f.hub)
+// Create runs the factories in dependency order and returns the hub. It fails
+// before running any factory when the declarations have missing
+// dependencies, duplicates or cycles, and stops at the first factory whose
+// Register fails. Providers implementing StartableProvider or
+// StoppableProvider get a lifecycle hook.
+func (f *ProviderHubFactory) Create(providers []domain.ProviderFactory) (*domain.ProviderHub, error) {
+	nodes, err := sortFactories(providers)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range nodes {
+		if missing := f.missingDependencies(node); len(missing) > 0 {
+			return nil, fmt.Errorf("%s requires %s, which was not registered", node.name(), strings.Join(missing, ", "))
+		}
+
+		before := f.registered()
+		node.factory.Init(f.hub)
+		if err := node.factory.Register(f.hub); err != nil {
+			return nil, fmt.Errorf("%s failed to register: %w", node.name(), err)
+		}
+		f.appendHooks(before)
+	}
+	return f.hub, nil
+}
+
+// missingDependencies returns the required dependencies of node that their
+// factory did not register
+func (f *ProviderHubFactory) missingDependencies(node *factoryNode) []string {
+	var missing []string
+	for _, dependency := range node.dependsOn {
+		if !dependency.Optional && !domain.ExistsType(f.hub, dependency.Type) {
+			missing = append(missing, domain.TypeName(dependency.Type))
+		}
+	}
+	return missing
+}
+
+// registered returns the set of provider types in the hub
+func (f *ProviderHubFactory) registered() map[reflect.Type]bool {
+	types := map[reflect.Type]bool{}
+	for _, t := range domain.RegisteredTypes(f.hub) {
+		types[t] = true
+	}
+	return types
+}
+
+// appendHooks adds a lifecycle hook for the providers registered since
+// before that can be started or stopped
+func (f *ProviderHubFactory) appendHooks(before map[reflect.Type]bool) {
+	lifecycle, ok := domain.Lookup[domlifecycle.Lifecycle](f.hub)
+	if !ok {
+		return
+	}
+
+	var hooked []any
+	for _, t := range domain.RegisteredTypes(f.hub) {
+		provider := domain.GetType(f.hub, t)
+		if before[t] || provider == lifecycle || domain.ContainsInstance(hooked, provider) {
+			continue
+		}
+		hook := domlifecycle.Hook{Name: domain.TypeName(t)}
+		if startable, ok := provider.(domain.StartableProvider); ok {
+			hook.OnStart = startable.Start
+		}
+		if stoppable, ok := provider.(domain.StoppableProvider); ok {
+			hook.OnStop = stoppable.Stop
+		}
+		if hook.OnStart != nil || hook.OnStop != nil {
+			hooked = append(hooked, provider)
+			lifecycle.Append(hook)
+		}
 	}
-	return f.hub
 }
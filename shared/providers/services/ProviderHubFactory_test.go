@@ -0,0 +1,177 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	domlifecycle "github.com/r0x16/Raidark/shared/lifecycle/domain"
+	driverlifecycle "github.com/r0x16/Raidark/shared/lifecycle/driver"
+	domlogger "github.com/r0x16/Raidark/shared/logger/domain"
+	obslog "github.com/r0x16/Raidark/shared/observability/log"
+	"github.com/r0x16/Raidark/shared/providers/domain"
+	providers "github.com/r0x16/Raidark/shared/providers/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock interface{ Now() string }
+type cache interface{ Get(key string) string }
+type queue interface{ Push(item string) }
+
+type fixedClock struct{}
+
+func (fixedClock) Now() string { return "noon" }
+
+type memoryCache struct {
+	calls *[]string
+}
+
+func (memoryCache) Get(string) string { return "" }
+
+func (c memoryCache) Start(context.Context) error {
+	*c.calls = append(*c.calls, "start cache")
+	return nil
+}
+
+func (c memoryCache) Stop(context.Context) error {
+	*c.calls = append(*c.calls, "stop cache")
+	return nil
+}
+
+func (memoryCache) HealthCheck(context.Context) error { return errors.New("evicting") }
+
+// testFactory declares the given types and records when it registers
+type testFactory struct {
+	name      string
+	provides  []reflect.Type
+	dependsOn []domain.Dependency
+	register  func(hub *domain.ProviderHub) error
+	ran       *[]string
+}
+
+func (f *testFactory) Init(*domain.ProviderHub) {}
+
+func (f *testFactory) Provides() []reflect.Type { return f.provides }
+
+func (f *testFactory) DependsOn() []domain.Dependency { return f.dependsOn }
+
+func (f *testFactory) Register(hub *domain.ProviderHub) error {
+	*f.ran = append(*f.ran, f.name)
+	if f.register != nil {
+		return f.register(hub)
+	}
+	return nil
+}
+
+func TestProviderHubFactory_runsFactoriesInDependencyOrder(t *testing.T) {
+	var ran []string
+	factories := []domain.ProviderFactory{
+		&testFactory{name: "queue", ran: &ran,
+			provides:  []reflect.Type{domain.TypeOf[queue]()},
+			dependsOn: []domain.Dependency{domain.Requires[cache](), domain.Uses[clock]()},
+		},
+		&testFactory{name: "cache", ran: &ran,
+			provides:  []reflect.Type{domain.TypeOf[cache]()},
+			dependsOn: []domain.Dependency{domain.Requires[clock]()},
+			register: func(hub *domain.ProviderHub) error {
+				domain.Register[cache](hub, memoryCache{calls: &[]string{}})
+				return nil
+			},
+		},
+		&testFactory{name: "clock", ran: &ran,
+			provides: []reflect.Type{domain.TypeOf[clock]()},
+			register: func(hub *domain.ProviderHub) error {
+				domain.Register[clock](hub, fixedClock{})
+				return nil
+			},
+		},
+	}
+
+	_, err := providers.NewProviderHubFactory().Create(factories)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"clock", "cache", "queue"}, ran)
+}
+
+func TestProviderHubFactory_reportsEveryDeclarationProblem(t *testing.T) {
+	var ran []string
+	factories := []domain.ProviderFactory{
+		&testFactory{name: "queue", ran: &ran,
+			provides:  []reflect.Type{domain.TypeOf[queue]()},
+			dependsOn: []domain.Dependency{domain.Requires[cache](), domain.Uses[domlogger.LogProvider]()},
+		},
+		&testFactory{name: "cache", ran: &ran,
+			provides:  []reflect.Type{domain.TypeOf[cache]()},
+			dependsOn: []domain.Dependency{domain.Requires[queue]()},
+		},
+		&testFactory{name: "clock", ran: &ran,
+			dependsOn: []domain.Dependency{domain.Requires[clock]()},
+		},
+	}
+
+	_, err := providers.NewProviderHubFactory().Create(factories)
+
+	var dependencyErr *providers.DependencyError
+	require.ErrorAs(t, err, &dependencyErr)
+	require.Len(t, dependencyErr.Problems, 2)
+	assert.Equal(t, "providers_test.testFactory requires providers_test.clock, which no factory provides", dependencyErr.Problems[0])
+	assert.True(t, strings.HasPrefix(dependencyErr.Problems[1], "dependency cycle: "), dependencyErr.Problems[1])
+	assert.Empty(t, ran)
+}
+
+func TestProviderHubFactory_stopsAtTheFirstFailedFactory(t *testing.T) {
+	var ran []string
+	factories := []domain.ProviderFactory{
+		&testFactory{name: "cache", ran: &ran,
+			provides: []reflect.Type{domain.TypeOf[cache]()},
+			register: func(*domain.ProviderHub) error { return errors.New("unreachable") },
+		},
+		&testFactory{name: "queue", ran: &ran,
+			provides:  []reflect.Type{domain.TypeOf[queue]()},
+			dependsOn: []domain.Dependency{domain.Requires[cache]()},
+		},
+		&testFactory{name: "clock", ran: &ran, provides: []reflect.Type{domain.TypeOf[clock]()}},
+	}
+
+	_, err := providers.NewProviderHubFactory().Create(factories)
+
+	assert.EqualError(t, err, "providers_test.testFactory failed to register: unreachable")
+	assert.Equal(t, []string{"cache"}, ran)
+}
+
+func TestProviderHubFactory_hooksProvidersIntoTheLifecycle(t *testing.T) {
+	var ran, calls []string
+	logger := obslog.NewWithWriter(io.Discard, obslog.FormatJSON, domlogger.Critical)
+	factories := []domain.ProviderFactory{
+		&testFactory{name: "lifecycle", ran: &ran,
+			provides: []reflect.Type{domain.TypeOf[domlifecycle.Lifecycle]()},
+			register: func(hub *domain.ProviderHub) error {
+				domain.Register[domlifecycle.Lifecycle](hub, driverlifecycle.NewLifecycleManager(logger))
+				return nil
+			},
+		},
+		&testFactory{name: "cache", ran: &ran,
+			provides:  []reflect.Type{domain.TypeOf[cache]()},
+			dependsOn: []domain.Dependency{domain.Uses[domlifecycle.Lifecycle]()},
+			register: func(hub *domain.ProviderHub) error {
+				domain.Register[cache](hub, memoryCache{calls: &calls})
+				return nil
+			},
+		},
+	}
+
+	hub, err := providers.NewProviderHubFactory().Create(factories)
+	require.NoError(t, err)
+
+	lifecycle := domain.Get[domlifecycle.Lifecycle](hub)
+	require.NoError(t, lifecycle.Start(context.Background()))
+	require.NoError(t, lifecycle.Stop(context.Background()))
+	assert.Equal(t, []string{"start cache", "stop cache"}, calls)
+
+	failures := domain.HealthCheck(context.Background(), hub)
+	assert.EqualError(t, failures["providers_test.cache"], "evicting")
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "errors"
+
+// ErrUnsupportedImage is wrapped by the errors the image pipeline returns for
+// content that is not a well-formed image in an accepted format, including
+// files that are also valid in another format (polyglots).
+var ErrUnsupportedImage = errors.New("storage: unsupported image")
+
+// ErrImageTooLarge is wrapped by the errors the image pipeline returns for an
+// image over its byte or pixel limit.
+var ErrImageTooLarge = errors.New("storage: image too large")
+
+// ImageVariant describes a resized rendition generated for every processed
+// image. Images are never upscaled.
+type ImageVariant struct {
+	// Name identifies the variant in the manifest and suffixes its usage
+	// segment through DeriveKey, e.g. "thumb" stores avatar variants under
+	// "{namespace}/avatar-thumb/...". It must not contain "/" or ".".
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+	// Crop fills the whole MaxWidth×MaxHeight box, cutting the centre of the
+	// image to its aspect ratio. Without Crop the image is fitted inside the
+	// box and keeps its own aspect ratio.
+	Crop bool
+}
+
+// ImageRendition is one stored rendition of a processed image.
+type ImageRendition struct {
+	Name        string `json:"name"`
+	Key         string `json:"key"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+}
+
+// ImageManifest lists the renditions stored for a processed image: the
+// sanitised original and one entry per configured variant, in configuration
+// order.
+type ImageManifest struct {
+	Original ImageRendition   `json:"original"`
+	Variants []ImageRendition `json:"variants"`
+}
+
+// Variant returns the rendition of the named variant.
+func (m ImageManifest) Variant(name string) (ImageRendition, bool) {
+	for _, rendition := range m.Variants {
+		if rendition.Name == name {
+			return rendition, true
+		}
+	}
+	return ImageRendition{}, false
+}
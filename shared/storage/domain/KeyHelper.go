@@ -100,6 +100,36 @@ func BuildKey(namespace, usage, ext string) (string, error) {
 	), nil
 }
 
+// DeriveKey returns the key of an object derived from key, such as a resized
+// image variant. The derived key keeps the namespace, date and UUID of key,
+// uses the usage "{usage}-{suffix}" and ends with ext, which follows the same
+// rules as in BuildKey:
+//
+//	users/avatars/2026/05/{uuid}.jpg  →  users/avatars-thumb/2026/05/{uuid}.png
+func DeriveKey(key, suffix, ext string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	if suffix == "" || strings.ContainsAny(suffix, "/.") {
+		return "", fmt.Errorf("storage: invalid derived key suffix %q", suffix)
+	}
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	segs, err := parseKeySegments(key)
+	if err != nil {
+		return "", err
+	}
+	uuidStem := strings.TrimSuffix(segs.uuidExt, filepath.Ext(segs.uuidExt))
+	return fmt.Sprintf(
+		"%s/%s-%s/%s/%s/%s%s",
+		segs.namespace, segs.usage, suffix,
+		segs.yearStr, segs.monthStr,
+		uuidStem, ext,
+	), nil
+}
+
 // parseKeySegments splits key on "/" and returns named segments.
 // Returns an error if the key does not have exactly keyPartCount parts.
 func parseKeySegments(key string) (keySegments, error) {
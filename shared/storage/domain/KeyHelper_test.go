@@ -67,3 +67,21 @@ func TestValidateKey_allowsLiteralDotsInsideSegmentNames(t *testing.T) {
 
 	assert.NoError(t, domain.ValidateKey(key))
 }
+
+// TestDeriveKey_keepsIdentityOfTheSourceKey fixes the derived key layout the
+// image variants rely on: same namespace, date and UUID, suffixed usage.
+func TestDeriveKey_keepsIdentityOfTheSourceKey(t *testing.T) {
+	id, err := ids.NewV7()
+	require.NoError(t, err)
+
+	derived, err := domain.DeriveKey("accounts/avatar/2026/05/"+id+".jpg", "thumb", "png")
+
+	require.NoError(t, err)
+	assert.Equal(t, "accounts/avatar-thumb/2026/05/"+id+".png", derived)
+	assert.NoError(t, domain.ValidateKey(derived))
+
+	_, err = domain.DeriveKey("accounts/avatar/2026/05/"+id+".jpg", "../x", ".png")
+	assert.Error(t, err)
+	_, err = domain.DeriveKey("accounts/avatar.jpg", "thumb", ".png")
+	assert.Error(t, err)
+}
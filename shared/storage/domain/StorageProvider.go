@@ -5,10 +5,21 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"time"
 )
 
+// ErrObjectNotFound is wrapped by the errors returned for a key that is not
+// stored under any visibility.
+var ErrObjectNotFound = errors.New("storage: key not found")
+
+// ErrInvalidCursor is wrapped by the errors List returns for a cursor that
+// was not produced by a previous List call.
+var ErrInvalidCursor = errors.New("storage: invalid list cursor")
+
 // StorageProvider is the single entry-point for all object storage operations.
 // Callers depend only on this interface; the concrete driver is injected at
 // bootstrap via StorageProviderFactory.
@@ -30,12 +41,43 @@ type StorageProvider interface {
 	// object without requiring authentication on each request.
 	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
 
+	// SignedURLWithOptions is SignedURL with control over the response, such
+	// as its Content-Disposition. The options are covered by the signature.
+	SignedURLWithOptions(ctx context.Context, key string, opts SignedURLOptions) (string, error)
+
 	// PublicURL returns the canonical public URL for an object stored with
 	// VisibilityPublic. The URL is not time-limited.
 	PublicURL(key string) string
 
 	// Exists reports whether the object identified by key is present in storage.
 	Exists(ctx context.Context, key string) (bool, error)
+
+	// Stat returns the object's metadata without transferring its content.
+	// A missing key returns an error wrapping ErrObjectNotFound.
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+
+	// List returns one page of the objects whose key starts with prefix, in
+	// key order and across both visibilities. cursor is empty for the first
+	// page and the NextCursor of the previous page afterwards; limit is
+	// bounded by rest.ClampLimit.
+	List(ctx context.Context, prefix, cursor string, limit int) (ObjectList, error)
+
+	// Copy duplicates the object at srcKey to dstKey, keeping its visibility,
+	// content type and metadata. An existing dstKey is overwritten.
+	Copy(ctx context.Context, srcKey, dstKey string) error
+
+	// Move renames the object at srcKey to dstKey, keeping its visibility,
+	// content type and metadata.
+	Move(ctx context.Context, srcKey, dstKey string) error
+
+	// SetVisibility makes an existing object public or private. Setting the
+	// visibility the object already has is a no-op.
+	SetVisibility(ctx context.Context, key string, v Visibility) error
+
+	// SignedUpload returns a time-limited form upload that lets a client send
+	// an object straight to storage within the limits of policy. A policy the
+	// driver cannot enforce returns an error wrapping ErrInvalidUploadPolicy.
+	SignedUpload(ctx context.Context, policy UploadPolicy) (UploadRequest, error)
 }
 
 // Visibility controls whether an object is served publicly or requires a
@@ -57,6 +99,39 @@ type PutOptions struct {
 	// Size is optional; supply it when known to allow drivers to pre-allocate
 	// or set Content-Length on upstream requests.
 	Size int64
+	// Metadata holds custom key/value pairs stored with the object. Keys are
+	// case-insensitive and returned in lower case; keep keys and values to
+	// printable ASCII so every driver can store them as HTTP headers.
+	Metadata map[string]string
+}
+
+// SignedURLOptions tunes a signed URL and the response it serves.
+type SignedURLOptions struct {
+	// TTL is how long the URL stays valid; 0 uses the driver default.
+	TTL time.Duration
+	// ContentDisposition is sent as the response's Content-Disposition
+	// header, e.g. the result of AttachmentDisposition. Empty leaves the
+	// header out, so browsers display the object inline.
+	ContentDisposition string
+}
+
+// AttachmentDisposition returns a Content-Disposition value that makes
+// browsers download the object under filename.
+func AttachmentDisposition(filename string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
+
+// ValidateContentDisposition checks that disposition is an inline or
+// attachment Content-Disposition value.
+func ValidateContentDisposition(disposition string) error {
+	kind, _, err := mime.ParseMediaType(disposition)
+	if err != nil {
+		return fmt.Errorf("storage: invalid content disposition %q: %w", disposition, err)
+	}
+	if kind != "inline" && kind != "attachment" {
+		return fmt.Errorf("storage: content disposition must be inline or attachment: %q", disposition)
+	}
+	return nil
 }
 
 // PutResult is returned after a successful Put.
@@ -74,4 +149,19 @@ type ObjectInfo struct {
 	SizeBytes   int64
 	ContentType string
 	ModifiedAt  time.Time
+	Visibility  Visibility
+	// ETag has the same form as PutResult.ETag. It may be empty for objects
+	// written by the filesystem driver before it recorded ETags.
+	ETag string
+	// Metadata holds the custom pairs given in PutOptions.Metadata. List
+	// leaves it and ContentType empty on drivers whose listings do not carry
+	// them; use Stat for the full metadata.
+	Metadata map[string]string
+}
+
+// ObjectList is one page of a List call.
+type ObjectList struct {
+	Objects []ObjectInfo
+	// NextCursor is empty on the last page.
+	NextCursor string
 }
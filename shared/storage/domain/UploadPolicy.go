@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+// ErrInvalidUploadPolicy is wrapped by the errors SignedUpload returns for a
+// policy that cannot be signed.
+var ErrInvalidUploadPolicy = errors.New("storage: invalid upload policy")
+
+// UploadPolicy holds the constraints of a direct upload: the client sends
+// the object straight to storage, and the storage side rejects any upload
+// that breaks them.
+type UploadPolicy struct {
+	// Key is the exact key the client must upload to. Leave it empty and set
+	// KeyPrefix instead to let the client choose a key under that prefix.
+	Key       string
+	KeyPrefix string
+	// Visibility is the visibility the uploaded object is stored with.
+	Visibility Visibility
+	// MaxSizeBytes bounds the size of the object. It must be positive.
+	MaxSizeBytes int64
+	// ContentTypes lists the accepted content types. An entry ending in "/*",
+	// such as "image/*", accepts the whole family except the types browsers
+	// run scripts in, such as image/svg+xml; list those explicitly to accept
+	// them. An empty list accepts any content type.
+	ContentTypes []string
+	// TTL is how long the upload stays valid; 0 uses the driver's default
+	// signed URL TTL.
+	TTL time.Duration
+	// Metadata is stored with the uploaded object, as in PutOptions.Metadata.
+	Metadata map[string]string
+}
+
+// UploadRequest describes the form upload a client must send. It is safe to
+// hand to the client as is, and its JSON form is meant for that.
+type UploadRequest struct {
+	// Method is always POST: a multipart form upload is the only presigned
+	// request whose size limit the store enforces.
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Fields are the form fields to send before the file field. When the
+	// policy has a KeyPrefix, the client appends the rest of its key to the
+	// "key" field.
+	Fields map[string]string `json:"fields"`
+	// FileField names the form field carrying the object's content. It must
+	// be the last field of the form.
+	FileField string    `json:"file_field"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ValidateUploadPolicy checks that policy can be signed: exactly one of Key
+// and KeyPrefix is set, the key or prefix is safe, the size limit is positive
+// and every content type is well formed.
+func ValidateUploadPolicy(policy UploadPolicy) error {
+	switch {
+	case policy.Key != "" && policy.KeyPrefix != "":
+		return fmt.Errorf("%w: set either Key or KeyPrefix, not both", ErrInvalidUploadPolicy)
+	case policy.Key != "":
+		if err := ValidateKey(policy.Key); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidUploadPolicy, err)
+		}
+	case policy.KeyPrefix != "":
+		for _, seg := range strings.Split(policy.KeyPrefix, "/") {
+			if seg == ".." || seg == "." {
+				return fmt.Errorf("%w: key prefix must not contain path traversal segments: %q", ErrInvalidUploadPolicy, policy.KeyPrefix)
+			}
+		}
+		if strings.HasPrefix(policy.KeyPrefix, "/") {
+			return fmt.Errorf("%w: key prefix must not be an absolute path: %q", ErrInvalidUploadPolicy, policy.KeyPrefix)
+		}
+	default:
+		return fmt.Errorf("%w: Key or KeyPrefix is required", ErrInvalidUploadPolicy)
+	}
+
+	if policy.MaxSizeBytes <= 0 {
+		return fmt.Errorf("%w: MaxSizeBytes must be positive", ErrInvalidUploadPolicy)
+	}
+	for _, ct := range policy.ContentTypes {
+		if !validPolicyContentType(ct) {
+			return fmt.Errorf("%w: invalid content type %q", ErrInvalidUploadPolicy, ct)
+		}
+	}
+	return nil
+}
+
+// AllowsKey reports whether the client may upload to key: the exact policy
+// key, or a valid key under the policy's prefix.
+func (p UploadPolicy) AllowsKey(key string) bool {
+	if p.Key != "" {
+		return key == p.Key
+	}
+	return strings.HasPrefix(key, p.KeyPrefix) && ValidateKey(key) == nil
+}
+
+// scriptableContentTypes are the types a browser may run scripts in when it
+// renders them. Family wildcards never match them.
+var scriptableContentTypes = map[string]bool{
+	"image/svg+xml":         true,
+	"text/html":             true,
+	"text/xml":              true,
+	"text/javascript":       true,
+	"application/xhtml+xml": true,
+}
+
+// AllowsContentType reports whether contentType, which may carry parameters
+// such as a charset, matches one of the policy's content types.
+func (p UploadPolicy) AllowsContentType(contentType string) bool {
+	if len(p.ContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range p.ContentTypes {
+		allowed = strings.ToLower(allowed)
+		if family, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(mediaType, family+"/") && !scriptableContentTypes[mediaType] {
+				return true
+			}
+		} else if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// validPolicyContentType accepts a bare media type such as "image/png", or a
+// family wildcard such as "image/*".
+func validPolicyContentType(ct string) bool {
+	family, subtype, ok := strings.Cut(ct, "/")
+	if !ok || family == "" || family == "*" || strings.ContainsAny(ct, "; ") {
+		return false
+	}
+	if subtype == "*" {
+		ct = family + "/x"
+	}
+	_, _, err := mime.ParseMediaType(ct)
+	return err == nil
+}
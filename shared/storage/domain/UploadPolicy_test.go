@@ -0,0 +1,71 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/r0x16/Raidark/shared/ids"
+	"github.com/r0x16/Raidark/shared/storage/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateUploadPolicy_rejectsUnsignablePolicies covers the policies no
+// driver may sign, so a client never receives an unbounded upload form.
+func TestValidateUploadPolicy_rejectsUnsignablePolicies(t *testing.T) {
+	id, err := ids.NewV7()
+	require.NoError(t, err)
+	key := "accounts/avatar/2026/05/" + id + ".png"
+
+	tests := map[string]domain.UploadPolicy{
+		"no-key":             {MaxSizeBytes: 1024},
+		"key-and-prefix":     {Key: key, KeyPrefix: "accounts/avatar/", MaxSizeBytes: 1024},
+		"invalid-key":        {Key: "accounts/avatar.png", MaxSizeBytes: 1024},
+		"traversal-prefix":   {KeyPrefix: "accounts/../", MaxSizeBytes: 1024},
+		"absolute-prefix":    {KeyPrefix: "/accounts/", MaxSizeBytes: 1024},
+		"no-size-limit":      {Key: key},
+		"wildcard-family":    {Key: key, MaxSizeBytes: 1024, ContentTypes: []string{"*/*"}},
+		"content-type-param": {Key: key, MaxSizeBytes: 1024, ContentTypes: []string{"text/plain; charset=utf-8"}},
+		"bare-family":        {Key: key, MaxSizeBytes: 1024, ContentTypes: []string{"image"}},
+	}
+
+	for name, policy := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, domain.ValidateUploadPolicy(policy), domain.ErrInvalidUploadPolicy)
+		})
+	}
+
+	assert.NoError(t, domain.ValidateUploadPolicy(domain.UploadPolicy{
+		KeyPrefix:    "accounts/avatar/",
+		MaxSizeBytes: 1024,
+		ContentTypes: []string{"image/*", "application/pdf"},
+	}))
+}
+
+// TestUploadPolicy_allowsKeysAndContentTypes fixes the matching rules the
+// upload handlers apply to the client's form.
+func TestUploadPolicy_allowsKeysAndContentTypes(t *testing.T) {
+	id, err := ids.NewV7()
+	require.NoError(t, err)
+	key := "accounts/avatar/2026/05/" + id + ".png"
+
+	exact := domain.UploadPolicy{Key: key}
+	assert.True(t, exact.AllowsKey(key))
+	assert.False(t, exact.AllowsKey("accounts/avatar/2026/05/"+id+".gif"))
+
+	prefixed := domain.UploadPolicy{KeyPrefix: "accounts/avatar/"}
+	assert.True(t, prefixed.AllowsKey(key))
+	assert.False(t, prefixed.AllowsKey("accounts/banner/2026/05/"+id+".png"))
+	assert.False(t, prefixed.AllowsKey("accounts/avatar/free-form.png"))
+
+	typed := domain.UploadPolicy{ContentTypes: []string{"image/*", "application/pdf"}}
+	assert.True(t, typed.AllowsContentType("image/png"))
+	assert.True(t, typed.AllowsContentType("Application/PDF"))
+	assert.True(t, typed.AllowsContentType("application/pdf; name=invoice.pdf"))
+	assert.False(t, typed.AllowsContentType("text/html"))
+	assert.False(t, typed.AllowsContentType(""))
+	assert.False(t, typed.AllowsContentType("image/svg+xml"), "families exclude scriptable types")
+	assert.False(t, domain.UploadPolicy{ContentTypes: []string{"text/*"}}.AllowsContentType("text/html"))
+	assert.True(t, domain.UploadPolicy{ContentTypes: []string{"image/svg+xml"}}.AllowsContentType("image/svg+xml"))
+
+	assert.True(t, domain.UploadPolicy{}.AllowsContentType(""))
+}
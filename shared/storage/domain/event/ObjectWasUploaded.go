@@ -0,0 +1,35 @@
+package event
+
+import (
+	"time"
+
+	"github.com/r0x16/Raidark/shared/events/domain"
+	domstorage "github.com/r0x16/Raidark/shared/storage/domain"
+)
+
+// ObjectWasUploaded is published when a client completes a direct upload
+// through a SignedUpload form
+type ObjectWasUploaded struct {
+	Key         string                `json:"key"`
+	Visibility  domstorage.Visibility `json:"visibility"`
+	SizeBytes   int64                 `json:"size_bytes"`
+	ContentType string                `json:"content_type"`
+	ETag        string                `json:"etag"`
+	Metadata    map[string]string     `json:"metadata,omitempty"`
+	UploadedAt  time.Time             `json:"uploaded_at"`
+}
+
+var _ domain.AggregateEvent = &ObjectWasUploaded{}
+
+func (e *ObjectWasUploaded) Name() string {
+	return "storage.object.uploaded"
+}
+
+func (e *ObjectWasUploaded) OccurredAt() time.Time {
+	return e.UploadedAt
+}
+
+// AggregateID orders the events of the same object by its key
+func (e *ObjectWasUploaded) AggregateID() string {
+	return e.Key
+}
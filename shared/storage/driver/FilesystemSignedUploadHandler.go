@@ -0,0 +1,266 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/r0x16/Raidark/shared/api/rest"
+	domevents "github.com/r0x16/Raidark/shared/events/domain"
+	domstorage "github.com/r0x16/Raidark/shared/storage/domain"
+	"github.com/r0x16/Raidark/shared/storage/domain/event"
+)
+
+// maxUploadFieldsBytes bounds the form fields read before the file field, so
+// a client cannot make the handler buffer an unbounded form.
+const maxUploadFieldsBytes = 64 * 1024
+
+// sniffLength is how much of the file http.DetectContentType looks at.
+const sniffLength = 512
+
+// errUploadTooLarge stops the write once the file exceeds the policy limit.
+var errUploadTooLarge = errors.New("storage: upload exceeds the policy size limit")
+
+// uploadResponse is the body of a successful upload.
+type uploadResponse struct {
+	Key         string `json:"key"`
+	SizeBytes   int64  `json:"size_bytes"`
+	ContentType string `json:"content_type"`
+	ETag        string `json:"etag"`
+}
+
+// NewSignedUploadHandler returns an Echo handler that accepts the multipart
+// forms issued by FilesystemStorageProvider.SignedUpload. It verifies the
+// policy HMAC and expiry the same way NewSignedUrlHandler does for downloads,
+// then streams the file field to disk while enforcing the policy's key,
+// content type and size limits. The declared content type must also agree
+// with the content sniffed from the file's first bytes, so a page cannot be
+// stored under an image type. Fields after the file field are ignored.
+//
+// events is optional; when set, every upload publishes an ObjectWasUploaded
+// event. The handler is mounted at POST /_storage/uploads by EchoStorageModule.
+func NewSignedUploadHandler(provider *FilesystemStorageProvider, events domevents.DomainEventsProvider) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		reader, err := c.Request().MultipartReader()
+		if err != nil {
+			return invalidUpload(c, "The upload must be a multipart form.")
+		}
+
+		fields := make(map[string]string)
+		remaining := int64(maxUploadFieldsBytes)
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return invalidUpload(c, "The upload form has no file field.")
+			}
+			if err != nil {
+				return invalidUpload(c, "The upload form is malformed.")
+			}
+			if part.FormName() == uploadFileField {
+				defer part.Close()
+				return receiveUpload(c, provider, events, fields, part)
+			}
+
+			value, err := io.ReadAll(io.LimitReader(part, remaining+1))
+			part.Close()
+			if err != nil {
+				return invalidUpload(c, "The upload form is malformed.")
+			}
+			if remaining -= int64(len(value)); remaining < 0 {
+				return invalidUpload(c, "The upload form fields are too large.")
+			}
+			fields[part.FormName()] = string(value)
+		}
+	}
+}
+
+// receiveUpload checks the signed policy against the form fields and writes
+// the file. A write that breaks the size limit or fails midway leaves any
+// object already stored under the key untouched.
+func receiveUpload(c echo.Context, provider *FilesystemStorageProvider, events domevents.DomainEventsProvider, fields map[string]string, file *multipart.Part) error {
+	grant, ok := verifyUploadPolicy(fields["policy"], fields["signature"], provider.signingSecret)
+	if !ok {
+		return signedURLForbidden(c, "storage.invalid_signature", "The upload signature is invalid.")
+	}
+	if time.Now().Unix() > grant.ExpiresAt {
+		return signedURLForbidden(c, "storage.upload_expired", "The upload form has expired.")
+	}
+
+	policy := grant.policy()
+	key := fields["key"]
+	if !policy.AllowsKey(key) {
+		return signedURLForbidden(c, "storage.key_not_allowed", "The upload key is not allowed by the upload policy.")
+	}
+
+	contentType := fields["Content-Type"]
+	if contentType == "" {
+		contentType = file.Header.Get("Content-Type")
+	}
+	if !policy.AllowsContentType(contentType) {
+		return rest.RenderError(c, http.StatusUnsupportedMediaType, &rest.RESTError{
+			Code:    "storage.content_type_not_allowed",
+			Message: "The content type is not allowed by the upload policy.",
+		})
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return invalidUpload(c, "The upload form is malformed.")
+	}
+	head = head[:n]
+	declared := contentType
+	if declared == "" {
+		declared = mime.TypeByExtension(path.Ext(key))
+	}
+	if !contentMatchesType(declared, head) {
+		return rest.RenderError(c, http.StatusUnsupportedMediaType, &rest.RESTError{
+			Code:    "storage.content_type_mismatch",
+			Message: "The content of the upload does not match its content type.",
+		})
+	}
+
+	ctx := c.Request().Context()
+	content := io.MultiReader(bytes.NewReader(head), file)
+	result, err := provider.Put(ctx, key, &uploadLimitReader{r: content, remaining: policy.MaxSizeBytes}, domstorage.PutOptions{
+		Visibility:  policy.Visibility,
+		ContentType: contentType,
+		Metadata:    policy.Metadata,
+	})
+	if err != nil {
+		if errors.Is(err, errUploadTooLarge) {
+			return rest.RenderError(c, http.StatusRequestEntityTooLarge, &rest.RESTError{
+				Code:    "storage.upload_too_large",
+				Message: fmt.Sprintf("The upload exceeds the limit of %d bytes.", policy.MaxSizeBytes),
+			})
+		}
+		return err
+	}
+
+	if events != nil {
+		publishUploaded(ctx, events, &event.ObjectWasUploaded{
+			Key:         result.Key,
+			Visibility:  policy.Visibility,
+			SizeBytes:   result.SizeBytes,
+			ContentType: contentType,
+			ETag:        result.ETag,
+			Metadata:    policy.Metadata,
+			UploadedAt:  time.Now(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, uploadResponse{
+		Key:         result.Key,
+		SizeBytes:   result.SizeBytes,
+		ContentType: contentType,
+		ETag:        result.ETag,
+	})
+}
+
+// verifyUploadPolicy performs a constant-time comparison of the hex signature
+// against the expected HMAC-SHA256 of "upload\n{policy}", then decodes the
+// policy. Returns false when either step fails.
+func verifyUploadPolicy(policy, sig string, secret []byte) (uploadGrant, bool) {
+	actual, err := hex.DecodeString(sig)
+	if err != nil {
+		return uploadGrant{}, false
+	}
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "upload\n%s", policy)
+	if !hmac.Equal(actual, mac.Sum(nil)) {
+		return uploadGrant{}, false
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(policy)
+	if err != nil {
+		return uploadGrant{}, false
+	}
+	var grant uploadGrant
+	if err := json.Unmarshal(data, &grant); err != nil {
+		return uploadGrant{}, false
+	}
+	return grant, true
+}
+
+// contentMatchesType reports whether a file starting with head may be stored
+// as declared. Markup, which browsers render as a page, is only accepted
+// under a markup type. Image, audio and video types are only accepted for
+// content that sniffs as media or as unknown binary data; SVG, being XML,
+// must sniff as text. An empty declared type is not checked.
+func contentMatchesType(declared string, head []byte) bool {
+	if declared == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(declared)
+	if err != nil {
+		return false
+	}
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+
+	family, _, _ := strings.Cut(mediaType, "/")
+	switch {
+	case sniffed == "text/html":
+		return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	case sniffed == "text/xml":
+		return mediaType == "text/xml" || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml")
+	case mediaType == "image/svg+xml":
+		return sniffed == "text/plain"
+	case family == "image" || family == "audio" || family == "video":
+		sniffedFamily, _, _ := strings.Cut(sniffed, "/")
+		return sniffed == "application/octet-stream" || sniffed == "application/ogg" ||
+			sniffedFamily == "image" || sniffedFamily == "audio" || sniffedFamily == "video"
+	}
+	return true
+}
+
+// publishUploaded publishes the event with the request context when the
+// events driver supports it. A failed publish does not fail the upload,
+// which is already stored.
+func publishUploaded(ctx context.Context, events domevents.DomainEventsProvider, uploaded *event.ObjectWasUploaded) {
+	if contextEvents, ok := events.(domevents.ContextEventsProvider); ok {
+		contextEvents.PublishWithContext(ctx, uploaded)
+		return
+	}
+	events.Publish(uploaded)
+}
+
+// uploadLimitReader reads at most remaining bytes and fails with
+// errUploadTooLarge as soon as the source holds more.
+type uploadLimitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *uploadLimitReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, errUploadTooLarge
+	}
+	return n, err
+}
+
+// invalidUpload renders a 400 response for a form the handler cannot read.
+func invalidUpload(c echo.Context, message string) error {
+	return rest.RenderError(c, http.StatusBadRequest, &rest.RESTError{
+		Code:    "storage.invalid_upload",
+		Message: message,
+	})
+}
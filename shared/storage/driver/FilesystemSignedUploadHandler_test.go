@@ -0,0 +1,355 @@
+package driver_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/r0x16/Raidark/shared/api/rest"
+	domevents "github.com/r0x16/Raidark/shared/events/domain"
+	storagedomain "github.com/r0x16/Raidark/shared/storage/domain"
+	"github.com/r0x16/Raidark/shared/storage/domain/event"
+	"github.com/r0x16/Raidark/shared/storage/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFilesystemSignedUploadHandler_storesObjectAndPublishesEvent covers the
+// happy path: a client-chosen key under the policy prefix is written with the
+// policy's visibility and metadata, and the upload is announced as an event.
+func TestFilesystemSignedUploadHandler_storesObjectAndPublishesEvent(t *testing.T) {
+	provider, roots := newFilesystemProvider(t)
+	events := &recordingEvents{}
+	upload, err := provider.SignedUpload(context.Background(), storagedomain.UploadPolicy{
+		KeyPrefix:    "profiles/avatar/",
+		Visibility:   storagedomain.VisibilityPrivate,
+		MaxSizeBytes: 1024,
+		ContentTypes: []string{"image/*"},
+		Metadata:     map[string]string{"Owner-ID": "42"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPost, upload.Method)
+	assert.Equal(t, "/_storage/uploads", upload.URL)
+	assert.Equal(t, "profiles/avatar/", upload.Fields["key"])
+
+	key := newStorageKey(t, "profiles", "avatar", ".png")
+	upload.Fields["key"] = key
+	recorder := postUpload(t, provider, events, upload, "image/png", pngContent)
+
+	require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+	assert.JSONEq(t, `{
+		"key": "`+key+`",
+		"size_bytes": `+strconv.Itoa(len(pngContent))+`,
+		"content_type": "image/png",
+		"etag": "`+md5Hex(pngContent)+`"
+	}`, recorder.Body.String())
+	assert.FileExists(t, filepath.Join(roots.private, filepath.FromSlash(key)))
+
+	info, err := provider.Stat(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, storagedomain.VisibilityPrivate, info.Visibility)
+	assert.Equal(t, "image/png", info.ContentType)
+	assert.Equal(t, map[string]string{"owner-id": "42"}, info.Metadata)
+
+	require.Len(t, events.published, 1)
+	uploaded, ok := events.published[0].(*event.ObjectWasUploaded)
+	require.True(t, ok)
+	assert.Equal(t, "storage.object.uploaded", uploaded.Name())
+	assert.Equal(t, key, uploaded.AggregateID())
+	assert.Equal(t, int64(len(pngContent)), uploaded.SizeBytes)
+	assert.Equal(t, "image/png", uploaded.ContentType)
+	assert.Equal(t, md5Hex(pngContent), uploaded.ETag)
+}
+
+// TestFilesystemSignedUploadHandler_rejectsOversizedUpload verifies the size
+// limit is enforced while streaming and the partial file is removed.
+func TestFilesystemSignedUploadHandler_rejectsOversizedUpload(t *testing.T) {
+	provider, roots := newFilesystemProvider(t)
+	key := newStorageKey(t, "archives", "backup", ".bin")
+	upload, err := provider.SignedUpload(context.Background(), storagedomain.UploadPolicy{
+		Key:          key,
+		MaxSizeBytes: 8,
+	})
+	require.NoError(t, err)
+
+	events := &recordingEvents{}
+	recorder := postUpload(t, provider, events, upload, "application/octet-stream", "more-than-eight-bytes")
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	assert.JSONEq(t, `{
+		"error": {
+			"code": "storage.upload_too_large",
+			"message": "The upload exceeds the limit of 8 bytes."
+		}
+	}`, recorder.Body.String())
+	assert.NoFileExists(t, filepath.Join(roots.public, filepath.FromSlash(key)))
+	assert.NoFileExists(t, filepath.Join(roots.public, filepath.FromSlash(key))+".meta.json")
+	assert.Empty(t, events.published)
+
+	recorder = postUpload(t, provider, events, upload, "application/octet-stream", "8-bytes!")
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+}
+
+// TestFilesystemSignedUploadHandler_oversizedUploadKeepsExistingObject
+// verifies a rejected upload to a stored key leaves the object, its sidecar
+// and no partial file behind.
+func TestFilesystemSignedUploadHandler_oversizedUploadKeepsExistingObject(t *testing.T) {
+	provider, roots := newFilesystemProvider(t)
+	key := newStorageKey(t, "archives", "backup", ".bin")
+	upload, err := provider.SignedUpload(context.Background(), storagedomain.UploadPolicy{
+		Key:          key,
+		MaxSizeBytes: 8,
+	})
+	require.NoError(t, err)
+	events := &recordingEvents{}
+	recorder := postUpload(t, provider, events, upload, "application/octet-stream", "8-bytes!")
+	require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+
+	recorder = postUpload(t, provider, events, upload, "application/octet-stream", "more-than-eight-bytes")
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	object := filepath.Join(roots.public, filepath.FromSlash(key))
+	content, err := os.ReadFile(object)
+	require.NoError(t, err)
+	assert.Equal(t, "8-bytes!", string(content))
+	info, err := provider.Stat(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, "application/octet-stream", info.ContentType)
+	assert.Equal(t, md5Hex("8-bytes!"), info.ETag)
+	entries, err := os.ReadDir(filepath.Dir(object))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+// TestFilesystemSignedUploadHandler_enforcesPolicy covers every way a form
+// can break its signed policy; none of them may write the object.
+func TestFilesystemSignedUploadHandler_enforcesPolicy(t *testing.T) {
+	provider, roots := newFilesystemProvider(t)
+	key := newStorageKey(t, "invoices", "pdfs", ".pdf")
+	policy := storagedomain.UploadPolicy{
+		Key:          key,
+		MaxSizeBytes: 1024,
+		ContentTypes: []string{"application/pdf"},
+	}
+
+	tests := map[string]struct {
+		tamper      func(fields map[string]string)
+		contentType string
+		status      int
+		code        string
+	}{
+		"tampered-signature": {
+			tamper:      func(fields map[string]string) { fields["signature"] = strings.Repeat("0", 64) },
+			contentType: "application/pdf",
+			status:      http.StatusForbidden,
+			code:        "storage.invalid_signature",
+		},
+		"tampered-policy": {
+			tamper: func(fields map[string]string) {
+				fields["policy"] = encodeUploadPolicy(t, map[string]any{"key": key, "max_size_bytes": 1 << 30, "expires": time.Now().Add(time.Hour).Unix()})
+			},
+			contentType: "application/pdf",
+			status:      http.StatusForbidden,
+			code:        "storage.invalid_signature",
+		},
+		"expired": {
+			tamper: func(fields map[string]string) {
+				fields["policy"] = encodeUploadPolicy(t, map[string]any{"key": key, "max_size_bytes": 1024, "expires": 1})
+				fields["signature"] = signUploadPolicy(fields["policy"])
+			},
+			contentType: "application/pdf",
+			status:      http.StatusForbidden,
+			code:        "storage.upload_expired",
+		},
+		"other-key": {
+			tamper:      func(fields map[string]string) { fields["key"] = newStorageKey(t, "invoices", "pdfs", ".pdf") },
+			contentType: "application/pdf",
+			status:      http.StatusForbidden,
+			code:        "storage.key_not_allowed",
+		},
+		"content-type": {
+			tamper:      func(map[string]string) {},
+			contentType: "text/html",
+			status:      http.StatusUnsupportedMediaType,
+			code:        "storage.content_type_not_allowed",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			upload, err := provider.SignedUpload(context.Background(), policy)
+			require.NoError(t, err)
+			tt.tamper(upload.Fields)
+
+			recorder := postUpload(t, provider, nil, upload, tt.contentType, "%PDF-1.7")
+
+			assert.Equal(t, tt.status, recorder.Code)
+			var body struct {
+				Error rest.RESTError `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			assert.Equal(t, tt.code, body.Error.Code)
+			assert.NoFileExists(t, filepath.Join(roots.public, filepath.FromSlash(key)))
+		})
+	}
+}
+
+// TestFilesystemSignedUploadHandler_rejectsContentNotMatchingItsType keeps
+// pages and scripts from being stored under an image type.
+func TestFilesystemSignedUploadHandler_rejectsContentNotMatchingItsType(t *testing.T) {
+	provider, roots := newFilesystemProvider(t)
+	policy := storagedomain.UploadPolicy{
+		KeyPrefix:    "profiles/avatar/",
+		Visibility:   storagedomain.VisibilityPublic,
+		MaxSizeBytes: 1024,
+		ContentTypes: []string{"image/*"},
+	}
+
+	tests := map[string]struct {
+		contentType string
+		content     string
+		code        string
+	}{
+		"html-as-png":  {contentType: "image/png", content: "<html><script>alert(1)</script></html>", code: "storage.content_type_mismatch"},
+		"text-as-jpeg": {contentType: "image/jpeg", content: "just some text", code: "storage.content_type_mismatch"},
+		"pdf-as-png":   {contentType: "image/png", content: "%PDF-1.7", code: "storage.content_type_mismatch"},
+		"svg":          {contentType: "image/svg+xml", content: `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`, code: "storage.content_type_not_allowed"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			upload, err := provider.SignedUpload(context.Background(), policy)
+			require.NoError(t, err)
+			key := newStorageKey(t, "profiles", "avatar", ".png")
+			upload.Fields["key"] = key
+
+			recorder := postUpload(t, provider, nil, upload, tt.contentType, tt.content)
+
+			assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+			var body struct {
+				Error rest.RESTError `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			assert.Equal(t, tt.code, body.Error.Code)
+			assert.NoFileExists(t, filepath.Join(roots.public, filepath.FromSlash(key)))
+		})
+	}
+}
+
+// TestFilesystemSignedUploadHandler_rejectsFormWithoutFile keeps malformed
+// forms from reaching the policy checks.
+func TestFilesystemSignedUploadHandler_rejectsFormWithoutFile(t *testing.T) {
+	provider, _ := newFilesystemProvider(t)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	require.NoError(t, form.WriteField("key", "anything"))
+	require.NoError(t, form.Close())
+
+	recorder := serveUpload(provider, nil, form.FormDataContentType(), &body)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "storage.invalid_upload")
+}
+
+// TestFilesystemStorageProvider_signedUploadRejectsInvalidPolicy verifies
+// policies are validated before a form is signed.
+func TestFilesystemStorageProvider_signedUploadRejectsInvalidPolicy(t *testing.T) {
+	provider, _ := newFilesystemProvider(t)
+
+	_, err := provider.SignedUpload(context.Background(), storagedomain.UploadPolicy{KeyPrefix: "profiles/avatar/"})
+
+	assert.ErrorIs(t, err, storagedomain.ErrInvalidUploadPolicy)
+}
+
+// postUpload sends the upload form with its fields first and the file last,
+// the order browsers use and the handler requires.
+func postUpload(t *testing.T, provider *driver.FilesystemStorageProvider, events domevents.DomainEventsProvider, upload storagedomain.UploadRequest, contentType, content string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range upload.Fields {
+		require.NoError(t, form.WriteField(name, value))
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="`+upload.FileField+`"; filename="upload"`)
+	header.Set("Content-Type", contentType)
+	part, err := form.CreatePart(header)
+	require.NoError(t, err)
+	_, err = io.WriteString(part, content)
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	return serveUpload(provider, events, form.FormDataContentType(), &body)
+}
+
+// serveUpload mounts only the upload route, as EchoStorageModule does.
+func serveUpload(provider *driver.FilesystemStorageProvider, events domevents.DomainEventsProvider, contentType string, body io.Reader) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.HTTPErrorHandler = rest.EchoErrorHandler
+	e.POST("/_storage/uploads", driver.NewSignedUploadHandler(provider, events))
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/_storage/uploads", body)
+	request.Header.Set("Content-Type", contentType)
+	e.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// encodeUploadPolicy builds a policy field by hand, as a forging client would.
+func encodeUploadPolicy(t *testing.T, grant map[string]any) string {
+	t.Helper()
+
+	data, err := json.Marshal(grant)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signUploadPolicy signs a policy field with the secret of
+// newFilesystemProvider.
+func signUploadPolicy(policy string) string {
+	mac := hmac.New(sha256.New, []byte("secret-for-tests"))
+	mac.Write([]byte("upload\n" + policy))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// md5Hex returns the ETag the filesystem driver computes for content.
+func md5Hex(content string) string {
+	sum := md5.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordingEvents keeps the published events instead of dispatching them.
+type recordingEvents struct {
+	published []domevents.DomainEvent
+}
+
+func (r *recordingEvents) Collect() {}
+func (r *recordingEvents) Publish(event domevents.DomainEvent) error {
+	r.published = append(r.published, event)
+	return nil
+}
+func (r *recordingEvents) Subscribe(domevents.EventListener) error    { return nil }
+func (r *recordingEvents) Dispatch(event domevents.DomainEvent) error { return nil }
+func (r *recordingEvents) Close() error                               { return nil }
+
+// pngContent starts with the PNG signature, so it sniffs as image/png.
+const pngContent = "\x89PNG\r\n\x1a\npng-bytes"
@@ -14,8 +14,8 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
-	"github.com/spf13/afero"
 	"github.com/r0x16/Raidark/shared/api/rest"
+	domstorage "github.com/r0x16/Raidark/shared/storage/domain"
 )
 
 // NewSignedUrlHandler returns an Echo handler that validates the HMAC signature
@@ -24,6 +24,9 @@ import (
 // The handler is mounted at GET /_storage/* by EchoStorageModule. It only serves
 // files from the private root — public objects are served directly via PublicURL
 // and never reach this handler.
+//
+// Responses may be cached privately until the URL expires, and carry the
+// signed Content-Disposition when the URL has one.
 func NewSignedUrlHandler(provider *FilesystemStorageProvider) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		// Echo's wildcard param "*" may include a leading slash.
@@ -34,11 +37,35 @@ func NewSignedUrlHandler(provider *FilesystemStorageProvider) echo.HandlerFunc {
 			return signedURLForbidden(c, "storage.url_expired", "The signed URL has expired or is invalid.")
 		}
 
-		if !verifyHMAC(c.QueryParam("sig"), key, expiresAt, provider.signingSecret) {
+		disposition := c.QueryParam("disposition")
+		if !verifyHMAC(c.QueryParam("sig"), key, expiresAt, disposition, provider.signingSecret) {
 			return signedURLForbidden(c, "storage.invalid_signature", "The signed URL signature is invalid.")
 		}
 
-		return servePrivateFile(c, provider.privateFs, key)
+		root := provider.rootFor(domstorage.VisibilityPrivate)
+		return serveObject(c, root, key, objectHeaders{
+			cacheControl: fmt.Sprintf("private, max-age=%d", max(expiresAt-time.Now().Unix(), 0)),
+			disposition:  disposition,
+		})
+	}
+}
+
+// NewPublicFileHandler returns an Echo handler that serves files from the
+// public root with the same Range, conditional and caching support as
+// NewSignedUrlHandler. Responses may be cached by shared caches for
+// STORAGE_PUBLIC_CACHE_MAX_AGE.
+//
+// The handler is mounted at GET /_public/* by EchoStorageModule, for
+// deployments whose STORAGE_PUBLIC_BASE_URL points at the service instead of
+// a CDN.
+func NewPublicFileHandler(provider *FilesystemStorageProvider) echo.HandlerFunc {
+	headers := objectHeaders{
+		cacheControl: fmt.Sprintf("public, max-age=%d", int64(provider.publicMaxAge.Seconds())),
+	}
+	return func(c echo.Context) error {
+		key := strings.TrimPrefix(c.Param("*"), "/")
+		root := provider.rootFor(domstorage.VisibilityPublic)
+		return serveObject(c, root, key, headers)
 	}
 }
 
@@ -53,28 +80,51 @@ func parseAndValidateExpiry(s string) (int64, bool) {
 }
 
 // verifyHMAC performs a constant-time comparison of the provided hex signature
-// against the expected HMAC-SHA256 of "{key}\n{expiresAt}".
+// against the expected HMAC-SHA256 of the signed URL message.
 // Constant-time comparison prevents timing-based signature oracle attacks.
-func verifyHMAC(sig, key string, expiresAt int64, secret []byte) bool {
+func verifyHMAC(sig, key string, expiresAt int64, disposition string, secret []byte) bool {
 	actual, err := hex.DecodeString(sig)
 	if err != nil {
 		return false
 	}
+	return hmac.Equal(actual, signedURLMAC(secret, key, expiresAt, disposition))
+}
+
+// signedURLMAC returns the HMAC-SHA256 of "{key}\n{expiresAt}", followed by
+// "\n{disposition}" when a disposition is signed.
+func signedURLMAC(secret []byte, key string, expiresAt int64, disposition string) []byte {
 	mac := hmac.New(sha256.New, secret)
 	fmt.Fprintf(mac, "%s\n%d", key, expiresAt)
-	return hmac.Equal(actual, mac.Sum(nil))
+	if disposition != "" {
+		fmt.Fprintf(mac, "\n%s", disposition)
+	}
+	return mac.Sum(nil)
 }
 
-// servePrivateFile opens the file from the private afero.Fs and serves it.
-// http.ServeContent handles Range, ETag, If-None-Match, and 304 responses automatically.
-func servePrivateFile(c echo.Context, fs afero.Fs, key string) error {
-	f, err := fs.Open(filepath.FromSlash(key))
+// objectHeaders are the response headers that depend on how the object is
+// served rather than on the object itself.
+type objectHeaders struct {
+	cacheControl string
+	disposition  string
+}
+
+// serveObject opens the object from root and serves it with its sidecar's
+// content type and ETag. http.ServeContent answers Range requests with 206
+// Partial Content and handles If-None-Match, If-Modified-Since, If-Range and
+// 304 responses. Sidecars and directories are never served.
+//
+// Browsers are told not to sniff the content type, and every object that is
+// not a raster image, audio or video is sandboxed, so an uploaded page or SVG
+// cannot run scripts on the service's origin.
+func serveObject(c echo.Context, root storageRoot, key string, headers objectHeaders) error {
+	if key == "" || strings.HasSuffix(key, metadataSuffix) {
+		return objectNotFound(c)
+	}
+
+	f, err := root.fs.Open(filepath.FromSlash(key))
 	if err != nil {
 		if os.IsNotExist(err) {
-			return rest.RenderError(c, http.StatusNotFound, &rest.RESTError{
-				Code:    "storage.not_found",
-				Message: "The requested object was not found.",
-			})
+			return objectNotFound(c)
 		}
 		return err
 	}
@@ -84,16 +134,51 @@ func servePrivateFile(c echo.Context, fs afero.Fs, key string) error {
 	if err != nil {
 		return err
 	}
+	if stat.IsDir() {
+		return objectNotFound(c)
+	}
+
+	info, err := objectInfo(root, key, stat)
+	if err != nil {
+		return err
+	}
 
-	ct := mime.TypeByExtension(filepath.Ext(key))
-	if ct == "" {
-		ct = "application/octet-stream"
+	header := c.Response().Header()
+	header.Set("Content-Type", info.ContentType)
+	header.Set("X-Content-Type-Options", "nosniff")
+	if !rendersInert(info.ContentType) {
+		header.Set("Content-Security-Policy", "sandbox")
+	}
+	header.Set("Cache-Control", headers.cacheControl)
+	if info.ETag != "" {
+		header.Set("ETag", `"`+info.ETag+`"`)
+	}
+	if headers.disposition != "" {
+		header.Set("Content-Disposition", headers.disposition)
 	}
-	c.Response().Header().Set("Content-Type", ct)
-	http.ServeContent(c.Response(), c.Request(), key, stat.ModTime(), f)
+	http.ServeContent(c.Response(), c.Request(), key, info.ModifiedAt, f)
 	return nil
 }
 
+// rendersInert reports whether browsers display contentType without running
+// scripts: raster images, audio and video. SVG is an image that runs them.
+func rendersInert(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "image/svg+xml" {
+		return false
+	}
+	family, _, _ := strings.Cut(mediaType, "/")
+	return family == "image" || family == "audio" || family == "video"
+}
+
+// objectNotFound renders a 404 response for a missing object.
+func objectNotFound(c echo.Context) error {
+	return rest.RenderError(c, http.StatusNotFound, &rest.RESTError{
+		Code:    "storage.not_found",
+		Message: "The requested object was not found.",
+	})
+}
+
 // signedURLForbidden renders a 403 response with a storage-specific RESTError.
 func signedURLForbidden(c echo.Context, code, message string) error {
 	return rest.RenderError(c, http.StatusForbidden, &rest.RESTError{
@@ -9,38 +9,71 @@ import (
 	"crypto/hmac"
 	"crypto/md5"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"mime"
+	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/afero"
 
+	"github.com/r0x16/Raidark/shared/api/rest"
 	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domhealth "github.com/r0x16/Raidark/shared/health/domain"
 	domstorage "github.com/r0x16/Raidark/shared/storage/domain"
 )
 
+// uploadPath is where NewSignedUploadHandler is mounted, and uploadFileField
+// the form field carrying the uploaded content.
+const (
+	uploadPath      = "/_storage/uploads"
+	uploadFileField = "file"
+)
+
+// metadataSuffix names the sidecar file of each object. Valid keys never end
+// with it, because the UUID segment cannot contain a dot.
+const metadataSuffix = ".meta.json"
+
+// partialPrefix names the temporary files Put and writeSidecar write before
+// renaming them over the key. List skips them.
+const partialPrefix = ".partial-"
+
 // FilesystemStorageProvider implements StorageProvider using the local filesystem.
 //
 // Public objects are stored under a BasePathFs rooted at STORAGE_PUBLIC_ROOT;
 // private objects under STORAGE_PRIVATE_ROOT. Using BasePathFs means path
 // traversal is prevented at the library level — no manual prefix checks needed.
 //
+// The content type, ETag and custom metadata of each object live in a JSON
+// sidecar file, {key}.meta.json, under STORAGE_METADATA_ROOT/public or
+// STORAGE_METADATA_ROOT/private. Sidecars never sit in a root that is served,
+// so a CDN or static server in front of the public root cannot expose them.
+//
 // Signed URLs for private objects are relative paths (/_storage/{key}?sig=...&expires=...)
-// served by the internal Echo handler registered via EchoStorageModule.
+// served by the internal Echo handler registered via EchoStorageModule. Signed
+// uploads are forms posted to /_storage/uploads, served by a second handler
+// registered by the same module, which also serves the public root at
+// /_public/{key} for deployments without a CDN.
 type FilesystemStorageProvider struct {
 	publicFs      afero.Fs // BasePathFs rooted at STORAGE_PUBLIC_ROOT
 	privateFs     afero.Fs // BasePathFs rooted at STORAGE_PRIVATE_ROOT
+	publicMetaFs  afero.Fs // BasePathFs rooted at STORAGE_METADATA_ROOT/public
+	privateMetaFs afero.Fs // BasePathFs rooted at STORAGE_METADATA_ROOT/private
 	publicBaseURL string
 	signingSecret []byte
 	defaultTTL    time.Duration
+	publicMaxAge  time.Duration // Cache-Control max-age of NewPublicFileHandler
 }
 
 // NewFilesystemStorageProvider constructs a FilesystemStorageProvider from
@@ -60,43 +93,61 @@ func NewFilesystemStorageProvider(env domenv.EnvProvider) (*FilesystemStoragePro
 		return nil, fmt.Errorf("storage: invalid STORAGE_SIGNED_URL_DEFAULT_TTL %q: %w", ttlStr, err)
 	}
 
+	maxAgeStr := env.GetString("STORAGE_PUBLIC_CACHE_MAX_AGE", "1h")
+	maxAge, err := time.ParseDuration(maxAgeStr)
+	if err != nil || maxAge < 0 {
+		return nil, fmt.Errorf("storage: invalid STORAGE_PUBLIC_CACHE_MAX_AGE %q", maxAgeStr)
+	}
+
 	base := afero.NewOsFs()
+	metadataRoot := env.GetString("STORAGE_METADATA_ROOT", "/storage/metadata")
 	return &FilesystemStorageProvider{
 		publicFs:      afero.NewBasePathFs(base, env.GetString("STORAGE_PUBLIC_ROOT", "/storage/public")),
 		privateFs:     afero.NewBasePathFs(base, env.GetString("STORAGE_PRIVATE_ROOT", "/storage/private")),
+		publicMetaFs:  afero.NewBasePathFs(base, filepath.Join(metadataRoot, "public")),
+		privateMetaFs: afero.NewBasePathFs(base, filepath.Join(metadataRoot, "private")),
 		publicBaseURL: env.GetString("STORAGE_PUBLIC_BASE_URL", ""),
 		signingSecret: secret,
 		defaultTTL:    ttl,
+		publicMaxAge:  maxAge,
 	}, nil
 }
 
 // Put writes the content of r to the key's path under the appropriate root.
 // The write is streaming — io.TeeReader feeds the MD5 hasher while io.Copy
 // writes directly to the file, keeping memory usage at O(io.Copy buffer size).
+// The content goes to a temporary file next to the key, renamed over it only
+// once fully written, so a failed write leaves the previous object intact.
 func (p *FilesystemStorageProvider) Put(ctx context.Context, key string, r io.Reader, opts domstorage.PutOptions) (domstorage.PutResult, error) {
-	fs := p.fsFor(opts.Visibility)
+	root := p.rootFor(opts.Visibility)
+	fs := root.fs
 	fkey := filepath.FromSlash(key)
 
 	if err := fs.MkdirAll(filepath.Dir(fkey), 0755); err != nil {
 		return domstorage.PutResult{}, fmt.Errorf("storage: create directories for %q: %w", key, err)
 	}
 
-	f, err := fs.Create(fkey)
-	if err != nil {
-		return domstorage.PutResult{}, fmt.Errorf("storage: create file for %q: %w", key, err)
-	}
-	defer f.Close()
-
 	hash := md5.New()
-	n, err := io.Copy(f, io.TeeReader(r, hash))
+	var n int64
+	err := writeAtomically(fs, fkey, func(f afero.File) error {
+		var err error
+		n, err = io.Copy(f, io.TeeReader(r, hash))
+		return err
+	})
 	if err != nil {
 		return domstorage.PutResult{}, fmt.Errorf("storage: write %q: %w", key, err)
 	}
 
+	etag := hex.EncodeToString(hash.Sum(nil))
+	sidecar := objectSidecar{ContentType: opts.ContentType, ETag: etag, Metadata: normalizeMetadata(opts.Metadata)}
+	if err := writeSidecar(root.meta, fkey, sidecar); err != nil {
+		return domstorage.PutResult{}, fmt.Errorf("storage: write metadata of %q: %w", key, err)
+	}
+
 	return domstorage.PutResult{
 		Key:       key,
 		SizeBytes: n,
-		ETag:      hex.EncodeToString(hash.Sum(nil)),
+		ETag:      etag,
 	}, nil
 }
 
@@ -105,8 +156,8 @@ func (p *FilesystemStorageProvider) Put(ctx context.Context, key string, r io.Re
 // The caller must close the returned ReadCloser.
 func (p *FilesystemStorageProvider) Get(ctx context.Context, key string) (io.ReadCloser, domstorage.ObjectInfo, error) {
 	fkey := filepath.FromSlash(key)
-	for _, fs := range []afero.Fs{p.publicFs, p.privateFs} {
-		f, err := fs.Open(fkey)
+	for _, root := range p.roots() {
+		f, err := root.fs.Open(fkey)
 		if err != nil {
 			if os.IsNotExist(err) {
 				continue
@@ -120,32 +171,27 @@ func (p *FilesystemStorageProvider) Get(ctx context.Context, key string) (io.Rea
 			return nil, domstorage.ObjectInfo{}, fmt.Errorf("storage: stat %q: %w", key, err)
 		}
 
-		ct := mime.TypeByExtension(filepath.Ext(key))
-		if ct == "" {
-			ct = "application/octet-stream"
+		info, err := objectInfo(root, key, stat)
+		if err != nil {
+			f.Close()
+			return nil, domstorage.ObjectInfo{}, err
 		}
-		return f, domstorage.ObjectInfo{
-			Key:         key,
-			SizeBytes:   stat.Size(),
-			ContentType: ct,
-			ModifiedAt:  stat.ModTime(),
-		}, nil
+		return f, info, nil
 	}
-	return nil, domstorage.ObjectInfo{}, fmt.Errorf("storage: key not found: %q", key)
+	return nil, domstorage.ObjectInfo{}, fmt.Errorf("%w: %q", domstorage.ErrObjectNotFound, key)
 }
 
 // Delete removes the object from whichever root it lives in.
 // Delete is idempotent: a missing key returns nil.
 func (p *FilesystemStorageProvider) Delete(ctx context.Context, key string) error {
-	fkey := filepath.FromSlash(key)
-	for _, fs := range []afero.Fs{p.publicFs, p.privateFs} {
-		err := fs.Remove(fkey)
-		if err == nil {
-			return nil
-		}
-		if !os.IsNotExist(err) {
+	for _, root := range p.roots() {
+		removed, err := removeObject(root, key)
+		if err != nil {
 			return fmt.Errorf("storage: delete %q: %w", key, err)
 		}
+		if removed {
+			return nil
+		}
 	}
 	return nil
 }
@@ -153,10 +199,24 @@ func (p *FilesystemStorageProvider) Delete(ctx context.Context, key string) erro
 // SignedURL generates a time-limited URL pointing to the internal handler at
 // /_storage/{key}. The URL is relative (path + query only) so it works
 // regardless of the host/scheme the service runs behind.
-//
-// The HMAC message is "{key}\n{expires_unix_decimal}". Both this method and
-// the handler in FilesystemSignedUrlHandler.go must use this identical format.
 func (p *FilesystemStorageProvider) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return p.SignedURLWithOptions(ctx, key, domstorage.SignedURLOptions{TTL: ttl})
+}
+
+// SignedURLWithOptions generates the URL of SignedURL. A ContentDisposition
+// travels in the disposition query parameter and is covered by the HMAC.
+//
+// The HMAC message is "{key}\n{expires_unix_decimal}", followed by
+// "\n{disposition}" when one is set, so URLs issued without a disposition keep
+// their original form. Both this method and the handler in
+// FilesystemSignedUrlHandler.go must use this identical format.
+func (p *FilesystemStorageProvider) SignedURLWithOptions(ctx context.Context, key string, opts domstorage.SignedURLOptions) (string, error) {
+	if opts.ContentDisposition != "" {
+		if err := domstorage.ValidateContentDisposition(opts.ContentDisposition); err != nil {
+			return "", err
+		}
+	}
+	ttl := opts.TTL
 	if ttl <= 0 {
 		ttl = p.defaultTTL
 	}
@@ -164,8 +224,11 @@ func (p *FilesystemStorageProvider) SignedURL(ctx context.Context, key string, t
 
 	u := &url.URL{Path: "/_storage/" + key}
 	q := url.Values{}
-	q.Set("sig", p.computeHMAC(key, expiresAt))
+	q.Set("sig", p.computeHMAC(key, expiresAt, opts.ContentDisposition))
 	q.Set("expires", strconv.FormatInt(expiresAt, 10))
+	if opts.ContentDisposition != "" {
+		q.Set("disposition", opts.ContentDisposition)
+	}
 	u.RawQuery = q.Encode()
 	return u.String(), nil
 }
@@ -190,19 +253,426 @@ func (p *FilesystemStorageProvider) Exists(ctx context.Context, key string) (boo
 	return false, nil
 }
 
-// fsFor returns the afero.Fs for the given visibility.
-func (p *FilesystemStorageProvider) fsFor(v domstorage.Visibility) afero.Fs {
+// Stat returns the metadata of the object from whichever root it lives in.
+func (p *FilesystemStorageProvider) Stat(ctx context.Context, key string) (domstorage.ObjectInfo, error) {
+	root, stat, err := p.locate(key)
+	if err != nil {
+		return domstorage.ObjectInfo{}, err
+	}
+	return objectInfo(root, key, stat)
+}
+
+// List walks the directory that holds prefix in both roots, merges the
+// matching objects in key order and returns the page after the cursor. Each
+// call walks the whole directory, which suits the development and
+// single-node deployments this driver targets.
+func (p *FilesystemStorageProvider) List(ctx context.Context, prefix, cursor string, limit int) (domstorage.ObjectList, error) {
+	after, err := decodeListCursor(cursor, prefix)
+	if err != nil {
+		return domstorage.ObjectList{}, err
+	}
+	limit = rest.ClampLimit(limit)
+
+	type entry struct {
+		root storageRoot
+		key  string
+		stat os.FileInfo
+	}
+	var entries []entry
+	for _, root := range p.roots() {
+		err := afero.Walk(root.fs, filepath.FromSlash(path.Dir(prefix)), func(name string, stat os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			key := filepath.ToSlash(name)
+			if stat.IsDir() || strings.HasSuffix(key, metadataSuffix) || strings.HasPrefix(stat.Name(), ".health-") || strings.HasPrefix(stat.Name(), partialPrefix) {
+				return nil
+			}
+			if strings.HasPrefix(key, prefix) && key > after {
+				entries = append(entries, entry{root: root, key: key, stat: stat})
+			}
+			return nil
+		})
+		if err != nil {
+			return domstorage.ObjectList{}, fmt.Errorf("storage: list %q: %w", prefix, err)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	if len(entries) > limit+1 {
+		entries = entries[:limit+1]
+	}
+
+	objects := make([]domstorage.ObjectInfo, 0, len(entries))
+	for _, e := range entries {
+		info, err := objectInfo(e.root, e.key, e.stat)
+		if err != nil {
+			return domstorage.ObjectList{}, err
+		}
+		objects = append(objects, info)
+	}
+	return pageObjects(objects, limit)
+}
+
+// Copy duplicates the object and its sidecar within the root it lives in.
+func (p *FilesystemStorageProvider) Copy(ctx context.Context, srcKey, dstKey string) error {
+	root, _, err := p.locate(srcKey)
+	if err != nil {
+		return err
+	}
+	if srcKey == dstKey {
+		return nil
+	}
+	if err := copyObject(root, root, srcKey, dstKey); err != nil {
+		return fmt.Errorf("storage: copy %q to %q: %w", srcKey, dstKey, err)
+	}
+	return nil
+}
+
+// Move renames the object and its sidecar within the root it lives in.
+func (p *FilesystemStorageProvider) Move(ctx context.Context, srcKey, dstKey string) error {
+	root, _, err := p.locate(srcKey)
+	if err != nil {
+		return err
+	}
+	if srcKey == dstKey {
+		return nil
+	}
+
+	src, dst := filepath.FromSlash(srcKey), filepath.FromSlash(dstKey)
+	if err := root.fs.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("storage: create directories for %q: %w", dstKey, err)
+	}
+	if err := root.fs.Rename(src, dst); err != nil {
+		return fmt.Errorf("storage: move %q to %q: %w", srcKey, dstKey, err)
+	}
+	if err := root.meta.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("storage: create metadata directories for %q: %w", dstKey, err)
+	}
+	if err := root.meta.Rename(src+metadataSuffix, dst+metadataSuffix); err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("storage: move metadata of %q: %w", srcKey, err)
+		}
+		if err := root.meta.Remove(dst + metadataSuffix); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("storage: remove stale metadata of %q: %w", dstKey, err)
+		}
+	}
+	return nil
+}
+
+// SetVisibility copies the object and its sidecar to the other root, then
+// removes the originals.
+func (p *FilesystemStorageProvider) SetVisibility(ctx context.Context, key string, v domstorage.Visibility) error {
+	root, _, err := p.locate(key)
+	if err != nil {
+		return err
+	}
+	if root.visibility == v {
+		return nil
+	}
+	if err := copyObject(root, p.rootFor(v), key, key); err != nil {
+		return fmt.Errorf("storage: change visibility of %q: %w", key, err)
+	}
+	if _, err := removeObject(root, key); err != nil {
+		return fmt.Errorf("storage: change visibility of %q: %w", key, err)
+	}
+	return nil
+}
+
+// SignedUpload returns a form upload to the internal handler at
+// /_storage/uploads. The policy travels in the "policy" field as base64url
+// JSON, signed in the "signature" field with STORAGE_SIGNING_SECRET; the
+// handler enforces it while streaming the file to disk.
+//
+// The HMAC message is "upload\n{policy}". Both this method and the handler in
+// FilesystemSignedUploadHandler.go must use this identical format.
+func (p *FilesystemStorageProvider) SignedUpload(ctx context.Context, policy domstorage.UploadPolicy) (domstorage.UploadRequest, error) {
+	if err := domstorage.ValidateUploadPolicy(policy); err != nil {
+		return domstorage.UploadRequest{}, err
+	}
+	ttl := policy.TTL
+	if ttl <= 0 {
+		ttl = p.defaultTTL
+	}
+	expiresAt := time.Now().Add(ttl).Unix()
+
+	data, err := json.Marshal(uploadGrant{
+		Key:          policy.Key,
+		KeyPrefix:    policy.KeyPrefix,
+		Visibility:   policy.Visibility,
+		MaxSizeBytes: policy.MaxSizeBytes,
+		ContentTypes: policy.ContentTypes,
+		Metadata:     normalizeMetadata(policy.Metadata),
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		return domstorage.UploadRequest{}, fmt.Errorf("storage: encode upload policy: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+
+	return domstorage.UploadRequest{
+		Method: http.MethodPost,
+		URL:    uploadPath,
+		Fields: map[string]string{
+			"key":       policy.Key + policy.KeyPrefix,
+			"policy":    encoded,
+			"signature": p.computeUploadHMAC(encoded),
+		},
+		FileField: uploadFileField,
+		ExpiresAt: time.Unix(expiresAt, 0),
+	}, nil
+}
+
+// HealthChecks reports whether both storage roots and the metadata root are
+// writable, so a full or read-only volume marks the instance unready.
+func (p *FilesystemStorageProvider) HealthChecks() []domhealth.Check {
+	return []domhealth.Check{
+		{Name: "storage.public", Run: func(context.Context) error { return probeWritable(p.publicFs) }},
+		{Name: "storage.private", Run: func(context.Context) error { return probeWritable(p.privateFs) }},
+		{Name: "storage.metadata", Run: func(context.Context) error { return probeWritable(p.privateMetaFs) }},
+	}
+}
+
+// probeWritable creates and removes a small file at the root of fs.
+func probeWritable(fs afero.Fs) error {
+	if err := fs.MkdirAll(".", 0755); err != nil {
+		return fmt.Errorf("storage: create root: %w", err)
+	}
+	f, err := afero.TempFile(fs, ".", ".health-*")
+	if err != nil {
+		return fmt.Errorf("storage: create probe file: %w", err)
+	}
+	_, writeErr := f.Write([]byte("ok"))
+	closeErr := f.Close()
+	removeErr := fs.Remove(f.Name())
+	if err := errors.Join(writeErr, closeErr, removeErr); err != nil {
+		return fmt.Errorf("storage: write probe file: %w", err)
+	}
+	return nil
+}
+
+// uploadGrant is the signed payload of a SignedUpload form.
+type uploadGrant struct {
+	Key          string                `json:"key,omitempty"`
+	KeyPrefix    string                `json:"key_prefix,omitempty"`
+	Visibility   domstorage.Visibility `json:"visibility"`
+	MaxSizeBytes int64                 `json:"max_size_bytes"`
+	ContentTypes []string              `json:"content_types,omitempty"`
+	Metadata     map[string]string     `json:"metadata,omitempty"`
+	ExpiresAt    int64                 `json:"expires"`
+}
+
+// policy returns the UploadPolicy the grant was signed for.
+func (g uploadGrant) policy() domstorage.UploadPolicy {
+	return domstorage.UploadPolicy{
+		Key:          g.Key,
+		KeyPrefix:    g.KeyPrefix,
+		Visibility:   g.Visibility,
+		MaxSizeBytes: g.MaxSizeBytes,
+		ContentTypes: g.ContentTypes,
+		Metadata:     g.Metadata,
+	}
+}
+
+// storageRoot pairs a root with the root holding its sidecars and the
+// visibility of the objects stored in it.
+type storageRoot struct {
+	fs         afero.Fs
+	meta       afero.Fs
+	visibility domstorage.Visibility
+}
+
+// roots returns both roots in probing order: public first, then private.
+func (p *FilesystemStorageProvider) roots() []storageRoot {
+	return []storageRoot{
+		p.rootFor(domstorage.VisibilityPublic),
+		p.rootFor(domstorage.VisibilityPrivate),
+	}
+}
+
+// rootFor returns the root storing objects of the given visibility.
+func (p *FilesystemStorageProvider) rootFor(v domstorage.Visibility) storageRoot {
 	if v == domstorage.VisibilityPrivate {
-		return p.privateFs
+		return storageRoot{fs: p.privateFs, meta: p.privateMetaFs, visibility: domstorage.VisibilityPrivate}
+	}
+	return storageRoot{fs: p.publicFs, meta: p.publicMetaFs, visibility: domstorage.VisibilityPublic}
+}
+
+// locate returns the root holding key and the object's file info.
+func (p *FilesystemStorageProvider) locate(key string) (storageRoot, os.FileInfo, error) {
+	fkey := filepath.FromSlash(key)
+	for _, root := range p.roots() {
+		stat, err := root.fs.Stat(fkey)
+		if err == nil && !stat.IsDir() {
+			return root, stat, nil
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return storageRoot{}, nil, fmt.Errorf("storage: stat %q: %w", key, err)
+		}
+	}
+	return storageRoot{}, nil, fmt.Errorf("%w: %q", domstorage.ErrObjectNotFound, key)
+}
+
+// objectSidecar is the content of the {key}.meta.json file.
+type objectSidecar struct {
+	ContentType string            `json:"content_type,omitempty"`
+	ETag        string            `json:"etag,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// objectInfo builds the ObjectInfo of a stored object from its file info and
+// sidecar. Objects without a sidecar get a content type from their extension.
+func objectInfo(root storageRoot, key string, stat os.FileInfo) (domstorage.ObjectInfo, error) {
+	sidecar, err := readSidecar(root.meta, filepath.FromSlash(key))
+	if err != nil {
+		return domstorage.ObjectInfo{}, fmt.Errorf("storage: read metadata of %q: %w", key, err)
+	}
+
+	ct := sidecar.ContentType
+	if ct == "" {
+		ct = mime.TypeByExtension(filepath.Ext(key))
+	}
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	return domstorage.ObjectInfo{
+		Key:         key,
+		SizeBytes:   stat.Size(),
+		ContentType: ct,
+		ModifiedAt:  stat.ModTime(),
+		Visibility:  root.visibility,
+		ETag:        sidecar.ETag,
+		Metadata:    sidecar.Metadata,
+	}, nil
+}
+
+// readSidecar returns the sidecar of the object at fkey, or an empty one
+// when the object has none.
+func readSidecar(fs afero.Fs, fkey string) (objectSidecar, error) {
+	var sidecar objectSidecar
+	data, err := afero.ReadFile(fs, fkey+metadataSuffix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sidecar, nil
+		}
+		return sidecar, err
+	}
+	err = json.Unmarshal(data, &sidecar)
+	return sidecar, err
+}
+
+// writeSidecar stores the sidecar of the object at fkey, replacing the
+// previous one only once the new one is written.
+func writeSidecar(fs afero.Fs, fkey string, sidecar objectSidecar) error {
+	data, err := json.Marshal(sidecar)
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(filepath.Dir(fkey), 0755); err != nil {
+		return err
+	}
+	return writeAtomically(fs, fkey+metadataSuffix, func(f afero.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+}
+
+// writeAtomically runs write on a temporary file in the directory of name
+// and renames it over name when write succeeds. The temporary file is
+// removed otherwise.
+func writeAtomically(fs afero.Fs, name string, write func(afero.File) error) error {
+	f, err := afero.TempFile(fs, filepath.Dir(name), partialPrefix+"*")
+	if err != nil {
+		return err
+	}
+	writeErr := write(f)
+	closeErr := f.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		return errors.Join(err, removeIfExists(fs, f.Name()))
+	}
+
+	// TempFile creates 0600 files; objects keep the permissions of Create
+	if err := fs.Chmod(f.Name(), 0644); err != nil {
+		return errors.Join(err, removeIfExists(fs, f.Name()))
+	}
+	if err := fs.Rename(f.Name(), name); err != nil {
+		return errors.Join(err, removeIfExists(fs, f.Name()))
+	}
+	return nil
+}
+
+// removeIfExists deletes name, ignoring a file that is already gone.
+func removeIfExists(fs afero.Fs, name string) error {
+	if err := fs.Remove(name); err != nil && !os.IsNotExist(err) {
+		return err
 	}
-	return p.publicFs
+	return nil
+}
+
+// copyObject copies the object at srcKey in from to dstKey in to, together
+// with its sidecar.
+func copyObject(from, to storageRoot, srcKey, dstKey string) error {
+	src, dst := filepath.FromSlash(srcKey), filepath.FromSlash(dstKey)
+	if err := to.fs.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	in, err := from.fs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := to.fs.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	sidecar, err := readSidecar(from.meta, src)
+	if err != nil {
+		return err
+	}
+	return writeSidecar(to.meta, dst, sidecar)
+}
+
+// removeObject deletes the object at key and its sidecar from root. It
+// reports whether the object existed.
+func removeObject(root storageRoot, key string) (bool, error) {
+	fkey := filepath.FromSlash(key)
+	if err := root.fs.Remove(fkey); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := root.meta.Remove(fkey + metadataSuffix); err != nil && !os.IsNotExist(err) {
+		return true, err
+	}
+	return true, nil
 }
 
 // computeHMAC returns the hex-encoded HMAC-SHA256 of the canonical signed
-// message for key and expiresAt. Must remain identical to the verification
-// logic in FilesystemSignedUrlHandler.
-func (p *FilesystemStorageProvider) computeHMAC(key string, expiresAt int64) string {
+// message for key, expiresAt and the optional disposition. Must remain
+// identical to the verification logic in FilesystemSignedUrlHandler.
+func (p *FilesystemStorageProvider) computeHMAC(key string, expiresAt int64, disposition string) string {
+	return hex.EncodeToString(signedURLMAC(p.signingSecret, key, expiresAt, disposition))
+}
+
+// computeUploadHMAC returns the hex-encoded HMAC-SHA256 of a SignedUpload
+// policy. The "upload" line keeps upload signatures apart from the download
+// signatures of computeHMAC. Must remain identical to the verification logic
+// in FilesystemSignedUploadHandler.
+func (p *FilesystemStorageProvider) computeUploadHMAC(policy string) string {
 	mac := hmac.New(sha256.New, p.signingSecret)
-	fmt.Fprintf(mac, "%s\n%d", key, expiresAt)
+	fmt.Fprintf(mac, "upload\n%s", policy)
 	return hex.EncodeToString(mac.Sum(nil))
 }
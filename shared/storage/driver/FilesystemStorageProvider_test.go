@@ -10,8 +10,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -80,6 +82,8 @@ func TestFilesystemStorageProvider_respectsVisibilityRootsAndPublicURL(t *testin
 	assert.NoFileExists(t, filepath.Join(roots.private, filepath.FromSlash(publicKey)))
 	assert.FileExists(t, filepath.Join(roots.private, filepath.FromSlash(privateKey)))
 	assert.NoFileExists(t, filepath.Join(roots.public, filepath.FromSlash(privateKey)))
+	assert.NoFileExists(t, filepath.Join(roots.public, filepath.FromSlash(publicKey))+".meta.json", "sidecars stay out of the served root")
+	assert.FileExists(t, filepath.Join(roots.metadata, "public", filepath.FromSlash(publicKey))+".meta.json")
 	assert.Equal(t, "https://cdn.example.test/assets/"+publicKey, provider.PublicURL(publicKey))
 }
 
@@ -109,6 +113,118 @@ func TestFilesystemStorageProvider_deleteAndExistsAreIdempotent(t *testing.T) {
 	assert.NoError(t, provider.Delete(context.Background(), key))
 }
 
+// TestFilesystemStorageProvider_healthChecksProbeEveryRoot verifies that the
+// readiness checks create the roots and leave no probe files behind.
+func TestFilesystemStorageProvider_healthChecksProbeEveryRoot(t *testing.T) {
+	provider, roots := newFilesystemProvider(t)
+
+	checks := provider.HealthChecks()
+	require.Len(t, checks, 3)
+	for _, check := range checks {
+		require.NoError(t, check.Run(context.Background()), check.Name)
+	}
+
+	for _, root := range []string{roots.public, roots.private, filepath.Join(roots.metadata, "private")} {
+		entries, err := os.ReadDir(root)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	}
+}
+
+// TestFilesystemStorageProvider_storesMetadataInSidecars verifies that the
+// content type, ETag and custom metadata survive in the sidecar file and are
+// returned by Stat and Get.
+func TestFilesystemStorageProvider_storesMetadataInSidecars(t *testing.T) {
+	provider, roots := newFilesystemProvider(t)
+	key := newStorageKey(t, "documents", "invoice", ".bin")
+
+	result, err := provider.Put(context.Background(), key, strings.NewReader("invoice"), storagedomain.PutOptions{
+		Visibility:  storagedomain.VisibilityPrivate,
+		ContentType: "application/pdf",
+		Metadata:    map[string]string{"Owner-ID": "42"},
+	})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(roots.metadata, "private", filepath.FromSlash(key))+".meta.json")
+	assert.NoFileExists(t, filepath.Join(roots.private, filepath.FromSlash(key))+".meta.json")
+
+	info, err := provider.Stat(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", info.ContentType)
+	assert.Equal(t, result.ETag, info.ETag)
+	assert.Equal(t, storagedomain.VisibilityPrivate, info.Visibility)
+	assert.Equal(t, map[string]string{"owner-id": "42"}, info.Metadata)
+
+	reader, got, err := provider.Get(context.Background(), key)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, info, got)
+
+	require.NoError(t, provider.Delete(context.Background(), key))
+	assert.NoFileExists(t, filepath.Join(roots.metadata, "private", filepath.FromSlash(key))+".meta.json")
+	_, err = provider.Stat(context.Background(), key)
+	assert.ErrorIs(t, err, storagedomain.ErrObjectNotFound)
+}
+
+// TestFilesystemStorageProvider_listsBothRootsInPages walks a prefix across
+// the public and private roots with a small page size.
+func TestFilesystemStorageProvider_listsBothRootsInPages(t *testing.T) {
+	provider, _ := newFilesystemProvider(t)
+	var keys []string
+	for i := range 5 {
+		key := newStorageKey(t, "galleries", "photo", ".jpg")
+		visibility := storagedomain.VisibilityPublic
+		if i%2 == 1 {
+			visibility = storagedomain.VisibilityPrivate
+		}
+		_, err := provider.Put(context.Background(), key, strings.NewReader("photo"), storagedomain.PutOptions{Visibility: visibility})
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	_, err := provider.Put(context.Background(), newStorageKey(t, "galleries", "video", ".mp4"), strings.NewReader("video"), storagedomain.PutOptions{})
+	require.NoError(t, err)
+
+	listed := listAll(t, provider, "galleries/photo/", 2)
+
+	assert.Equal(t, keys, listed)
+	_, err = provider.List(context.Background(), "galleries/photo/", "not-a-cursor", 2)
+	assert.ErrorIs(t, err, storagedomain.ErrInvalidCursor)
+}
+
+// TestFilesystemStorageProvider_copyMoveAndSetVisibility keeps the sidecar
+// with the object through every operation.
+func TestFilesystemStorageProvider_copyMoveAndSetVisibility(t *testing.T) {
+	provider, roots := newFilesystemProvider(t)
+	src := newStorageKey(t, "documents", "draft", ".txt")
+	copied := newStorageKey(t, "documents", "copy", ".txt")
+	moved := newStorageKey(t, "documents", "final", ".txt")
+	_, err := provider.Put(context.Background(), src, strings.NewReader("draft"), storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPrivate,
+		Metadata:   map[string]string{"author": "ana"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Copy(context.Background(), src, copied))
+	require.NoError(t, provider.Move(context.Background(), src, moved))
+	require.NoError(t, provider.SetVisibility(context.Background(), moved, storagedomain.VisibilityPublic))
+
+	exists, err := provider.Exists(context.Background(), src)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	for key, visibility := range map[string]storagedomain.Visibility{
+		copied: storagedomain.VisibilityPrivate,
+		moved:  storagedomain.VisibilityPublic,
+	} {
+		info, err := provider.Stat(context.Background(), key)
+		require.NoError(t, err)
+		assert.Equal(t, visibility, info.Visibility)
+		assert.Equal(t, map[string]string{"author": "ana"}, info.Metadata)
+	}
+	assert.NoFileExists(t, filepath.Join(roots.private, filepath.FromSlash(moved)))
+	assert.NoFileExists(t, filepath.Join(roots.metadata, "private", filepath.FromSlash(moved))+".meta.json")
+	assert.FileExists(t, filepath.Join(roots.metadata, "public", filepath.FromSlash(moved))+".meta.json")
+	assert.ErrorIs(t, provider.Copy(context.Background(), src, copied), storagedomain.ErrObjectNotFound)
+}
+
 // TestFilesystemSignedURLHandler_servesValidURL covers the happy path for the
 // internal static handler used by filesystem signed URLs.
 func TestFilesystemSignedURLHandler_servesValidURL(t *testing.T) {
@@ -124,6 +240,8 @@ func TestFilesystemSignedURLHandler_servesValidURL(t *testing.T) {
 	assert.Equal(t, http.StatusOK, recorder.Code)
 	assert.Equal(t, "secret", recorder.Body.String())
 	assert.Equal(t, "text/plain; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "sandbox", recorder.Header().Get("Content-Security-Policy"))
 }
 
 // TestFilesystemSignedURLHandler_rejectsExpiredURL verifies that TTL validation
@@ -195,6 +313,153 @@ func TestFilesystemSignedURLHandler_returnsNotFoundForMissingKey(t *testing.T) {
 	}`, recorder.Body.String())
 }
 
+// TestFilesystemSignedURLHandler_servesRanges verifies partial content for
+// the video and large document downloads that seek within a file.
+func TestFilesystemSignedURLHandler_servesRanges(t *testing.T) {
+	provider, _ := newFilesystemProvider(t)
+	key := newStorageKey(t, "media", "video", ".mp4")
+	_, err := provider.Put(context.Background(), key, strings.NewReader("0123456789"), storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPrivate,
+	})
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, mustSignedURL(t, provider, key).String(), nil)
+	request.Header.Set("Range", "bytes=2-5")
+	recorder := serveStorageRequest(provider, request)
+
+	assert.Equal(t, http.StatusPartialContent, recorder.Code)
+	assert.Equal(t, "2345", recorder.Body.String())
+	assert.Equal(t, "bytes 2-5/10", recorder.Header().Get("Content-Range"))
+	assert.Equal(t, "video/mp4", recorder.Header().Get("Content-Type"))
+}
+
+// TestFilesystemSignedURLHandler_answersConditionalRequests checks the MD5
+// ETag and modification time revalidate cached copies, and that caches keep
+// them no longer than the signature is valid.
+func TestFilesystemSignedURLHandler_answersConditionalRequests(t *testing.T) {
+	provider, _ := newFilesystemProvider(t)
+	key := newStorageKey(t, "invoices", "pdfs", ".pdf")
+	result, err := provider.Put(context.Background(), key, strings.NewReader("%PDF-1.7"), storagedomain.PutOptions{
+		Visibility:  storagedomain.VisibilityPrivate,
+		ContentType: "application/pdf",
+	})
+	require.NoError(t, err)
+	signed := mustSignedURL(t, provider, key)
+
+	recorder := serveSignedURL(t, provider, signed)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `"`+result.ETag+`"`, recorder.Header().Get("ETag"))
+	assert.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+	maxAge, ok := strings.CutPrefix(recorder.Header().Get("Cache-Control"), "private, max-age=")
+	require.True(t, ok)
+	seconds, err := strconv.Atoi(maxAge)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour.Seconds(), seconds, 5)
+
+	request := httptest.NewRequest(http.MethodGet, signed.String(), nil)
+	request.Header.Set("If-None-Match", recorder.Header().Get("ETag"))
+	recorder = serveStorageRequest(provider, request)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+
+	request = httptest.NewRequest(http.MethodGet, signed.String(), nil)
+	request.Header.Set("If-Modified-Since", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	recorder = serveStorageRequest(provider, request)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	request = httptest.NewRequest(http.MethodGet, signed.String(), nil)
+	request.Header.Set("If-None-Match", `"stale"`)
+	recorder = serveStorageRequest(provider, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+// TestFilesystemSignedURLHandler_sendsSignedDisposition verifies the
+// Content-Disposition is part of the signature and reaches the response.
+func TestFilesystemSignedURLHandler_sendsSignedDisposition(t *testing.T) {
+	provider, _ := newFilesystemProvider(t)
+	key := newStorageKey(t, "invoices", "pdfs", ".pdf")
+	_, err := provider.Put(context.Background(), key, strings.NewReader("%PDF-1.7"), storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPrivate,
+	})
+	require.NoError(t, err)
+
+	rawURL, err := provider.SignedURLWithOptions(context.Background(), key, storagedomain.SignedURLOptions{
+		ContentDisposition: storagedomain.AttachmentDisposition("invoice 2026.pdf"),
+	})
+	require.NoError(t, err)
+	signed, err := url.Parse(rawURL)
+	require.NoError(t, err)
+
+	recorder := serveSignedURL(t, provider, signed)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `attachment; filename="invoice 2026.pdf"`, recorder.Header().Get("Content-Disposition"))
+
+	values := signed.Query()
+	values.Set("disposition", "inline")
+	signed.RawQuery = values.Encode()
+	assert.Equal(t, http.StatusForbidden, serveSignedURL(t, provider, signed).Code)
+
+	values.Del("disposition")
+	signed.RawQuery = values.Encode()
+	assert.Equal(t, http.StatusForbidden, serveSignedURL(t, provider, signed).Code)
+
+	_, err = provider.SignedURLWithOptions(context.Background(), key, storagedomain.SignedURLOptions{ContentDisposition: "evil"})
+	assert.Error(t, err)
+}
+
+// TestFilesystemPublicFileHandler_servesPublicRootOnly covers the static
+// handler: public objects get shared caching and ranges, while private
+// objects and sidecars are never reachable through it.
+func TestFilesystemPublicFileHandler_servesPublicRootOnly(t *testing.T) {
+	provider, _ := newFilesystemProvider(t)
+	publicKey := newStorageKey(t, "profiles", "avatar", ".png")
+	privateKey := newStorageKey(t, "profiles", "contract", ".pdf")
+	result, err := provider.Put(context.Background(), publicKey, strings.NewReader("png-bytes"), storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPublic,
+	})
+	require.NoError(t, err)
+	_, err = provider.Put(context.Background(), privateKey, strings.NewReader("private"), storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPrivate,
+	})
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/_public/"+publicKey, nil)
+	request.Header.Set("Range", "bytes=0-2")
+	recorder := serveStorageRequest(provider, request)
+	assert.Equal(t, http.StatusPartialContent, recorder.Code)
+	assert.Equal(t, "png", recorder.Body.String())
+	assert.Equal(t, "public, max-age=3600", recorder.Header().Get("Cache-Control"))
+	assert.Equal(t, `"`+result.ETag+`"`, recorder.Header().Get("ETag"))
+	assert.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+	assert.Empty(t, recorder.Header().Get("Content-Security-Policy"), "raster images need no sandbox")
+
+	for _, path := range []string{"/_public/" + privateKey, "/_public/" + publicKey + ".meta.json", "/_public/profiles/avatar"} {
+		recorder := serveStorageRequest(provider, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, recorder.Code, path)
+	}
+}
+
+// TestFilesystemPublicFileHandler_sandboxesActiveContent keeps stored SVG and
+// HTML from running scripts on the service's origin.
+func TestFilesystemPublicFileHandler_sandboxesActiveContent(t *testing.T) {
+	provider, _ := newFilesystemProvider(t)
+	for _, contentType := range []string{"image/svg+xml", "text/html", "application/pdf"} {
+		key := newStorageKey(t, "profiles", "banner", ".bin")
+		_, err := provider.Put(context.Background(), key, strings.NewReader("<svg><script>alert(1)</script></svg>"), storagedomain.PutOptions{
+			Visibility:  storagedomain.VisibilityPublic,
+			ContentType: contentType,
+		})
+		require.NoError(t, err)
+
+		recorder := serveStorageRequest(provider, httptest.NewRequest(http.MethodGet, "/_public/"+key, nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code, contentType)
+		assert.Equal(t, contentType, recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"), contentType)
+		assert.Equal(t, "sandbox", recorder.Header().Get("Content-Security-Policy"), contentType)
+	}
+}
+
 // TestNewFilesystemStorageProvider_rejectsInvalidConfiguration covers startup
 // failures before a misconfigured service can accept storage traffic.
 func TestNewFilesystemStorageProvider_rejectsInvalidConfiguration(t *testing.T) {
@@ -209,6 +474,10 @@ func TestNewFilesystemStorageProvider_rejectsInvalidConfiguration(t *testing.T)
 			"STORAGE_SIGNING_SECRET":         "736563726574",
 			"STORAGE_SIGNED_URL_DEFAULT_TTL": "forever",
 		},
+		"negative-public-max-age": {
+			"STORAGE_SIGNING_SECRET":       "736563726574",
+			"STORAGE_PUBLIC_CACHE_MAX_AGE": "-1h",
+		},
 	}
 
 	for name, values := range tests {
@@ -220,8 +489,9 @@ func TestNewFilesystemStorageProvider_rejectsInvalidConfiguration(t *testing.T)
 }
 
 type storageRoots struct {
-	public  string
-	private string
+	public   string
+	private  string
+	metadata string
 }
 
 // newFilesystemProvider creates a real filesystem-backed provider rooted in the
@@ -231,12 +501,14 @@ func newFilesystemProvider(t *testing.T) (*driver.FilesystemStorageProvider, sto
 
 	base := t.TempDir()
 	roots := storageRoots{
-		public:  filepath.Join(base, "public"),
-		private: filepath.Join(base, "private"),
+		public:   filepath.Join(base, "public"),
+		private:  filepath.Join(base, "private"),
+		metadata: filepath.Join(base, "metadata"),
 	}
 	provider, err := driver.NewFilesystemStorageProvider(mapEnv{
 		"STORAGE_PUBLIC_ROOT":            roots.public,
 		"STORAGE_PRIVATE_ROOT":           roots.private,
+		"STORAGE_METADATA_ROOT":          roots.metadata,
 		"STORAGE_PUBLIC_BASE_URL":        "https://cdn.example.test/assets/",
 		"STORAGE_SIGNING_SECRET":         "7365637265742d666f722d7465737473",
 		"STORAGE_SIGNED_URL_DEFAULT_TTL": "10m",
@@ -259,6 +531,27 @@ func newStorageKey(t *testing.T, namespace, usage, ext string) string {
 	return key
 }
 
+// listAll follows the cursors of List until the last page and returns the
+// listed keys.
+func listAll(t *testing.T, provider storagedomain.StorageProvider, prefix string, limit int) []string {
+	t.Helper()
+
+	var keys []string
+	cursor := ""
+	for {
+		page, err := provider.List(context.Background(), prefix, cursor, limit)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page.Objects), limit)
+		for _, object := range page.Objects {
+			keys = append(keys, object.Key)
+		}
+		if page.NextCursor == "" {
+			return keys
+		}
+		cursor = page.NextCursor
+	}
+}
+
 // mustSignedURL parses the relative URL returned by the provider for handler
 // tests that need to mutate or replay its query parameters.
 func mustSignedURL(t *testing.T, provider *driver.FilesystemStorageProvider, key string) *url.URL {
@@ -272,17 +565,23 @@ func mustSignedURL(t *testing.T, provider *driver.FilesystemStorageProvider, key
 	return parsed
 }
 
-// serveSignedURL mounts only the signed URL route so handler tests exercise the
-// same Echo routing pattern used by EchoStorageModule.
+// serveSignedURL sends a plain GET for the signed URL through
+// serveStorageRequest.
 func serveSignedURL(t *testing.T, provider *driver.FilesystemStorageProvider, signed *url.URL) *httptest.ResponseRecorder {
 	t.Helper()
 
+	return serveStorageRequest(provider, httptest.NewRequest(http.MethodGet, signed.String(), nil))
+}
+
+// serveStorageRequest mounts the download routes so handler tests exercise
+// the same Echo routing pattern used by EchoStorageModule.
+func serveStorageRequest(provider *driver.FilesystemStorageProvider, request *http.Request) *httptest.ResponseRecorder {
 	e := echo.New()
 	e.HTTPErrorHandler = rest.EchoErrorHandler
 	e.GET("/_storage/*", driver.NewSignedUrlHandler(provider))
+	e.GET("/_public/*", driver.NewPublicFileHandler(provider))
 
 	recorder := httptest.NewRecorder()
-	request := httptest.NewRequest(http.MethodGet, signed.String(), nil)
 	e.ServeHTTP(recorder, request)
 	return recorder
 }
@@ -344,8 +643,18 @@ func (e mapEnv) GetString(key, defaultValue string) string {
 	return defaultValue
 }
 
-func (e mapEnv) GetBool(_ string, defaultValue bool) bool { return defaultValue }
-func (e mapEnv) GetInt(_ string, defaultValue int) int    { return defaultValue }
+func (e mapEnv) GetBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(e[key]); err == nil {
+		return value
+	}
+	return defaultValue
+}
+func (e mapEnv) GetInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(e[key]); err == nil {
+		return value
+	}
+	return defaultValue
+}
 func (e mapEnv) GetFloat(_ string, defaultValue float64) float64 {
 	return defaultValue
 }
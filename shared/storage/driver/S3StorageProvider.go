@@ -0,0 +1,593 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/r0x16/Raidark/shared/api/rest"
+	domenv "github.com/r0x16/Raidark/shared/env/domain"
+	domhealth "github.com/r0x16/Raidark/shared/health/domain"
+	domstorage "github.com/r0x16/Raidark/shared/storage/domain"
+)
+
+const (
+	// S3VisibilityPrefix stores public and private objects under two key
+	// prefixes of the bucket; a bucket policy grants anonymous reads on the
+	// public one.
+	S3VisibilityPrefix = "prefix"
+	// S3VisibilityACL stores objects under their own key and marks them with
+	// the public-read or private canned ACL.
+	S3VisibilityACL = "acl"
+)
+
+// minS3PartSizeMB is the smallest part S3 accepts in a multipart upload.
+const minS3PartSizeMB = 5
+
+// S3StorageProvider implements StorageProvider against S3 or any
+// S3-compatible store such as MinIO.
+//
+// Visibility is mapped either to the STORAGE_S3_PUBLIC_PREFIX and
+// STORAGE_S3_PRIVATE_PREFIX prefixes or to canned object ACLs, depending on
+// STORAGE_S3_VISIBILITY_MODE. Signed URLs are presigned GETs issued by the
+// store, so no internal handler is needed. Custom metadata is stored as
+// x-amz-meta-* headers.
+//
+// In acl mode the visibility of an object is read back from its ACL, which
+// costs one extra request per object in Get, Stat and List.
+type S3StorageProvider struct {
+	client        *minio.Client
+	bucket        string
+	mode          string
+	publicPrefix  string
+	privatePrefix string
+	publicBaseURL string
+	defaultTTL    time.Duration
+	partSize      uint64
+}
+
+// NewS3StorageProvider constructs an S3StorageProvider from environment
+// variables. STORAGE_S3_BUCKET is required. Without STORAGE_S3_ACCESS_KEY the
+// credentials come from the AWS_* variables or the instance's IAM role.
+func NewS3StorageProvider(env domenv.EnvProvider) (*S3StorageProvider, error) {
+	bucket := env.GetString("STORAGE_S3_BUCKET", "")
+	if bucket == "" {
+		return nil, errors.New("storage: STORAGE_S3_BUCKET must not be empty")
+	}
+
+	mode := env.GetString("STORAGE_S3_VISIBILITY_MODE", S3VisibilityPrefix)
+	if mode != S3VisibilityPrefix && mode != S3VisibilityACL {
+		return nil, fmt.Errorf("storage: invalid STORAGE_S3_VISIBILITY_MODE %q", mode)
+	}
+
+	ttlStr := env.GetString("STORAGE_SIGNED_URL_DEFAULT_TTL", "600s")
+	ttl, err := time.ParseDuration(ttlStr)
+	if err != nil {
+		return nil, fmt.Errorf("storage: invalid STORAGE_SIGNED_URL_DEFAULT_TTL %q: %w", ttlStr, err)
+	}
+
+	partSizeMB := env.GetInt("STORAGE_S3_PART_SIZE_MB", 16)
+	if partSizeMB < minS3PartSizeMB {
+		return nil, fmt.Errorf("storage: STORAGE_S3_PART_SIZE_MB must be at least %d", minS3PartSizeMB)
+	}
+
+	endpoint := env.GetString("STORAGE_S3_ENDPOINT", "s3.amazonaws.com")
+	secure := env.GetBool("STORAGE_S3_USE_SSL", true)
+	if rest, ok := strings.CutPrefix(endpoint, "https://"); ok {
+		endpoint, secure = rest, true
+	} else if rest, ok := strings.CutPrefix(endpoint, "http://"); ok {
+		endpoint, secure = rest, false
+	}
+
+	lookup := minio.BucketLookupAuto
+	if env.GetBool("STORAGE_S3_PATH_STYLE", false) {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(strings.TrimRight(endpoint, "/"), &minio.Options{
+		Creds:        s3Credentials(env),
+		Secure:       secure,
+		Region:       env.GetString("STORAGE_S3_REGION", "us-east-1"),
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: create S3 client: %w", err)
+	}
+
+	return &S3StorageProvider{
+		client:        client,
+		bucket:        bucket,
+		mode:          mode,
+		publicPrefix:  env.GetString("STORAGE_S3_PUBLIC_PREFIX", "public/"),
+		privatePrefix: env.GetString("STORAGE_S3_PRIVATE_PREFIX", "private/"),
+		publicBaseURL: env.GetString("STORAGE_PUBLIC_BASE_URL", ""),
+		defaultTTL:    ttl,
+		partSize:      uint64(partSizeMB) * 1024 * 1024,
+	}, nil
+}
+
+// s3Credentials uses the static STORAGE_S3_* keys when set, and otherwise
+// the standard AWS environment variables followed by the IAM role.
+func s3Credentials(env domenv.EnvProvider) *credentials.Credentials {
+	if accessKey := env.GetString("STORAGE_S3_ACCESS_KEY", ""); accessKey != "" {
+		return credentials.NewStaticV4(accessKey, env.GetString("STORAGE_S3_SECRET_KEY", ""), "")
+	}
+	return credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+	})
+}
+
+// Put streams r to the store. Readers larger than STORAGE_S3_PART_SIZE_MB,
+// or of unknown size, are sent as a multipart upload holding one part in
+// memory at a time. The ETag is the one returned by the store.
+func (p *S3StorageProvider) Put(ctx context.Context, key string, r io.Reader, opts domstorage.PutOptions) (domstorage.PutResult, error) {
+	size := opts.Size
+	if size <= 0 {
+		size = -1
+	}
+
+	putOpts := minio.PutObjectOptions{
+		ContentType:  opts.ContentType,
+		PartSize:     p.partSize,
+		UserMetadata: p.userMetadata(opts.Metadata, opts.Visibility),
+	}
+
+	info, err := p.client.PutObject(ctx, p.bucket, p.objectName(key, opts.Visibility), r, size, putOpts)
+	if err != nil {
+		return domstorage.PutResult{}, fmt.Errorf("storage: write %q: %w", key, err)
+	}
+
+	return domstorage.PutResult{
+		Key:       key,
+		SizeBytes: info.Size,
+		ETag:      info.ETag,
+	}, nil
+}
+
+// Get opens the object for reading and returns its metadata. In prefix mode
+// it probes the public prefix first, then the private one.
+// The caller must close the returned ReadCloser.
+func (p *S3StorageProvider) Get(ctx context.Context, key string) (io.ReadCloser, domstorage.ObjectInfo, error) {
+	for _, location := range p.locations(key) {
+		object, err := p.client.GetObject(ctx, p.bucket, location.name, minio.GetObjectOptions{})
+		if err != nil {
+			return nil, domstorage.ObjectInfo{}, fmt.Errorf("storage: open %q: %w", key, err)
+		}
+
+		stat, err := object.Stat()
+		if err != nil {
+			object.Close()
+			if isS3NotFound(err) {
+				continue
+			}
+			return nil, domstorage.ObjectInfo{}, fmt.Errorf("storage: stat %q: %w", key, err)
+		}
+
+		info := s3ObjectInfo(key, location.visibility, stat)
+		if p.mode == S3VisibilityACL {
+			acl, err := p.client.GetObjectACL(ctx, p.bucket, location.name)
+			if err != nil {
+				object.Close()
+				return nil, domstorage.ObjectInfo{}, fmt.Errorf("storage: read ACL of %q: %w", key, err)
+			}
+			info.Visibility = aclVisibility(acl)
+		}
+		return object, info, nil
+	}
+	return nil, domstorage.ObjectInfo{}, fmt.Errorf("%w: %q", domstorage.ErrObjectNotFound, key)
+}
+
+// Delete removes the object from every location it may live in.
+// Delete is idempotent: a missing key returns nil.
+func (p *S3StorageProvider) Delete(ctx context.Context, key string) error {
+	for _, location := range p.locations(key) {
+		if err := p.client.RemoveObject(ctx, p.bucket, location.name, minio.RemoveObjectOptions{}); err != nil && !isS3NotFound(err) {
+			return fmt.Errorf("storage: delete %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// SignedURL returns a presigned GET for the object. The signature is
+// computed locally; in prefix mode the object is looked up first so the URL
+// targets the prefix it was stored under.
+func (p *S3StorageProvider) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return p.SignedURLWithOptions(ctx, key, domstorage.SignedURLOptions{TTL: ttl})
+}
+
+// SignedURLWithOptions returns the presigned GET of SignedURL. A
+// ContentDisposition is signed as the response-content-disposition
+// parameter, which the store sends back as the header.
+func (p *S3StorageProvider) SignedURLWithOptions(ctx context.Context, key string, opts domstorage.SignedURLOptions) (string, error) {
+	params := url.Values{}
+	if opts.ContentDisposition != "" {
+		if err := domstorage.ValidateContentDisposition(opts.ContentDisposition); err != nil {
+			return "", err
+		}
+		params.Set("response-content-disposition", opts.ContentDisposition)
+	}
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = p.defaultTTL
+	}
+
+	name, err := p.locate(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := p.client.PresignedGetObject(ctx, p.bucket, name, ttl, params)
+	if err != nil {
+		return "", fmt.Errorf("storage: presign %q: %w", key, err)
+	}
+	return u.String(), nil
+}
+
+// PublicURL returns the absolute public URL for the given key. With
+// STORAGE_PUBLIC_BASE_URL set, typically a CDN whose origin is the public
+// prefix, the URL is that base plus the key; otherwise it points at the
+// object in the bucket directly.
+func (p *S3StorageProvider) PublicURL(key string) string {
+	if p.publicBaseURL != "" {
+		return strings.TrimRight(p.publicBaseURL, "/") + "/" + key
+	}
+	endpoint := p.client.EndpointURL()
+	return endpoint.Scheme + "://" + endpoint.Host + "/" + p.bucket + "/" + p.objectName(key, domstorage.VisibilityPublic)
+}
+
+// Exists reports whether the object is present under any of its locations.
+func (p *S3StorageProvider) Exists(ctx context.Context, key string) (bool, error) {
+	for _, location := range p.locations(key) {
+		_, err := p.client.StatObject(ctx, p.bucket, location.name, minio.StatObjectOptions{})
+		if err == nil {
+			return true, nil
+		}
+		if !isS3NotFound(err) {
+			return false, fmt.Errorf("storage: stat %q: %w", key, err)
+		}
+	}
+	return false, nil
+}
+
+// Stat returns the object's metadata from a HEAD request, or from its ACL in
+// acl mode.
+func (p *S3StorageProvider) Stat(ctx context.Context, key string) (domstorage.ObjectInfo, error) {
+	info, _, err := p.stat(ctx, key)
+	return info, err
+}
+
+// List merges the store's listings of both prefixes (or of the bucket in acl
+// mode) in key order and returns the page after the cursor. Listings carry
+// no content type nor metadata, so those fields are left empty.
+func (p *S3StorageProvider) List(ctx context.Context, prefix, cursor string, limit int) (domstorage.ObjectList, error) {
+	after, err := decodeListCursor(cursor, prefix)
+	if err != nil {
+		return domstorage.ObjectList{}, err
+	}
+	limit = rest.ClampLimit(limit)
+
+	var objects []domstorage.ObjectInfo
+	for _, location := range p.locations("") {
+		opts := minio.ListObjectsOptions{Prefix: location.name + prefix, Recursive: true, MaxKeys: limit + 1}
+		if after != "" {
+			opts.StartAfter = location.name + after
+		}
+
+		found := 0
+		for object := range p.client.ListObjectsIter(ctx, p.bucket, opts) {
+			if object.Err != nil {
+				return domstorage.ObjectList{}, fmt.Errorf("storage: list %q: %w", prefix, object.Err)
+			}
+			objects = append(objects, domstorage.ObjectInfo{
+				Key:        strings.TrimPrefix(object.Key, location.name),
+				SizeBytes:  object.Size,
+				ModifiedAt: object.LastModified,
+				Visibility: location.visibility,
+				ETag:       object.ETag,
+			})
+			if found++; found > limit {
+				break
+			}
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	if len(objects) > limit+1 {
+		objects = objects[:limit+1]
+	}
+	if p.mode == S3VisibilityACL {
+		for i := range objects[:min(len(objects), limit)] {
+			acl, err := p.client.GetObjectACL(ctx, p.bucket, objects[i].Key)
+			if err != nil {
+				return domstorage.ObjectList{}, fmt.Errorf("storage: read ACL of %q: %w", objects[i].Key, err)
+			}
+			objects[i].Visibility = aclVisibility(acl)
+		}
+	}
+	return pageObjects(objects, limit)
+}
+
+// Copy duplicates the object with a server-side copy. Prefix mode keeps the
+// prefix and metadata as they are; acl mode rewrites the metadata together
+// with the ACL, which S3 does not copy.
+func (p *S3StorageProvider) Copy(ctx context.Context, srcKey, dstKey string) error {
+	info, name, err := p.stat(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	if srcKey == dstKey {
+		return nil
+	}
+	if err := p.copyObject(ctx, info, name, p.objectName(dstKey, info.Visibility)); err != nil {
+		return fmt.Errorf("storage: copy %q to %q: %w", srcKey, dstKey, err)
+	}
+	return nil
+}
+
+// Move copies the object to dstKey and then removes srcKey.
+func (p *S3StorageProvider) Move(ctx context.Context, srcKey, dstKey string) error {
+	info, name, err := p.stat(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	if srcKey == dstKey {
+		return nil
+	}
+	if err := p.copyObject(ctx, info, name, p.objectName(dstKey, info.Visibility)); err != nil {
+		return fmt.Errorf("storage: move %q to %q: %w", srcKey, dstKey, err)
+	}
+	if err := p.client.RemoveObject(ctx, p.bucket, name, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("storage: move %q to %q: %w", srcKey, dstKey, err)
+	}
+	return nil
+}
+
+// SetVisibility moves the object to the other prefix, or rewrites its ACL
+// with a copy onto itself in acl mode.
+func (p *S3StorageProvider) SetVisibility(ctx context.Context, key string, v domstorage.Visibility) error {
+	info, name, err := p.stat(ctx, key)
+	if err != nil {
+		return err
+	}
+	if info.Visibility == v {
+		return nil
+	}
+
+	info.Visibility = v
+	target := p.objectName(key, v)
+	if err := p.copyObject(ctx, info, name, target); err != nil {
+		return fmt.Errorf("storage: change visibility of %q: %w", key, err)
+	}
+	if target != name {
+		if err := p.client.RemoveObject(ctx, p.bucket, name, minio.RemoveObjectOptions{}); err != nil {
+			return fmt.Errorf("storage: change visibility of %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// SignedUpload returns a presigned POST policy form. The store checks the
+// key, size range, content type and metadata conditions itself. S3 policies
+// carry a single content type condition, so the policy may list at most one
+// content type (or family), and a KeyPrefix cannot force the client's key to
+// follow the key convention. In acl mode the form cannot set the ACL, so
+// uploads get the bucket's default ACL; VisibilityPublic is rejected there
+// and SetVisibility publishes the object after the upload.
+func (p *S3StorageProvider) SignedUpload(ctx context.Context, policy domstorage.UploadPolicy) (domstorage.UploadRequest, error) {
+	if err := domstorage.ValidateUploadPolicy(policy); err != nil {
+		return domstorage.UploadRequest{}, err
+	}
+	if len(policy.ContentTypes) > 1 {
+		return domstorage.UploadRequest{}, fmt.Errorf("%w: the s3 driver accepts at most one content type", domstorage.ErrInvalidUploadPolicy)
+	}
+	if p.mode == S3VisibilityACL && policy.Visibility == domstorage.VisibilityPublic {
+		return domstorage.UploadRequest{}, fmt.Errorf("%w: acl mode cannot upload public objects directly", domstorage.ErrInvalidUploadPolicy)
+	}
+	ttl := policy.TTL
+	if ttl <= 0 {
+		ttl = p.defaultTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	post := minio.NewPostPolicy()
+	err := errors.Join(
+		post.SetBucket(p.bucket),
+		post.SetExpires(expiresAt),
+		post.SetContentLengthRange(0, policy.MaxSizeBytes),
+	)
+	if policy.Key != "" {
+		err = errors.Join(err, post.SetKey(p.objectName(policy.Key, policy.Visibility)))
+	} else {
+		err = errors.Join(err, post.SetKeyStartsWith(p.objectName(policy.KeyPrefix, policy.Visibility)))
+	}
+	for _, ct := range policy.ContentTypes {
+		if family, ok := strings.CutSuffix(ct, "/*"); ok {
+			err = errors.Join(err, post.SetContentTypeStartsWith(family+"/"))
+		} else {
+			err = errors.Join(err, post.SetContentType(ct))
+		}
+	}
+	for name, value := range normalizeMetadata(policy.Metadata) {
+		err = errors.Join(err, post.SetUserMetadata(name, value))
+	}
+	if err != nil {
+		return domstorage.UploadRequest{}, fmt.Errorf("%w: %w", domstorage.ErrInvalidUploadPolicy, err)
+	}
+
+	u, fields, err := p.client.PresignedPostPolicy(ctx, post)
+	if err != nil {
+		return domstorage.UploadRequest{}, fmt.Errorf("storage: presign upload: %w", err)
+	}
+	if strings.HasSuffix(fields["Content-Type"], "/") {
+		// A content type family leaves its prefix in the form; the client
+		// sends its own type instead.
+		delete(fields, "Content-Type")
+	}
+
+	return domstorage.UploadRequest{
+		Method:    http.MethodPost,
+		URL:       u.String(),
+		Fields:    fields,
+		FileField: uploadFileField,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// HealthChecks reports whether the bucket is reachable with the configured
+// credentials.
+func (p *S3StorageProvider) HealthChecks() []domhealth.Check {
+	return []domhealth.Check{{Name: "storage.s3", Run: p.checkBucket}}
+}
+
+// checkBucket fails when the bucket is missing or the store is unreachable.
+func (p *S3StorageProvider) checkBucket(ctx context.Context) error {
+	exists, err := p.client.BucketExists(ctx, p.bucket)
+	if err != nil {
+		return fmt.Errorf("storage: reach bucket %q: %w", p.bucket, err)
+	}
+	if !exists {
+		return fmt.Errorf("storage: bucket %q does not exist", p.bucket)
+	}
+	return nil
+}
+
+// stat returns the object's info and the bucket key it is stored under.
+func (p *S3StorageProvider) stat(ctx context.Context, key string) (domstorage.ObjectInfo, string, error) {
+	for _, location := range p.locations(key) {
+		if p.mode == S3VisibilityACL {
+			acl, err := p.client.GetObjectACL(ctx, p.bucket, location.name)
+			if err == nil {
+				return s3ObjectInfo(key, aclVisibility(acl), *acl), location.name, nil
+			}
+			if !isS3NotFound(err) {
+				return domstorage.ObjectInfo{}, "", fmt.Errorf("storage: stat %q: %w", key, err)
+			}
+			continue
+		}
+
+		info, err := p.client.StatObject(ctx, p.bucket, location.name, minio.StatObjectOptions{})
+		if err == nil {
+			return s3ObjectInfo(key, location.visibility, info), location.name, nil
+		}
+		if !isS3NotFound(err) {
+			return domstorage.ObjectInfo{}, "", fmt.Errorf("storage: stat %q: %w", key, err)
+		}
+	}
+	return domstorage.ObjectInfo{}, "", fmt.Errorf("%w: %q", domstorage.ErrObjectNotFound, key)
+}
+
+// copyObject copies the object described by info from the bucket key src to
+// dst. In acl mode the metadata is replaced so the ACL of info.Visibility can
+// be set; S3 requires that for a copy onto the same key.
+func (p *S3StorageProvider) copyObject(ctx context.Context, info domstorage.ObjectInfo, src, dst string) error {
+	destination := minio.CopyDestOptions{Bucket: p.bucket, Object: dst}
+	if p.mode == S3VisibilityACL {
+		destination.ReplaceMetadata = true
+		destination.ContentType = info.ContentType
+		destination.UserMetadata = p.userMetadata(info.Metadata, info.Visibility)
+	}
+	_, err := p.client.CopyObject(ctx, destination, minio.CopySrcOptions{Bucket: p.bucket, Object: src})
+	return err
+}
+
+// locate returns the object name the key is stored under, defaulting to the
+// private location when it is missing.
+func (p *S3StorageProvider) locate(ctx context.Context, key string) (string, error) {
+	if p.mode == S3VisibilityACL {
+		return key, nil
+	}
+	_, name, err := p.stat(ctx, key)
+	if errors.Is(err, domstorage.ErrObjectNotFound) {
+		return p.objectName(key, domstorage.VisibilityPrivate), nil
+	}
+	return name, err
+}
+
+// objectName returns the bucket key for the given visibility.
+func (p *S3StorageProvider) objectName(key string, v domstorage.Visibility) string {
+	if p.mode == S3VisibilityACL {
+		return key
+	}
+	if v == domstorage.VisibilityPrivate {
+		return p.privatePrefix + key
+	}
+	return p.publicPrefix + key
+}
+
+// s3Location is a bucket key an object may be stored under, with the
+// visibility it implies. In acl mode the visibility comes from the ACL.
+type s3Location struct {
+	name       string
+	visibility domstorage.Visibility
+}
+
+// locations returns every bucket key the object may be stored under, public
+// first. With an empty key it returns the prefixes to list.
+func (p *S3StorageProvider) locations(key string) []s3Location {
+	if p.mode == S3VisibilityACL {
+		return []s3Location{{name: key}}
+	}
+	return []s3Location{
+		{name: p.publicPrefix + key, visibility: domstorage.VisibilityPublic},
+		{name: p.privatePrefix + key, visibility: domstorage.VisibilityPrivate},
+	}
+}
+
+// userMetadata returns the headers stored with an object: the custom
+// metadata and, in acl mode, the canned ACL of the visibility.
+func (p *S3StorageProvider) userMetadata(metadata map[string]string, v domstorage.Visibility) map[string]string {
+	headers := normalizeMetadata(metadata)
+	if p.mode == S3VisibilityACL {
+		if headers == nil {
+			headers = make(map[string]string, 1)
+		}
+		headers["x-amz-acl"] = s3CannedACL(v)
+	}
+	return headers
+}
+
+// s3ObjectInfo converts the store's object info.
+func s3ObjectInfo(key string, v domstorage.Visibility, info minio.ObjectInfo) domstorage.ObjectInfo {
+	return domstorage.ObjectInfo{
+		Key:         key,
+		SizeBytes:   info.Size,
+		ContentType: info.ContentType,
+		ModifiedAt:  info.LastModified,
+		Visibility:  v,
+		ETag:        info.ETag,
+		Metadata:    normalizeMetadata(info.UserMetadata),
+	}
+}
+
+// aclVisibility maps the canned ACL reported by GetObjectACL to a
+// visibility; anything but public-read is private.
+func aclVisibility(info *minio.ObjectInfo) domstorage.Visibility {
+	if info.Metadata.Get("X-Amz-Acl") == "public-read" {
+		return domstorage.VisibilityPublic
+	}
+	return domstorage.VisibilityPrivate
+}
+
+// s3CannedACL maps a visibility to its canned ACL.
+func s3CannedACL(v domstorage.Visibility) string {
+	if v == domstorage.VisibilityPrivate {
+		return "private"
+	}
+	return "public-read"
+}
+
+// isS3NotFound reports whether err means the object does not exist.
+func isS3NotFound(err error) bool {
+	code := minio.ToErrorResponse(err).Code
+	return code == "NoSuchKey" || code == "NotFound"
+}
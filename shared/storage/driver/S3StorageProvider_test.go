@@ -0,0 +1,412 @@
+package driver_test
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	s3fake "github.com/r0x16/Raidark/shared/internal/testutil/s3"
+	storagedomain "github.com/r0x16/Raidark/shared/storage/domain"
+	"github.com/r0x16/Raidark/shared/storage/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const s3Bucket = "raidark-media"
+
+// TestS3StorageProvider_putGetRoundTripUsesStoreETag verifies that metadata
+// and the ETag come from the object store, and that prefix mode places the
+// object under the private prefix.
+func TestS3StorageProvider_putGetRoundTripUsesStoreETag(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	provider := newS3Provider(t, server, nil)
+	key := newStorageKey(t, "documents", "invoice", ".txt")
+
+	result, err := provider.Put(context.Background(), key, strings.NewReader("invoice"), storagedomain.PutOptions{
+		Visibility:  storagedomain.VisibilityPrivate,
+		ContentType: "text/plain",
+		Size:        7,
+	})
+	require.NoError(t, err)
+
+	stored, ok := server.Object(s3Bucket, "private/"+key)
+	require.True(t, ok)
+	assert.Equal(t, stored.ETag, result.ETag)
+	assert.Equal(t, int64(7), result.SizeBytes)
+
+	reader, info, err := provider.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer reader.Close()
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "invoice", string(body))
+	assert.Equal(t, key, info.Key)
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, int64(7), info.SizeBytes)
+}
+
+// TestS3StorageProvider_usesMultipartForLargeReaders sends a reader of unknown
+// size larger than one part and checks the object is reassembled intact.
+func TestS3StorageProvider_usesMultipartForLargeReaders(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	provider := newS3Provider(t, server, mapEnv{"STORAGE_S3_PART_SIZE_MB": "5"})
+	key := newStorageKey(t, "archives", "backup", ".bin")
+	const size = 11 * 1024 * 1024
+
+	result, err := provider.Put(context.Background(), key, &repeatingReader{remaining: size}, storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPrivate,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, server.CompletedMultipartUploads())
+	assert.Equal(t, int64(size), result.SizeBytes)
+	assert.True(t, strings.HasSuffix(result.ETag, "-3"), result.ETag)
+
+	stored, ok := server.Object(s3Bucket, "private/"+key)
+	require.True(t, ok)
+	sum := md5.Sum(stored.Data)
+	assert.Equal(t, expectedRepeatingReaderMD5(size), hex.EncodeToString(sum[:]))
+}
+
+// TestS3StorageProvider_mapsVisibilityToACLs verifies the acl mode keeps the
+// key as is and sends the canned ACL matching the visibility.
+func TestS3StorageProvider_mapsVisibilityToACLs(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	provider := newS3Provider(t, server, mapEnv{"STORAGE_S3_VISIBILITY_MODE": "acl"})
+	publicKey := newStorageKey(t, "profiles", "avatar", ".png")
+	privateKey := newStorageKey(t, "profiles", "contract", ".pdf")
+
+	_, err := provider.Put(context.Background(), publicKey, strings.NewReader("public"), storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPublic,
+	})
+	require.NoError(t, err)
+	_, err = provider.Put(context.Background(), privateKey, strings.NewReader("private"), storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPrivate,
+	})
+	require.NoError(t, err)
+
+	public, ok := server.Object(s3Bucket, publicKey)
+	require.True(t, ok)
+	assert.Equal(t, "public-read", public.ACL)
+	private, ok := server.Object(s3Bucket, privateKey)
+	require.True(t, ok)
+	assert.Equal(t, "private", private.ACL)
+}
+
+// TestS3StorageProvider_signedURLIsAPresignedGet checks the URL carries a
+// SigV4 query signature for the stored prefix and serves the object.
+func TestS3StorageProvider_signedURLIsAPresignedGet(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	provider := newS3Provider(t, server, nil)
+	key := newStorageKey(t, "documents", "report", ".txt")
+	_, err := provider.Put(context.Background(), key, strings.NewReader("report"), storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPrivate,
+	})
+	require.NoError(t, err)
+
+	rawURL, err := provider.SignedURL(context.Background(), key, 0)
+	require.NoError(t, err)
+
+	signed, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/"+s3Bucket+"/private/"+key, signed.Path)
+	assert.Equal(t, "600", signed.Query().Get("X-Amz-Expires"))
+	assert.NotEmpty(t, signed.Query().Get("X-Amz-Signature"))
+
+	response, err := http.Get(rawURL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "report", string(body))
+}
+
+// TestS3StorageProvider_signsTheContentDisposition checks the disposition is
+// passed to the store as a signed response override.
+func TestS3StorageProvider_signsTheContentDisposition(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	provider := newS3Provider(t, server, nil)
+	key := newStorageKey(t, "documents", "report", ".pdf")
+	_, err := provider.Put(context.Background(), key, strings.NewReader("report"), storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPrivate,
+	})
+	require.NoError(t, err)
+
+	rawURL, err := provider.SignedURLWithOptions(context.Background(), key, storagedomain.SignedURLOptions{
+		TTL:                time.Minute,
+		ContentDisposition: storagedomain.AttachmentDisposition("report.pdf"),
+	})
+	require.NoError(t, err)
+
+	signed, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	assert.Equal(t, "60", signed.Query().Get("X-Amz-Expires"))
+	assert.Equal(t, `attachment; filename=report.pdf`, signed.Query().Get("response-content-disposition"))
+}
+
+// TestS3StorageProvider_publicURLUsesTheCDNBase verifies the configured base
+// wins over the bucket URL.
+func TestS3StorageProvider_publicURLUsesTheCDNBase(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	key := newStorageKey(t, "profiles", "avatar", ".png")
+
+	withCDN := newS3Provider(t, server, mapEnv{"STORAGE_PUBLIC_BASE_URL": "https://cdn.example.test/"})
+	assert.Equal(t, "https://cdn.example.test/"+key, withCDN.PublicURL(key))
+
+	direct := newS3Provider(t, server, nil)
+	assert.Equal(t, server.Server.URL+"/"+s3Bucket+"/public/"+key, direct.PublicURL(key))
+}
+
+// TestS3StorageProvider_deleteAndExistsAreIdempotent mirrors the filesystem
+// contract: missing keys are not errors.
+func TestS3StorageProvider_deleteAndExistsAreIdempotent(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	provider := newS3Provider(t, server, nil)
+	key := newStorageKey(t, "documents", "pdf", ".pdf")
+
+	exists, err := provider.Exists(context.Background(), key)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	_, _, err = provider.Get(context.Background(), key)
+	assert.ErrorContains(t, err, "key not found")
+
+	_, err = provider.Put(context.Background(), key, strings.NewReader("content"), storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPublic,
+	})
+	require.NoError(t, err)
+	exists, err = provider.Exists(context.Background(), key)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, provider.Delete(context.Background(), key))
+	assert.Empty(t, server.Keys(s3Bucket))
+	assert.NoError(t, provider.Delete(context.Background(), key))
+}
+
+// TestS3StorageProvider_storesMetadataAsHeaders verifies custom metadata is
+// sent as x-amz-meta-* headers and read back by Stat.
+func TestS3StorageProvider_storesMetadataAsHeaders(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	provider := newS3Provider(t, server, nil)
+	key := newStorageKey(t, "documents", "invoice", ".pdf")
+
+	result, err := provider.Put(context.Background(), key, strings.NewReader("invoice"), storagedomain.PutOptions{
+		Visibility:  storagedomain.VisibilityPublic,
+		ContentType: "application/pdf",
+		Metadata:    map[string]string{"Owner-ID": "42"},
+	})
+	require.NoError(t, err)
+
+	stored, ok := server.Object(s3Bucket, "public/"+key)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"owner-id": "42"}, stored.Metadata)
+
+	info, err := provider.Stat(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", info.ContentType)
+	assert.Equal(t, result.ETag, info.ETag)
+	assert.Equal(t, storagedomain.VisibilityPublic, info.Visibility)
+	assert.Equal(t, map[string]string{"owner-id": "42"}, info.Metadata)
+
+	_, err = provider.Stat(context.Background(), newStorageKey(t, "documents", "missing", ".pdf"))
+	assert.ErrorIs(t, err, storagedomain.ErrObjectNotFound)
+}
+
+// TestS3StorageProvider_listsBothPrefixesInPages merges the listings of the
+// public and private prefixes with a small page size.
+func TestS3StorageProvider_listsBothPrefixesInPages(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	provider := newS3Provider(t, server, nil)
+	var keys []string
+	for i := range 5 {
+		key := newStorageKey(t, "galleries", "photo", ".jpg")
+		visibility := storagedomain.VisibilityPublic
+		if i%2 == 1 {
+			visibility = storagedomain.VisibilityPrivate
+		}
+		_, err := provider.Put(context.Background(), key, strings.NewReader("photo"), storagedomain.PutOptions{Visibility: visibility})
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	_, err := provider.Put(context.Background(), newStorageKey(t, "galleries", "video", ".mp4"), strings.NewReader("video"), storagedomain.PutOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, keys, listAll(t, provider, "galleries/photo/", 2))
+
+	page, err := provider.List(context.Background(), "galleries/photo/", "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, storagedomain.VisibilityPrivate, page.Objects[1].Visibility)
+	assert.Empty(t, page.NextCursor)
+}
+
+// TestS3StorageProvider_copyMoveAndSetVisibilityWithPrefixes moves objects
+// between prefixes with server-side copies.
+func TestS3StorageProvider_copyMoveAndSetVisibilityWithPrefixes(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	provider := newS3Provider(t, server, nil)
+	src := newStorageKey(t, "documents", "draft", ".txt")
+	copied := newStorageKey(t, "documents", "copy", ".txt")
+	moved := newStorageKey(t, "documents", "final", ".txt")
+	_, err := provider.Put(context.Background(), src, strings.NewReader("draft"), storagedomain.PutOptions{
+		Visibility: storagedomain.VisibilityPrivate,
+		Metadata:   map[string]string{"author": "ana"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Copy(context.Background(), src, copied))
+	require.NoError(t, provider.Move(context.Background(), src, moved))
+	require.NoError(t, provider.SetVisibility(context.Background(), moved, storagedomain.VisibilityPublic))
+
+	assert.ElementsMatch(t, []string{"private/" + copied, "public/" + moved}, server.Keys(s3Bucket))
+	public, _ := server.Object(s3Bucket, "public/"+moved)
+	assert.Equal(t, map[string]string{"author": "ana"}, public.Metadata)
+	assert.ErrorIs(t, provider.Move(context.Background(), src, moved), storagedomain.ErrObjectNotFound)
+}
+
+// TestS3StorageProvider_setVisibilityRewritesTheACL checks acl mode copies
+// the object onto itself with the new canned ACL and its metadata.
+func TestS3StorageProvider_setVisibilityRewritesTheACL(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	provider := newS3Provider(t, server, mapEnv{"STORAGE_S3_VISIBILITY_MODE": "acl"})
+	key := newStorageKey(t, "profiles", "avatar", ".png")
+	_, err := provider.Put(context.Background(), key, strings.NewReader("avatar"), storagedomain.PutOptions{
+		Visibility:  storagedomain.VisibilityPrivate,
+		ContentType: "image/png",
+		Metadata:    map[string]string{"author": "ana"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, provider.SetVisibility(context.Background(), key, storagedomain.VisibilityPublic))
+
+	stored, ok := server.Object(s3Bucket, key)
+	require.True(t, ok)
+	assert.Equal(t, "public-read", stored.ACL)
+	assert.Equal(t, "image/png", stored.ContentType)
+	assert.Equal(t, map[string]string{"author": "ana"}, stored.Metadata)
+
+	info, err := provider.Stat(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, storagedomain.VisibilityPublic, info.Visibility)
+	page, err := provider.List(context.Background(), "profiles/", "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Objects, 1)
+	assert.Equal(t, storagedomain.VisibilityPublic, page.Objects[0].Visibility)
+}
+
+// TestS3StorageProvider_signedUploadIsAPresignedPostPolicy checks the form
+// fields and the policy conditions the store enforces on the upload.
+func TestS3StorageProvider_signedUploadIsAPresignedPostPolicy(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	provider := newS3Provider(t, server, nil)
+	key := newStorageKey(t, "profiles", "avatar", ".png")
+
+	upload, err := provider.SignedUpload(context.Background(), storagedomain.UploadPolicy{
+		Key:          key,
+		Visibility:   storagedomain.VisibilityPrivate,
+		MaxSizeBytes: 2048,
+		ContentTypes: []string{"image/*"},
+		Metadata:     map[string]string{"Owner-ID": "42"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, upload.Method)
+	assert.Equal(t, server.Server.URL+"/"+s3Bucket+"/", upload.URL)
+	assert.Equal(t, "file", upload.FileField)
+	assert.Equal(t, "private/"+key, upload.Fields["key"])
+	assert.Equal(t, "42", upload.Fields["x-amz-meta-owner-id"])
+	assert.NotEmpty(t, upload.Fields["x-amz-signature"])
+	assert.NotContains(t, upload.Fields, "Content-Type")
+
+	data, err := base64.StdEncoding.DecodeString(upload.Fields["policy"])
+	require.NoError(t, err)
+	var policy struct {
+		Conditions []any `json:"conditions"`
+	}
+	require.NoError(t, json.Unmarshal(data, &policy))
+	assert.Contains(t, policy.Conditions, []any{"eq", "$key", "private/" + key})
+	assert.Contains(t, policy.Conditions, []any{"starts-with", "$Content-Type", "image/"})
+	assert.Contains(t, policy.Conditions, []any{"content-length-range", float64(0), float64(2048)})
+}
+
+// TestS3StorageProvider_signedUploadRejectsUnenforceablePolicies covers the
+// limits a POST policy cannot express.
+func TestS3StorageProvider_signedUploadRejectsUnenforceablePolicies(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+	key := newStorageKey(t, "profiles", "avatar", ".png")
+
+	_, err := newS3Provider(t, server, nil).SignedUpload(context.Background(), storagedomain.UploadPolicy{
+		Key:          key,
+		MaxSizeBytes: 2048,
+		ContentTypes: []string{"image/png", "image/jpeg"},
+	})
+	assert.ErrorIs(t, err, storagedomain.ErrInvalidUploadPolicy)
+
+	_, err = newS3Provider(t, server, mapEnv{"STORAGE_S3_VISIBILITY_MODE": driver.S3VisibilityACL}).SignedUpload(context.Background(), storagedomain.UploadPolicy{
+		Key:          key,
+		Visibility:   storagedomain.VisibilityPublic,
+		MaxSizeBytes: 2048,
+	})
+	assert.ErrorIs(t, err, storagedomain.ErrInvalidUploadPolicy)
+}
+
+// TestS3StorageProvider_healthCheckRequiresTheBucket fails readiness when the
+// bucket does not exist.
+func TestS3StorageProvider_healthCheckRequiresTheBucket(t *testing.T) {
+	server := s3fake.NewServer(t, s3Bucket)
+
+	checks := newS3Provider(t, server, nil).HealthChecks()
+	require.Len(t, checks, 1)
+	assert.Equal(t, "storage.s3", checks[0].Name)
+	assert.NoError(t, checks[0].Run(context.Background()))
+
+	missing := newS3Provider(t, server, mapEnv{"STORAGE_S3_BUCKET": "missing"})
+	assert.ErrorContains(t, missing.HealthChecks()[0].Run(context.Background()), `bucket "missing" does not exist`)
+}
+
+// TestNewS3StorageProvider_rejectsInvalidConfiguration covers startup
+// failures for the s3 driver.
+func TestNewS3StorageProvider_rejectsInvalidConfiguration(t *testing.T) {
+	tests := map[string]map[string]string{
+		"missing-bucket":  {},
+		"unknown-mode":    {"STORAGE_S3_BUCKET": s3Bucket, "STORAGE_S3_VISIBILITY_MODE": "bucket"},
+		"small-part-size": {"STORAGE_S3_BUCKET": s3Bucket, "STORAGE_S3_PART_SIZE_MB": "1"},
+		"invalid-ttl":     {"STORAGE_S3_BUCKET": s3Bucket, "STORAGE_SIGNED_URL_DEFAULT_TTL": "forever"},
+	}
+
+	for name, values := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := driver.NewS3StorageProvider(mapEnv(values))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// newS3Provider points a provider at the fake server; overrides replace the
+// default test configuration.
+func newS3Provider(t *testing.T, server *s3fake.Server, overrides mapEnv) *driver.S3StorageProvider {
+	t.Helper()
+
+	env := mapEnv{
+		"STORAGE_S3_ENDPOINT":            "http://" + server.Endpoint,
+		"STORAGE_S3_BUCKET":              s3Bucket,
+		"STORAGE_S3_ACCESS_KEY":          "test-access",
+		"STORAGE_S3_SECRET_KEY":          "test-secret",
+		"STORAGE_S3_PATH_STYLE":          "true",
+		"STORAGE_SIGNED_URL_DEFAULT_TTL": "10m",
+	}
+	for name, value := range overrides {
+		env[name] = value
+	}
+
+	provider, err := driver.NewS3StorageProvider(env)
+	require.NoError(t, err)
+	return provider
+}
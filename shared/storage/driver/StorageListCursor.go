@@ -0,0 +1,61 @@
+package driver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/r0x16/Raidark/shared/api/rest"
+	domstorage "github.com/r0x16/Raidark/shared/storage/domain"
+)
+
+// listCursor is the payload of the opaque List cursors: the key of the last
+// object returned. Both drivers list in key order, so the next page starts
+// right after it.
+type listCursor struct {
+	After string `json:"after"`
+}
+
+// decodeListCursor returns the key to resume after, or "" for the first page.
+// A cursor whose key falls outside prefix was issued for another listing.
+func decodeListCursor(cursor, prefix string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+
+	var c listCursor
+	if err := rest.DecodeCursor(cursor, &c); err != nil {
+		return "", fmt.Errorf("%w: %w", domstorage.ErrInvalidCursor, err)
+	}
+	if !strings.HasPrefix(c.After, prefix) {
+		return "", fmt.Errorf("%w: cursor does not belong to prefix %q", domstorage.ErrInvalidCursor, prefix)
+	}
+	return c.After, nil
+}
+
+// pageObjects cuts objects, sorted by key and holding up to limit+1 entries,
+// to one page and sets the cursor when more remain.
+func pageObjects(objects []domstorage.ObjectInfo, limit int) (domstorage.ObjectList, error) {
+	if len(objects) <= limit {
+		return domstorage.ObjectList{Objects: objects}, nil
+	}
+
+	objects = objects[:limit]
+	next, err := rest.EncodeCursor(listCursor{After: objects[limit-1].Key})
+	if err != nil {
+		return domstorage.ObjectList{}, fmt.Errorf("storage: encode list cursor: %w", err)
+	}
+	return domstorage.ObjectList{Objects: objects, NextCursor: next}, nil
+}
+
+// normalizeMetadata lower-cases the metadata keys so every driver returns
+// them the same way. It returns nil for empty metadata.
+func normalizeMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	normalized := make(map[string]string, len(metadata))
+	for name, value := range metadata {
+		normalized[strings.ToLower(name)] = value
+	}
+	return normalized
+}
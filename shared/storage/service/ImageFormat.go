@@ -0,0 +1,110 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	domstorage "github.com/r0x16/Raidark/shared/storage/domain"
+	"golang.org/x/image/webp"
+)
+
+// imageFormat is an input format the pipeline accepts. Formats are matched
+// on their magic bytes only; the name and content type the client declared
+// are never trusted.
+type imageFormat struct {
+	name         string
+	magic        func(data []byte) bool
+	complete     func(data []byte) bool
+	decodeConfig func(r io.Reader) (image.Config, error)
+	decode       func(r io.Reader) (image.Image, error)
+}
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	// pngIEND is the whole IEND chunk: zero length, type and fixed CRC.
+	pngIEND = []byte("\x00\x00\x00\x00IEND\xae\x42\x60\x82")
+)
+
+// imageFormats lists the accepted input formats.
+var imageFormats = []imageFormat{
+	{
+		name:         "jpeg",
+		magic:        func(data []byte) bool { return bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}) },
+		complete:     func(data []byte) bool { return bytes.HasSuffix(data, []byte{0xFF, 0xD9}) },
+		decodeConfig: jpeg.DecodeConfig,
+		decode:       jpeg.Decode,
+	},
+	{
+		name:         "png",
+		magic:        func(data []byte) bool { return bytes.HasPrefix(data, pngSignature) },
+		complete:     pngComplete,
+		decodeConfig: png.DecodeConfig,
+		decode:       png.Decode,
+	},
+	{
+		name: "gif",
+		magic: func(data []byte) bool {
+			return bytes.HasPrefix(data, []byte("GIF87a")) || bytes.HasPrefix(data, []byte("GIF89a"))
+		},
+		complete:     func(data []byte) bool { return bytes.HasSuffix(data, []byte{0x3B}) },
+		decodeConfig: gif.DecodeConfig,
+		// gif.Decode stops at the first frame: animations are stored still.
+		decode: gif.Decode,
+	},
+	{
+		name: "webp",
+		magic: func(data []byte) bool {
+			return len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+		},
+		complete:     webpComplete,
+		decodeConfig: webp.DecodeConfig,
+		decode:       webp.Decode,
+	},
+}
+
+// sniffImageFormat returns the format of data, rejecting content in no
+// accepted format and content carrying bytes past the end of the image,
+// where polyglots hide a second file such as a ZIP archive or a script.
+func sniffImageFormat(data []byte) (imageFormat, error) {
+	for _, format := range imageFormats {
+		if !format.magic(data) {
+			continue
+		}
+		if !format.complete(data) {
+			return imageFormat{}, fmt.Errorf("%w: %s data does not end where the image ends", domstorage.ErrUnsupportedImage, format.name)
+		}
+		return format, nil
+	}
+	return imageFormat{}, fmt.Errorf("%w: unrecognised format", domstorage.ErrUnsupportedImage)
+}
+
+// pngComplete walks the chunks of a PNG and reports whether the file ends
+// exactly with its IEND chunk.
+func pngComplete(data []byte) bool {
+	offset := len(pngSignature)
+	for offset+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		end := offset + 12 + length
+		if length < 0 || end > len(data) {
+			return false
+		}
+		if string(data[offset+4:offset+8]) == "IEND" {
+			return end == len(data) && bytes.Equal(data[offset:end], pngIEND)
+		}
+		offset = end
+	}
+	return false
+}
+
+// webpComplete reports whether the RIFF container size covers the whole
+// file and nothing more.
+func webpComplete(data []byte) bool {
+	size := int64(binary.LittleEndian.Uint32(data[4:8]))
+	return size+8 == int64(len(data))
+}
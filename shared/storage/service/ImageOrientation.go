@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// exifOrientationTag is the TIFF tag holding the EXIF orientation, 1 to 8.
+const exifOrientationTag = 0x0112
+
+// exifOrientation returns the EXIF orientation of a JPEG, PNG or WebP image,
+// or 1 (no transform) when it has none or its EXIF data is malformed.
+func exifOrientation(format string, data []byte) int {
+	var tiff []byte
+	switch format {
+	case "jpeg":
+		tiff = jpegExif(data)
+	case "png":
+		tiff = pngExif(data)
+	case "webp":
+		tiff = webpExif(data)
+	}
+	return tiffOrientation(tiff)
+}
+
+// jpegExif returns the TIFF payload of the first APP1 Exif segment.
+func jpegExif(data []byte) []byte {
+	offset := 2
+	for offset+4 <= len(data) && data[offset] == 0xFF {
+		marker := data[offset+1]
+		// Metadata segments all come before the first scan.
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		end := offset + 2 + length
+		if length < 2 || end > len(data) {
+			return nil
+		}
+		segment := data[offset+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		offset = end
+	}
+	return nil
+}
+
+// pngExif returns the payload of the eXIf chunk.
+func pngExif(data []byte) []byte {
+	offset := len(pngSignature)
+	for offset+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		end := offset + 12 + length
+		if length < 0 || end > len(data) {
+			return nil
+		}
+		if string(data[offset+4:offset+8]) == "eXIf" {
+			return data[offset+8 : end-4]
+		}
+		offset = end
+	}
+	return nil
+}
+
+// webpExif returns the payload of the EXIF chunk, which some encoders
+// prefix with the JPEG "Exif\0\0" header.
+func webpExif(data []byte) []byte {
+	offset := 12
+	for offset+8 <= len(data) {
+		length := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		end := offset + 8 + length
+		if length < 0 || end > len(data) {
+			return nil
+		}
+		if string(data[offset:offset+4]) == "EXIF" {
+			return bytes.TrimPrefix(data[offset+8:end], []byte("Exif\x00\x00"))
+		}
+		// Chunks are padded to an even size.
+		offset = end + length%2
+	}
+	return nil
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of a TIFF
+// structure.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd+2 > int64(len(tiff)) {
+		return 1
+	}
+	count := int64(order.Uint16(tiff[ifd:]))
+	for i := int64(0); i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > int64(len(tiff)) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+		// The orientation is a SHORT stored inline in the value field.
+		if order.Uint16(tiff[entry+2:]) != 3 {
+			return 1
+		}
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return 1
+		}
+		return orientation
+	}
+	return 1
+}
+
+// applyOrientation returns src transformed so that it displays upright for
+// the given EXIF orientation. Orientations 5 to 8 swap width and height.
+func applyOrientation(src *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for sy := 0; sy < h; sy++ {
+		for sx := 0; sx < w; sx++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-sx, sy
+			case 3: // rotated 180°
+				dx, dy = w-1-sx, h-1-sy
+			case 4: // mirrored vertically
+				dx, dy = sx, h-1-sy
+			case 5: // transposed
+				dx, dy = sy, sx
+			case 6: // rotated 90° clockwise to display
+				dx, dy = h-1-sy, sx
+			case 7: // transversed
+				dx, dy = h-1-sy, w-1-sx
+			case 8: // rotated 90° counter-clockwise to display
+				dx, dy = sy, w-1-sx
+			}
+			si := src.PixOffset(src.Rect.Min.X+sx, src.Rect.Min.Y+sy)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
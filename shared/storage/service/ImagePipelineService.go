@@ -0,0 +1,285 @@
+// Package service holds application services built on top of StorageProvider.
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	domstorage "github.com/r0x16/Raidark/shared/storage/domain"
+	"golang.org/x/image/draw"
+)
+
+const (
+	// DefaultImageMaxInputBytes bounds the encoded size of an input image.
+	DefaultImageMaxInputBytes = 20 << 20
+	// DefaultImageMaxPixels bounds the decoded size of an input image, so a
+	// small file declaring huge dimensions (a decompression bomb) is rejected
+	// before it is decoded.
+	DefaultImageMaxPixels = 25_000_000
+	// DefaultImageJPEGQuality is the quality JPEG renditions are encoded with.
+	DefaultImageJPEGQuality = 85
+)
+
+// DefaultImageVariants are the variants generated when ImagePipelineConfig
+// leaves Variants nil.
+var DefaultImageVariants = []domstorage.ImageVariant{
+	{Name: "thumb", MaxWidth: 256, MaxHeight: 256, Crop: true},
+	{Name: "medium", MaxWidth: 1024, MaxHeight: 1024},
+}
+
+// ImagePipelineConfig tunes an ImagePipelineService. Zero values use the
+// defaults above.
+type ImagePipelineConfig struct {
+	MaxInputBytes int64
+	MaxPixels     int64
+	JPEGQuality   int
+	// Variants are generated for every image, in order. Set an empty,
+	// non-nil slice to store only the original.
+	Variants []domstorage.ImageVariant
+}
+
+// ImageOptions locates and labels the renditions of one processed image.
+type ImageOptions struct {
+	// Namespace and Usage build the original's key through BuildKey; the
+	// variants' keys are derived from it through DeriveKey.
+	Namespace  string
+	Usage      string
+	Visibility domstorage.Visibility
+	// Metadata is stored with every rendition, as in PutOptions.Metadata.
+	Metadata map[string]string
+}
+
+// ImagePipelineService stores user images safely. Instead of storing the
+// uploaded bytes, it decodes the image and stores a fresh encoding of its
+// pixels, which drops EXIF, GPS and any other embedded data, together with
+// resized variants.
+type ImagePipelineService struct {
+	storage domstorage.StorageProvider
+	config  ImagePipelineConfig
+}
+
+// NewImagePipelineService creates an image pipeline storing through storage.
+// It returns an error for a variant with no size or a name DeriveKey cannot
+// use.
+func NewImagePipelineService(storage domstorage.StorageProvider, config ImagePipelineConfig) (*ImagePipelineService, error) {
+	if config.MaxInputBytes <= 0 {
+		config.MaxInputBytes = DefaultImageMaxInputBytes
+	}
+	if config.MaxPixels <= 0 {
+		config.MaxPixels = DefaultImageMaxPixels
+	}
+	if config.JPEGQuality <= 0 {
+		config.JPEGQuality = DefaultImageJPEGQuality
+	}
+	if config.Variants == nil {
+		config.Variants = DefaultImageVariants
+	}
+
+	// Variant names are checked against a sample key of the convention.
+	sampleKey, err := domstorage.BuildKey("images", "original", "")
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(config.Variants))
+	for _, variant := range config.Variants {
+		if variant.MaxWidth <= 0 || variant.MaxHeight <= 0 {
+			return nil, fmt.Errorf("image pipeline: variant %q must have a positive size", variant.Name)
+		}
+		if _, err := domstorage.DeriveKey(sampleKey, variant.Name, ""); err != nil {
+			return nil, fmt.Errorf("image pipeline: invalid variant name: %w", err)
+		}
+		if names[variant.Name] {
+			return nil, fmt.Errorf("image pipeline: duplicate variant %q", variant.Name)
+		}
+		names[variant.Name] = true
+	}
+
+	return &ImagePipelineService{storage: storage, config: config}, nil
+}
+
+// Process validates the image read from r, stores its sanitised original and
+// every configured variant, and returns their manifest.
+//
+// The format is sniffed from the content; JPEG, PNG, GIF and WebP are
+// accepted. Content in another format or carrying trailing data returns an
+// error wrapping ErrUnsupportedImage, and content over the byte or pixel
+// limit one wrapping ErrImageTooLarge. The EXIF orientation is applied to
+// the pixels. JPEG input is stored as JPEG and PNG input as PNG; GIF and
+// WebP input is stored as PNG when it has transparency and as JPEG
+// otherwise. If storing any rendition fails, the ones already stored are
+// deleted.
+func (s *ImagePipelineService) Process(ctx context.Context, r io.Reader, opts ImageOptions) (domstorage.ImageManifest, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.config.MaxInputBytes+1))
+	if err != nil {
+		return domstorage.ImageManifest{}, fmt.Errorf("image pipeline: failed to read image: %w", err)
+	}
+	if int64(len(data)) > s.config.MaxInputBytes {
+		return domstorage.ImageManifest{}, fmt.Errorf("%w: over %d bytes", domstorage.ErrImageTooLarge, s.config.MaxInputBytes)
+	}
+
+	img, format, err := s.decode(data)
+	if err != nil {
+		return domstorage.ImageManifest{}, err
+	}
+	img = applyOrientation(img, exifOrientation(format.name, data))
+
+	output := jpegOutput(s.config.JPEGQuality)
+	if format.name == "png" || !img.Opaque() {
+		output = pngOutput
+	}
+
+	key, err := domstorage.BuildKey(opts.Namespace, opts.Usage, output.ext)
+	if err != nil {
+		return domstorage.ImageManifest{}, err
+	}
+
+	manifest, err := s.store(ctx, key, img, output, opts)
+	if err != nil {
+		// The caller's context may be what failed the upload; the cleanup
+		// must still run.
+		cleanup := context.WithoutCancel(ctx)
+		if manifest.Original.Key != "" {
+			_ = s.storage.Delete(cleanup, manifest.Original.Key)
+		}
+		for _, rendition := range manifest.Variants {
+			_ = s.storage.Delete(cleanup, rendition.Key)
+		}
+		return domstorage.ImageManifest{}, err
+	}
+	return manifest, nil
+}
+
+// decode sniffs data and decodes it into NRGBA pixels, as stored before any
+// EXIF orientation. The pixel limit is checked against the header before any
+// pixel is decoded.
+func (s *ImagePipelineService) decode(data []byte) (*image.NRGBA, imageFormat, error) {
+	format, err := sniffImageFormat(data)
+	if err != nil {
+		return nil, imageFormat{}, err
+	}
+
+	config, err := format.decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, imageFormat{}, fmt.Errorf("%w: invalid %s header: %w", domstorage.ErrUnsupportedImage, format.name, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, imageFormat{}, fmt.Errorf("%w: empty %s image", domstorage.ErrUnsupportedImage, format.name)
+	}
+	if int64(config.Width)*int64(config.Height) > s.config.MaxPixels {
+		return nil, imageFormat{}, fmt.Errorf("%w: %dx%d is over %d pixels", domstorage.ErrImageTooLarge, config.Width, config.Height, s.config.MaxPixels)
+	}
+
+	decoded, err := format.decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, imageFormat{}, fmt.Errorf("%w: invalid %s data: %w", domstorage.ErrUnsupportedImage, format.name, err)
+	}
+
+	bounds := decoded.Bounds()
+	img := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(img, img.Rect, decoded, bounds.Min, draw.Src)
+	return img, format, nil
+}
+
+// store writes the original under key and each variant under its derived
+// key. On error, the returned manifest lists the renditions already stored.
+func (s *ImagePipelineService) store(ctx context.Context, key string, img *image.NRGBA, output imageOutput, opts ImageOptions) (domstorage.ImageManifest, error) {
+	var manifest domstorage.ImageManifest
+	original, err := s.put(ctx, "original", key, img, output, opts)
+	if err != nil {
+		return manifest, err
+	}
+	manifest.Original = original
+
+	manifest.Variants = make([]domstorage.ImageRendition, 0, len(s.config.Variants))
+	for _, variant := range s.config.Variants {
+		variantKey, err := domstorage.DeriveKey(key, variant.Name, output.ext)
+		if err != nil {
+			return manifest, err
+		}
+		rendition, err := s.put(ctx, variant.Name, variantKey, resize(img, variant), output, opts)
+		if err != nil {
+			return manifest, err
+		}
+		manifest.Variants = append(manifest.Variants, rendition)
+	}
+	return manifest, nil
+}
+
+// put encodes img and stores it under key.
+func (s *ImagePipelineService) put(ctx context.Context, name, key string, img *image.NRGBA, output imageOutput, opts ImageOptions) (domstorage.ImageRendition, error) {
+	var encoded bytes.Buffer
+	if err := output.encode(&encoded, img); err != nil {
+		return domstorage.ImageRendition{}, fmt.Errorf("image pipeline: failed to encode %s: %w", name, err)
+	}
+
+	result, err := s.storage.Put(ctx, key, &encoded, domstorage.PutOptions{
+		Visibility:  opts.Visibility,
+		ContentType: output.contentType,
+		Size:        int64(encoded.Len()),
+		Metadata:    opts.Metadata,
+	})
+	if err != nil {
+		return domstorage.ImageRendition{}, fmt.Errorf("image pipeline: failed to store %s: %w", name, err)
+	}
+
+	return domstorage.ImageRendition{
+		Name:        name,
+		Key:         key,
+		Width:       img.Rect.Dx(),
+		Height:      img.Rect.Dy(),
+		ContentType: output.contentType,
+		SizeBytes:   result.SizeBytes,
+	}, nil
+}
+
+// imageOutput is the encoding renditions are stored with.
+type imageOutput struct {
+	ext         string
+	contentType string
+	encode      func(w io.Writer, img image.Image) error
+}
+
+var pngOutput = imageOutput{ext: ".png", contentType: "image/png", encode: png.Encode}
+
+func jpegOutput(quality int) imageOutput {
+	return imageOutput{
+		ext:         ".jpg",
+		contentType: "image/jpeg",
+		encode: func(w io.Writer, img image.Image) error {
+			return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+		},
+	}
+}
+
+// resize scales img into variant's box, cropping its centre when the variant
+// crops. The result is never larger than img.
+func resize(img *image.NRGBA, variant domstorage.ImageVariant) *image.NRGBA {
+	src := img.Rect
+	w, h := src.Dx(), src.Dy()
+
+	if variant.Crop {
+		// Cut the largest centred rectangle with the box's aspect ratio.
+		cropW, cropH := w, h
+		if w*variant.MaxHeight > h*variant.MaxWidth {
+			cropW = max(h*variant.MaxWidth/variant.MaxHeight, 1)
+		} else {
+			cropH = max(w*variant.MaxHeight/variant.MaxWidth, 1)
+		}
+		src = image.Rect(0, 0, cropW, cropH).Add(image.Pt((w-cropW)/2, (h-cropH)/2))
+		w, h = cropW, cropH
+	}
+
+	scale := math.Min(1, math.Min(float64(variant.MaxWidth)/float64(w), float64(variant.MaxHeight)/float64(h)))
+	dstW := max(int(math.Round(float64(w)*scale)), 1)
+	dstH := max(int(math.Round(float64(h)*scale)), 1)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	draw.CatmullRom.Scale(dst, dst.Rect, img, src, draw.Src, nil)
+	return dst
+}
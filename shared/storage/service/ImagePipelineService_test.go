@@ -0,0 +1,280 @@
+// Package service_test verifies the image pipeline against the filesystem
+// storage driver.
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	envdriver "github.com/r0x16/Raidark/shared/env/driver"
+	storagedomain "github.com/r0x16/Raidark/shared/storage/domain"
+	storagedriver "github.com/r0x16/Raidark/shared/storage/driver"
+	"github.com/r0x16/Raidark/shared/storage/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImagePipelineService_storesOriginalAndVariants covers the default
+// variants: a cropped square thumbnail and a fitted medium rendition, stored
+// under keys derived from the original's.
+func TestImagePipelineService_storesOriginalAndVariants(t *testing.T) {
+	pipeline, storage := newImagePipeline(t, service.ImagePipelineConfig{})
+
+	manifest, err := pipeline.Process(context.Background(), bytes.NewReader(encodeJPEG(t, solidImage(1200, 800, color.NRGBA{R: 200, A: 255}))), service.ImageOptions{
+		Namespace:  "profiles",
+		Usage:      "avatar",
+		Visibility: storagedomain.VisibilityPrivate,
+		Metadata:   map[string]string{"Owner-ID": "42"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "original", manifest.Original.Name)
+	assert.Equal(t, 1200, manifest.Original.Width)
+	assert.Equal(t, 800, manifest.Original.Height)
+	assert.Equal(t, "image/jpeg", manifest.Original.ContentType)
+	assert.True(t, strings.HasPrefix(manifest.Original.Key, "profiles/avatar/"))
+	assert.Equal(t, ".jpg", filepath.Ext(manifest.Original.Key))
+
+	thumb, ok := manifest.Variant("thumb")
+	require.True(t, ok)
+	assert.Equal(t, [2]int{256, 256}, [2]int{thumb.Width, thumb.Height})
+	medium, ok := manifest.Variant("medium")
+	require.True(t, ok)
+	assert.Equal(t, [2]int{1024, 683}, [2]int{medium.Width, medium.Height})
+
+	for _, rendition := range append([]storagedomain.ImageRendition{manifest.Original}, manifest.Variants...) {
+		if rendition.Name != "original" {
+			derived, err := storagedomain.DeriveKey(manifest.Original.Key, rendition.Name, ".jpg")
+			require.NoError(t, err)
+			assert.Equal(t, derived, rendition.Key)
+		}
+
+		info, err := storage.Stat(context.Background(), rendition.Key)
+		require.NoError(t, err, rendition.Name)
+		assert.Equal(t, storagedomain.VisibilityPrivate, info.Visibility)
+		assert.Equal(t, "image/jpeg", info.ContentType)
+		assert.Equal(t, rendition.SizeBytes, info.SizeBytes)
+		assert.Equal(t, map[string]string{"owner-id": "42"}, info.Metadata)
+
+		stored := readImage(t, storage, rendition.Key)
+		assert.Equal(t, rendition.Width, stored.Bounds().Dx())
+		assert.Equal(t, rendition.Height, stored.Bounds().Dy())
+	}
+}
+
+// TestImagePipelineService_keepsTransparencyAndNeverUpscales verifies a small
+// transparent PNG stays a PNG and no variant is larger than the original.
+func TestImagePipelineService_keepsTransparencyAndNeverUpscales(t *testing.T) {
+	pipeline, _ := newImagePipeline(t, service.ImagePipelineConfig{})
+
+	manifest, err := pipeline.Process(context.Background(), bytes.NewReader(encodePNG(t, solidImage(100, 50, color.NRGBA{B: 255, A: 128}))), service.ImageOptions{
+		Namespace: "profiles",
+		Usage:     "avatar",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", manifest.Original.ContentType)
+	assert.Equal(t, ".png", filepath.Ext(manifest.Original.Key))
+	thumb, _ := manifest.Variant("thumb")
+	assert.Equal(t, [2]int{50, 50}, [2]int{thumb.Width, thumb.Height})
+	medium, _ := manifest.Variant("medium")
+	assert.Equal(t, [2]int{100, 50}, [2]int{medium.Width, medium.Height})
+	assert.Equal(t, ".png", filepath.Ext(medium.Key))
+}
+
+// TestImagePipelineService_appliesOrientationAndStripsExif verifies the
+// stored image is upright and carries none of the input's EXIF data.
+func TestImagePipelineService_appliesOrientationAndStripsExif(t *testing.T) {
+	pipeline, storage := newImagePipeline(t, service.ImagePipelineConfig{Variants: []storagedomain.ImageVariant{}})
+
+	// Left half red, right half blue, stored sideways: orientation 6 asks for
+	// a clockwise rotation, which brings the red half to the top.
+	src := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= 20 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			src.SetNRGBA(x, y, c)
+		}
+	}
+	input := withExifOrientation(encodeJPEG(t, src), 6)
+
+	manifest, err := pipeline.Process(context.Background(), bytes.NewReader(input), service.ImageOptions{
+		Namespace: "profiles",
+		Usage:     "photo",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 20, manifest.Original.Width)
+	assert.Equal(t, 40, manifest.Original.Height)
+	assert.Empty(t, manifest.Variants)
+
+	reader, _, err := storage.Get(context.Background(), manifest.Original.Key)
+	require.NoError(t, err)
+	defer reader.Close()
+	stored, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored), "Exif")
+	assert.NotContains(t, string(stored), "GPS-marker")
+
+	upright, err := jpeg.Decode(bytes.NewReader(stored))
+	require.NoError(t, err)
+	top := color.NRGBAModel.Convert(upright.At(10, 5)).(color.NRGBA)
+	bottom := color.NRGBAModel.Convert(upright.At(10, 35)).(color.NRGBA)
+	assert.Greater(t, top.R, uint8(200))
+	assert.Greater(t, bottom.B, uint8(200))
+}
+
+// TestImagePipelineService_rejectsUnsafeInput covers the inputs that must be
+// refused before anything is stored.
+func TestImagePipelineService_rejectsUnsafeInput(t *testing.T) {
+	pipeline, storage := newImagePipeline(t, service.ImagePipelineConfig{MaxInputBytes: 64 << 10})
+	validPNG := encodePNG(t, solidImage(16, 16, color.NRGBA{G: 255, A: 255}))
+	validJPEG := encodeJPEG(t, solidImage(16, 16, color.NRGBA{G: 255, A: 255}))
+
+	tests := map[string]struct {
+		input []byte
+		err   error
+	}{
+		"decompression-bomb": {input: withPNGSize(validPNG, 100_000, 100_000), err: storagedomain.ErrImageTooLarge},
+		"over-byte-limit":    {input: append(bytes.Clone(validPNG), make([]byte, 64<<10)...), err: storagedomain.ErrImageTooLarge},
+		"png-zip-polyglot":   {input: append(bytes.Clone(validPNG), []byte("PK\x03\x04payload")...), err: storagedomain.ErrUnsupportedImage},
+		"jpeg-php-polyglot":  {input: append(bytes.Clone(validJPEG), []byte("<?php system($_GET['c']); ?>")...), err: storagedomain.ErrUnsupportedImage},
+		"truncated-png":      {input: validPNG[:len(validPNG)/2], err: storagedomain.ErrUnsupportedImage},
+		"html":               {input: []byte("<html><script>alert(1)</script></html>"), err: storagedomain.ErrUnsupportedImage},
+		"magic-only":         {input: []byte("GIF89a\x10\x00\x10\x00<script>alert(1)</script>;"), err: storagedomain.ErrUnsupportedImage},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := pipeline.Process(context.Background(), bytes.NewReader(tt.input), service.ImageOptions{
+				Namespace: "profiles",
+				Usage:     "avatar",
+			})
+
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	page, err := storage.List(context.Background(), "profiles/", "", 100)
+	require.NoError(t, err)
+	assert.Empty(t, page.Objects)
+}
+
+// TestNewImagePipelineService_rejectsInvalidVariants keeps variants that
+// cannot be stored out of the configuration.
+func TestNewImagePipelineService_rejectsInvalidVariants(t *testing.T) {
+	_, storage := newImagePipeline(t, service.ImagePipelineConfig{})
+
+	tests := map[string][]storagedomain.ImageVariant{
+		"no-size":   {{Name: "thumb", MaxWidth: 256}},
+		"no-name":   {{MaxWidth: 256, MaxHeight: 256}},
+		"traversal": {{Name: "../thumb", MaxWidth: 256, MaxHeight: 256}},
+		"duplicate": {{Name: "thumb", MaxWidth: 256, MaxHeight: 256}, {Name: "thumb", MaxWidth: 64, MaxHeight: 64}},
+	}
+
+	for name, variants := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.NewImagePipelineService(storage, service.ImagePipelineConfig{Variants: variants})
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+// newImagePipeline builds a pipeline over a filesystem provider rooted in a
+// temporary directory.
+func newImagePipeline(t *testing.T, config service.ImagePipelineConfig) (*service.ImagePipelineService, storagedomain.StorageProvider) {
+	t.Helper()
+
+	base := t.TempDir()
+	t.Setenv("STORAGE_PUBLIC_ROOT", filepath.Join(base, "public"))
+	t.Setenv("STORAGE_PRIVATE_ROOT", filepath.Join(base, "private"))
+	t.Setenv("STORAGE_METADATA_ROOT", filepath.Join(base, "metadata"))
+	t.Setenv("STORAGE_PUBLIC_BASE_URL", "https://cdn.example.test/assets/")
+	t.Setenv("STORAGE_SIGNING_SECRET", "7365637265742d666f722d7465737473")
+	storage, err := storagedriver.NewFilesystemStorageProvider(envdriver.NewEnvProvider())
+	require.NoError(t, err)
+
+	pipeline, err := service.NewImagePipelineService(storage, config)
+	require.NoError(t, err)
+	return pipeline, storage
+}
+
+func solidImage(width, height int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func readImage(t *testing.T, storage storagedomain.StorageProvider, key string) image.Image {
+	t.Helper()
+
+	reader, _, err := storage.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer reader.Close()
+	img, _, err := image.Decode(reader)
+	require.NoError(t, err)
+	return img
+}
+
+// withExifOrientation inserts an APP1 Exif segment right after the JPEG SOI
+// marker, holding the orientation tag followed by a recognisable string
+// standing in for GPS data.
+func withExifOrientation(jpegData []byte, orientation uint16) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("MM\x00\x2a\x00\x00\x00\x08")
+	_ = binary.Write(&tiff, binary.BigEndian, []uint16{1, 0x0112, 3, 0, 1, orientation, 0})
+	_ = binary.Write(&tiff, binary.BigEndian, uint32(0))
+	tiff.WriteString("GPS-marker")
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, segment...)
+	out = append(out, payload...)
+	return append(out, jpegData[2:]...)
+}
+
+// withPNGSize rewrites the IHDR dimensions of a PNG and fixes its CRC, as a
+// decompression bomb declares a huge image in a tiny file.
+func withPNGSize(pngData []byte, width, height uint32) []byte {
+	out := bytes.Clone(pngData)
+	// The IHDR chunk follows the 8-byte signature: length, type, data, CRC.
+	binary.BigEndian.PutUint32(out[16:], width)
+	binary.BigEndian.PutUint32(out[20:], height)
+	binary.BigEndian.PutUint32(out[29:], crc32.ChecksumIEEE(out[12:29]))
+	return out
+}